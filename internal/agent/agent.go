@@ -14,14 +14,17 @@ import (
 	"github.com/yaydraco/tandem/internal/models"
 	"github.com/yaydraco/tandem/internal/provider"
 	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/redact"
 	"github.com/yaydraco/tandem/internal/session"
 	"github.com/yaydraco/tandem/internal/tools"
+	"github.com/yaydraco/tandem/internal/usage"
 )
 
 // Common errors
 var (
 	ErrRequestCancelled = errors.New("request cancelled by user")
 	ErrSessionBusy      = errors.New("session is currently processing another request")
+	ErrNothingToResume  = errors.New("the last turn of the session is complete, there is nothing to resume")
 )
 
 type AgentEventType string
@@ -47,6 +50,13 @@ type Service interface {
 	pubsub.Subscriber[AgentEvent]
 	Model() models.Model
 	Run(ctx context.Context, sessionID string, content string, attachments ...message.Attachment) (<-chan AgentEvent, error)
+	// Resume runs the agent over the messages of the session as they're stored, without a new prompt, to carry on a
+	// turn which was cancelled or failed from where it stopped.
+	Resume(ctx context.Context, sessionID string) (<-chan AgentEvent, error)
+	// Regenerate deletes the message of the session and the ones after it, if any, and resumes the turn from the
+	// messages left. The turn is generated with the model given, without changing the model of the agent, or with the
+	// latter if it's empty. The messages are deleted only once the turn is started, for them to be kept if it can't be.
+	Regenerate(ctx context.Context, sessionID, fromMessageID string, modelID models.ModelID) (<-chan AgentEvent, error)
 	Cancel(sessionID string)
 	IsSessionBusy(sessionID string) bool
 	IsBusy() bool
@@ -56,11 +66,23 @@ type Service interface {
 
 type agent struct {
 	*pubsub.Broker[AgentEvent]
+	name     config.AgentName
 	sessions session.Service
 	messages message.Service
+	usage    usage.Service
 
-	tools    []tools.BaseTool
-	provider provider.Provider
+	tools []tools.BaseTool
+	// memories is the part of the system prompt holding the memories of the engagement relevant to the task.
+	memories string
+
+	// provider is the provider of the model in use, one of the fallbacks after a fail over. It's guarded by providerMu,
+	// along with the fallbacks left and the time of the fail over, the agent going back to its model once it's old enough.
+	provider   provider.Provider
+	primary    provider.Provider
+	fallbacks  []provider.Provider
+	chain      []provider.Provider
+	failedOver time.Time
+	providerMu sync.Mutex
 
 	titleProvider     provider.Provider
 	summarizeProvider provider.Provider
@@ -69,13 +91,55 @@ type agent struct {
 }
 
 func (a *agent) Model() models.Model {
-	return a.provider.Model()
+	return a.currentProvider().Model()
+}
+
+// currentProvider returns the provider of the model in use.
+func (a *agent) currentProvider() provider.Provider {
+	a.providerMu.Lock()
+	defer a.providerMu.Unlock()
+	return a.provider
 }
 
 func (a *agent) Run(ctx context.Context, sessionID string, content string, attachments ...message.Attachment) (<-chan AgentEvent, error) {
-	if !a.provider.Model().SupportsAttachments && attachments != nil {
+	if !a.Model().SupportsAttachments && attachments != nil {
 		attachments = nil
 	}
+	var attachmentParts []message.ContentPart
+	for _, attachment := range attachments {
+		attachmentParts = append(attachmentParts, message.BinaryContent{Path: attachment.FilePath, MIMEType: attachment.MimeType, Data: attachment.Content})
+	}
+	return a.start(ctx, sessionID, func(genCtx context.Context) AgentEvent {
+		return a.processGeneration(genCtx, sessionID, content, attachmentParts)
+	})
+}
+
+func (a *agent) Resume(ctx context.Context, sessionID string) (<-chan AgentEvent, error) {
+	return a.start(ctx, sessionID, func(genCtx context.Context) AgentEvent {
+		return a.processResume(genCtx, sessionID, nil)
+	})
+}
+
+func (a *agent) Regenerate(ctx context.Context, sessionID, fromMessageID string, modelID models.ModelID) (<-chan AgentEvent, error) {
+	var p provider.Provider
+	if modelID != "" {
+		var err error
+		if p, err = createModelProvider(a.name, modelID, nil, a.memories); err != nil {
+			return nil, fmt.Errorf("failed to create provider for model %s: %w", modelID, err)
+		}
+	}
+	return a.start(ctx, sessionID, func(genCtx context.Context) AgentEvent {
+		if fromMessageID != "" {
+			if err := a.messages.DeleteFrom(genCtx, sessionID, fromMessageID); err != nil {
+				return a.err(fmt.Errorf("failed to delete the messages to regenerate: %w", err))
+			}
+		}
+		return a.processResume(genCtx, sessionID, p)
+	})
+}
+
+// start runs the generation in the background, the session being busy until it's done.
+func (a *agent) start(ctx context.Context, sessionID string, generate func(ctx context.Context) AgentEvent) (<-chan AgentEvent, error) {
 	events := make(chan AgentEvent)
 	if a.IsSessionBusy(sessionID) {
 		return nil, ErrSessionBusy
@@ -89,11 +153,7 @@ func (a *agent) Run(ctx context.Context, sessionID string, content string, attac
 		defer logging.RecoverPanic("agent.Run", func() {
 			events <- a.err(fmt.Errorf("panic while running the agent"))
 		})
-		var attachmentParts []message.ContentPart
-		for _, attachment := range attachments {
-			attachmentParts = append(attachmentParts, message.BinaryContent{Path: attachment.FilePath, MIMEType: attachment.MimeType, Data: attachment.Content})
-		}
-		result := a.processGeneration(genCtx, sessionID, content, attachmentParts)
+		result := generate(genCtx)
 		if result.Error != nil && !errors.Is(result.Error, ErrRequestCancelled) && !errors.Is(result.Error, context.Canceled) {
 			logging.ErrorPersist(result.Error.Error())
 		}
@@ -108,7 +168,6 @@ func (a *agent) Run(ctx context.Context, sessionID string, content string, attac
 }
 
 func (a *agent) processGeneration(ctx context.Context, sessionID, content string, attachmentParts []message.ContentPart) AgentEvent {
-	cfg := config.Get()
 	// List existing messages; if none, start title generation asynchronously.
 	msgs, err := a.messages.List(ctx, sessionID)
 	if err != nil {
@@ -125,9 +184,83 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			}
 		}()
 	}
+	msgs, err = a.sinceSummary(ctx, sessionID, msgs)
+	if err != nil {
+		return a.err(err)
+	}
+
+	if err := a.checkBudget(ctx, sessionID); err != nil {
+		return a.err(err)
+	}
+
+	userMsg, err := a.createUserMessage(ctx, sessionID, content, attachmentParts)
+	if err != nil {
+		return a.err(fmt.Errorf("failed to create user message: %w", err))
+	}
+	// Append the new user message to the conversation history.
+	return a.generate(ctx, sessionID, append(msgs, userMsg), nil)
+}
+
+func (a *agent) processResume(ctx context.Context, sessionID string, p provider.Provider) AgentEvent {
+	msgs, err := a.messages.List(ctx, sessionID)
+	if err != nil {
+		return a.err(fmt.Errorf("failed to list messages: %w", err))
+	}
+	// NOTE: the step the turn stopped at is generated anew, along with the results of its tool calls if they were
+	// cancelled, the steps before it are kept.
+	last := len(msgs) - 1
+	for last >= 0 && msgs[last].Role == message.System {
+		last--
+	}
+	if last < 0 {
+		return a.err(fmt.Errorf("no messages to resume"))
+	}
+	from := last
+	switch msg := msgs[last]; msg.Role {
+	case message.Assistant:
+		if msg.FinishReason() == message.FinishReasonEndTurn && len(msg.ToolCalls()) == 0 {
+			return a.err(ErrNothingToResume)
+		}
+	case message.Tool:
+		if last == 0 || !stopped(msgs[last-1]) {
+			from = last + 1
+			break
+		}
+		from = last - 1
+	default:
+		from = last + 1
+	}
+	for _, msg := range msgs[from : last+1] {
+		if err := a.messages.Delete(ctx, msg.ID); err != nil {
+			return a.err(fmt.Errorf("failed to delete the interrupted message: %w", err))
+		}
+	}
+	msgs = msgs[:from]
+
+	msgs, err = a.sinceSummary(ctx, sessionID, msgs)
+	if err != nil {
+		return a.err(err)
+	}
+	if err := a.checkBudget(ctx, sessionID); err != nil {
+		return a.err(err)
+	}
+	if p != nil {
+		msgs = convertHistory(msgs, p.Model())
+	}
+	return a.generate(ctx, sessionID, msgs, p)
+}
+
+// stopped reports whether the agent was stopped generating the message, cancelled or failing.
+func stopped(msg message.Message) bool {
+	return msg.Role == message.Assistant &&
+		(msg.FinishReason() == message.FinishReasonCanceled || msg.FinishReason() == message.FinishReasonError)
+}
+
+// sinceSummary returns the messages from the summary of the session on, if it was summarized.
+func (a *agent) sinceSummary(ctx context.Context, sessionID string, msgs []message.Message) ([]message.Message, error) {
 	session, err := a.sessions.Get(ctx, sessionID)
 	if err != nil {
-		return a.err(fmt.Errorf("failed to get session: %w", err))
+		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 	if session.SummaryMessageID != "" {
 		summaryMsgInex := -1
@@ -142,14 +275,14 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			msgs[0].Role = message.User
 		}
 	}
+	return msgs, nil
+}
 
-	userMsg, err := a.createUserMessage(ctx, sessionID, content, attachmentParts)
-	if err != nil {
-		return a.err(fmt.Errorf("failed to create user message: %w", err))
-	}
-	// Append the new user message to the conversation history.
-	msgHistory := append(msgs, userMsg)
-
+// generate runs the turn of the agent over the conversation history, until it responds without calling tools. The
+// turn is generated by the provider given if any, which doesn't fail over, or else by the one of the agent.
+func (a *agent) generate(ctx context.Context, sessionID string, msgHistory []message.Message, p provider.Provider) AgentEvent {
+	cfg := config.Get()
+	a.recover()
 	for {
 		// Check for cancellation before each iteration
 		select {
@@ -158,13 +291,21 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		default:
 			// Continue processing
 		}
-		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, msgHistory)
+		current := p
+		if current == nil {
+			current = a.currentProvider()
+		}
+		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, current, msgHistory)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				agentMessage.AddFinish(message.FinishReasonCanceled)
 				a.messages.Update(context.Background(), agentMessage)
 				return a.err(ErrRequestCancelled)
 			}
+			if p == nil && a.fallback(ctx, sessionID, agentMessage, err) {
+				msgHistory = convertHistory(msgHistory, a.Model())
+				continue
+			}
 			return a.err(fmt.Errorf("failed to process events: %w", err))
 		}
 		if cfg.Debug {
@@ -176,7 +317,14 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		}
 		if (agentMessage.FinishReason() == message.FinishReasonToolUse) && toolResults != nil {
 			// We are not done, we need to respond with the tool response
+			// NOTE: the message is reloaded as persisted, for the secrets stored in the vault along the way not to be sent back.
+			if persisted, err := a.messages.Get(ctx, agentMessage.ID); err == nil {
+				agentMessage = persisted
+			}
 			msgHistory = append(msgHistory, agentMessage, *toolResults)
+			if err := a.checkBudget(ctx, sessionID); err != nil {
+				return a.err(err)
+			}
 			continue
 		}
 		return AgentEvent{
@@ -233,14 +381,14 @@ func (a *agent) Update(agentName config.AgentName, modelID models.ModelID) (mode
 		return models.Model{}, fmt.Errorf("failed to update config: %w", err)
 	}
 
-	provider, err := createAgentProvider(agentName, nil)
+	provider, err := createAgentProvider(agentName, nil, a.memories)
 	if err != nil {
 		return models.Model{}, fmt.Errorf("failed to create provider for model %s: %w", modelID, err)
 	}
 
-	a.provider = provider
+	a.setProviders(provider, createFallbackProviders(agentName, nil, a.memories))
 
-	return a.provider.Model(), nil
+	return provider.Model(), nil
 }
 
 func (a *agent) Summarize(ctx context.Context, sessionID string) error {
@@ -384,15 +532,8 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			return
 		}
 		oldSession.SummaryMessageID = msg.ID
-		oldSession.CompletionTokens = response.Usage.OutputTokens
-		oldSession.PromptTokens = 0
-		model := a.summarizeProvider.Model()
-		usage := response.Usage
-		cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-			model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-			model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-			model.CostPer1MOut/1e6*float64(usage.OutputTokens)
-		oldSession.Cost += cost
+		// NOTE: the summary is all that remains of the context window.
+		oldSession.ContextTokens = response.Usage.OutputTokens
 		_, err = a.sessions.Save(summarizeCtx, oldSession)
 		if err != nil {
 			event = AgentEvent{
@@ -402,6 +543,14 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			}
 			a.Publish(pubsub.CreatedEvent, event)
 		}
+		if err := a.TrackUsage(summarizeCtx, oldSession.ID, config.AgentSummarizer, usage.KindSummarize, a.summarizeProvider.Model(), response.Usage); err != nil {
+			event = AgentEvent{
+				Type:  AgentEventTypeError,
+				Error: err,
+				Done:  true,
+			}
+			a.Publish(pubsub.CreatedEvent, event)
+		}
 
 		event = AgentEvent{
 			Type:      AgentEventTypeSummarize,
@@ -430,10 +579,6 @@ func (a *agent) generateTitle(ctx context.Context, sessionID string, content str
 	if a.titleProvider == nil {
 		return nil
 	}
-	session, err := a.sessions.Get(ctx, sessionID)
-	if err != nil {
-		return err
-	}
 	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
 	parts := []message.ContentPart{message.TextContent{Text: content}}
 	response, err := a.titleProvider.SendMessages(
@@ -450,11 +595,20 @@ func (a *agent) generateTitle(ctx context.Context, sessionID string, content str
 		return err
 	}
 
+	if err := a.TrackUsage(ctx, sessionID, config.AgentTitle, usage.KindTitle, a.titleProvider.Model(), response.Usage); err != nil {
+		return err
+	}
+
 	title := strings.TrimSpace(strings.ReplaceAll(response.Content, "\n", " "))
 	if title == "" {
 		return nil
 	}
 
+	// NOTE: getting the session only after the provider call so that the usage tracked meanwhile isn't overwritten.
+	session, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
 	session.Title = title
 	_, err = a.sessions.Save(ctx, session)
 	return err
@@ -469,14 +623,14 @@ func (a *agent) createUserMessage(ctx context.Context, sessionID, content string
 	})
 }
 
-func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message) (message.Message, *message.Message, error) {
+func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, p provider.Provider, msgHistory []message.Message) (message.Message, *message.Message, error) {
 	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
-	eventChan := a.provider.StreamResponse(ctx, msgHistory, a.tools)
+	eventChan := p.StreamResponse(ctx, msgHistory, a.tools)
 
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
 		Role:  message.Assistant,
 		Parts: []message.ContentPart{},
-		Model: a.provider.Model().ID,
+		Model: p.Model().ID,
 	})
 	if err != nil {
 		return assistantMsg, nil, fmt.Errorf("failed to create assistant message: %w", err)
@@ -487,8 +641,12 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 
 	// Process each event in the stream.
 	for event := range eventChan {
-		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event); processErr != nil {
-			a.finishMessage(ctx, &assistantMsg, message.FinishReasonCanceled)
+		if processErr := a.processEvent(ctx, sessionID, p.Model(), &assistantMsg, event); processErr != nil {
+			reason := message.FinishReasonError
+			if errors.Is(processErr, context.Canceled) {
+				reason = message.FinishReasonCanceled
+			}
+			a.finishMessage(context.Background(), &assistantMsg, reason)
 			return assistantMsg, nil, processErr
 		}
 		if ctx.Err() != nil {
@@ -539,11 +697,21 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 				}
 				continue
 			}
+			// NOTE: the agents use the credentials of the vault by their placeholders, the tools get their secrets.
+			input, err := redact.RestoreJSON(toolCall.Input)
+			if err != nil {
+				toolResults[i] = message.ToolResult{
+					ToolCallID: toolCall.ID,
+					Content:    err.Error(),
+					IsError:    true,
+				}
+				continue
+			}
 			// toolResult, toolErr := tool.Run(ctx, tools.ToolCall{
 			toolResult, _ := tool.Run(ctx, tools.ToolCall{
 				ID:    toolCall.ID,
 				Name:  toolCall.Name,
-				Input: toolCall.Input,
+				Input: input,
 			})
 
 			// TODO: Figure out how to finish message when tool execution fails. earlier we were appending the finish message only when its of the type permission denied.
@@ -576,16 +744,38 @@ out:
 	return assistantMsg, &msg, err
 }
 
-func createAgentProvider(agentName config.AgentName, expectedOutput map[string]any) (provider.Provider, error) {
+// createAgentProvider creates the provider of the agent's model, the memories appended to its system prompt if any.
+func createAgentProvider(agentName config.AgentName, expectedOutput map[string]any, memories string) (provider.Provider, error) {
+	agentConfig, ok := config.Get().Agents[agentName]
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", agentName)
+	}
+	return createModelProvider(agentName, agentConfig.Model, expectedOutput, memories)
+}
 
+// createFallbackProviders creates the providers of the agent's fallback models, skipping the ones which can't be created.
+func createFallbackProviders(agentName config.AgentName, expectedOutput map[string]any, memories string) []provider.Provider {
+	var fallbacks []provider.Provider
+	for _, modelID := range config.Get().Agents[agentName].Fallbacks {
+		fallback, err := createModelProvider(agentName, modelID, expectedOutput, memories)
+		if err != nil {
+			logging.Warn("failed to create fallback provider", "agent", agentName, "model", modelID, "error", err)
+			continue
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+	return fallbacks
+}
+
+func createModelProvider(agentName config.AgentName, modelID models.ModelID, expectedOutput map[string]any, memories string) (provider.Provider, error) {
 	cfg := config.Get()
 	agentConfig, ok := cfg.Agents[agentName]
 	if !ok {
 		return nil, fmt.Errorf("agent %s not found", agentName)
 	}
-	model, ok := models.SupportedModels[agentConfig.Model]
+	model, ok := models.SupportedModels[modelID]
 	if !ok {
-		return nil, fmt.Errorf("model %s not supported", agentConfig.Model)
+		return nil, fmt.Errorf("model %s not supported", modelID)
 	}
 
 	providerCfg, ok := cfg.Providers[model.Provider]
@@ -596,11 +786,14 @@ func createAgentProvider(agentName config.AgentName, expectedOutput map[string]a
 		return nil, fmt.Errorf("provider %s is not enabled", model.Provider)
 	}
 	maxTokens := model.DefaultMaxTokens
-	if agentConfig.MaxTokens > 0 {
+	if agentConfig.MaxTokens > 0 && modelID == agentConfig.Model {
 		maxTokens = agentConfig.MaxTokens
 	}
 
 	systemMessage := config.GetAgentPrompt(agentName, model.Provider)
+	if memories != "" {
+		systemMessage += "\n\n" + memories
+	}
 	opts := []provider.ProviderClientOption{
 		provider.WithAPIKey(providerCfg.APIKey),
 		provider.WithModel(model),
@@ -657,7 +850,7 @@ func createAgentProvider(agentName config.AgentName, expectedOutput map[string]a
 	return agentProvider, nil
 }
 
-func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent) error {
+func (a *agent) processEvent(ctx context.Context, sessionID string, model models.Model, assistantMsg *message.Message, event provider.ProviderEvent) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -700,7 +893,7 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		if err := a.messages.Update(ctx, *assistantMsg); err != nil {
 			return fmt.Errorf("failed to update message: %w", err)
 		}
-		return a.TrackUsage(ctx, sessionID, a.provider.Model(), event.Response.Usage)
+		return a.TrackUsage(ctx, sessionID, a.name, usage.KindCompletion, model, event.Response.Usage)
 	}
 
 	return nil
@@ -711,24 +904,46 @@ func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishR
 	_ = a.messages.Update(ctx, *msg)
 }
 
-func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.Model, usage provider.TokenUsage) error {
-	sess, err := a.sessions.Get(ctx, sessionID)
+// TrackUsage records the provider call in the usage ledger and recomputes the totals of the session and its parents from it.
+func (a *agent) TrackUsage(ctx context.Context, sessionID string, agentName config.AgentName, kind usage.Kind, model models.Model, tokenUsage provider.TokenUsage) error {
+	_, messageID := tools.GetContextValues(ctx)
+	_, err := a.usage.Record(ctx, usage.CreateUsageParams{
+		SessionID:           sessionID,
+		MessageID:           messageID,
+		Model:               model,
+		Agent:               string(agentName),
+		Kind:                kind,
+		InputTokens:         tokenUsage.InputTokens,
+		OutputTokens:        tokenUsage.OutputTokens,
+		CacheCreationTokens: tokenUsage.CacheCreationTokens,
+		CacheReadTokens:     tokenUsage.CacheReadTokens,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get session: %w", err)
+		return fmt.Errorf("failed to record usage: %w", err)
 	}
 
-	cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
-
-	sess.Cost += cost
-	sess.CompletionTokens = usage.OutputTokens + usage.CacheReadTokens
-	sess.PromptTokens = usage.InputTokens + usage.CacheCreationTokens
+	var contextTokens *int64
+	if kind == usage.KindCompletion {
+		tokens := tokenUsage.InputTokens + tokenUsage.CacheCreationTokens + tokenUsage.CacheReadTokens + tokenUsage.OutputTokens
+		contextTokens = &tokens
+	}
 
-	_, err = a.sessions.Save(ctx, sess)
-	if err != nil {
-		return fmt.Errorf("failed to save session: %w", err)
+	// NOTE: rolling up the totals as they are spent so that the parent sessions reflect the subagents while they are still running.
+	for id := sessionID; id != ""; contextTokens = nil {
+		totals, err := a.usage.Totals(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to compute usage totals: %w", err)
+		}
+		sess, err := a.sessions.UpdateUsage(ctx, id, session.UpdateUsageParams{
+			PromptTokens:     totals.PromptTokens,
+			CompletionTokens: totals.CompletionTokens,
+			Cost:             totals.Cost,
+			ContextTokens:    contextTokens,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		id = sess.ParentSessionID
 	}
 	return nil
 }
@@ -737,10 +952,12 @@ func NewAgent(
 	agentName config.AgentName,
 	sessions session.Service,
 	messages message.Service,
+	usages usage.Service,
 	agentTools []tools.BaseTool,
 	expectedOutput map[string]any,
+	memories string,
 ) (Service, error) {
-	agentProvider, err := createAgentProvider(agentName, expectedOutput)
+	agentProvider, err := createAgentProvider(agentName, expectedOutput, memories)
 	if err != nil {
 		return nil, err
 	}
@@ -750,7 +967,7 @@ func NewAgent(
 	// Only generate titles and summaries for the orchestrator agent
 	if agentName == config.Orchestrator {
 		// NOTE: we can use the expected output schema in here as well.
-		titleProvider, err = createAgentProvider(config.AgentTitle, nil)
+		titleProvider, err = createAgentProvider(config.AgentTitle, nil, "")
 		if err != nil {
 			return nil, err
 		}
@@ -759,7 +976,7 @@ func NewAgent(
 	var summarizeProvider provider.Provider
 	if agentName == config.Orchestrator {
 		// NOTE: we can use the expected output schema in here as well.
-		summarizeProvider, err = createAgentProvider(config.AgentSummarizer, nil)
+		summarizeProvider, err = createAgentProvider(config.AgentSummarizer, nil, "")
 		if err != nil {
 			return nil, err
 		}
@@ -767,14 +984,17 @@ func NewAgent(
 
 	agent := &agent{
 		Broker:            pubsub.NewBroker[AgentEvent](),
-		provider:          agentProvider,
+		name:              agentName,
+		memories:          memories,
 		messages:          messages,
 		sessions:          sessions,
+		usage:             usages,
 		tools:             agentTools,
 		titleProvider:     titleProvider,
 		summarizeProvider: summarizeProvider,
 		activeRequests:    sync.Map{},
 	}
+	agent.setProviders(agentProvider, createFallbackProviders(agentName, expectedOutput, memories))
 
 	return agent, nil
 }
@@ -0,0 +1,306 @@
+package agent
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/provider"
+	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/session"
+	"github.com/yaydraco/tandem/internal/tools"
+	"github.com/yaydraco/tandem/internal/usage"
+)
+
+// fakeMessages keeps the messages of the sessions in memory, in the order they're created.
+type fakeMessages struct {
+	message.Service
+	mu       sync.Mutex
+	messages []message.Message
+	created  int
+}
+
+func (s *fakeMessages) Create(ctx context.Context, sessionID string, params message.CreateMessageParams) (message.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created++
+	msg := message.Message{
+		ID:        fmt.Sprintf("created-%d", s.created),
+		SessionID: sessionID,
+		Role:      params.Role,
+		Parts:     params.Parts,
+		Model:     params.Model,
+	}
+	s.messages = append(s.messages, msg)
+	return msg, nil
+}
+
+func (s *fakeMessages) Update(ctx context.Context, msg message.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.messages, func(m message.Message) bool { return m.ID == msg.ID })
+	if i == -1 {
+		return sql.ErrNoRows
+	}
+	s.messages[i] = msg
+	return nil
+}
+
+func (s *fakeMessages) Get(ctx context.Context, id string) (message.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.messages, func(m message.Message) bool { return m.ID == id })
+	if i == -1 {
+		return message.Message{}, sql.ErrNoRows
+	}
+	return s.messages[i], nil
+}
+
+func (s *fakeMessages) List(ctx context.Context, sessionID string) ([]message.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []message.Message
+	for _, m := range s.messages {
+		if m.SessionID == sessionID {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func (s *fakeMessages) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = slices.DeleteFunc(s.messages, func(m message.Message) bool { return m.ID == id })
+	return nil
+}
+
+func (s *fakeMessages) DeleteFrom(ctx context.Context, sessionID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.messages, func(m message.Message) bool { return m.ID == id })
+	if i == -1 {
+		return sql.ErrNoRows
+	}
+	var kept []message.Message
+	for j, m := range s.messages {
+		if j < i || m.SessionID != sessionID {
+			kept = append(kept, m)
+		}
+	}
+	s.messages = kept
+	return nil
+}
+
+// ids returns the IDs of the messages stored.
+func (s *fakeMessages) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, m := range s.messages {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+// fakeSessions returns sessions without parents, budgets or summaries.
+type fakeSessions struct {
+	session.Service
+}
+
+func (s *fakeSessions) Get(ctx context.Context, id string) (session.Session, error) {
+	return session.Session{ID: id}, nil
+}
+
+func (s *fakeSessions) UpdateUsage(ctx context.Context, id string, params session.UpdateUsageParams) (session.Session, error) {
+	return session.Session{ID: id}, nil
+}
+
+// fakeUsage drops the provider calls recorded, the engagement having spent the cost given.
+type fakeUsage struct {
+	usage.Service
+	engagementCost float64
+}
+
+func (s *fakeUsage) Record(ctx context.Context, params usage.CreateUsageParams) (usage.Usage, error) {
+	return usage.Usage{}, nil
+}
+
+func (s *fakeUsage) Totals(ctx context.Context, sessionID string) (usage.Totals, error) {
+	return usage.Totals{}, nil
+}
+
+func (s *fakeUsage) EngagementTotals(ctx context.Context) (usage.Totals, error) {
+	return usage.Totals{Cost: s.engagementCost}, nil
+}
+
+// fakeProvider responds with the text given, or fails with the error given. The requests wait for each other at the
+// barrier if any, before failing.
+type fakeProvider struct {
+	provider.Provider
+	model   models.Model
+	text    string
+	err     error
+	barrier *sync.WaitGroup
+}
+
+func (p *fakeProvider) Model() models.Model {
+	return p.model
+}
+
+func (p *fakeProvider) StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool, options ...provider.GenerateContentConfigOption) <-chan provider.ProviderEvent {
+	events := make(chan provider.ProviderEvent, 2)
+	if p.err != nil {
+		if p.barrier != nil {
+			p.barrier.Done()
+			p.barrier.Wait()
+		}
+		events <- provider.ProviderEvent{Type: provider.EventError, Error: p.err}
+	} else {
+		events <- provider.ProviderEvent{Type: provider.EventContentDelta, Content: p.text}
+		events <- provider.ProviderEvent{Type: provider.EventComplete, Response: &provider.ProviderResponse{Content: p.text, FinishReason: message.FinishReasonEndTurn}}
+	}
+	close(events)
+	return events
+}
+
+// loadTestConfig loads the default configuration, without any config file.
+func loadTestConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load() failed: %v", err)
+	}
+}
+
+func newTestAgent(messages message.Service, p provider.Provider, fallbacks ...provider.Provider) *agent {
+	a := &agent{
+		Broker:   pubsub.NewBroker[AgentEvent](),
+		name:     config.Orchestrator,
+		messages: messages,
+		sessions: &fakeSessions{},
+		usage:    &fakeUsage{},
+	}
+	a.setProviders(p, fallbacks)
+	return a
+}
+
+func TestRegenerate(t *testing.T) {
+	loadTestConfig(t)
+
+	tests := []struct {
+		name string
+		// busy makes the session busy with another turn.
+		busy        bool
+		from        string
+		modelID     models.ModelID
+		expectedErr bool
+		expectedIDs []string
+	}{
+		{
+			name:        "response regenerated",
+			from:        "m2",
+			expectedIDs: []string{"m1", "created-1"},
+		},
+		{
+			name:        "unsupported model",
+			from:        "m2",
+			modelID:     "unknown-model",
+			expectedErr: true,
+			expectedIDs: []string{"m1", "m2", "m3", "m4"},
+		},
+		{
+			name:        "busy session",
+			busy:        true,
+			from:        "m2",
+			expectedErr: true,
+			expectedIDs: []string{"m1", "m2", "m3", "m4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := &fakeMessages{messages: []message.Message{
+				{ID: "m1", SessionID: "s1", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "scan 10.0.0.5"}}},
+				{ID: "m2", SessionID: "s1", Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "done"}, message.Finish{Reason: message.FinishReasonEndTurn}}},
+				{ID: "m3", SessionID: "s1", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "and 10.0.0.6"}}},
+				{ID: "m4", SessionID: "s1", Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "done too"}, message.Finish{Reason: message.FinishReasonEndTurn}}},
+			}}
+			a := newTestAgent(messages, &fakeProvider{model: models.Model{ID: models.Claude4Sonnet}, text: "regenerated"})
+			if tt.busy {
+				a.activeRequests.Store("s1", context.CancelFunc(func() {}))
+			}
+
+			events, err := a.Regenerate(context.Background(), "s1", tt.from, tt.modelID)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("Regenerate() succeeded, expected an error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Regenerate() failed: %v", err)
+				}
+				if event := <-events; event.Error != nil {
+					t.Fatalf("Regenerate() failed: %v", event.Error)
+				}
+			}
+			if ids := messages.ids(); !slices.Equal(ids, tt.expectedIDs) {
+				t.Errorf("messages = %v, expected %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	loadTestConfig(t)
+
+	tests := []struct {
+		name        string
+		last        message.Message
+		expectedErr error
+		expectedIDs []string
+	}{
+		{
+			name:        "cancelled response",
+			last:        message.Message{ID: "m2", SessionID: "s1", Role: message.Assistant, Parts: []message.ContentPart{message.Finish{Reason: message.FinishReasonCanceled}}},
+			expectedIDs: []string{"m1", "created-1"},
+		},
+		{
+			name:        "prompt without response",
+			last:        message.Message{ID: "m2", SessionID: "s1", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "and 10.0.0.6"}}},
+			expectedIDs: []string{"m1", "m2", "created-1"},
+		},
+		{
+			name:        "complete turn",
+			last:        message.Message{ID: "m2", SessionID: "s1", Role: message.Assistant, Parts: []message.ContentPart{message.Finish{Reason: message.FinishReasonEndTurn}}},
+			expectedErr: ErrNothingToResume,
+			expectedIDs: []string{"m1", "m2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := &fakeMessages{messages: []message.Message{
+				{ID: "m1", SessionID: "s1", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "scan 10.0.0.5"}}},
+				tt.last,
+			}}
+			a := newTestAgent(messages, &fakeProvider{model: models.Model{ID: models.Claude4Sonnet}, text: "resumed"})
+
+			events, err := a.Resume(context.Background(), "s1")
+			if err != nil {
+				t.Fatalf("Resume() failed: %v", err)
+			}
+			if event := <-events; !errors.Is(event.Error, tt.expectedErr) {
+				t.Fatalf("Resume() error = %v, expected %v", event.Error, tt.expectedErr)
+			}
+			if ids := messages.ids(); !slices.Equal(ids, tt.expectedIDs) {
+				t.Errorf("messages = %v, expected %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+}
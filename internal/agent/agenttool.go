@@ -3,16 +3,28 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/yaydraco/tandem/internal/artifact"
+	"github.com/yaydraco/tandem/internal/finding"
+	"github.com/yaydraco/tandem/internal/inventory"
+	"github.com/yaydraco/tandem/internal/memory"
 	"github.com/yaydraco/tandem/internal/config"
 	"github.com/yaydraco/tandem/internal/message"
 	"github.com/yaydraco/tandem/internal/session"
 	"github.com/yaydraco/tandem/internal/tools"
+	"github.com/yaydraco/tandem/internal/usage"
+	"github.com/yaydraco/tandem/internal/vault"
+	"github.com/yaydraco/tandem/internal/vulndb"
 )
 
 const AgentToolName = "agent_tool"
 
+// relevantMemories is the number of memories of the engagement given to the agent of a task.
+const relevantMemories = 30
+
 var AgentNames = []string{
 	string(config.Reconnoiter),
 	string(config.VulnerabilityScanner),
@@ -27,8 +39,15 @@ type AgentToolArgs struct {
 }
 
 type AgentTool struct {
-	messages message.Service
-	sessions session.Service
+	messages        message.Service
+	sessions        session.Service
+	usages          usage.Service
+	artifacts       artifact.Service
+	inventory       inventory.Service
+	findings        finding.Service
+	vulnerabilities vulndb.Service
+	vault           vault.Service
+	memories        memory.Service
 }
 
 func (a *AgentTool) Info() tools.ToolInfo {
@@ -75,8 +94,25 @@ func (a *AgentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	}
 
 	// NOTE: you can add more tools later here if needed on AgentName basis.
-	agentTools := tools.PenetrationTestingAgentTools
-	agent, err := NewAgent(args.AgentName, a.sessions, a.messages, agentTools, args.ExpectedOutput)
+	agentTools := append(slices.Clone(tools.PenetrationTestingAgentTools),
+		tools.NewDownloadArtifactTool(a.artifacts),
+		tools.NewNmapScanTool(a.inventory),
+		tools.NewContentDiscoveryTool(a.inventory),
+		tools.NewListFindingsTool(a.findings),
+		tools.NewVaultTool(a.vault, a.sessions, a.messages),
+		tools.NewSearchHistoryTool(a.messages),
+		tools.NewMemoryTool(a.memories, string(args.AgentName)),
+	)
+	agentTools = append(agentTools, tools.NewVulnerabilityScanTools(a.inventory, a.findings)...)
+	if args.AgentName == config.VulnerabilityScanner || args.AgentName == config.Exploiter {
+		agentTools = append(agentTools, tools.NewVulnLookupTool(a.vulnerabilities))
+	}
+	// NOTE: the agent starts off knowing what the swarm learned so far, the memories relevant to its task first.
+	memories, err := a.memories.Relevant(ctx, args.Prompt, relevantMemories)
+	if err != nil {
+		return tools.ToolResponse{}, fmt.Errorf("error recalling the memories: %s", err)
+	}
+	agent, err := NewAgent(args.AgentName, a.sessions, a.messages, a.usages, agentTools, args.ExpectedOutput, memory.Prompt(memories))
 	if err != nil {
 		return tools.NewTextErrorResponse("failed to create agent: " + err.Error()), nil
 	}
@@ -92,6 +128,9 @@ func (a *AgentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	}
 	result := <-done
 	if result.Error != nil {
+		if errors.Is(result.Error, ErrBudgetExceeded) {
+			return tools.NewTextErrorResponse(result.Error.Error()), nil
+		}
 		return tools.ToolResponse{}, fmt.Errorf("error generating agent: %s", result.Error)
 	}
 
@@ -100,21 +139,7 @@ func (a *AgentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 		return tools.NewTextErrorResponse("no response"), nil
 	}
 
-	updatedSession, err := a.sessions.Get(ctx, session.ID)
-	if err != nil {
-		return tools.ToolResponse{}, fmt.Errorf("error getting session: %s", err)
-	}
-	parentSession, err := a.sessions.Get(ctx, sessionID)
-	if err != nil {
-		return tools.ToolResponse{}, fmt.Errorf("error getting parent session: %s", err)
-	}
-
-	parentSession.Cost += updatedSession.Cost
-
-	_, err = a.sessions.Save(ctx, parentSession)
-	if err != nil {
-		return tools.ToolResponse{}, fmt.Errorf("error saving parent session: %s", err)
-	}
+	// NOTE: the cost of the task session is rolled up into the parent session by TrackUsage as it is spent.
 	return tools.NewTextResponse(response.Content().String()), nil
 }
 
@@ -125,9 +150,23 @@ NOTE: we havent passed the expected output schema to the agent yet.
 func NewAgentTool(
 	Sessions session.Service,
 	Messages message.Service,
+	Usages usage.Service,
+	Artifacts artifact.Service,
+	Inventory inventory.Service,
+	Findings finding.Service,
+	Vulnerabilities vulndb.Service,
+	Vault vault.Service,
+	Memories memory.Service,
 ) tools.BaseTool {
 	return &AgentTool{
-		sessions: Sessions,
-		messages: Messages,
+		sessions:        Sessions,
+		messages:        Messages,
+		usages:          Usages,
+		artifacts:       Artifacts,
+		inventory:       Inventory,
+		findings:        Findings,
+		vulnerabilities: Vulnerabilities,
+		vault:           Vault,
+		memories:        Memories,
 	}
 }
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/session"
+)
+
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// NOTE: subagents are created per task, thus the announced thresholds are kept process wide so that each one is published only once.
+var budgetWarnings sync.Map
+
+// checkBudget validates the spend of the agent's session, its root session and the whole engagement against the configured budgets.
+// It publishes a warning to the status bar when a threshold is crossed and returns ErrBudgetExceeded once a limit is reached.
+func (a *agent) checkBudget(ctx context.Context, sessionID string) error {
+	cfg := config.Get()
+
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if agentCfg, ok := cfg.Agents[a.name]; ok {
+		if err := enforceBudget(fmt.Sprintf("agent %s", a.name), sess.ID, sess.Cost, agentCfg.Budget); err != nil {
+			return err
+		}
+	}
+
+	root, err := a.rootSession(ctx, sess)
+	if err != nil {
+		return err
+	}
+	if err := enforceBudget("session", root.ID, root.Cost, cfg.Budgets.Session); err != nil {
+		return err
+	}
+
+	if cfg.Budgets.Engagement.Limit <= 0 {
+		return nil
+	}
+	// NOTE: the spend of the engagement is summed from the ledger, which keeps the entries of the sessions deleted.
+	totals, err := a.usage.EngagementTotals(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compute the engagement usage: %w", err)
+	}
+	return enforceBudget("engagement", "", totals.Cost, cfg.Budgets.Engagement)
+}
+
+// rootSession walks up the parent sessions of the given task session.
+func (a *agent) rootSession(ctx context.Context, sess session.Session) (session.Session, error) {
+	for sess.ParentSessionID != "" {
+		parent, err := a.sessions.Get(ctx, sess.ParentSessionID)
+		if err != nil {
+			return session.Session{}, fmt.Errorf("failed to get parent session: %w", err)
+		}
+		sess = parent
+	}
+	return sess, nil
+}
+
+func enforceBudget(scope, id string, spent float64, budget config.Budget) error {
+	if budget.Exceeded(spent) {
+		return fmt.Errorf("%w: %s spent $%.2f of $%.2f", ErrBudgetExceeded, scope, spent, budget.Limit)
+	}
+	threshold, crossed := budget.Crossed(spent)
+	if !crossed {
+		return nil
+	}
+	key := fmt.Sprintf("%s/%s/%.2f", scope, id, threshold)
+	if _, announced := budgetWarnings.LoadOrStore(key, struct{}{}); !announced {
+		logging.WarnPersist(fmt.Sprintf("%s budget at %d%%: $%.2f of $%.2f", scope, int(threshold*100), spent, budget.Limit))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+func TestCheckBudgetEngagement(t *testing.T) {
+	loadTestConfig(t)
+	cfg := config.Get()
+	budgets := cfg.Budgets
+	t.Cleanup(func() { cfg.Budgets = budgets })
+	cfg.Budgets.Engagement = config.Budget{Limit: 50}
+
+	tests := []struct {
+		name        string
+		spent       float64
+		expectedErr error
+	}{
+		{name: "under the limit", spent: 10},
+		// NOTE: the spend is taken from the ledger alone, the sessions it was recorded for being possibly deleted.
+		{name: "limit reached by deleted sessions", spent: 50, expectedErr: ErrBudgetExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAgent(&fakeMessages{}, &fakeProvider{})
+			a.usage = &fakeUsage{engagementCost: tt.spent}
+			if err := a.checkBudget(context.Background(), "s1"); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("checkBudget() error = %v, expected %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/provider"
+)
+
+// fallbackRecovery is how long an agent sticks to the fallback model it switched over to before trying its own model again.
+const fallbackRecovery = 10 * time.Minute
+
+// setProviders sets the provider of the agent's model and the ones of its fallback chain, the agent going back to its model.
+func (a *agent) setProviders(primary provider.Provider, fallbacks []provider.Provider) {
+	a.providerMu.Lock()
+	defer a.providerMu.Unlock()
+	a.provider = primary
+	a.primary = primary
+	a.chain = fallbacks
+	a.fallbacks = slices.Clone(fallbacks)
+	a.failedOver = time.Time{}
+}
+
+// recover switches the agent back to its own model once it failed over long enough ago, the error being likely gone by then.
+func (a *agent) recover() {
+	a.providerMu.Lock()
+	defer a.providerMu.Unlock()
+	if a.failedOver.IsZero() || time.Since(a.failedOver) < fallbackRecovery {
+		return
+	}
+	logging.Info("Switching back to the model of the agent", "agent", a.name, "from", a.provider.Model().Name, "to", a.primary.Model().Name)
+	a.provider = a.primary
+	a.fallbacks = slices.Clone(a.chain)
+	a.failedOver = time.Time{}
+}
+
+// fallback switches the agent over to the next model of its fallback chain when the error is persistent for the current model.
+// The failed assistant message is dropped and the switch is noted in the session. It reports whether the request should be retried.
+func (a *agent) fallback(ctx context.Context, sessionID string, failed message.Message, err error) bool {
+	if !provider.IsPersistentError(err) {
+		return false
+	}
+
+	from, to, ok := a.failOver(failed, err)
+	if !ok {
+		return false
+	}
+
+	if failed.ID != "" {
+		if err := a.messages.Delete(ctx, failed.ID); err != nil {
+			logging.Warn("failed to delete the failed assistant message", "message", failed.ID, "error", err)
+		}
+	}
+
+	note := fmt.Sprintf("Falling back from %s to %s: %s", from.Name, to.Name, err)
+	logging.WarnPersist(note)
+	if _, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role:  message.System,
+		Parts: []message.ContentPart{message.TextContent{Text: note}},
+		Model: to.ID,
+	}); err != nil {
+		logging.Warn("failed to log the fallback in the session", "session", sessionID, "error", err)
+	}
+	return true
+}
+
+// failOver switches the provider over to the next one of the fallback chain fit for the error, returning the models switched between.
+func (a *agent) failOver(failed message.Message, err error) (models.Model, models.Model, bool) {
+	a.providerMu.Lock()
+	defer a.providerMu.Unlock()
+
+	current := a.provider.Model()
+	// NOTE: another session of the same agent already switched the model meanwhile, the request is retried with it.
+	if failed.Model != "" && failed.Model != current.ID {
+		from := models.Model{ID: failed.Model, Name: string(failed.Model)}
+		for _, p := range append([]provider.Provider{a.primary}, a.chain...) {
+			if p.Model().ID == failed.Model {
+				from = p.Model()
+			}
+		}
+		return from, current, true
+	}
+	contextLength := provider.IsContextLengthError(err)
+	for len(a.fallbacks) > 0 {
+		next := a.fallbacks[0]
+		a.fallbacks = a.fallbacks[1:]
+		// A model with a smaller context window would be rejected all the same.
+		if contextLength && next.Model().ContextWindow <= current.ContextWindow {
+			continue
+		}
+		a.provider = next
+		a.failedOver = time.Now()
+		return current, next.Model(), true
+	}
+	return current, current, false
+}
+
+// convertHistory adapts the message history for the given model, dropping the attachments it doesn't support.
+// NOTE: the rest of the history is in a provider agnostic format and is converted by the provider itself.
+func convertHistory(msgs []message.Message, model models.Model) []message.Message {
+	if model.SupportsAttachments {
+		return msgs
+	}
+	converted := make([]message.Message, len(msgs))
+	for i, msg := range msgs {
+		parts := make([]message.ContentPart, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			if _, ok := part.(message.BinaryContent); ok {
+				continue
+			}
+			parts = append(parts, part)
+		}
+		msg.Parts = parts
+		converted[i] = msg
+	}
+	return converted
+}
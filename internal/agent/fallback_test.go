@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/provider"
+)
+
+func TestFallbackConcurrentSessions(t *testing.T) {
+	loadTestConfig(t)
+
+	// Both sessions fail on the model of the agent before either of them falls back.
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	primary := &fakeProvider{model: models.Model{ID: "model-a", Name: "Model A"}, err: provider.ErrMaxRetries, barrier: &barrier}
+	fallback := &fakeProvider{model: models.Model{ID: "model-b", Name: "Model B"}, text: "done"}
+	last := &fakeProvider{model: models.Model{ID: "model-c", Name: "Model C"}, text: "done"}
+
+	sessions := []string{"s1", "s2"}
+	messages := &fakeMessages{}
+	for _, sessionID := range sessions {
+		messages.messages = append(messages.messages, message.Message{
+			ID:        "prompt-" + sessionID,
+			SessionID: sessionID,
+			Role:      message.User,
+			Parts:     []message.ContentPart{message.TextContent{Text: "scan 10.0.0.5"}},
+		})
+	}
+	a := newTestAgent(messages, primary, fallback, last)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(sessions))
+	for i, sessionID := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events, err := a.Resume(context.Background(), sessionID)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = (<-events).Error
+		}()
+	}
+	wg.Wait()
+
+	for i, sessionID := range sessions {
+		if errs[i] != nil {
+			t.Fatalf("session %s failed: %v", sessionID, errs[i])
+		}
+		msgs, _ := messages.List(context.Background(), sessionID)
+		var notes []string
+		for _, msg := range msgs {
+			if msg.Role == message.System {
+				notes = append(notes, msg.Content().Text)
+			}
+		}
+		expected := fmt.Sprintf("Falling back from Model A to Model B: %s", provider.ErrMaxRetries)
+		if len(notes) != 1 || !strings.HasPrefix(notes[0], expected) {
+			t.Errorf("notes of session %s = %q, expected %q", sessionID, notes, expected)
+		}
+		if response := msgs[len(msgs)-1]; response.Role != message.Assistant || response.Model != "model-b" {
+			t.Errorf("session %s was answered by %s, expected model-b", sessionID, response.Model)
+		}
+	}
+	if model := a.Model(); model.ID != "model-b" {
+		t.Errorf("Model() = %s, expected model-b, the sessions falling back once", model.ID)
+	}
+}
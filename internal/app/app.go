@@ -2,44 +2,104 @@ package app
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/yaydraco/tandem/internal/agent"
+	"github.com/yaydraco/tandem/internal/artifact"
+	"github.com/yaydraco/tandem/internal/audit"
+	"github.com/yaydraco/tandem/internal/bundle"
 	"github.com/yaydraco/tandem/internal/config"
 	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/finding"
 	"github.com/yaydraco/tandem/internal/format"
+	"github.com/yaydraco/tandem/internal/inventory"
 	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/memory"
 	"github.com/yaydraco/tandem/internal/message"
 	"github.com/yaydraco/tandem/internal/session"
 	"github.com/yaydraco/tandem/internal/tools"
+	"github.com/yaydraco/tandem/internal/usage"
+	"github.com/yaydraco/tandem/internal/vault"
+	"github.com/yaydraco/tandem/internal/vulndb"
 )
 
 type App struct {
-	Sessions     session.Service
-	Messages     message.Service
-	Orchestrator agent.Service
+	Sessions        session.Service
+	Messages        message.Service
+	Usage           usage.Service
+	Artifacts       artifact.Service
+	Inventory       inventory.Service
+	Findings        finding.Service
+	Vulnerabilities vulndb.Service
+	Vault           vault.Service
+	Memories        memory.Service
+	Audit           audit.Service
+	Orchestrator    agent.Service
 	// ADHD: why we shouldn't initialise all the agents at once right in here? here's another thought. we don't want to have multiple agents of the same time, say couple of reconnoiters, doing some scanning because of the nature of the task in hand.
 }
 
 func New(ctx context.Context, conn *sql.DB) (*App, error) {
 	q := db.New(conn)
-	sessions := session.NewService(q)
-	messages := message.NewService(q)
+	var signingKey ed25519.PrivateKey
+	if config.Get().Audit.Sign {
+		key, err := bundle.SigningKey()
+		if err != nil {
+			return nil, err
+		}
+		signingKey = key
+	}
+	auditLog := audit.NewService(q, signingKey)
+	if err := auditLog.RecordStored(ctx); err != nil {
+		return nil, err
+	}
+	if err := auditLog.RecordConfig(ctx); err != nil {
+		return nil, err
+	}
+	messages := message.NewService(q, auditLog)
+	sessions := session.NewService(q, auditLog, messages)
+	usages := usage.NewService(q)
+	artifacts := artifact.NewService(q)
 
 	app := &App{
-		Sessions: sessions,
-		Messages: messages,
+		Sessions:        sessions,
+		Messages:        messages,
+		Usage:           usages,
+		Artifacts:       artifacts,
+		Inventory:       inventory.NewService(q),
+		Findings:        finding.NewService(q),
+		Vulnerabilities: vulndb.NewService(q),
+		Memories:        memory.NewService(q),
+		Audit:           auditLog,
+	}
+
+	key, err := vault.LoadKey(config.Get().Data.Directory)
+	if err != nil {
+		return nil, err
+	}
+	app.Vault, err = vault.NewService(q, key)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: the secrets of the vault are redacted from anything persisted from now on.
+	if err := app.Vault.Load(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load the vault: %w", err)
 	}
 
-	var err error
 	app.Orchestrator, err = agent.NewAgent(
 		config.Orchestrator,
 		app.Sessions,
 		app.Messages,
-		[]tools.BaseTool{agent.NewAgentTool(app.Sessions, app.Messages)},
+		app.Usage,
+		[]tools.BaseTool{
+			agent.NewAgentTool(app.Sessions, app.Messages, app.Usage, app.Artifacts, app.Inventory, app.Findings, app.Vulnerabilities, app.Vault, app.Memories),
+			tools.NewSearchHistoryTool(app.Messages),
+			tools.NewMemoryTool(app.Memories, string(config.Orchestrator)),
+		},
 		nil,
+		"",
 	)
 
 	if err != nil {
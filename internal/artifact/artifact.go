@@ -0,0 +1,113 @@
+package artifact
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/pubsub"
+)
+
+// Artifact is a file, e.g. loot, downloaded from the sandbox into the engagement directory.
+type Artifact struct {
+	ID          string
+	SessionID   string
+	MessageID   string
+	Name        string
+	SourcePath  string
+	Path        string
+	Size        int64
+	Sha256      string
+	MimeType    string
+	Description string
+	CreatedAt   int64
+}
+
+type CreateArtifactParams struct {
+	SessionID   string
+	MessageID   string
+	Name        string
+	SourcePath  string
+	Path        string
+	Size        int64
+	Sha256      string
+	MimeType    string
+	Description string
+}
+
+type Service interface {
+	pubsub.Subscriber[Artifact]
+	Create(ctx context.Context, params CreateArtifactParams) (Artifact, error)
+	Get(ctx context.Context, id string) (Artifact, error)
+	List(ctx context.Context, sessionID string) ([]Artifact, error)
+}
+
+type service struct {
+	*pubsub.Broker[Artifact]
+	q db.Querier
+}
+
+func (s *service) Create(ctx context.Context, params CreateArtifactParams) (Artifact, error) {
+	dbArtifact, err := s.q.CreateArtifact(ctx, db.CreateArtifactParams{
+		ID:          uuid.New().String(),
+		SessionID:   params.SessionID,
+		MessageID:   sql.NullString{String: params.MessageID, Valid: params.MessageID != ""},
+		Name:        params.Name,
+		SourcePath:  params.SourcePath,
+		Path:        params.Path,
+		Size:        params.Size,
+		Sha256:      params.Sha256,
+		MimeType:    params.MimeType,
+		Description: params.Description,
+	})
+	if err != nil {
+		return Artifact{}, err
+	}
+	artifact := s.fromDBItem(dbArtifact)
+	s.Publish(pubsub.CreatedEvent, artifact)
+	return artifact, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (Artifact, error) {
+	dbArtifact, err := s.q.GetArtifact(ctx, id)
+	if err != nil {
+		return Artifact{}, err
+	}
+	return s.fromDBItem(dbArtifact), nil
+}
+
+func (s *service) List(ctx context.Context, sessionID string) ([]Artifact, error) {
+	dbArtifacts, err := s.q.ListArtifactsBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := make([]Artifact, len(dbArtifacts))
+	for i, dbArtifact := range dbArtifacts {
+		artifacts[i] = s.fromDBItem(dbArtifact)
+	}
+	return artifacts, nil
+}
+
+func (s *service) fromDBItem(item db.Artifact) Artifact {
+	return Artifact{
+		ID:          item.ID,
+		SessionID:   item.SessionID,
+		MessageID:   item.MessageID.String,
+		Name:        item.Name,
+		SourcePath:  item.SourcePath,
+		Path:        item.Path,
+		Size:        item.Size,
+		Sha256:      item.Sha256,
+		MimeType:    item.MimeType,
+		Description: item.Description,
+		CreatedAt:   item.CreatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Artifact](),
+		q:      q,
+	}
+}
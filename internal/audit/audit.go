@@ -0,0 +1,171 @@
+// Package audit keeps the tamper-evident log of what the swarm did to the targets: the prompts, the responses and
+// tool calls of the agents, the tool results and the configuration changes. The log is
+// append-only, every entry is chained to the previous one by its hash, and signed with the key of the install
+// if the configuration says so.
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+)
+
+type Kind string
+
+const (
+	KindPrompt     Kind = "prompt"
+	KindResponse   Kind = "response"
+	KindToolCall   Kind = "tool_call"
+	KindToolResult Kind = "tool_result"
+	// KindDeletion records the deletion of a message, or of a session along with its messages.
+	KindDeletion Kind = "deletion"
+	KindConfig   Kind = "config"
+	// KindStored records a message stored otherwise than by the agents: before the log was started, or by the import
+	// of a bundle.
+	KindStored Kind = "stored"
+)
+
+// genesisHash is the previous hash of the first entry of the log.
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+type Service interface {
+	// Record appends an entry to the log.
+	Record(ctx context.Context, kind Kind, sessionID, messageID, payload string) error
+	// RecordMessage records the parts of the message as they're stored, unless they're already recorded as is.
+	RecordMessage(ctx context.Context, kind Kind, sessionID, messageID, parts string) error
+	// RecordConfig records the configuration in effect, without the API keys of the providers, unless it's
+	// already recorded as is.
+	RecordConfig(ctx context.Context) error
+	// RecordStored records the messages stored before the log was started, for them to be checked from then on.
+	// Nothing is recorded once the log is started, lest the messages inserted behind the back of tandem be.
+	RecordStored(ctx context.Context) error
+}
+
+type service struct {
+	// NOTE: the last entry is read and the next one appended under the lock, for the chain not to fork.
+	mu  sync.Mutex
+	q   db.Querier
+	key ed25519.PrivateKey
+}
+
+// NewService returns the audit log stored by q, whose entries are signed with the key unless it's nil.
+func NewService(q db.Querier, key ed25519.PrivateKey) Service {
+	return &service{q: q, key: key}
+}
+
+func (s *service) Record(ctx context.Context, kind Kind, sessionID, messageID, payload string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.append(ctx, kind, sessionID, messageID, payload)
+}
+
+func (s *service) RecordMessage(ctx context.Context, kind Kind, sessionID, messageID, parts string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, err := s.q.GetLastAuditEntryByMessage(ctx, messageID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	if err == nil && Kind(last.Kind) == kind && last.Payload == parts {
+		return nil
+	}
+	return s.append(ctx, kind, sessionID, messageID, parts)
+}
+
+func (s *service) RecordConfig(ctx context.Context) error {
+	cfg := *config.Get()
+	cfg.Providers = maps.Clone(cfg.Providers)
+	for name, provider := range cfg.Providers {
+		provider.APIKey = ""
+		cfg.Providers[name] = provider
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, err := s.q.GetLastAuditEntryByKind(ctx, string(KindConfig))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	if err == nil && last.Payload == string(payload) {
+		return nil
+	}
+	return s.append(ctx, KindConfig, "", "", string(payload))
+}
+
+func (s *service) RecordStored(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.q.GetLastAuditEntry(ctx)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	sessions, err := s.q.ListAllSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the sessions: %w", err)
+	}
+	for _, session := range sessions {
+		messages, err := s.q.ListMessagesBySession(ctx, session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list the messages of session %s: %w", session.ID, err)
+		}
+		for _, m := range messages {
+			if err := s.append(ctx, KindStored, m.SessionID, m.ID, m.Parts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) append(ctx context.Context, kind Kind, sessionID, messageID, payload string) error {
+	prevHash := genesisHash
+	last, err := s.q.GetLastAuditEntry(ctx)
+	switch {
+	case err == nil:
+		prevHash = last.Hash
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	entry := db.AppendAuditEntryParams{
+		Kind:      string(kind),
+		SessionID: sessionID,
+		MessageID: messageID,
+		Payload:   payload,
+		PrevHash:  prevHash,
+		CreatedAt: time.Now().Unix(),
+	}
+	entry.Hash = hash(entry.PrevHash, entry.Kind, entry.SessionID, entry.MessageID, entry.Payload, entry.CreatedAt)
+	if s.key != nil {
+		entry.Signature = ed25519.Sign(s.key, []byte(entry.Hash))
+	}
+	if _, err := s.q.AppendAuditEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to append to the audit log: %w", err)
+	}
+	return nil
+}
+
+// hash returns the hash of an entry, computed over the JSON array of its fields for it to be unambiguous.
+func hash(prevHash, kind, sessionID, messageID, payload string, createdAt int64) string {
+	data, _ := json.Marshal([]any{prevHash, kind, sessionID, messageID, payload, createdAt})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
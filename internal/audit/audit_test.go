@@ -0,0 +1,304 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/db"
+)
+
+// fakeQuerier keeps the audit log, the sessions and their messages in memory.
+type fakeQuerier struct {
+	db.Querier
+	entries  []db.AuditLogEntry
+	sessions []db.Session
+	messages []db.Message
+}
+
+func (q *fakeQuerier) AppendAuditEntry(ctx context.Context, arg db.AppendAuditEntryParams) (db.AuditLogEntry, error) {
+	e := db.AuditLogEntry{
+		Seq:       int64(len(q.entries) + 1),
+		Kind:      arg.Kind,
+		SessionID: arg.SessionID,
+		MessageID: arg.MessageID,
+		Payload:   arg.Payload,
+		PrevHash:  arg.PrevHash,
+		Hash:      arg.Hash,
+		Signature: arg.Signature,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.entries = append(q.entries, e)
+	return e, nil
+}
+
+func (q *fakeQuerier) last(match func(db.AuditLogEntry) bool) (db.AuditLogEntry, error) {
+	for _, e := range slices.Backward(q.entries) {
+		if match(e) {
+			return e, nil
+		}
+	}
+	return db.AuditLogEntry{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetLastAuditEntry(ctx context.Context) (db.AuditLogEntry, error) {
+	return q.last(func(db.AuditLogEntry) bool { return true })
+}
+
+func (q *fakeQuerier) GetLastAuditEntryByKind(ctx context.Context, kind string) (db.AuditLogEntry, error) {
+	return q.last(func(e db.AuditLogEntry) bool { return e.Kind == kind })
+}
+
+func (q *fakeQuerier) GetLastAuditEntryByMessage(ctx context.Context, messageID string) (db.AuditLogEntry, error) {
+	return q.last(func(e db.AuditLogEntry) bool { return e.MessageID == messageID })
+}
+
+func (q *fakeQuerier) ListAuditEntries(ctx context.Context) ([]db.AuditLogEntry, error) {
+	return q.entries, nil
+}
+
+func (q *fakeQuerier) ListAllSessions(ctx context.Context) ([]db.Session, error) {
+	return q.sessions, nil
+}
+
+func (q *fakeQuerier) ListMessagesBySession(ctx context.Context, sessionID string) ([]db.Message, error) {
+	var messages []db.Message
+	for _, m := range q.messages {
+		if m.SessionID == sessionID {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func newTestKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestLog returns a log recording a prompt and its response, both stored.
+func newTestLog(t *testing.T, key ed25519.PrivateKey) *fakeQuerier {
+	t.Helper()
+	ctx := context.Background()
+	q := &fakeQuerier{sessions: []db.Session{{ID: "s1"}}}
+	log := NewService(q, key)
+	now := time.Now().Unix()
+	for _, m := range []db.Message{
+		{ID: "m1", SessionID: "s1", Role: "user", Parts: `[{"text":"scan 10.0.0.5"}]`, CreatedAt: now},
+		{ID: "m2", SessionID: "s1", Role: "assistant", Parts: `[{"text":"done"}]`, CreatedAt: now, FinishedAt: sql.NullInt64{Int64: now, Valid: true}},
+	} {
+		kind := KindPrompt
+		if m.Role == "assistant" {
+			kind = KindResponse
+		}
+		if err := log.RecordMessage(ctx, kind, m.SessionID, m.ID, m.Parts); err != nil {
+			t.Fatalf("RecordMessage() failed: %v", err)
+		}
+		// Recording the same parts again is a no-op.
+		if err := log.RecordMessage(ctx, kind, m.SessionID, m.ID, m.Parts); err != nil {
+			t.Fatalf("RecordMessage() failed: %v", err)
+		}
+		q.messages = append(q.messages, m)
+	}
+	if err := log.Record(ctx, KindToolResult, "s1", "", "nmap output"); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	return q
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+
+	tests := []struct {
+		name string
+		// tamper alters the log or the messages, before they're verified.
+		tamper func(q *fakeQuerier)
+		// public is the key the log is verified against, the one it was signed with if nil.
+		public          ed25519.PublicKey
+		requireSigned   bool
+		expectedProblem string
+	}{
+		{
+			name:   "intact",
+			tamper: func(q *fakeQuerier) {},
+		},
+		{
+			name:            "altered entry",
+			tamper:          func(q *fakeQuerier) { q.entries[2].Payload = "nothing found" },
+			expectedProblem: "entry 3 was altered",
+		},
+		{
+			name:            "removed entry",
+			tamper:          func(q *fakeQuerier) { q.entries = slices.Delete(q.entries, 1, 2) },
+			expectedProblem: "the chain is broken",
+		},
+		{
+			name:            "wrong key",
+			tamper:          func(q *fakeQuerier) {},
+			public:          otherKey.Public().(ed25519.PublicKey),
+			expectedProblem: "isn't signed with the key of the install",
+		},
+		{
+			name:            "forged signature",
+			tamper:          func(q *fakeQuerier) { q.entries[0].Signature = ed25519.Sign(otherKey, []byte(q.entries[0].Hash)) },
+			expectedProblem: "entry 1 isn't signed",
+		},
+		{
+			name:            "signature removed",
+			tamper:          func(q *fakeQuerier) { q.entries[1].Signature = nil },
+			requireSigned:   true,
+			expectedProblem: "entry 2 isn't signed",
+		},
+		{
+			name:            "altered message",
+			tamper:          func(q *fakeQuerier) { q.messages[0].Parts = `[{"text":"scan 10.0.0.6"}]` },
+			expectedProblem: "message m1 was altered",
+		},
+		{
+			name:            "deleted message",
+			tamper:          func(q *fakeQuerier) { q.messages = q.messages[:1] },
+			expectedProblem: "message m2 recorded in entry 2 was deleted",
+		},
+		{
+			name: "inserted message",
+			tamper: func(q *fakeQuerier) {
+				q.messages = append(q.messages, db.Message{ID: "m3", SessionID: "s1", Role: "user", Parts: "[]", CreatedAt: time.Now().Unix()})
+			},
+			expectedProblem: "message m3 isn't recorded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestLog(t, key)
+			tt.tamper(q)
+			public := tt.public
+			if public == nil {
+				public = key.Public().(ed25519.PublicKey)
+			}
+			report, err := Verify(context.Background(), q, public, tt.requireSigned)
+			if err != nil {
+				t.Fatalf("Verify() failed: %v", err)
+			}
+			if tt.expectedProblem == "" {
+				if !report.OK() {
+					t.Errorf("Verify() reported problems: %v", report.Problems)
+				}
+				if report.Entries != 3 || report.Signed != 3 || report.Messages != 2 {
+					t.Errorf("Verify() = %+v, expected 3 signed entries and 2 messages", report)
+				}
+				return
+			}
+			if !slices.ContainsFunc(report.Problems, func(p string) bool { return strings.Contains(p, tt.expectedProblem) }) {
+				t.Errorf("Verify() problems = %v, expected one containing %q", report.Problems, tt.expectedProblem)
+			}
+		})
+	}
+}
+
+func TestRecordStored(t *testing.T) {
+	ctx := context.Background()
+	key := newTestKey(t)
+	now := time.Now().Unix()
+	q := &fakeQuerier{
+		sessions: []db.Session{{ID: "s1"}},
+		messages: []db.Message{
+			{ID: "m1", SessionID: "s1", Role: "user", Parts: `[{"text":"scan 10.0.0.5"}]`, CreatedAt: now},
+		},
+	}
+	log := NewService(q, key)
+
+	// The messages stored before the log was started are recorded, once.
+	for range 2 {
+		if err := log.RecordStored(ctx); err != nil {
+			t.Fatalf("RecordStored() failed: %v", err)
+		}
+	}
+	report, err := Verify(ctx, q, key.Public().(ed25519.PublicKey), true)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if !report.OK() || report.Entries != 1 || report.Messages != 1 {
+		t.Errorf("Verify() = %+v, expected the stored message to be recorded", report)
+	}
+
+	// A message inserted once the log is started isn't.
+	q.messages = append(q.messages, db.Message{ID: "m2", SessionID: "s1", Role: "user", Parts: "[]", CreatedAt: now})
+	if err := log.RecordStored(ctx); err != nil {
+		t.Fatalf("RecordStored() failed: %v", err)
+	}
+	if report, err = Verify(ctx, q, key.Public().(ed25519.PublicKey), true); err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if !slices.ContainsFunc(report.Problems, func(p string) bool { return strings.Contains(p, "message m2 isn't recorded") }) {
+		t.Errorf("Verify() problems = %v, expected m2 to be reported", report.Problems)
+	}
+}
+
+func TestVerifyDeletedSession(t *testing.T) {
+	ctx := context.Background()
+	key := newTestKey(t)
+
+	tests := []struct {
+		name string
+		// tamper alters the log, recording with log, or the messages, once the session s1 is deleted.
+		tamper          func(q *fakeQuerier, log Service)
+		expectedProblem string
+	}{
+		{
+			name: "session deleted",
+			tamper: func(q *fakeQuerier, log Service) {
+				q.sessions, q.messages = nil, nil
+			},
+		},
+		{
+			name:            "messages of the session deleted still stored",
+			tamper:          func(q *fakeQuerier, log Service) {},
+			expectedProblem: "session s1 was deleted in entry 4, but message m1 is stored",
+		},
+		{
+			name: "message deleted once the session is imported again",
+			tamper: func(q *fakeQuerier, log Service) {
+				for _, m := range q.messages {
+					if err := log.RecordMessage(ctx, KindStored, m.SessionID, m.ID, m.Parts); err != nil {
+						t.Fatalf("RecordMessage() failed: %v", err)
+					}
+				}
+				q.messages = q.messages[:1]
+			},
+			expectedProblem: "message m2 recorded in entry 6 was deleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestLog(t, key)
+			log := NewService(q, key)
+			if err := log.Record(ctx, KindDeletion, "s1", "", ""); err != nil {
+				t.Fatalf("Record() failed: %v", err)
+			}
+			tt.tamper(q, log)
+			report, err := Verify(ctx, q, key.Public().(ed25519.PublicKey), true)
+			if err != nil {
+				t.Fatalf("Verify() failed: %v", err)
+			}
+			if tt.expectedProblem == "" {
+				if !report.OK() {
+					t.Errorf("Verify() reported problems: %v", report.Problems)
+				}
+				return
+			}
+			if !slices.ContainsFunc(report.Problems, func(p string) bool { return strings.Contains(p, tt.expectedProblem) }) {
+				t.Errorf("Verify() problems = %v, expected one containing %q", report.Problems, tt.expectedProblem)
+			}
+		})
+	}
+}
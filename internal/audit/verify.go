@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/yaydraco/tandem/internal/db"
+)
+
+// Report is the outcome of the verification of the audit log.
+type Report struct {
+	Entries int
+	Signed  int
+	// Messages is the number of stored messages checked against the log.
+	Messages int
+	// Problems are the breaks of the chain, the invalid or missing signatures and the messages tampered with.
+	Problems []string
+}
+
+// OK reports whether neither the log nor the messages were tampered with.
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify checks the chain of the audit log, and the signatures of its entries against the public key, every entry
+// having to be signed if requireSigned is set. The messages stored are then checked against the entries recording them,
+// for any message altered, deleted or inserted behind the back of tandem to be reported.
+func Verify(ctx context.Context, q db.Querier, public ed25519.PublicKey, requireSigned bool) (Report, error) {
+	var report Report
+	entries, err := q.ListAuditEntries(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	var (
+		prevHash = genesisHash
+		recorded = map[string]db.AuditLogEntry{}
+		// deletedSessions are the entries of the sessions deleted, by session.
+		deletedSessions = map[string]db.AuditLogEntry{}
+	)
+	for _, e := range entries {
+		if e.PrevHash != prevHash {
+			report.Problems = append(report.Problems, fmt.Sprintf("the chain is broken before entry %d, an entry was removed or inserted", e.Seq))
+		}
+		if e.Hash != hash(e.PrevHash, e.Kind, e.SessionID, e.MessageID, e.Payload, e.CreatedAt) {
+			report.Problems = append(report.Problems, fmt.Sprintf("entry %d was altered, its hash doesn't match", e.Seq))
+		}
+		// NOTE: the driver stores the signature of the entries unsigned as an empty blob.
+		if len(e.Signature) > 0 {
+			report.Signed++
+			if len(public) != ed25519.PublicKeySize || !ed25519.Verify(public, []byte(e.Hash), e.Signature) {
+				report.Problems = append(report.Problems, fmt.Sprintf("entry %d isn't signed with the key of the install", e.Seq))
+			}
+		} else if requireSigned {
+			report.Problems = append(report.Problems, fmt.Sprintf("entry %d isn't signed", e.Seq))
+		}
+		prevHash = e.Hash
+
+		switch {
+		case e.MessageID != "":
+			recorded[e.MessageID] = e
+		case Kind(e.Kind) == KindDeletion && e.SessionID != "":
+			deletedSessions[e.SessionID] = e
+		}
+	}
+	report.Entries = len(entries)
+
+	sessions, err := q.ListAllSessions(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list the sessions: %w", err)
+	}
+	stored := map[string]bool{}
+	for _, s := range sessions {
+		messages, err := q.ListMessagesBySession(ctx, s.ID)
+		if err != nil {
+			return report, fmt.Errorf("failed to list the messages of session %s: %w", s.ID, err)
+		}
+		for _, m := range messages {
+			stored[m.ID] = true
+			e, ok := recorded[m.ID]
+			switch {
+			case ok && Kind(e.Kind) == KindDeletion:
+				report.Problems = append(report.Problems, fmt.Sprintf("message %s was deleted in entry %d, but is stored", m.ID, e.Seq))
+			case ok && deletedSince(deletedSessions, e):
+				report.Problems = append(report.Problems, fmt.Sprintf("session %s was deleted in entry %d, but message %s is stored", m.SessionID, deletedSessions[e.SessionID].Seq, m.ID))
+			case ok && e.Payload != m.Parts:
+				report.Problems = append(report.Problems, fmt.Sprintf("message %s was altered since entry %d", m.ID, e.Seq))
+			case ok:
+			// NOTE: the messages of the agents are recorded once they're finished, not while they're streamed.
+			case m.Role == "assistant" && !m.FinishedAt.Valid:
+				continue
+			default:
+				report.Problems = append(report.Problems, fmt.Sprintf("message %s isn't recorded, it was inserted behind the back of tandem", m.ID))
+			}
+			report.Messages++
+		}
+	}
+	for id, e := range recorded {
+		if !stored[id] && Kind(e.Kind) != KindDeletion && !deletedSince(deletedSessions, e) {
+			report.Problems = append(report.Problems, fmt.Sprintf("message %s recorded in entry %d was deleted", id, e.Seq))
+		}
+	}
+	return report, nil
+}
+
+// deletedSince reports whether the session of the entry was deleted since it was recorded, the messages recorded
+// once the session is deleted, e.g. as it's imported again, being checked as any other.
+func deletedSince(deletedSessions map[string]db.AuditLogEntry, e db.AuditLogEntry) bool {
+	deletion, ok := deletedSessions[e.SessionID]
+	return ok && deletion.Seq > e.Seq
+}
@@ -0,0 +1,336 @@
+// Package bundle exports the record of an engagement into a single signed and compressed bundle, and imports it back
+// into another install. The bundle is a gzipped tarball of the records, the artifacts, the RoE and the swarm.json,
+// along with a manifest of their hashes signed with the ed25519 key of the install exporting it.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/engagement"
+)
+
+const (
+	// FormatVersion is the version of the layout of the bundles, bumped when it changes incompatibly.
+	FormatVersion = 1
+
+	ManifestFile  = "manifest.json"
+	SignatureFile = "manifest.sig"
+
+	signingKeyFile = "signing.key"
+	// TrustedSignersFile lists the public keys of the bundles imported without --signer, one per line.
+	TrustedSignersFile = "trusted_signers"
+)
+
+// Manifest describes the content of a bundle, every file of which is listed along with its hash.
+type Manifest struct {
+	Version       int       `json:"version"`
+	Engagement    string    `json:"engagement,omitempty"`
+	TandemVersion string    `json:"tandemVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	// PublicKey is the ed25519 key the manifest is signed with.
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+	Files     []File            `json:"files"`
+}
+
+// File is a file of a bundle.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Signer returns the public key the manifest is signed with, encoded as it's given to import --signer.
+func (m Manifest) Signer() string {
+	return base64.StdEncoding.EncodeToString(m.PublicKey)
+}
+
+// Trusted reports whether the manifest is signed with the key of the install, or with one of the keys
+// listed in TrustedSignersFile.
+func (m Manifest) Trusted() (bool, error) {
+	home, err := engagement.Home()
+	if err != nil {
+		return false, err
+	}
+	key, err := readSigningKey(filepath.Join(home, signingKeyFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if key != nil && m.PublicKey.Equal(key.Public()) {
+		return true, nil
+	}
+	data, err := os.ReadFile(filepath.Join(home, TrustedSignersFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read the trusted signers: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if signer, _, _ := strings.Cut(line, "#"); strings.TrimSpace(signer) == m.Signer() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SigningKey returns the ed25519 key of the install the bundles are signed with, generating it on first use.
+func SigningKey() (ed25519.PrivateKey, error) {
+	home, err := engagement.Home()
+	if err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(home, signingKeyFile)
+	key, err := readSigningKey(keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return newSigningKey(keyPath)
+	}
+	return key, err
+}
+
+func readSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid signing key %s", keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", keyPath, err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s isn't an ed25519 key", keyPath)
+	}
+	return private, nil
+}
+
+func newSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(keyPath, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write the signing key: %w", err)
+	}
+	return private, nil
+}
+
+// Writer writes a bundle, the files added to it first, the manifest and its signature last.
+type Writer struct {
+	gz       *gzip.Writer
+	tar      *tar.Writer
+	key      ed25519.PrivateKey
+	manifest Manifest
+}
+
+// NewWriter starts a bundle of the engagement signed with the key.
+func NewWriter(w io.Writer, key ed25519.PrivateKey, engagement, tandemVersion string) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{
+		gz:  gz,
+		tar: tar.NewWriter(gz),
+		key: key,
+		manifest: Manifest{
+			Version:       FormatVersion,
+			Engagement:    engagement,
+			TandemVersion: tandemVersion,
+			CreatedAt:     time.Now().UTC(),
+			PublicKey:     key.Public().(ed25519.PublicKey),
+		},
+	}
+}
+
+// Add adds the file to the bundle, listing it in the manifest.
+func (w *Writer) Add(name string, data []byte) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	w.manifest.Files = append(w.manifest.Files, File{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+	return w.write(name, data)
+}
+
+// AddJSON adds the value to the bundle as a JSON file.
+func (w *Writer) AddJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.Add(name, data)
+}
+
+func (w *Writer) write(name string, data []byte) error {
+	if err := w.tar.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     0o600,
+		ModTime:  w.manifest.CreatedAt,
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := w.tar.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close writes the manifest and its signature, and completes the bundle.
+func (w *Writer) Close() (Manifest, error) {
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := w.write(ManifestFile, data); err != nil {
+		return Manifest{}, err
+	}
+	if err := w.write(SignatureFile, ed25519.Sign(w.key, data)); err != nil {
+		return Manifest{}, err
+	}
+	if err := w.tar.Close(); err != nil {
+		return Manifest{}, err
+	}
+	if err := w.gz.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return w.manifest, nil
+}
+
+// Extract extracts the bundle into the directory, and verifies it: its manifest must be signed with the public key
+// it holds, and list every file of the bundle with its hash.
+func Extract(r io.Reader, dir string) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer gz.Close()
+	var (
+		archive   = tar.NewReader(gz)
+		manifest  []byte
+		signature []byte
+		files     = map[string]File{}
+	)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("corrupted bundle: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return Manifest{}, fmt.Errorf("corrupted bundle: %s is not a regular file", header.Name)
+		}
+		if err := validName(header.Name); err != nil {
+			return Manifest{}, fmt.Errorf("corrupted bundle: %w", err)
+		}
+		switch header.Name {
+		case ManifestFile:
+			if manifest, err = io.ReadAll(archive); err != nil {
+				return Manifest{}, fmt.Errorf("corrupted bundle: %w", err)
+			}
+			continue
+		case SignatureFile:
+			if signature, err = io.ReadAll(archive); err != nil {
+				return Manifest{}, fmt.Errorf("corrupted bundle: %w", err)
+			}
+			continue
+		}
+		if _, ok := files[header.Name]; ok {
+			return Manifest{}, fmt.Errorf("corrupted bundle: %s is there twice", header.Name)
+		}
+		file, err := extractFile(archive, filepath.Join(dir, filepath.FromSlash(header.Name)))
+		if err != nil {
+			return Manifest{}, fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+		file.Path = header.Name
+		files[header.Name] = file
+	}
+
+	if manifest == nil || signature == nil {
+		return Manifest{}, fmt.Errorf("corrupted bundle: no signed manifest")
+	}
+	var m Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return Manifest{}, fmt.Errorf("corrupted bundle: invalid manifest: %w", err)
+	}
+	if m.Version != FormatVersion {
+		return Manifest{}, fmt.Errorf("unsupported bundle version %d", m.Version)
+	}
+	if len(m.PublicKey) != ed25519.PublicKeySize || !ed25519.Verify(m.PublicKey, manifest, signature) {
+		return Manifest{}, fmt.Errorf("the signature of the manifest is invalid")
+	}
+	for _, want := range m.Files {
+		got, ok := files[want.Path]
+		if !ok {
+			return Manifest{}, fmt.Errorf("corrupted bundle: %s is missing", want.Path)
+		}
+		if got != want {
+			return Manifest{}, fmt.Errorf("corrupted bundle: the hash of %s doesn't match the manifest", want.Path)
+		}
+		delete(files, want.Path)
+	}
+	for name := range files {
+		return Manifest{}, fmt.Errorf("corrupted bundle: %s isn't listed in the manifest", name)
+	}
+	return m, nil
+}
+
+func extractFile(r io.Reader, dest string) (File, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return File{}, err
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, hash), r)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, f.Close()
+}
+
+// validName rejects the names of files which would be extracted out of the directory of the bundle.
+func validName(name string) error {
+	clean := path.Clean(name)
+	if clean != name || path.IsAbs(name) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") ||
+		slices.Contains(strings.Split(name, "/"), "") || strings.Contains(name, `\`) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name string
+	data []byte
+}
+
+func newTestKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// writeTestBundle writes a bundle of the files, returning its entries.
+func writeTestBundle(t *testing.T, key ed25519.PrivateKey, files []entry) []entry {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf, key, "acme", "test")
+	for _, f := range files {
+		if err := w.Add(f.name, f.data); err != nil {
+			t.Fatalf("Add(%s) failed: %v", f.name, err)
+		}
+	}
+	if _, err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []entry
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry{name: header.Name, data: data})
+	}
+	return entries
+}
+
+// tarball packs the entries back into a bundle.
+func tarball(t *testing.T, entries []entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	for _, e := range entries {
+		if err := archive.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: e.name, Size: int64(len(e.data)), Mode: 0o600}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := archive.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func replaceEntry(entries []entry, name string, data []byte) []entry {
+	for i := range entries {
+		if entries[i].name == name {
+			entries[i].data = data
+		}
+	}
+	return entries
+}
+
+func TestBundle(t *testing.T) {
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+	files := []entry{
+		{name: "sessions.json", data: []byte(`[{"id":"1"}]`)},
+		{name: "artifacts/scan.xml", data: []byte("<nmaprun/>")},
+	}
+
+	tests := []struct {
+		name string
+		// tamper alters the entries of the bundle, before it's extracted.
+		tamper      func(entries []entry) []entry
+		expectedErr string
+	}{
+		{
+			name:   "round trip",
+			tamper: func(entries []entry) []entry { return entries },
+		},
+		{
+			name: "tampered file",
+			tamper: func(entries []entry) []entry {
+				return replaceEntry(entries, "sessions.json", []byte(`[{"id":"2"}]`))
+			},
+			expectedErr: "hash",
+		},
+		{
+			name: "tampered manifest",
+			tamper: func(entries []entry) []entry {
+				for _, e := range entries {
+					if e.name == ManifestFile {
+						return replaceEntry(entries, ManifestFile, bytes.Replace(e.data, []byte("acme"), []byte("evil"), 1))
+					}
+				}
+				return entries
+			},
+			expectedErr: "signature",
+		},
+		{
+			name: "manifest signed with another key",
+			tamper: func(entries []entry) []entry {
+				for _, e := range entries {
+					if e.name == ManifestFile {
+						return replaceEntry(entries, SignatureFile, ed25519.Sign(otherKey, e.data))
+					}
+				}
+				return entries
+			},
+			expectedErr: "signature",
+		},
+		{
+			name: "missing file",
+			tamper: func(entries []entry) []entry {
+				return entries[1:]
+			},
+			expectedErr: "missing",
+		},
+		{
+			name: "file not listed",
+			tamper: func(entries []entry) []entry {
+				return append([]entry{{name: "extra.json", data: []byte("{}")}}, entries...)
+			},
+			expectedErr: "isn't listed",
+		},
+		{
+			name: "file out of the directory",
+			tamper: func(entries []entry) []entry {
+				return append([]entry{{name: "../evil", data: []byte("x")}}, entries...)
+			},
+			expectedErr: "invalid file name",
+		},
+		{
+			name: "no signature",
+			tamper: func(entries []entry) []entry {
+				return entries[:len(entries)-1]
+			},
+			expectedErr: "no signed manifest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.tamper(writeTestBundle(t, key, files))
+			dir := t.TempDir()
+			manifest, err := Extract(bytes.NewReader(tarball(t, entries)), dir)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("Extract() error = %v, expected an error containing %q", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() failed: %v", err)
+			}
+			if manifest.Engagement != "acme" || !manifest.PublicKey.Equal(key.Public()) {
+				t.Errorf("Extract() = %+v", manifest)
+			}
+			for _, f := range files {
+				data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.name)))
+				if err != nil {
+					t.Fatalf("%s wasn't extracted: %v", f.name, err)
+				}
+				if !bytes.Equal(data, f.data) {
+					t.Errorf("%s = %q, expected %q", f.name, data, f.data)
+				}
+			}
+		})
+	}
+}
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "sessions.json", valid: true},
+		{name: "artifacts/scan.xml", valid: true},
+		{name: "/etc/passwd"},
+		{name: "../evil"},
+		{name: "artifacts/../../evil"},
+		{name: "artifacts//scan.xml"},
+		{name: `artifacts\scan.xml`},
+		{name: "."},
+		{name: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validName(tt.name); (err == nil) != tt.valid {
+				t.Errorf("validName(%q) = %v, expected valid: %t", tt.name, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestTrusted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	own, err := SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey() failed: %v", err)
+	}
+	listed := newTestKey(t)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := "# the client\n" + Manifest{PublicKey: listed.Public().(ed25519.PublicKey)}.Signer() + " # acme\n"
+	if err := os.WriteFile(filepath.Join(home, ".tandem", TrustedSignersFile), []byte(signers), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     ed25519.PrivateKey
+		trusted bool
+	}{
+		{name: "key of the install", key: own, trusted: true},
+		{name: "listed key", key: listed, trusted: true},
+		{name: "unknown key", key: newTestKey(t)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trusted, err := Manifest{PublicKey: tt.key.Public().(ed25519.PublicKey)}.Trusted()
+			if err != nil {
+				t.Fatalf("Trusted() failed: %v", err)
+			}
+			if trusted != tt.trusted {
+				t.Errorf("Trusted() = %t, expected %t", trusted, tt.trusted)
+			}
+		})
+	}
+}
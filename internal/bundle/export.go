@@ -0,0 +1,138 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/encryption"
+)
+
+// The files of a bundle.
+const (
+	sessionsFile  = "sessions.json"
+	messagesFile  = "messages.json"
+	findingsFile  = "findings.json"
+	artifactsFile = "artifacts.json"
+	roeFile       = "RoE.md"
+	configFile    = "swarm.json"
+	artifactsDir  = "artifacts"
+)
+
+// Summary counts the records of a bundle.
+type Summary struct {
+	Sessions  int
+	Messages  int
+	Findings  int
+	Artifacts int
+	// MissingArtifacts are the artifacts recorded whose file is gone, exported without it.
+	MissingArtifacts []string
+}
+
+// Export adds the record of the engagement to the bundle: its sessions, the task sessions of the subagents included,
+// their messages, the findings, the artifacts along with their files, the RoE and the swarm.json, without its providers
+// and their API keys.
+func Export(ctx context.Context, q db.Querier, w *Writer, roe, swarm []byte) (Summary, error) {
+	var summary Summary
+	sessions, err := q.ListAllSessions(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("failed to list the sessions: %w", err)
+	}
+	var (
+		messages  []db.Message
+		artifacts []db.Artifact
+	)
+	for _, s := range sessions {
+		sessionMessages, err := q.ListMessagesBySession(ctx, s.ID)
+		if err != nil {
+			return summary, fmt.Errorf("failed to list the messages of session %s: %w", s.ID, err)
+		}
+		messages = append(messages, sessionMessages...)
+		sessionArtifacts, err := q.ListArtifactsBySession(ctx, s.ID)
+		if err != nil {
+			return summary, fmt.Errorf("failed to list the artifacts of session %s: %w", s.ID, err)
+		}
+		artifacts = append(artifacts, sessionArtifacts...)
+	}
+	findings, err := q.ListFindings(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("failed to list the findings: %w", err)
+	}
+
+	for _, a := range artifacts {
+		data, err := encryption.ReadFile(a.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			summary.MissingArtifacts = append(summary.MissingArtifacts, a.Path)
+			continue
+		}
+		if err != nil {
+			return summary, fmt.Errorf("failed to read the artifact %s: %w", a.Path, err)
+		}
+		if err := w.Add(artifactPath(a), data); err != nil {
+			return summary, err
+		}
+	}
+	for _, records := range []struct {
+		name  string
+		value any
+	}{
+		{sessionsFile, sessions},
+		{messagesFile, messages},
+		{findingsFile, findings},
+		{artifactsFile, artifacts},
+	} {
+		if err := w.AddJSON(records.name, records.value); err != nil {
+			return summary, err
+		}
+	}
+	if err := w.Add(roeFile, roe); err != nil {
+		return summary, err
+	}
+	if swarm != nil {
+		if swarm, err = withoutKeys(swarm, "providers"); err != nil {
+			return summary, fmt.Errorf("invalid swarm.json: %w", err)
+		}
+		if err := w.Add(configFile, swarm); err != nil {
+			return summary, err
+		}
+	}
+
+	summary.Sessions, summary.Messages, summary.Findings, summary.Artifacts = len(sessions), len(messages), len(findings), len(artifacts)
+	return summary, nil
+}
+
+// artifactPath is the path of the file of the artifact in the bundle.
+func artifactPath(a db.Artifact) string {
+	return artifactsDir + "/" + a.ID + "/" + filepath.Base(a.Path)
+}
+
+// withoutKeys removes the keys of the JSON object, given by their dot separated paths, "*" matching any key.
+// The keys are matched case insensitively, as they are by the configuration.
+func withoutKeys(data []byte, paths ...string) ([]byte, error) {
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		removeKey(config, strings.Split(p, "."))
+	}
+	return json.MarshalIndent(config, "", "  ")
+}
+
+func removeKey(object map[string]any, keys []string) {
+	for k, v := range object {
+		if keys[0] != "*" && !strings.EqualFold(k, keys[0]) {
+			continue
+		}
+		if len(keys) == 1 {
+			delete(object, k)
+		} else if child, ok := v.(map[string]any); ok {
+			removeKey(child, keys[1:])
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package bundle
+
+import (
+	"cmp"
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/audit"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/encryption"
+)
+
+// RoE returns the RoE of the bundle extracted into the directory.
+func RoE(dir string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, roeFile))
+}
+
+// Config returns the swarm.json of the bundle extracted into the directory, nil if there is none. The data directory,
+// the RoE and the sandbox it sets are left out, the ones of the engagement it's imported into being used instead.
+func Config(dir string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, configFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withoutKeys(data, "data", "contextPaths", "sandbox")
+}
+
+// Import restores the records of the bundle extracted into the directory into the database, in a transaction,
+// and copies the files of the artifacts under artifactsDir. The findings lose their host, the inventory not being
+// part of the bundle. The messages are recorded in the audit log, signed with signingKey unless it's nil, for them to
+// be checked from then on.
+func Import(ctx context.Context, conn *sql.DB, dir, artifactsDir string, signingKey ed25519.PrivateKey) (Summary, error) {
+	var (
+		summary   Summary
+		sessions  []db.Session
+		messages  []db.Message
+		findings  []db.Finding
+		artifacts []db.Artifact
+	)
+	for name, records := range map[string]any{
+		sessionsFile:  &sessions,
+		messagesFile:  &messages,
+		findingsFile:  &findings,
+		artifactsFile: &artifacts,
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return summary, fmt.Errorf("corrupted bundle: %w", err)
+		}
+		if err := json.Unmarshal(data, records); err != nil {
+			return summary, fmt.Errorf("corrupted bundle: invalid %s: %w", name, err)
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer tx.Rollback()
+	q := db.New(tx)
+	auditLog := audit.NewService(q, signingKey)
+	// NOTE: the messages stored before are recorded first, the log being started by the import otherwise.
+	if err := auditLog.RecordStored(ctx); err != nil {
+		return summary, err
+	}
+	// NOTE: the files of the artifacts are copied along, and removed unless the transaction is committed.
+	var copied []string
+	committed := false
+	defer func() {
+		if !committed {
+			for _, path := range copied {
+				os.Remove(path)
+			}
+		}
+	}()
+
+	for _, s := range parentsFirst(sessions) {
+		// NOTE: the sessions of the bundles of older versions have no tags.
+		if err := q.ImportSession(ctx, db.ImportSessionParams{
+			ID:                  s.ID,
+			ParentSessionID:     s.ParentSessionID,
+			Title:               s.Title,
+			PromptTokens:        s.PromptTokens,
+			CompletionTokens:    s.CompletionTokens,
+			Cost:                s.Cost,
+			ContextTokens:       s.ContextTokens,
+			SummaryMessageID:    s.SummaryMessageID,
+			ForkedFromSessionID: s.ForkedFromSessionID,
+			ForkedFromMessageID: s.ForkedFromMessageID,
+			Tags:                cmp.Or(s.Tags, "[]"),
+			ArchivedAt:          s.ArchivedAt,
+			UpdatedAt:           s.UpdatedAt,
+			CreatedAt:           s.CreatedAt,
+		}); err != nil {
+			return summary, fmt.Errorf("failed to import the session %s: %w", s.ID, err)
+		}
+	}
+	for _, m := range messages {
+		if err := q.ImportMessage(ctx, db.ImportMessageParams{
+			ID:         m.ID,
+			SessionID:  m.SessionID,
+			Role:       m.Role,
+			Parts:      m.Parts,
+			Model:      m.Model,
+			CreatedAt:  m.CreatedAt,
+			UpdatedAt:  m.UpdatedAt,
+			FinishedAt: m.FinishedAt,
+		}); err != nil {
+			return summary, fmt.Errorf("failed to import the message %s: %w", m.ID, err)
+		}
+		if err := auditLog.RecordMessage(ctx, audit.KindStored, m.SessionID, m.ID, m.Parts); err != nil {
+			return summary, err
+		}
+	}
+	for _, f := range findings {
+		if err := q.ImportFinding(ctx, db.ImportFindingParams{
+			ID:          f.ID,
+			SessionID:   f.SessionID,
+			Fingerprint: f.Fingerprint,
+			Scanner:     f.Scanner,
+			TemplateID:  f.TemplateID,
+			Name:        f.Name,
+			Severity:    f.Severity,
+			Target:      f.Target,
+			Description: f.Description,
+			Evidence:    f.Evidence,
+			Refs:        f.Refs,
+			Occurrences: f.Occurrences,
+			CreatedAt:   f.CreatedAt,
+			UpdatedAt:   f.UpdatedAt,
+		}); err != nil {
+			return summary, fmt.Errorf("failed to import the finding %s: %w", f.ID, err)
+		}
+	}
+	for _, a := range artifacts {
+		dest, err := importArtifactFile(filepath.Join(dir, filepath.FromSlash(artifactPath(a))), filepath.Join(artifactsDir, a.SessionID))
+		if errors.Is(err, os.ErrNotExist) {
+			summary.MissingArtifacts = append(summary.MissingArtifacts, a.Path)
+		} else if err != nil {
+			return summary, fmt.Errorf("failed to import the artifact %s: %w", a.ID, err)
+		} else {
+			copied = append(copied, dest)
+		}
+		if err := q.ImportArtifact(ctx, db.ImportArtifactParams{
+			ID:          a.ID,
+			SessionID:   a.SessionID,
+			MessageID:   a.MessageID,
+			Name:        a.Name,
+			SourcePath:  a.SourcePath,
+			Path:        dest,
+			Size:        a.Size,
+			Sha256:      a.Sha256,
+			MimeType:    a.MimeType,
+			Description: a.Description,
+			CreatedAt:   a.CreatedAt,
+		}); err != nil {
+			return summary, fmt.Errorf("failed to import the artifact %s: %w", a.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return summary, err
+	}
+	committed = true
+
+	summary.Sessions, summary.Messages, summary.Findings, summary.Artifacts = len(sessions), len(messages), len(findings), len(artifacts)
+	return summary, nil
+}
+
+// parentsFirst orders the sessions for every session to come after its parent.
+func parentsFirst(sessions []db.Session) []db.Session {
+	children := map[string][]db.Session{}
+	ids := map[string]bool{}
+	for _, s := range sessions {
+		ids[s.ID] = true
+	}
+	var ordered []db.Session
+	for _, s := range sessions {
+		if s.ParentSessionID.Valid && ids[s.ParentSessionID.String] {
+			children[s.ParentSessionID.String] = append(children[s.ParentSessionID.String], s)
+		} else {
+			ordered = append(ordered, s)
+		}
+	}
+	for i := 0; i < len(ordered); i++ {
+		ordered = append(ordered, children[ordered[i].ID]...)
+	}
+	return ordered
+}
+
+// importArtifactFile copies the file of an artifact into the directory, encrypted if the data directory is,
+// suffixing its name if it's already taken, and returns where it's copied to.
+func importArtifactFile(src, dir string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	name := filepath.Base(src)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	dest := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(dest); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		dest = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+	}
+	if err := encryption.WriteFile(dest, data, 0o600); err != nil {
+		os.Remove(dest)
+		return "", err
+	}
+	return dest, nil
+}
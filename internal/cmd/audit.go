@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/audit"
+	"github.com/yaydraco/tandem/internal/bundle"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/engagement"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of what the swarm did",
+	Long: `Inspect the append-only audit log of the prompts, the responses and tool calls of the agents, the tool results and
+the configuration changes. Every entry is chained to the previous one by its hash, and signed
+with the ed25519 key of the install, in ~/.tandem/signing.key, when "audit": {"sign": true} is set in swarm.json.`,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the audit log, and the messages stored against it",
+	Long: `Verify the chain of the audit log and the signatures of its entries, then check the messages stored against the
+entries recording them: any entry altered, removed or inserted, and any message altered, deleted or inserted behind
+the back of tandem is reported, and the command fails. Every entry must be signed when --signer is given, or when
+"audit": {"sign": true} is set.`,
+	Example: `  tandem audit verify
+  tandem audit verify --engagement acme --signer <key printed by tandem export>`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if name, _ := cmd.Flags().GetString("engagement"); name != "" {
+			os.Setenv(engagement.Env, name)
+		}
+		cwd, _ := cmd.Flags().GetString("cwd")
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %v", err)
+			}
+			cwd = c
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+		var public ed25519.PublicKey
+		requireSigned := config.Get().Audit.Sign
+		if signer, _ := cmd.Flags().GetString("signer"); signer != "" {
+			key, err := base64.StdEncoding.DecodeString(signer)
+			if err != nil || len(key) != ed25519.PublicKeySize {
+				return fmt.Errorf("invalid signer %s, not an ed25519 public key", signer)
+			}
+			public = key
+			requireSigned = true
+		} else {
+			key, err := bundle.SigningKey()
+			if err != nil {
+				return err
+			}
+			public = key.Public().(ed25519.PublicKey)
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		report, err := audit.Verify(cmd.Context(), db.New(conn), public, requireSigned)
+		if err != nil {
+			return err
+		}
+		for _, problem := range report.Problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+		fmt.Printf("Checked %d entries, %d of them signed, and %d messages\n", report.Entries, report.Signed, report.Messages)
+		if !report.OK() {
+			return fmt.Errorf("the audit log or the messages were tampered with, %d problems found", len(report.Problems))
+		}
+		fmt.Println("No tampering found")
+		return nil
+	},
+}
+
+func init() {
+	auditVerifyCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+	auditVerifyCmd.Flags().StringP("engagement", "e", "", "Engagement to verify, the current one by default")
+	auditVerifyCmd.Flags().String("signer", "", "Public key the entries must be signed with, the one of the install by default")
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
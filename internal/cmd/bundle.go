@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/bundle"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/engagement"
+	"github.com/yaydraco/tandem/internal/version"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the record of the engagement into a signed bundle",
+	Long: `Export the record of the engagement into a single signed and compressed bundle, to hand it to the client or move it
+to cold storage: its sessions, the task sessions of the subagents, their messages, the findings, the artifacts, the RoE
+and the swarm.json, without its providers and their API keys. A manifest lists the hashes of the files, signed with
+the ed25519 key of the install, generated in ~/.tandem/signing.key on first use. The credentials of the vault aren't
+exported.`,
+	Example: `  tandem export
+  tandem export --engagement acme -o acme.tandem`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if name, _ := cmd.Flags().GetString("engagement"); name != "" {
+			os.Setenv(engagement.Env, name)
+		}
+		cwd, _ := cmd.Flags().GetString("cwd")
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %v", err)
+			}
+			cwd = c
+		}
+		cfg, err := config.Load(cwd, false)
+		if err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		roe, err := os.ReadFile(cfg.RoEPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read the RoE: %w", err)
+		}
+		swarmPath := filepath.Join(cwd, ".tandem", "swarm.json")
+		if cfg.Engagement != "" {
+			e, err := engagement.Get(cfg.Engagement)
+			if err != nil {
+				return err
+			}
+			swarmPath = e.ConfigPath()
+		}
+		swarm, err := os.ReadFile(swarmPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", swarmPath, err)
+		}
+
+		key, err := bundle.SigningKey()
+		if err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			name := cfg.Engagement
+			if name == "" {
+				name = filepath.Base(cwd)
+			}
+			output = fmt.Sprintf("%s-%s.tandem", name, time.Now().Format("20060102"))
+		}
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create the bundle: %w", err)
+		}
+		w := bundle.NewWriter(f, key, cfg.Engagement, version.Version)
+		var manifest bundle.Manifest
+		summary, err := bundle.Export(cmd.Context(), db.New(conn), w, roe, swarm)
+		if err == nil {
+			manifest, err = w.Close()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+			return fmt.Errorf("failed to export the engagement: %w", err)
+		}
+
+		for _, path := range summary.MissingArtifacts {
+			fmt.Fprintf(os.Stderr, "The file of the artifact %s is gone, exported without it\n", path)
+		}
+		fmt.Printf("Exported %d sessions, %d messages, %d findings and %d artifacts to %s\n",
+			summary.Sessions, summary.Messages, summary.Findings, summary.Artifacts, output)
+		fmt.Printf("Signed with the key %s\n", manifest.Signer())
+		return nil
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Import a bundle into a new engagement",
+	Long: `Import a bundle exported by tandem export into a new engagement, named after the one exported unless --name is given.
+The bundle is verified first: its manifest must be signed, and list every file of the bundle with its hash. The key it's
+signed with must be the one given with --signer, the one of the install or one listed in ~/.tandem/` + bundle.TrustedSignersFile + `,
+one per line, unless --insecure is given. The findings are imported without their host, the inventory not being exported.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open the bundle: %w", err)
+		}
+		defer f.Close()
+		dir, err := os.MkdirTemp("", "tandem-import-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		manifest, err := bundle.Extract(f, dir)
+		if err != nil {
+			return err
+		}
+		if signer, _ := cmd.Flags().GetString("signer"); signer != "" {
+			if signer != manifest.Signer() {
+				return fmt.Errorf("the bundle is signed with the key %s, not %s", manifest.Signer(), signer)
+			}
+		} else if trusted, err := manifest.Trusted(); err != nil {
+			return err
+		} else if !trusted {
+			// NOTE: anyone can sign a bundle with a key of their own, the signature proves nothing unless the key is known.
+			if insecure, _ := cmd.Flags().GetBool("insecure"); !insecure {
+				return fmt.Errorf("the bundle is signed with the key %s, which isn't trusted: check it with whoever exported the bundle "+
+					"and give it with --signer, or list it in ~/.tandem/%s", manifest.Signer(), bundle.TrustedSignersFile)
+			}
+			fmt.Fprintf(os.Stderr, "Importing the bundle signed with the untrusted key %s\n", manifest.Signer())
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			name = manifest.Engagement
+		}
+		if name == "" {
+			return fmt.Errorf("the bundle isn't of an engagement, name the one to import it into with --name")
+		}
+		roe, err := bundle.RoE(dir)
+		if err != nil {
+			return fmt.Errorf("corrupted bundle: %w", err)
+		}
+		swarm, err := bundle.Config(dir)
+		if err != nil {
+			return fmt.Errorf("corrupted bundle: invalid swarm.json: %w", err)
+		}
+		e, err := engagement.New(name, roe)
+		if err != nil {
+			return err
+		}
+		if err := importEngagement(cmd, e, dir, swarm); err != nil {
+			// NOTE: the engagement is created by the import, nothing is lost by removing it.
+			os.RemoveAll(e.Dir())
+			return err
+		}
+		fmt.Printf("Signed with the key %s\n", manifest.Signer())
+		return nil
+	},
+}
+
+// importEngagement restores the records of the bundle extracted into dir into the engagement just created.
+func importEngagement(cmd *cobra.Command, e engagement.Engagement, dir string, swarm []byte) error {
+	if swarm != nil {
+		if err := os.WriteFile(e.ConfigPath(), swarm, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", engagement.ConfigFile, err)
+		}
+	}
+	os.Setenv(engagement.Env, e.Name)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %v", err)
+	}
+	if _, err := config.Load(cwd, false); err != nil {
+		return err
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var signingKey ed25519.PrivateKey
+	if config.Get().Audit.Sign {
+		if signingKey, err = bundle.SigningKey(); err != nil {
+			return err
+		}
+	}
+	summary, err := bundle.Import(cmd.Context(), conn, dir, filepath.Join(config.EngagementDirectory(), "artifacts"), signingKey)
+	if err != nil {
+		return fmt.Errorf("failed to import the bundle: %w", err)
+	}
+	for _, path := range summary.MissingArtifacts {
+		fmt.Fprintf(os.Stderr, "The file of the artifact %s wasn't exported, imported without it\n", path)
+	}
+	fmt.Printf("Imported %d sessions, %d messages, %d findings and %d artifacts into engagement %s\n",
+		summary.Sessions, summary.Messages, summary.Findings, summary.Artifacts, e.Name)
+	return nil
+}
+
+func init() {
+	exportCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+	exportCmd.Flags().StringP("output", "o", "", "File of the bundle, <engagement>-<date>.tandem by default")
+	exportCmd.Flags().StringP("engagement", "e", "", "Engagement to export, the current one by default")
+	importCmd.Flags().String("name", "", "Name of the engagement to import the bundle into, the one exported by default")
+	importCmd.Flags().String("signer", "", "Public key the bundle must be signed with, as printed by tandem export")
+	importCmd.Flags().Bool("insecure", false, "Import the bundle even though it isn't signed with a trusted key")
+	rootCmd.AddCommand(exportCmd, importCmd)
+}
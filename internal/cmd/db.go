@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/encryption"
+	"github.com/yaydraco/tandem/internal/vault"
+)
+
+const (
+	// newPassphraseEnv is the environment variable the new passphrase of rekey is taken from, before the prompt.
+	newPassphraseEnv = "TANDEM_DB_NEW_PASSPHRASE"
+	// debugLogFile is the debug log of the data directory, no longer written once the data directory is encrypted.
+	debugLogFile = "debug.log"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the database of the engagement",
+}
+
+var dbEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the database, the session log files and the artifacts at rest",
+	Long: `Encrypt the database of the data directory, the session log files and the artifacts at rest, with a key derived
+from a new passphrase. The passphrase is taken from ` + encryption.PassphraseEnv + ` or prompted for, and is asked for
+whenever the database is opened afterwards, unless it's in ` + encryption.PassphraseEnv + ` or the OS keyring.
+An interrupted encryption is resumed by running it again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDir, err := loadDataDir(cmd)
+		if err != nil {
+			return err
+		}
+		var (
+			params encryption.Params
+			key    *encryption.Key
+		)
+		if encryption.Enabled(dataDir) {
+			// NOTE: the encryption of a plaintext data directory was interrupted, it's resumed with its key.
+			if params, err = encryption.ReadParams(dataDir); err != nil {
+				return err
+			}
+			if key, err = encryption.Unlock(dataDir, params); err != nil {
+				return err
+			}
+		} else {
+			passphrase := os.Getenv(encryption.PassphraseEnv)
+			if passphrase == "" {
+				if passphrase, err = encryption.PromptNew(); err != nil {
+					return err
+				}
+			}
+			if params, key, err = encryption.NewParams(passphrase); err != nil {
+				return err
+			}
+			if err := storeInKeyring(cmd, dataDir, passphrase); err != nil {
+				return err
+			}
+			// NOTE: the parameters are written first, for the data encrypted with their key not to be lost if interrupted.
+			if err := encryption.WriteParams(dataDir, params); err != nil {
+				return err
+			}
+		}
+		if err := reencryptDataDir(cmd.Context(), dataDir, nil, key); err != nil {
+			return err
+		}
+		fmt.Println("The data directory is encrypted")
+		return nil
+	},
+}
+
+var dbRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Change the passphrase the database is encrypted with",
+	Long: `Change the passphrase the database of the data directory, the session log files and the artifacts are encrypted with.
+The current passphrase is taken from ` + encryption.PassphraseEnv + `, the OS keyring or prompted for, the new one from ` + newPassphraseEnv + `
+or prompted for. The new passphrase replaces the former one in the OS keyring if it's there. An interrupted rekey is resumed
+by running it again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDir, err := loadDataDir(cmd)
+		if err != nil {
+			return err
+		}
+		if !encryption.Enabled(dataDir) {
+			return fmt.Errorf("the data directory isn't encrypted, see tandem db encrypt")
+		}
+		params, err := encryption.ReadParams(dataDir)
+		if err != nil {
+			return err
+		}
+		from, err := encryption.Unlock(dataDir, params)
+		if err != nil {
+			return err
+		}
+
+		newPassphrase := os.Getenv(newPassphraseEnv)
+		if newPassphrase == "" {
+			if newPassphrase, err = encryption.PromptNew(); err != nil {
+				return err
+			}
+		}
+		var to *encryption.Key
+		pending, ok, err := encryption.PendingRekey(dataDir)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if to, err = pending.Unlock(newPassphrase); err != nil {
+				return fmt.Errorf("an interrupted rekey is resumed with its new passphrase: %w", err)
+			}
+		} else {
+			var newParams encryption.Params
+			if newParams, to, err = encryption.NewParams(newPassphrase); err != nil {
+				return err
+			}
+			if err := encryption.BeginRekey(dataDir, newParams); err != nil {
+				return err
+			}
+		}
+		if err := reencryptDataDir(cmd.Context(), dataDir, from, to); err != nil {
+			return err
+		}
+		if err := encryption.CommitRekey(dataDir); err != nil {
+			return err
+		}
+		if err := storeInKeyring(cmd, dataDir, newPassphrase); err != nil {
+			return err
+		}
+		fmt.Println("The data directory is encrypted with the new passphrase")
+		return nil
+	},
+}
+
+var dbCatCmd = &cobra.Command{
+	Use:   "cat <file>",
+	Short: "Print a session log file or an artifact, decrypted",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDir, err := loadDataDir(cmd)
+		if err != nil {
+			return err
+		}
+		if encryption.Enabled(dataDir) {
+			params, err := encryption.ReadParams(dataDir)
+			if err != nil {
+				return err
+			}
+			key, err := encryption.Unlock(dataDir, params)
+			if err != nil {
+				return err
+			}
+			encryption.Activate(key)
+		}
+		data, err := encryption.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+// loadDataDir loads the configuration of the working directory and returns its data directory.
+func loadDataDir(cmd *cobra.Command) (string, error) {
+	cwd, _ := cmd.Flags().GetString("cwd")
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		cwd = c
+	}
+	cfg, err := config.Load(cwd, false)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Data.Directory == "" {
+		return "", fmt.Errorf("data.dir is not set")
+	}
+	return cfg.Data.Directory, nil
+}
+
+// storeInKeyring stores the new passphrase of the data directory in the OS keyring if asked to, or if the keyring holds
+// a former one, which would no longer unlock the data directory.
+func storeInKeyring(cmd *cobra.Command, dataDir, passphrase string) error {
+	keyring, _ := cmd.Flags().GetBool("keyring")
+	if !keyring {
+		if former, err := encryption.KeyringGet(dataDir); err != nil || former == "" || former == passphrase {
+			return nil
+		}
+	}
+	return encryption.KeyringSet(dataDir, passphrase)
+}
+
+// reencryptDataDir encrypts the database, the key of the vault, the debug log written before the data directory
+// was encrypted, the session log files and the artifacts with the key to, decrypting them with the key from,
+// nil if they're in plaintext.
+func reencryptDataDir(ctx context.Context, dataDir string, from, to *encryption.Key) error {
+	if err := db.Reencrypt(ctx, filepath.Join(dataDir, db.FileName), from, to); err != nil {
+		return err
+	}
+	for _, name := range []string{vault.KeyFile, debugLogFile} {
+		if err := encryption.ReencryptFile(filepath.Join(dataDir, name), from, to); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to encrypt %s: %w", name, err)
+		}
+	}
+	for _, dir := range []string{
+		filepath.Join(dataDir, "messages"),
+		filepath.Join(config.EngagementDirectory(), "artifacts"),
+	} {
+		count, err := encryption.ReencryptDir(dir, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", dir, err)
+		}
+		if count > 0 {
+			fmt.Printf("Encrypted %d files of %s\n", count, dir)
+		}
+	}
+	return nil
+}
+
+func init() {
+	dbCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
+	dbEncryptCmd.Flags().Bool("keyring", false, "Store the passphrase in the OS keyring")
+	dbRekeyCmd.Flags().Bool("keyring", false, "Store the new passphrase in the OS keyring")
+	dbCmd.AddCommand(dbEncryptCmd, dbRekeyCmd, dbCatCmd)
+	rootCmd.AddCommand(dbCmd)
+}
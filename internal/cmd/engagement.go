@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/engagement"
+	"github.com/yaydraco/tandem/internal/tools"
+)
+
+var engagementCmd = &cobra.Command{
+	Use:   "engagement",
+	Short: "Manage the engagements, the workspaces of the clients",
+	Long: `Manage the engagements, each one having its own RoE, swarm.json overrides, data directory, database and sandbox,
+under ~/.tandem/engagements. tandem opens the current engagement, or the one named by ` + engagement.Env + `,
+and uses the configuration of the working directory when there is none.`,
+}
+
+var engagementNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Create an engagement",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var roe []byte
+		if path, _ := cmd.Flags().GetString("roe"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read the RoE: %w", err)
+			}
+			roe = data
+		}
+		e, err := engagement.New(args[0], roe)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created engagement %s in %s\n", e.Name, e.Dir())
+		if switchTo, _ := cmd.Flags().GetBool("switch"); switchTo {
+			if err := engagement.Switch(e.Name); err != nil {
+				return err
+			}
+			fmt.Printf("Switched to engagement %s\n", e.Name)
+		}
+		return nil
+	},
+}
+
+var engagementListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the engagements, the current one marked with *",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engagements, err := engagement.List()
+		if err != nil {
+			return err
+		}
+		current, err := engagement.Current()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tNAME\tCREATED\tSTATUS")
+		for _, e := range engagements {
+			marker, status := "", "active"
+			if e.Name == current {
+				marker = "*"
+			}
+			if e.Archived {
+				status = "archived " + e.ArchivedAt.Local().Format(time.DateOnly)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, e.Name, e.CreatedAt.Local().Format(time.DateOnly), status)
+		}
+		return w.Flush()
+	},
+}
+
+var engagementSwitchCmd = &cobra.Command{
+	Use:   "switch [name]",
+	Short: "Switch to an engagement, or back to the configuration of the working directory with --none",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		none, _ := cmd.Flags().GetBool("none")
+		if none == (len(args) == 1) {
+			return fmt.Errorf("either an engagement or --none is required")
+		}
+		if none {
+			if err := engagement.Switch(""); err != nil {
+				return err
+			}
+			fmt.Println("Switched to the configuration of the working directory")
+			return nil
+		}
+		if err := engagement.Switch(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to engagement %s\n", args[0])
+		return nil
+	},
+}
+
+var engagementArchiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Archive an engagement, keeping its data and stopping its sandbox",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		e, err := engagement.Archive(args[0])
+		if err != nil {
+			return err
+		}
+		if err := tools.StopSandbox(cmd.Context(), e.Sandbox()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to stop the sandbox of engagement %s: %v\n", e.Name, err)
+		}
+		fmt.Printf("Archived engagement %s\n", e.Name)
+		return nil
+	},
+}
+
+func init() {
+	engagementNewCmd.Flags().String("roe", "", "File of the rules of engagement to copy into the engagement")
+	engagementNewCmd.Flags().Bool("switch", false, "Switch to the engagement once created")
+	engagementSwitchCmd.Flags().Bool("none", false, "Switch back to the configuration of the working directory")
+	engagementCmd.AddCommand(engagementNewCmd, engagementListCmd, engagementSwitchCmd, engagementArchiveCmd)
+	rootCmd.AddCommand(engagementCmd)
+}
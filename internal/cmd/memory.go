@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/engagement"
+	"github.com/yaydraco/tandem/internal/memory"
+)
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Manage the long-term memory of the engagement",
+	Long: `Manage the facts the agents remembered over the engagement with the memory tool. The ones relevant to a task
+are given to the agent it's assigned to, along with its system prompt.`,
+}
+
+var memoryListCmd = &cobra.Command{
+	Use:   "list [words]...",
+	Short: "List the memories, or the ones holding any of the words",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		memories, closeMemory, err := openMemory(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeMemory()
+
+		var items []memory.Memory
+		if len(args) > 0 {
+			limit, _ := cmd.Flags().GetInt("limit")
+			items, err = memories.Search(cmd.Context(), strings.Join(args, " "), limit)
+		} else {
+			items, err = memories.List(cmd.Context())
+		}
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCREATED\tAGENT\tSUBJECT\tCONTENT")
+		for _, m := range items {
+			content := strings.Join(strings.Fields(m.Content), " ")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.ID, time.Unix(m.CreatedAt, 0).Local().Format(time.DateTime), m.Agent, m.Subject, content)
+		}
+		return w.Flush()
+	},
+}
+
+var memoryForgetCmd = &cobra.Command{
+	Use:   "forget <id>...",
+	Short: "Delete memories which are wrong or outdated",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		memories, closeMemory, err := openMemory(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeMemory()
+
+		for _, id := range args {
+			err := memories.Forget(cmd.Context(), id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no memory %s", id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// openMemory opens the memory of the current engagement, or of the one named by the --engagement flag.
+func openMemory(cmd *cobra.Command) (memory.Service, func(), error) {
+	if name, _ := cmd.Flags().GetString("engagement"); name != "" {
+		os.Setenv(engagement.Env, name)
+	}
+	cwd, _ := cmd.Flags().GetString("cwd")
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		cwd = c
+	}
+	if _, err := config.Load(cwd, false); err != nil {
+		return nil, nil, err
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return memory.NewService(db.New(conn)), func() { conn.Close() }, nil
+}
+
+func init() {
+	memoryCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
+	memoryCmd.PersistentFlags().StringP("engagement", "e", "", "Engagement of the memory, the current one by default")
+	memoryListCmd.Flags().IntP("limit", "n", 50, "Maximum number of memories matching the words")
+	memoryCmd.AddCommand(memoryListCmd, memoryForgetCmd)
+	rootCmd.AddCommand(memoryCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/models"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Manage the catalog of supported models",
+}
+
+var modelsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync the catalog of models from models.dev into the data directory",
+	Long: fmt.Sprintf(`Sync the catalog of models from %s into the data directory.
+The pricing and limits of the supported models are refreshed and the new models become available.
+Private models can be listed in %s within the data directory, taking precedence over the catalog.`, models.CatalogURL, models.OverridesFile),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %v", err)
+			}
+			cwd = c
+		}
+		cfg, err := config.Load(cwd, false)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+		defer cancel()
+		data, err := models.FetchCatalog(ctx)
+		if err != nil {
+			return err
+		}
+		catalog, err := models.ParseCatalog(data)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(cfg.Data.Directory, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		path := filepath.Join(cfg.Data.Directory, models.CatalogFile)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write the catalog: %w", err)
+		}
+		fmt.Printf("Synced %d models from models.dev into %s\n", len(catalog), path)
+		return nil
+	},
+}
+
+func init() {
+	modelsSyncCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+	modelsCmd.AddCommand(modelsSyncCmd)
+	rootCmd.AddCommand(modelsCmd)
+}
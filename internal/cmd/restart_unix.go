@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cmd
+
+import "syscall"
+
+// execSelf replaces the process with tandem run with the arguments and the environment, the terminal and
+// the PID being handed over as they are.
+func execSelf(exe string, args, env []string) error {
+	return syscall.Exec(exe, append([]string{exe}, args...), env)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// execSelf runs tandem with the arguments and the environment, exiting with its exit code once it's done,
+// Windows having no exec.
+func execSelf(exe string, args, env []string) error {
+	restart := exec.Command(exe, args...)
+	restart.Env = env
+	restart.Stdin, restart.Stdout, restart.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := restart.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
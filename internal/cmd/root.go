@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,9 +15,11 @@ import (
 	"github.com/yaydraco/tandem/internal/app"
 	"github.com/yaydraco/tandem/internal/config"
 	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/engagement"
 	"github.com/yaydraco/tandem/internal/format"
 	"github.com/yaydraco/tandem/internal/logging"
 	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/tools"
 	"github.com/yaydraco/tandem/internal/tui"
 	"github.com/yaydraco/tandem/internal/version"
 )
@@ -152,10 +156,35 @@ var rootCmd = cobra.Command{
 		}
 
 		logging.Info("TUI exited with result: %v", result)
+		if name, ok := tui.SwitchedEngagement(result); ok {
+			conn.Close()
+			return restartInEngagement(name)
+		}
 		return nil
 	},
 }
 
+// restartInEngagement runs tandem again with the same arguments, in the engagement switched to from the TUI,
+// the configuration of the working directory if name is empty.
+func restartInEngagement(name string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to restart in engagement %s: %w", name, err)
+	}
+	logging.Info("Restarting in engagement", "engagement", name)
+	args := os.Args[1:]
+	// NOTE: the working directory was changed to --cwd already, a relative one wouldn't be found again.
+	if wd, err := os.Getwd(); err == nil {
+		args = append(args, "--cwd", wd)
+	}
+	// NOTE: the engagement the process was started in is replaced, the first one of the environment being the one read.
+	env := slices.DeleteFunc(os.Environ(), func(v string) bool { return strings.HasPrefix(v, engagement.Env+"=") })
+	if err := execSelf(exe, args, append(env, engagement.Env+"="+name)); err != nil {
+		return fmt.Errorf("failed to restart in engagement %s: %w", name, err)
+	}
+	return nil
+}
+
 func setupSubscriptions(app *app.App, parentCtx context.Context) (chan tea.Msg, func()) {
 	ch := make(chan tea.Msg, 100)
 
@@ -166,6 +195,7 @@ func setupSubscriptions(app *app.App, parentCtx context.Context) (chan tea.Msg,
 	setupSubscriber(ctx, &wg, "sessions", app.Sessions.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "messages", app.Messages.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "orchestrator", app.Orchestrator.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "shell-sessions", tools.ShellSessions.Subscribe, ch)
 
 	cleanupFunc := func() {
 		logging.Info("Cancelling all subscriptions")
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/audit"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/engagement"
+	"github.com/yaydraco/tandem/internal/message"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <words>...",
+	Short: "Search the messages and tool results of every session",
+	Long: `Search the messages and the tool results of every session of the engagement, the task sessions of the agents
+included, for the ones holding all the words, the best matches first. A word ending with * matches the words it
+prefixes.`,
+	Example: `  tandem search tomcat manager creds
+  tandem search --engagement acme 10.10.10.5 smb*`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if name, _ := cmd.Flags().GetString("engagement"); name != "" {
+			os.Setenv(engagement.Env, name)
+		}
+		cwd, _ := cmd.Flags().GetString("cwd")
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %v", err)
+			}
+			cwd = c
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		q := db.New(conn)
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		results, err := message.NewService(q, audit.NewService(q, nil)).Search(cmd.Context(), strings.Join(args, " "), limit)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			fmt.Println("No matches")
+			return nil
+		}
+		bold := lipgloss.NewStyle().Bold(true)
+		highlight := func(term string) string { return bold.Render(term) }
+		for _, r := range results {
+			session := r.SessionTitle
+			if r.SessionID != r.RootSessionID {
+				session = r.RootSessionTitle + " > " + session
+			}
+			fmt.Printf("%s  %s  (%s, %s)\n", time.Unix(r.CreatedAt, 0).Local().Format(time.DateTime), bold.Render(session), r.SessionID, r.Role)
+			fmt.Printf("    %s\n\n", r.HighlightSnippet(highlight))
+		}
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+	searchCmd.Flags().StringP("engagement", "e", "", "Engagement to search, the current one by default")
+	searchCmd.Flags().IntP("limit", "n", 20, "Maximum number of results")
+	rootCmd.AddCommand(searchCmd)
+}
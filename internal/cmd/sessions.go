@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/audit"
+	"github.com/yaydraco/tandem/internal/bundle"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/engagement"
+	"github.com/yaydraco/tandem/internal/format"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/session"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage the sessions of the engagement",
+	Long: `Manage the chat sessions of the engagement: rename them, tag them to sort them out, archive the ones done with
+and delete the ones of no use, along with their messages and the task sessions of their agents. The archived
+sessions are left out of the lists unless asked for.`,
+	Example: `  tandem sessions list --tag web
+  tandem sessions tag <id> web foothold
+  tandem sessions archive <id>
+  tandem sessions show <id> -f json`,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the sessions, the latest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, closeSessions, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+
+		all, err := sessions.List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		archived, _ := cmd.Flags().GetBool("archived")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		items := []session.Session{}
+		for _, s := range all {
+			if s.Archived() != archived {
+				continue
+			}
+			if slices.ContainsFunc(tags, func(tag string) bool { return !s.HasTag(tag) }) {
+				continue
+			}
+			items = append(items, s)
+		}
+
+		if jsonOutput(cmd) {
+			return printJSON(sessionsJSON(items))
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUPDATED\tTITLE\tMESSAGES\tCOST\tTAGS")
+		for _, s := range items {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t$%.2f\t%s\n", s.ID, time.Unix(s.UpdatedAt, 0).Local().Format(time.DateTime), s.Title, s.MessageCount, s.Cost, strings.Join(s.Tags, " "))
+		}
+		return w.Flush()
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, closeSessions, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+
+		s, err := getSession(cmd, sessions, args[0])
+		if err != nil {
+			return err
+		}
+		return printSession(cmd, s)
+	},
+}
+
+var sessionsRenameCmd = &cobra.Command{
+	Use:   "rename <id> <title>...",
+	Short: "Rename a session",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, closeSessions, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+
+		if _, err := getSession(cmd, sessions, args[0]); err != nil {
+			return err
+		}
+		s, err := sessions.Rename(cmd.Context(), args[0], strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		return printSession(cmd, s)
+	},
+}
+
+var sessionsTagCmd = &cobra.Command{
+	Use:   "tag <id> <tag>...",
+	Short: "Tag a session, or untag it with --remove",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, closeSessions, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+
+		s, err := getSession(cmd, sessions, args[0])
+		if err != nil {
+			return err
+		}
+		tags := append(slices.Clone(s.Tags), args[1:]...)
+		if remove, _ := cmd.Flags().GetBool("remove"); remove {
+			tags = slices.DeleteFunc(slices.Clone(s.Tags), func(tag string) bool {
+				return slices.ContainsFunc(args[1:], func(removed string) bool {
+					return strings.EqualFold(strings.TrimSpace(removed), tag)
+				})
+			})
+		}
+		s, err = sessions.SetTags(cmd.Context(), s.ID, tags)
+		if err != nil {
+			return err
+		}
+		return printSession(cmd, s)
+	},
+}
+
+var sessionsArchiveCmd = &cobra.Command{
+	Use:   "archive <id>...",
+	Short: "Archive sessions, or restore them with --undo",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, closeSessions, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+
+		undo, _ := cmd.Flags().GetBool("undo")
+		items := []session.Session{}
+		for _, id := range args {
+			if _, err := getSession(cmd, sessions, id); err != nil {
+				return err
+			}
+			s, err := sessions.Archive(cmd.Context(), id, !undo)
+			if err != nil {
+				return err
+			}
+			items = append(items, s)
+		}
+		if jsonOutput(cmd) {
+			return printJSON(sessionsJSON(items))
+		}
+		return nil
+	},
+}
+
+var sessionsDeleteCmd = &cobra.Command{
+	Use:   "delete <id>...",
+	Short: "Delete sessions, along with their messages and the task sessions of their agents",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, closeSessions, err := openSessions(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+
+		for _, id := range args {
+			if _, err := getSession(cmd, sessions, id); err != nil {
+				return err
+			}
+			if err := sessions.Delete(cmd.Context(), id); err != nil {
+				return err
+			}
+		}
+		if jsonOutput(cmd) {
+			return printJSON(map[string][]string{"deleted": args})
+		}
+		return nil
+	},
+}
+
+// sessionJSON is the JSON output of a session.
+type sessionJSON struct {
+	ID                  string   `json:"id"`
+	Title               string   `json:"title"`
+	Tags                []string `json:"tags"`
+	Archived            bool     `json:"archived"`
+	ArchivedAt          int64    `json:"archived_at,omitempty"`
+	ForkedFromSessionID string   `json:"forked_from_session_id,omitempty"`
+	ForkedFromMessageID string   `json:"forked_from_message_id,omitempty"`
+	MessageCount        int64    `json:"message_count"`
+	PromptTokens        int64    `json:"prompt_tokens"`
+	CompletionTokens    int64    `json:"completion_tokens"`
+	Cost                float64  `json:"cost"`
+	CreatedAt           int64    `json:"created_at"`
+	UpdatedAt           int64    `json:"updated_at"`
+}
+
+func toSessionJSON(s session.Session) sessionJSON {
+	tags := s.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return sessionJSON{
+		ID:                  s.ID,
+		Title:               s.Title,
+		Tags:                tags,
+		Archived:            s.Archived(),
+		ArchivedAt:          s.ArchivedAt,
+		ForkedFromSessionID: s.ForkedFromSessionID,
+		ForkedFromMessageID: s.ForkedFromMessageID,
+		MessageCount:        s.MessageCount,
+		PromptTokens:        s.PromptTokens,
+		CompletionTokens:    s.CompletionTokens,
+		Cost:                s.Cost,
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	}
+}
+
+func sessionsJSON(sessions []session.Session) []sessionJSON {
+	items := make([]sessionJSON, len(sessions))
+	for i, s := range sessions {
+		items[i] = toSessionJSON(s)
+	}
+	return items
+}
+
+func printSession(cmd *cobra.Command, s session.Session) error {
+	if jsonOutput(cmd) {
+		return printJSON(toSessionJSON(s))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", s.ID)
+	fmt.Fprintf(w, "Title:\t%s\n", s.Title)
+	fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(s.Tags, " "))
+	if s.Archived() {
+		fmt.Fprintf(w, "Archived:\t%s\n", time.Unix(s.ArchivedAt, 0).Local().Format(time.DateTime))
+	}
+	if s.ForkedFromSessionID != "" {
+		fmt.Fprintf(w, "Forked from:\t%s, message %s\n", s.ForkedFromSessionID, s.ForkedFromMessageID)
+	}
+	fmt.Fprintf(w, "Messages:\t%d\n", s.MessageCount)
+	fmt.Fprintf(w, "Tokens:\t%d prompt, %d completion\n", s.PromptTokens, s.CompletionTokens)
+	fmt.Fprintf(w, "Cost:\t$%.2f\n", s.Cost)
+	fmt.Fprintf(w, "Created:\t%s\n", time.Unix(s.CreatedAt, 0).Local().Format(time.DateTime))
+	fmt.Fprintf(w, "Updated:\t%s\n", time.Unix(s.UpdatedAt, 0).Local().Format(time.DateTime))
+	return w.Flush()
+}
+
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func jsonOutput(cmd *cobra.Command) bool {
+	outputFormat, _ := cmd.Flags().GetString("output-format")
+	f, _ := format.Parse(outputFormat)
+	return f == format.JSON
+}
+
+// getSession gets the session, which must be one of the operator's, not the task session of an agent.
+func getSession(cmd *cobra.Command, sessions session.Service, id string) (session.Session, error) {
+	s, err := sessions.Get(cmd.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && s.ParentSessionID != "") {
+		return session.Session{}, fmt.Errorf("no session %s", id)
+	}
+	return s, err
+}
+
+// openSessions opens the sessions of the current engagement, or of the one named by the --engagement flag.
+func openSessions(cmd *cobra.Command) (session.Service, func(), error) {
+	outputFormat, _ := cmd.Flags().GetString("output-format")
+	if !format.IsValid(outputFormat) {
+		return nil, nil, fmt.Errorf("invalid format option: %s\n%s", outputFormat, format.GetHelpText())
+	}
+	if name, _ := cmd.Flags().GetString("engagement"); name != "" {
+		os.Setenv(engagement.Env, name)
+	}
+	cwd, _ := cmd.Flags().GetString("cwd")
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		cwd = c
+	}
+	if _, err := config.Load(cwd, false); err != nil {
+		return nil, nil, err
+	}
+	// NOTE: the deletions are recorded into the audit log, signed as they are by the app.
+	var signingKey ed25519.PrivateKey
+	if config.Get().Audit.Sign {
+		key, err := bundle.SigningKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		signingKey = key
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	q := db.New(conn)
+	auditLog := audit.NewService(q, signingKey)
+	return session.NewService(q, auditLog, message.NewService(q, auditLog)), func() { conn.Close() }, nil
+}
+
+func init() {
+	sessionsCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
+	sessionsCmd.PersistentFlags().StringP("engagement", "e", "", "Engagement of the sessions, the current one by default")
+	sessionsCmd.PersistentFlags().StringP("output-format", "f", format.Text.String(), "Output format (text, json)")
+	sessionsListCmd.Flags().StringSliceP("tag", "t", nil, "List the sessions with the tag only, repeatable")
+	sessionsListCmd.Flags().Bool("archived", false, "List the archived sessions instead")
+	sessionsTagCmd.Flags().BoolP("remove", "r", false, "Remove the tags instead")
+	sessionsArchiveCmd.Flags().BoolP("undo", "u", false, "Restore the archived sessions instead")
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsShowCmd, sessionsRenameCmd, sessionsTagCmd, sessionsArchiveCmd, sessionsDeleteCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/vault"
+)
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Manage the credentials discovered during the engagement",
+	Long: `Manage the credentials the agents stored in the encrypted vault of the data directory.
+Their secrets are redacted from the messages and the logs, the agents use them by their placeholders.`,
+}
+
+var vaultListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the credentials of the vault, without their secrets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vaults, closeVault, err := openVault(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeVault()
+
+		credentials, err := vaults.List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "REF\tKIND\tUSERNAME\tTARGET\tNOTES")
+		for _, c := range credentials {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Ref, c.Kind, c.Username, c.Target, c.Notes)
+		}
+		return w.Flush()
+	},
+}
+
+var vaultRevealCmd = &cobra.Command{
+	Use:   "reveal <ref>",
+	Short: "Print the secret of a credential of the vault in plaintext",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vaults, closeVault, err := openVault(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeVault()
+
+		secret, err := vaults.Reveal(cmd.Context(), args[0])
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no credential stored for %s", args[0])
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(secret)
+		return nil
+	},
+}
+
+// openVault opens the vault of the data directory of the working directory.
+func openVault(cmd *cobra.Command) (vault.Service, func(), error) {
+	cwd, _ := cmd.Flags().GetString("cwd")
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		cwd = c
+	}
+	cfg, err := config.Load(cwd, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := vault.LoadKey(cfg.Data.Directory)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	vaults, err := vault.NewService(db.New(conn), key)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return vaults, func() { conn.Close() }, nil
+}
+
+func init() {
+	vaultCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
+	vaultCmd.AddCommand(vaultListCmd, vaultRevealCmd)
+	rootCmd.AddCommand(vaultCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/vulndb"
+)
+
+var vulndbCmd = &cobra.Command{
+	Use:   "vulndb",
+	Short: "Manage the offline vulnerability database",
+}
+
+var vulndbImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import vulnerability datasets into the database of the data directory",
+	Long: `Import vulnerability datasets into the database of the data directory, for the vuln_lookup tool to work offline.
+The NVD JSON 2.0 feeds (nvdcve-2.0-<year>.json, gzipped or not), the CISA catalog of known exploited vulnerabilities
+(known_exploited_vulnerabilities.json) and the Exploit-DB index searchsploit reads (files_exploits.csv) are supported.
+Importing a dataset again updates what was imported of it before.`,
+	Example: `  tandem vulndb import --nvd nvdcve-2.0-2023.json.gz --nvd nvdcve-2.0-2024.json.gz
+  tandem vulndb import --kev known_exploited_vulnerabilities.json --exploitdb /usr/share/exploitdb/files_exploits.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := cmd.Flags().GetString("cwd")
+		if cwd == "" {
+			c, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current working directory: %v", err)
+			}
+			cwd = c
+		}
+		nvd, _ := cmd.Flags().GetStringSlice("nvd")
+		kev, _ := cmd.Flags().GetStringSlice("kev")
+		exploitdb, _ := cmd.Flags().GetStringSlice("exploitdb")
+		if len(nvd)+len(kev)+len(exploitdb) == 0 {
+			return fmt.Errorf("no datasets to import, see --help")
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx := cmd.Context()
+		datasets := []struct {
+			name     string
+			paths    []string
+			items    string
+			importer func(context.Context, *sql.DB, io.Reader) (int, error)
+		}{
+			{"NVD", nvd, "CVEs", vulndb.ImportNVD},
+			{"KEV", kev, "known exploited CVEs", vulndb.ImportKEV},
+			{"Exploit-DB", exploitdb, "exploits", vulndb.ImportExploitDB},
+		}
+		for _, dataset := range datasets {
+			for _, path := range dataset.paths {
+				f, err := os.Open(path)
+				if err != nil {
+					return fmt.Errorf("failed to open the %s dataset: %w", dataset.name, err)
+				}
+				count, err := dataset.importer(ctx, conn, f)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("failed to import %s: %w", path, err)
+				}
+				fmt.Printf("Imported %d %s from %s\n", count, dataset.items, path)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	vulndbImportCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+	vulndbImportCmd.Flags().StringSlice("nvd", nil, "NVD JSON 2.0 feed to import, repeated for every year")
+	vulndbImportCmd.Flags().StringSlice("kev", nil, "CISA KEV catalog to import")
+	vulndbImportCmd.Flags().StringSlice("exploitdb", nil, "Exploit-DB files_exploits.csv to import")
+	vulndbCmd.AddCommand(vulndbImportCmd)
+	rootCmd.AddCommand(vulndbCmd)
+}
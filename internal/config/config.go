@@ -4,15 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/spf13/viper"
+	"github.com/yaydraco/tandem/internal/encryption"
+	"github.com/yaydraco/tandem/internal/engagement"
 	"github.com/yaydraco/tandem/internal/logging"
 	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/redact"
 )
 
 // Application constants
@@ -28,6 +34,8 @@ const (
 var (
 	onceContext    sync.Once
 	contextContent string
+
+	scopeNamePattern = regexp.MustCompile(`^(\*\.)?[A-Za-z0-9]([A-Za-z0-9\-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9\-]*[A-Za-z0-9])?)*$`)
 )
 
 // NOTE: corresponds to swarm.json
@@ -39,6 +47,13 @@ type Config struct {
 	Agents      map[AgentName]Agent               `json:"agents,omitempty"`
 	Debug       bool                              `json:"debug,omitempty"`
 	AutoCompact bool                              `json:"autoCompact,omitempty"`
+	Budgets     Budgets                           `json:"budgets,omitempty"`
+	Scope       Scope                             `json:"scope,omitempty"`
+	Redaction   Redaction                         `json:"redaction,omitempty"`
+	Sandbox     string                            `json:"sandbox,omitempty"`
+	Audit       Audit                             `json:"audit,omitempty"`
+	// Engagement is the name of the engagement opened, empty when the configuration of the working directory is used.
+	Engagement string `json:"-"`
 }
 
 // Global configuration instance
@@ -52,8 +67,145 @@ type Data struct {
 
 // Provider defines configuration for an LLM provider.
 type Provider struct {
-	APIKey   string `json:"apiKey"`
-	Disabled bool   `json:"disabled"`
+	APIKey    string    `json:"apiKey"`
+	Disabled  bool      `json:"disabled"`
+	RateLimit RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit defines the quota shared by every agent calling a provider. zero means unlimited.
+type RateLimit struct {
+	RequestsPerMinute int `json:"requestsPerMinute,omitempty"`
+	TokensPerMinute   int `json:"tokensPerMinute,omitempty"`
+}
+
+// Budget defines a spend limit in USD along with the fractions of the limit
+// at which a warning is published.
+type Budget struct {
+	Limit      float64   `json:"limit,omitempty"`
+	Thresholds []float64 `json:"thresholds,omitempty"`
+}
+
+// Budgets defines the spend limits for the whole engagement and for every
+// root session, subagent task sessions included.
+type Budgets struct {
+	Engagement Budget `json:"engagement,omitempty"`
+	Session    Budget `json:"session,omitempty"`
+}
+
+// DefaultBudgetThresholds are used when a budget has a limit but no thresholds.
+var DefaultBudgetThresholds = []float64{0.5, 0.8, 0.9}
+
+// Exceeded reports whether the spent amount has reached the limit.
+// A budget without a limit is never exceeded.
+func (b Budget) Exceeded(spent float64) bool {
+	return b.Limit > 0 && spent >= b.Limit
+}
+
+// Crossed returns the highest warning threshold reached by the spent amount.
+func (b Budget) Crossed(spent float64) (float64, bool) {
+	if b.Limit <= 0 {
+		return 0, false
+	}
+	thresholds := b.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = DefaultBudgetThresholds
+	}
+	crossed, ok := 0.0, false
+	for _, threshold := range thresholds {
+		if spent >= threshold*b.Limit && threshold > crossed {
+			crossed, ok = threshold, true
+		}
+	}
+	return crossed, ok
+}
+
+// Scope defines the targets the agents are allowed to reach as per the RoE. Its entries are hostnames,
+// "*.example.com" matching the subdomains of example.com, IP addresses or CIDRs. Exclusions take precedence.
+type Scope struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Audit defines the audit log of what the swarm did, whose entries are signed with the key of the install if Sign is set.
+type Audit struct {
+	Sign bool `json:"sign,omitempty"`
+}
+
+// Redaction defines the sensitive values of the client replaced with placeholders before they're sent to the providers,
+// and restored in their responses. The values matching the patterns or listed in Deny are replaced, but the ones listed in Allow.
+type Redaction struct {
+	Patterns []RedactionPattern `json:"patterns,omitempty"`
+	Deny     []string           `json:"deny,omitempty"`
+	Allow    []string           `json:"allow,omitempty"`
+}
+
+// RedactionPattern is a regular expression of the values to replace, naming their placeholders.
+// A builtin pattern, such as email or ipv4, is used by its name only.
+type RedactionPattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Enabled reports whether any value is to be replaced.
+func (r Redaction) Enabled() bool {
+	return len(r.Patterns) > 0 || len(r.Deny) > 0
+}
+
+// Tokenizer returns the tokenizer replacing the values of the redaction.
+func (r Redaction) Tokenizer() (*redact.Tokenizer, error) {
+	patterns := make([]redact.Pattern, len(r.Patterns))
+	for i, p := range r.Patterns {
+		patterns[i] = redact.Pattern{Name: p.Name, Regexp: p.Pattern}
+	}
+	return redact.NewTokenizer(patterns, r.Deny, r.Allow)
+}
+
+// Contains reports whether the host is within the scope. A host included by name is still out of it
+// when any of the addresses it resolves to is excluded, and one included by none of its names
+// is within it when all of its addresses are.
+func (s Scope) Contains(host string, addrs ...netip.Addr) bool {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	}
+	for _, entry := range s.Exclude {
+		if matchScopeName(entry, host) || slices.ContainsFunc(addrs, func(addr netip.Addr) bool { return matchScopeAddr(entry, addr) }) {
+			return false
+		}
+	}
+	for _, entry := range s.Include {
+		if matchScopeName(entry, host) {
+			return true
+		}
+	}
+	if len(addrs) == 0 {
+		return false
+	}
+	for _, addr := range addrs {
+		if !slices.ContainsFunc(s.Include, func(entry string) bool { return matchScopeAddr(entry, addr) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchScopeName(entry, host string) bool {
+	entry = strings.ToLower(entry)
+	if domain, ok := strings.CutPrefix(entry, "*."); ok {
+		return strings.HasSuffix(host, "."+domain)
+	}
+	return entry == host
+}
+
+func matchScopeAddr(entry string, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if prefix, err := netip.ParsePrefix(entry); err == nil {
+		return prefix.Contains(addr)
+	}
+	if entryAddr, err := netip.ParseAddr(entry); err == nil {
+		return entryAddr.Unmap() == addr
+	}
+	return false
 }
 
 type AgentName string
@@ -73,15 +225,17 @@ const (
 )
 
 type Agent struct {
-	AgentID         string         `json:"agentId"`
-	Name            AgentName      `json:"name,omitempty"`
-	Description     string         `json:"description"`
-	Goal            string         `json:"goal"`
-	Model           models.ModelID `json:"model"`
-	MaxTokens       int64          `json:"maxTokens,omitempty"`
-	ReasoningEffort string         `json:"reasoningEffort,omitempty"` // For openai models low,medium,high
-	Instructions    []string       `json:"instructions"`
-	Tools           []string       `json:"tools,omitempty"`
+	AgentID         string           `json:"agentId"`
+	Name            AgentName        `json:"name,omitempty"`
+	Description     string           `json:"description"`
+	Goal            string           `json:"goal"`
+	Model           models.ModelID   `json:"model"`
+	MaxTokens       int64            `json:"maxTokens,omitempty"`
+	ReasoningEffort string           `json:"reasoningEffort,omitempty"` // For openai models low,medium,high
+	Instructions    []string         `json:"instructions"`
+	Tools           []string         `json:"tools,omitempty"`
+	Budget          Budget           `json:"budget,omitempty"`
+	Fallbacks       []models.ModelID `json:"fallbacks,omitempty"` // Models to switch to, in order, once the current one keeps failing
 }
 
 // Get returns the current configuration.
@@ -114,12 +268,21 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	// Load and merge local config
 	mergeLocalConfig(workingDir)
 
+	// Load and merge the config of the current engagement
+	current, err := mergeEngagementConfig()
+	if err != nil {
+		return cfg, err
+	}
+
 	setProviderDefaults()
 
 	// Apply configuration to the struct
 	if err := viper.Unmarshal(cfg); err != nil {
 		return cfg, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	// NOTE: the key of the RoE doesn't match the name of its field.
+	cfg.RoEPath = viper.GetString("contextPaths")
+	cfg.Engagement = current
 
 	defaultLevel := slog.LevelInfo
 	if cfg.Debug {
@@ -131,16 +294,6 @@ func Load(workingDir string, debug bool) (*Config, error) {
 		loggingFile := fmt.Sprintf("%s/%s", cfg.Data.Directory, "debug.log")
 		messagesPath := fmt.Sprintf("%s/%s", cfg.Data.Directory, "messages")
 
-		// if file does not exist create it
-		if _, err := os.Stat(loggingFile); os.IsNotExist(err) {
-			if err := os.MkdirAll(cfg.Data.Directory, 0o755); err != nil {
-				return cfg, fmt.Errorf("failed to create directory: %w", err)
-			}
-			if _, err := os.Create(loggingFile); err != nil {
-				return cfg, fmt.Errorf("failed to create log file: %w", err)
-			}
-		}
-
 		if _, err := os.Stat(messagesPath); os.IsNotExist(err) {
 			if err := os.MkdirAll(messagesPath, 0o756); err != nil {
 				return cfg, fmt.Errorf("failed to create directory: %w", err)
@@ -148,23 +301,47 @@ func Load(workingDir string, debug bool) (*Config, error) {
 		}
 		logging.MessageDir = messagesPath
 
-		sloggingFileWriter, err := os.OpenFile(loggingFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
-		if err != nil {
-			return cfg, fmt.Errorf("failed to open log file: %w", err)
+		// NOTE: the debug log is written as it goes, in plaintext, thus it's kept in memory while the data directory is encrypted.
+		if encryption.Enabled(cfg.Data.Directory) {
+			logger := slog.New(slog.NewTextHandler(redact.NewWriter(logging.NewWriter()), &slog.HandlerOptions{
+				Level: defaultLevel,
+			}))
+			slog.SetDefault(logger)
+			logging.Warn("the debug log isn't written to debug.log while the data directory is encrypted")
+		} else {
+			// if file does not exist create it
+			if _, err := os.Stat(loggingFile); os.IsNotExist(err) {
+				if err := os.MkdirAll(cfg.Data.Directory, 0o755); err != nil {
+					return cfg, fmt.Errorf("failed to create directory: %w", err)
+				}
+				if _, err := os.Create(loggingFile); err != nil {
+					return cfg, fmt.Errorf("failed to create log file: %w", err)
+				}
+			}
+
+			sloggingFileWriter, err := os.OpenFile(loggingFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+			if err != nil {
+				return cfg, fmt.Errorf("failed to open log file: %w", err)
+			}
+			// Configure logger
+			logger := slog.New(slog.NewTextHandler(redact.NewWriter(sloggingFileWriter), &slog.HandlerOptions{
+				Level: defaultLevel,
+			}))
+			slog.SetDefault(logger)
 		}
-		// Configure logger
-		logger := slog.New(slog.NewTextHandler(sloggingFileWriter, &slog.HandlerOptions{
-			Level: defaultLevel,
-		}))
-		slog.SetDefault(logger)
 	} else {
 		// Configure logger
-		logger := slog.New(slog.NewTextHandler(logging.NewWriter(), &slog.HandlerOptions{
+		logger := slog.New(slog.NewTextHandler(redact.NewWriter(logging.NewWriter()), &slog.HandlerOptions{
 			Level: defaultLevel,
 		}))
 		slog.SetDefault(logger)
 	}
 
+	// Merge the models.dev catalog synced by `tandem models sync` and the local model overrides
+	if err := models.LoadCatalog(cfg.Data.Directory); err != nil {
+		logging.Warn("failed to load the model catalog", "error", err)
+	}
+
 	// Validate configuration
 	if err := Validate(); err != nil {
 		return cfg, fmt.Errorf("config validation failed: %w", err)
@@ -204,6 +381,8 @@ func UpdateAgentModel(agentName AgentName, modelID models.ModelID) error {
 		Model:           modelID,
 		MaxTokens:       maxTokens,
 		ReasoningEffort: existingAgentCfg.ReasoningEffort,
+		Budget:          existingAgentCfg.Budget,
+		Fallbacks:       existingAgentCfg.Fallbacks,
 	}
 	cfg.Agents[agentName] = newAgentCfg
 
@@ -279,6 +458,18 @@ func WorkingDirectory() string {
 	return cfg.WorkingDir
 }
 
+// EngagementDirectory returns the directory of the host shared with the sandbox, Data.BindMount if configured, the working directory otherwise.
+func EngagementDirectory() string {
+	dir := Get().Data.BindMount
+	if dir == "" {
+		return WorkingDirectory()
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(WorkingDirectory(), dir)
+	}
+	return filepath.Clean(dir)
+}
+
 func configureViper() {
 	viper.SetConfigName(configFileName)
 	viper.SetConfigType("json")
@@ -339,6 +530,36 @@ func mergeLocalConfig(workingDir string) {
 	}
 }
 
+// mergeEngagementConfig merges the configuration of the current engagement, if any, over the global and local ones.
+// The data directory, the RoE, the directory shared with the sandbox and the sandbox are the engagement's own,
+// unless its swarm.json says otherwise. It returns the name of the engagement.
+func mergeEngagementConfig() (string, error) {
+	name, err := engagement.Current()
+	if err != nil || name == "" {
+		return "", err
+	}
+	e, err := engagement.Get(name)
+	if err != nil {
+		return "", err
+	}
+	if e.Archived {
+		return "", fmt.Errorf("%w: %s", engagement.ErrArchived, name)
+	}
+
+	local := viper.New()
+	local.SetConfigFile(e.ConfigPath())
+	local.SetConfigType("json")
+	local.SetDefault("data.directory", e.DataDir())
+	local.SetDefault("data.bindMount", e.Workspace())
+	local.SetDefault("contextPaths", e.RoEPath())
+	local.SetDefault("sandbox", e.Sandbox())
+	if err := local.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read config of engagement %s: %w", name, err)
+	}
+	viper.MergeConfigMap(local.AllSettings())
+	return name, nil
+}
+
 // TODO: update this for the swarm config.
 // setProviderDefaults configures LLM provider defaults based on provider provided by
 // environment variables and configuration file.
@@ -457,10 +678,37 @@ func Validate() error {
 		if err := validateAgent(cfg, name, agent); err != nil {
 			return err
 		}
+		validateFallbacks(cfg, name, agent)
+	}
+
+	// Validate budgets
+	if err := validateBudget("engagement", cfg.Budgets.Engagement); err != nil {
+		return err
+	}
+	if err := validateBudget("session", cfg.Budgets.Session); err != nil {
+		return err
+	}
+	for name, agent := range cfg.Agents {
+		if err := validateBudget(fmt.Sprintf("agent %s", name), agent.Budget); err != nil {
+			return err
+		}
+	}
+
+	// Validate scope
+	if err := validateScope(cfg.Scope); err != nil {
+		return err
+	}
+
+	// Validate redaction
+	if _, err := cfg.Redaction.Tokenizer(); err != nil {
+		return err
 	}
 
 	// Validate providers
 	for provider, providerCfg := range cfg.Providers {
+		if providerCfg.RateLimit.RequestsPerMinute < 0 || providerCfg.RateLimit.TokensPerMinute < 0 {
+			return fmt.Errorf("%s rate limit must not be negative", provider)
+		}
 		if providerCfg.APIKey == "" && !providerCfg.Disabled {
 			fmt.Printf("provider has no API key, marking as disabled %s", provider)
 			logging.Warn("provider has no API key, marking as disabled", "provider", provider)
@@ -606,6 +854,78 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 	return nil
 }
 
+// validateFallbacks drops the fallback models which are not supported or whose provider isn't available.
+func validateFallbacks(cfg *Config, name AgentName, agent Agent) {
+	if len(agent.Fallbacks) == 0 {
+		return
+	}
+	fallbacks := make([]models.ModelID, 0, len(agent.Fallbacks))
+	for _, fallback := range agent.Fallbacks {
+		model, ok := models.SupportedModels[fallback]
+		if !ok {
+			logging.Warn("unsupported fallback model configured, ignoring",
+				"agent", name,
+				"fallback", fallback)
+			continue
+		}
+		providerCfg, ok := cfg.Providers[model.Provider]
+		if !ok {
+			apiKey := getProviderAPIKey(model.Provider)
+			if apiKey == "" {
+				logging.Warn("provider not configured for fallback model, ignoring",
+					"agent", name,
+					"fallback", fallback,
+					"provider", model.Provider)
+				continue
+			}
+			cfg.Providers[model.Provider] = Provider{
+				APIKey: apiKey,
+			}
+			logging.Info("added provider from environment", "provider", model.Provider)
+		} else if providerCfg.Disabled || providerCfg.APIKey == "" {
+			logging.Warn("provider is disabled or has no API key for fallback model, ignoring",
+				"agent", name,
+				"fallback", fallback,
+				"provider", model.Provider)
+			continue
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	updatedAgent := cfg.Agents[name]
+	updatedAgent.Fallbacks = fallbacks
+	cfg.Agents[name] = updatedAgent
+}
+
+// validateBudget rejects negative limits and thresholds outside of (0, 1].
+func validateBudget(scope string, budget Budget) error {
+	if budget.Limit < 0 {
+		return fmt.Errorf("%s budget limit must not be negative: %.2f", scope, budget.Limit)
+	}
+	for _, threshold := range budget.Thresholds {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("%s budget threshold must be within (0, 1]: %.2f", scope, threshold)
+		}
+	}
+	return nil
+}
+
+// validateScope rejects the entries which are neither a hostname, an IP address nor a CIDR.
+func validateScope(scope Scope) error {
+	for _, entry := range slices.Concat(scope.Include, scope.Exclude) {
+		if _, err := netip.ParsePrefix(entry); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(entry); err == nil {
+			continue
+		}
+		if !scopeNamePattern.MatchString(entry) {
+			return fmt.Errorf("invalid scope entry: %q", entry)
+		}
+	}
+	return nil
+}
+
 func updateCfgFile(updateCfg func(config *Config)) error {
 	if cfg == nil {
 		return fmt.Errorf("config not loaded")
@@ -868,6 +1188,9 @@ func GetAgentPrompt(agentName AgentName, provider models.ModelProvider) (basePro
 		<team>
 			%s
 		</team>
+		<operator>
+			the operator_shell tool calls are the commands the operator typed themselves into the shell sessions of the sandbox, along with their output.
+		</operator>
 		`, basePrompt, teamInfo)
 
 		RoE := getRoE()
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,6 +42,7 @@ func TestGetAgentPrompt_Orchestrator(t *testing.T) {
 				"<description>",
 				"<goal>",
 				"<instructions>",
+				"<operator>",
 			},
 			notExpectedInPrompt: []string{
 				"<context>",
@@ -308,3 +310,129 @@ func TestGetAgentPrompt_Orchestrator_RoEFileHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestBudget(t *testing.T) {
+	testCases := []struct {
+		name          string
+		budget        Budget
+		spent         float64
+		wantExceeded  bool
+		wantThreshold float64
+		wantCrossed   bool
+	}{
+		{
+			name:   "no limit",
+			budget: Budget{},
+			spent:  100,
+		},
+		{
+			name:   "below default thresholds",
+			budget: Budget{Limit: 10},
+			spent:  4,
+		},
+		{
+			name:          "highest default threshold crossed",
+			budget:        Budget{Limit: 10},
+			spent:         8.5,
+			wantThreshold: 0.8,
+			wantCrossed:   true,
+		},
+		{
+			name:          "custom thresholds",
+			budget:        Budget{Limit: 10, Thresholds: []float64{0.75, 0.25}},
+			spent:         3,
+			wantThreshold: 0.25,
+			wantCrossed:   true,
+		},
+		{
+			name:          "limit reached",
+			budget:        Budget{Limit: 10},
+			spent:         10,
+			wantExceeded:  true,
+			wantThreshold: 0.9,
+			wantCrossed:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.budget.Exceeded(tc.spent); got != tc.wantExceeded {
+				t.Errorf("Exceeded(%v) = %v, want %v", tc.spent, got, tc.wantExceeded)
+			}
+			threshold, crossed := tc.budget.Crossed(tc.spent)
+			if crossed != tc.wantCrossed || threshold != tc.wantThreshold {
+				t.Errorf("Crossed(%v) = (%v, %v), want (%v, %v)", tc.spent, threshold, crossed, tc.wantThreshold, tc.wantCrossed)
+			}
+		})
+	}
+}
+
+func TestScope(t *testing.T) {
+	scope := Scope{
+		Include: []string{"example.com", "*.example.com", "10.0.0.0/24", "192.168.1.5"},
+		Exclude: []string{"admin.example.com", "10.0.0.1"},
+	}
+	testCases := []struct {
+		name  string
+		host  string
+		addrs []netip.Addr
+		want  bool
+	}{
+		{name: "included hostname", host: "example.com", want: true},
+		{name: "included subdomain", host: "www.Example.com.", want: true},
+		{name: "wildcard not matching the domain itself", host: "example.com.evil.net", want: false},
+		{name: "excluded subdomain", host: "admin.example.com", want: false},
+		{name: "included address", host: "192.168.1.5", want: true},
+		{name: "included cidr", host: "10.0.0.7", want: true},
+		{name: "included ipv6 mapped address", host: "[::ffff:10.0.0.7]", want: true},
+		{name: "excluded address", host: "10.0.0.1", want: false},
+		{name: "address out of scope", host: "10.0.1.7", want: false},
+		{name: "hostname resolving within the cidr", host: "intranet", addrs: []netip.Addr{netip.MustParseAddr("10.0.0.8")}, want: true},
+		{name: "hostname partly resolving out of scope", host: "intranet", addrs: []netip.Addr{netip.MustParseAddr("10.0.0.8"), netip.MustParseAddr("8.8.8.8")}, want: false},
+		{name: "included hostname resolving to an excluded address", host: "www.example.com", addrs: []netip.Addr{netip.MustParseAddr("10.0.0.1")}, want: false},
+		{name: "unresolved hostname out of scope", host: "other.net", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := scope.Contains(tc.host, tc.addrs...); got != tc.want {
+				t.Errorf("Contains(%q, %v) = %v, want %v", tc.host, tc.addrs, got, tc.want)
+			}
+		})
+	}
+
+	if err := validateScope(scope); err != nil {
+		t.Errorf("validateScope() = %v, want nil", err)
+	}
+	if err := validateScope(Scope{Include: []string{"http://example.com"}}); err == nil {
+		t.Error("validateScope() accepted a url")
+	}
+}
+
+func TestValidateFallbacks(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	testCfg := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			models.ProviderAnthropic: {APIKey: "test-key"},
+			models.ProviderGemini:    {APIKey: "test-key", Disabled: true},
+		},
+		Agents: map[AgentName]Agent{
+			Orchestrator: {
+				Model: models.Claude4Sonnet,
+				Fallbacks: []models.ModelID{
+					"unknown-model",
+					models.Gemini25Pro,
+					models.GPT41,
+					models.Claude37Sonnet,
+				},
+			},
+		},
+	}
+
+	validateFallbacks(testCfg, Orchestrator, testCfg.Agents[Orchestrator])
+
+	fallbacks := testCfg.Agents[Orchestrator].Fallbacks
+	if len(fallbacks) != 1 || fallbacks[0] != models.Claude37Sonnet {
+		t.Errorf("validateFallbacks() kept %v, want [%s]", fallbacks, models.Claude37Sonnet)
+	}
+}
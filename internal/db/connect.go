@@ -10,6 +10,7 @@ import (
 	_ "github.com/ncruces/go-sqlite3/embed"
 	"github.com/pressly/goose/v3"
 	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/encryption"
 	"github.com/yaydraco/tandem/internal/logging"
 )
 
@@ -21,9 +22,23 @@ func Connect() (*sql.DB, error) {
 	if err := os.MkdirAll(dataDir, 0o700); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
-	dbPath := filepath.Join(dataDir, "tandem.db")
+	dbPath := filepath.Join(dataDir, FileName)
+
+	// Unlock the data directory if it's encrypted
+	var key *encryption.Key
+	if encryption.Enabled(dataDir) {
+		params, err := encryption.ReadParams(dataDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the encryption parameters: %w", err)
+		}
+		if key, err = encryption.Unlock(dataDir, params); err != nil {
+			return nil, fmt.Errorf("failed to unlock the database: %w", err)
+		}
+		encryption.Activate(key)
+	}
+
 	// Open the SQLite database
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := Open(dbPath, key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
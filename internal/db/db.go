@@ -24,12 +24,36 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.appendAuditEntryStmt, err = db.PrepareContext(ctx, appendAuditEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query AppendAuditEntry: %w", err)
+	}
+	if q.createArtifactStmt, err = db.PrepareContext(ctx, createArtifact); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateArtifact: %w", err)
+	}
+	if q.createExploitVulnerabilityStmt, err = db.PrepareContext(ctx, createExploitVulnerability); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateExploitVulnerability: %w", err)
+	}
 	if q.createMessageStmt, err = db.PrepareContext(ctx, createMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMessage: %w", err)
 	}
+	if q.createScanStmt, err = db.PrepareContext(ctx, createScan); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateScan: %w", err)
+	}
 	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
 	}
+	if q.createUsageStmt, err = db.PrepareContext(ctx, createUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUsage: %w", err)
+	}
+	if q.createVulnerabilityCPEStmt, err = db.PrepareContext(ctx, createVulnerabilityCPE); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateVulnerabilityCPE: %w", err)
+	}
+	if q.deleteExploitVulnerabilitiesStmt, err = db.PrepareContext(ctx, deleteExploitVulnerabilities); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExploitVulnerabilities: %w", err)
+	}
+	if q.deleteMemoryStmt, err = db.PrepareContext(ctx, deleteMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMemory: %w", err)
+	}
 	if q.deleteMessageStmt, err = db.PrepareContext(ctx, deleteMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMessage: %w", err)
 	}
@@ -39,39 +63,235 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteSessionMessagesStmt, err = db.PrepareContext(ctx, deleteSessionMessages); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSessionMessages: %w", err)
 	}
+	if q.deleteVulnerabilityCPEsStmt, err = db.PrepareContext(ctx, deleteVulnerabilityCPEs); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteVulnerabilityCPEs: %w", err)
+	}
+	if q.forkSessionStmt, err = db.PrepareContext(ctx, forkSession); err != nil {
+		return nil, fmt.Errorf("error preparing query ForkSession: %w", err)
+	}
+	if q.getArtifactStmt, err = db.PrepareContext(ctx, getArtifact); err != nil {
+		return nil, fmt.Errorf("error preparing query GetArtifact: %w", err)
+	}
+	if q.getCredentialByRefStmt, err = db.PrepareContext(ctx, getCredentialByRef); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCredentialByRef: %w", err)
+	}
+	if q.getEngagementUsageStmt, err = db.PrepareContext(ctx, getEngagementUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetEngagementUsage: %w", err)
+	}
+	if q.getExploitStmt, err = db.PrepareContext(ctx, getExploit); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExploit: %w", err)
+	}
+	if q.getFindingStmt, err = db.PrepareContext(ctx, getFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFinding: %w", err)
+	}
+	if q.getHostByAddressStmt, err = db.PrepareContext(ctx, getHostByAddress); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHostByAddress: %w", err)
+	}
+	if q.getKnownExploitedVulnerabilityStmt, err = db.PrepareContext(ctx, getKnownExploitedVulnerability); err != nil {
+		return nil, fmt.Errorf("error preparing query GetKnownExploitedVulnerability: %w", err)
+	}
+	if q.getLastAuditEntryStmt, err = db.PrepareContext(ctx, getLastAuditEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastAuditEntry: %w", err)
+	}
+	if q.getLastAuditEntryByKindStmt, err = db.PrepareContext(ctx, getLastAuditEntryByKind); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastAuditEntryByKind: %w", err)
+	}
+	if q.getLastAuditEntryByMessageStmt, err = db.PrepareContext(ctx, getLastAuditEntryByMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastAuditEntryByMessage: %w", err)
+	}
+	if q.getMemoryStmt, err = db.PrepareContext(ctx, getMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMemory: %w", err)
+	}
 	if q.getMessageStmt, err = db.PrepareContext(ctx, getMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMessage: %w", err)
 	}
 	if q.getSessionByIDStmt, err = db.PrepareContext(ctx, getSessionByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSessionByID: %w", err)
 	}
+	if q.getSessionTreeUsageStmt, err = db.PrepareContext(ctx, getSessionTreeUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSessionTreeUsage: %w", err)
+	}
+	if q.getVulnerabilityStmt, err = db.PrepareContext(ctx, getVulnerability); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVulnerability: %w", err)
+	}
+	if q.importArtifactStmt, err = db.PrepareContext(ctx, importArtifact); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportArtifact: %w", err)
+	}
+	if q.importFindingStmt, err = db.PrepareContext(ctx, importFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportFinding: %w", err)
+	}
+	if q.importMessageStmt, err = db.PrepareContext(ctx, importMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportMessage: %w", err)
+	}
+	if q.importSessionStmt, err = db.PrepareContext(ctx, importSession); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportSession: %w", err)
+	}
+	if q.listAllSessionsStmt, err = db.PrepareContext(ctx, listAllSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAllSessions: %w", err)
+	}
+	if q.listArtifactsBySessionStmt, err = db.PrepareContext(ctx, listArtifactsBySession); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArtifactsBySession: %w", err)
+	}
+	if q.listAuditEntriesStmt, err = db.PrepareContext(ctx, listAuditEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditEntries: %w", err)
+	}
+	if q.listCredentialsStmt, err = db.PrepareContext(ctx, listCredentials); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCredentials: %w", err)
+	}
+	if q.listEndpointsStmt, err = db.PrepareContext(ctx, listEndpoints); err != nil {
+		return nil, fmt.Errorf("error preparing query ListEndpoints: %w", err)
+	}
+	if q.listEndpointsByHostStmt, err = db.PrepareContext(ctx, listEndpointsByHost); err != nil {
+		return nil, fmt.Errorf("error preparing query ListEndpointsByHost: %w", err)
+	}
+	if q.listExploitVulnerabilitiesByCVEStmt, err = db.PrepareContext(ctx, listExploitVulnerabilitiesByCVE); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExploitVulnerabilitiesByCVE: %w", err)
+	}
+	if q.listFindingsStmt, err = db.PrepareContext(ctx, listFindings); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFindings: %w", err)
+	}
+	if q.listHostsStmt, err = db.PrepareContext(ctx, listHosts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListHosts: %w", err)
+	}
+	if q.listKnownExploitedVulnerabilitiesByProductStmt, err = db.PrepareContext(ctx, listKnownExploitedVulnerabilitiesByProduct); err != nil {
+		return nil, fmt.Errorf("error preparing query ListKnownExploitedVulnerabilitiesByProduct: %w", err)
+	}
+	if q.listMemoriesStmt, err = db.PrepareContext(ctx, listMemories); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMemories: %w", err)
+	}
 	if q.listMessagesBySessionStmt, err = db.PrepareContext(ctx, listMessagesBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query ListMessagesBySession: %w", err)
 	}
+	if q.listPortsStmt, err = db.PrepareContext(ctx, listPorts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPorts: %w", err)
+	}
+	if q.listPortsByHostStmt, err = db.PrepareContext(ctx, listPortsByHost); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPortsByHost: %w", err)
+	}
+	if q.listScansBySessionStmt, err = db.PrepareContext(ctx, listScansBySession); err != nil {
+		return nil, fmt.Errorf("error preparing query ListScansBySession: %w", err)
+	}
 	if q.listSessionsStmt, err = db.PrepareContext(ctx, listSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSessions: %w", err)
 	}
+	if q.listTaskSessionsStmt, err = db.PrepareContext(ctx, listTaskSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTaskSessions: %w", err)
+	}
+	if q.listUsageBySessionStmt, err = db.PrepareContext(ctx, listUsageBySession); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsageBySession: %w", err)
+	}
+	if q.listVulnerabilityCPEsByProductStmt, err = db.PrepareContext(ctx, listVulnerabilityCPEsByProduct); err != nil {
+		return nil, fmt.Errorf("error preparing query ListVulnerabilityCPEsByProduct: %w", err)
+	}
+	if q.renameSessionStmt, err = db.PrepareContext(ctx, renameSession); err != nil {
+		return nil, fmt.Errorf("error preparing query RenameSession: %w", err)
+	}
+	if q.searchExploitsStmt, err = db.PrepareContext(ctx, searchExploits); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchExploits: %w", err)
+	}
+	if q.searchMemoriesStmt, err = db.PrepareContext(ctx, searchMemories); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchMemories: %w", err)
+	}
+	if q.searchMessagesStmt, err = db.PrepareContext(ctx, searchMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchMessages: %w", err)
+	}
+	if q.setSessionArchivedStmt, err = db.PrepareContext(ctx, setSessionArchived); err != nil {
+		return nil, fmt.Errorf("error preparing query SetSessionArchived: %w", err)
+	}
+	if q.setSessionTagsStmt, err = db.PrepareContext(ctx, setSessionTags); err != nil {
+		return nil, fmt.Errorf("error preparing query SetSessionTags: %w", err)
+	}
 	if q.updateMessageStmt, err = db.PrepareContext(ctx, updateMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateMessage: %w", err)
 	}
 	if q.updateSessionStmt, err = db.PrepareContext(ctx, updateSession); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSession: %w", err)
 	}
+	if q.updateSessionUsageStmt, err = db.PrepareContext(ctx, updateSessionUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSessionUsage: %w", err)
+	}
+	if q.upsertCredentialStmt, err = db.PrepareContext(ctx, upsertCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertCredential: %w", err)
+	}
+	if q.upsertEndpointStmt, err = db.PrepareContext(ctx, upsertEndpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertEndpoint: %w", err)
+	}
+	if q.upsertExploitStmt, err = db.PrepareContext(ctx, upsertExploit); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertExploit: %w", err)
+	}
+	if q.upsertFindingStmt, err = db.PrepareContext(ctx, upsertFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertFinding: %w", err)
+	}
+	if q.upsertHostStmt, err = db.PrepareContext(ctx, upsertHost); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertHost: %w", err)
+	}
+	if q.upsertKnownExploitedVulnerabilityStmt, err = db.PrepareContext(ctx, upsertKnownExploitedVulnerability); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertKnownExploitedVulnerability: %w", err)
+	}
+	if q.upsertMemoryStmt, err = db.PrepareContext(ctx, upsertMemory); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertMemory: %w", err)
+	}
+	if q.upsertPortStmt, err = db.PrepareContext(ctx, upsertPort); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPort: %w", err)
+	}
+	if q.upsertVulnerabilityStmt, err = db.PrepareContext(ctx, upsertVulnerability); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertVulnerability: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.appendAuditEntryStmt != nil {
+		if cerr := q.appendAuditEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing appendAuditEntryStmt: %w", cerr)
+		}
+	}
+	if q.createArtifactStmt != nil {
+		if cerr := q.createArtifactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createArtifactStmt: %w", cerr)
+		}
+	}
+	if q.createExploitVulnerabilityStmt != nil {
+		if cerr := q.createExploitVulnerabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createExploitVulnerabilityStmt: %w", cerr)
+		}
+	}
 	if q.createMessageStmt != nil {
 		if cerr := q.createMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createMessageStmt: %w", cerr)
 		}
 	}
+	if q.createScanStmt != nil {
+		if cerr := q.createScanStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createScanStmt: %w", cerr)
+		}
+	}
 	if q.createSessionStmt != nil {
 		if cerr := q.createSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
 		}
 	}
+	if q.createUsageStmt != nil {
+		if cerr := q.createUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUsageStmt: %w", cerr)
+		}
+	}
+	if q.createVulnerabilityCPEStmt != nil {
+		if cerr := q.createVulnerabilityCPEStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createVulnerabilityCPEStmt: %w", cerr)
+		}
+	}
+	if q.deleteExploitVulnerabilitiesStmt != nil {
+		if cerr := q.deleteExploitVulnerabilitiesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExploitVulnerabilitiesStmt: %w", cerr)
+		}
+	}
+	if q.deleteMemoryStmt != nil {
+		if cerr := q.deleteMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMemoryStmt: %w", cerr)
+		}
+	}
 	if q.deleteMessageStmt != nil {
 		if cerr := q.deleteMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteMessageStmt: %w", cerr)
@@ -87,6 +307,71 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteSessionMessagesStmt: %w", cerr)
 		}
 	}
+	if q.deleteVulnerabilityCPEsStmt != nil {
+		if cerr := q.deleteVulnerabilityCPEsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteVulnerabilityCPEsStmt: %w", cerr)
+		}
+	}
+	if q.forkSessionStmt != nil {
+		if cerr := q.forkSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing forkSessionStmt: %w", cerr)
+		}
+	}
+	if q.getArtifactStmt != nil {
+		if cerr := q.getArtifactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getArtifactStmt: %w", cerr)
+		}
+	}
+	if q.getCredentialByRefStmt != nil {
+		if cerr := q.getCredentialByRefStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCredentialByRefStmt: %w", cerr)
+		}
+	}
+	if q.getEngagementUsageStmt != nil {
+		if cerr := q.getEngagementUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getEngagementUsageStmt: %w", cerr)
+		}
+	}
+	if q.getExploitStmt != nil {
+		if cerr := q.getExploitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExploitStmt: %w", cerr)
+		}
+	}
+	if q.getFindingStmt != nil {
+		if cerr := q.getFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFindingStmt: %w", cerr)
+		}
+	}
+	if q.getHostByAddressStmt != nil {
+		if cerr := q.getHostByAddressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHostByAddressStmt: %w", cerr)
+		}
+	}
+	if q.getKnownExploitedVulnerabilityStmt != nil {
+		if cerr := q.getKnownExploitedVulnerabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getKnownExploitedVulnerabilityStmt: %w", cerr)
+		}
+	}
+	if q.getLastAuditEntryStmt != nil {
+		if cerr := q.getLastAuditEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastAuditEntryStmt: %w", cerr)
+		}
+	}
+	if q.getLastAuditEntryByKindStmt != nil {
+		if cerr := q.getLastAuditEntryByKindStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastAuditEntryByKindStmt: %w", cerr)
+		}
+	}
+	if q.getLastAuditEntryByMessageStmt != nil {
+		if cerr := q.getLastAuditEntryByMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastAuditEntryByMessageStmt: %w", cerr)
+		}
+	}
+	if q.getMemoryStmt != nil {
+		if cerr := q.getMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMemoryStmt: %w", cerr)
+		}
+	}
 	if q.getMessageStmt != nil {
 		if cerr := q.getMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMessageStmt: %w", cerr)
@@ -97,16 +382,161 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getSessionByIDStmt: %w", cerr)
 		}
 	}
+	if q.getSessionTreeUsageStmt != nil {
+		if cerr := q.getSessionTreeUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSessionTreeUsageStmt: %w", cerr)
+		}
+	}
+	if q.getVulnerabilityStmt != nil {
+		if cerr := q.getVulnerabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVulnerabilityStmt: %w", cerr)
+		}
+	}
+	if q.importArtifactStmt != nil {
+		if cerr := q.importArtifactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importArtifactStmt: %w", cerr)
+		}
+	}
+	if q.importFindingStmt != nil {
+		if cerr := q.importFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importFindingStmt: %w", cerr)
+		}
+	}
+	if q.importMessageStmt != nil {
+		if cerr := q.importMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importMessageStmt: %w", cerr)
+		}
+	}
+	if q.importSessionStmt != nil {
+		if cerr := q.importSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importSessionStmt: %w", cerr)
+		}
+	}
+	if q.listAllSessionsStmt != nil {
+		if cerr := q.listAllSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAllSessionsStmt: %w", cerr)
+		}
+	}
+	if q.listArtifactsBySessionStmt != nil {
+		if cerr := q.listArtifactsBySessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArtifactsBySessionStmt: %w", cerr)
+		}
+	}
+	if q.listAuditEntriesStmt != nil {
+		if cerr := q.listAuditEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listCredentialsStmt != nil {
+		if cerr := q.listCredentialsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCredentialsStmt: %w", cerr)
+		}
+	}
+	if q.listEndpointsStmt != nil {
+		if cerr := q.listEndpointsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listEndpointsStmt: %w", cerr)
+		}
+	}
+	if q.listEndpointsByHostStmt != nil {
+		if cerr := q.listEndpointsByHostStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listEndpointsByHostStmt: %w", cerr)
+		}
+	}
+	if q.listExploitVulnerabilitiesByCVEStmt != nil {
+		if cerr := q.listExploitVulnerabilitiesByCVEStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExploitVulnerabilitiesByCVEStmt: %w", cerr)
+		}
+	}
+	if q.listFindingsStmt != nil {
+		if cerr := q.listFindingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFindingsStmt: %w", cerr)
+		}
+	}
+	if q.listHostsStmt != nil {
+		if cerr := q.listHostsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listHostsStmt: %w", cerr)
+		}
+	}
+	if q.listKnownExploitedVulnerabilitiesByProductStmt != nil {
+		if cerr := q.listKnownExploitedVulnerabilitiesByProductStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listKnownExploitedVulnerabilitiesByProductStmt: %w", cerr)
+		}
+	}
+	if q.listMemoriesStmt != nil {
+		if cerr := q.listMemoriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMemoriesStmt: %w", cerr)
+		}
+	}
 	if q.listMessagesBySessionStmt != nil {
 		if cerr := q.listMessagesBySessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listMessagesBySessionStmt: %w", cerr)
 		}
 	}
+	if q.listPortsStmt != nil {
+		if cerr := q.listPortsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPortsStmt: %w", cerr)
+		}
+	}
+	if q.listPortsByHostStmt != nil {
+		if cerr := q.listPortsByHostStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPortsByHostStmt: %w", cerr)
+		}
+	}
+	if q.listScansBySessionStmt != nil {
+		if cerr := q.listScansBySessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listScansBySessionStmt: %w", cerr)
+		}
+	}
 	if q.listSessionsStmt != nil {
 		if cerr := q.listSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listSessionsStmt: %w", cerr)
 		}
 	}
+	if q.listTaskSessionsStmt != nil {
+		if cerr := q.listTaskSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTaskSessionsStmt: %w", cerr)
+		}
+	}
+	if q.listUsageBySessionStmt != nil {
+		if cerr := q.listUsageBySessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsageBySessionStmt: %w", cerr)
+		}
+	}
+	if q.listVulnerabilityCPEsByProductStmt != nil {
+		if cerr := q.listVulnerabilityCPEsByProductStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listVulnerabilityCPEsByProductStmt: %w", cerr)
+		}
+	}
+	if q.renameSessionStmt != nil {
+		if cerr := q.renameSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing renameSessionStmt: %w", cerr)
+		}
+	}
+	if q.searchExploitsStmt != nil {
+		if cerr := q.searchExploitsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchExploitsStmt: %w", cerr)
+		}
+	}
+	if q.searchMemoriesStmt != nil {
+		if cerr := q.searchMemoriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchMemoriesStmt: %w", cerr)
+		}
+	}
+	if q.searchMessagesStmt != nil {
+		if cerr := q.searchMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchMessagesStmt: %w", cerr)
+		}
+	}
+	if q.setSessionArchivedStmt != nil {
+		if cerr := q.setSessionArchivedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setSessionArchivedStmt: %w", cerr)
+		}
+	}
+	if q.setSessionTagsStmt != nil {
+		if cerr := q.setSessionTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setSessionTagsStmt: %w", cerr)
+		}
+	}
 	if q.updateMessageStmt != nil {
 		if cerr := q.updateMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateMessageStmt: %w", cerr)
@@ -117,6 +547,56 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateSessionStmt: %w", cerr)
 		}
 	}
+	if q.updateSessionUsageStmt != nil {
+		if cerr := q.updateSessionUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSessionUsageStmt: %w", cerr)
+		}
+	}
+	if q.upsertCredentialStmt != nil {
+		if cerr := q.upsertCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertCredentialStmt: %w", cerr)
+		}
+	}
+	if q.upsertEndpointStmt != nil {
+		if cerr := q.upsertEndpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertEndpointStmt: %w", cerr)
+		}
+	}
+	if q.upsertExploitStmt != nil {
+		if cerr := q.upsertExploitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertExploitStmt: %w", cerr)
+		}
+	}
+	if q.upsertFindingStmt != nil {
+		if cerr := q.upsertFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertFindingStmt: %w", cerr)
+		}
+	}
+	if q.upsertHostStmt != nil {
+		if cerr := q.upsertHostStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertHostStmt: %w", cerr)
+		}
+	}
+	if q.upsertKnownExploitedVulnerabilityStmt != nil {
+		if cerr := q.upsertKnownExploitedVulnerabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertKnownExploitedVulnerabilityStmt: %w", cerr)
+		}
+	}
+	if q.upsertMemoryStmt != nil {
+		if cerr := q.upsertMemoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertMemoryStmt: %w", cerr)
+		}
+	}
+	if q.upsertPortStmt != nil {
+		if cerr := q.upsertPortStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPortStmt: %w", cerr)
+		}
+	}
+	if q.upsertVulnerabilityStmt != nil {
+		if cerr := q.upsertVulnerabilityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertVulnerabilityStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -154,35 +634,155 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                        DBTX
-	tx                        *sql.Tx
-	createMessageStmt         *sql.Stmt
-	createSessionStmt         *sql.Stmt
-	deleteMessageStmt         *sql.Stmt
-	deleteSessionStmt         *sql.Stmt
-	deleteSessionMessagesStmt *sql.Stmt
-	getMessageStmt            *sql.Stmt
-	getSessionByIDStmt        *sql.Stmt
-	listMessagesBySessionStmt *sql.Stmt
-	listSessionsStmt          *sql.Stmt
-	updateMessageStmt         *sql.Stmt
-	updateSessionStmt         *sql.Stmt
+	db                                             DBTX
+	tx                                             *sql.Tx
+	appendAuditEntryStmt                           *sql.Stmt
+	createArtifactStmt                             *sql.Stmt
+	createExploitVulnerabilityStmt                 *sql.Stmt
+	createMessageStmt                              *sql.Stmt
+	createScanStmt                                 *sql.Stmt
+	createSessionStmt                              *sql.Stmt
+	createUsageStmt                                *sql.Stmt
+	createVulnerabilityCPEStmt                     *sql.Stmt
+	deleteExploitVulnerabilitiesStmt               *sql.Stmt
+	deleteMemoryStmt                               *sql.Stmt
+	deleteMessageStmt                              *sql.Stmt
+	deleteSessionStmt                              *sql.Stmt
+	deleteSessionMessagesStmt                      *sql.Stmt
+	deleteVulnerabilityCPEsStmt                    *sql.Stmt
+	forkSessionStmt                                *sql.Stmt
+	getArtifactStmt                                *sql.Stmt
+	getCredentialByRefStmt                         *sql.Stmt
+	getEngagementUsageStmt                         *sql.Stmt
+	getExploitStmt                                 *sql.Stmt
+	getFindingStmt                                 *sql.Stmt
+	getHostByAddressStmt                           *sql.Stmt
+	getKnownExploitedVulnerabilityStmt             *sql.Stmt
+	getLastAuditEntryStmt                          *sql.Stmt
+	getLastAuditEntryByKindStmt                    *sql.Stmt
+	getLastAuditEntryByMessageStmt                 *sql.Stmt
+	getMemoryStmt                                  *sql.Stmt
+	getMessageStmt                                 *sql.Stmt
+	getSessionByIDStmt                             *sql.Stmt
+	getSessionTreeUsageStmt                        *sql.Stmt
+	getVulnerabilityStmt                           *sql.Stmt
+	importArtifactStmt                             *sql.Stmt
+	importFindingStmt                              *sql.Stmt
+	importMessageStmt                              *sql.Stmt
+	importSessionStmt                              *sql.Stmt
+	listAllSessionsStmt                            *sql.Stmt
+	listArtifactsBySessionStmt                     *sql.Stmt
+	listAuditEntriesStmt                           *sql.Stmt
+	listCredentialsStmt                            *sql.Stmt
+	listEndpointsStmt                              *sql.Stmt
+	listEndpointsByHostStmt                        *sql.Stmt
+	listExploitVulnerabilitiesByCVEStmt            *sql.Stmt
+	listFindingsStmt                               *sql.Stmt
+	listHostsStmt                                  *sql.Stmt
+	listKnownExploitedVulnerabilitiesByProductStmt *sql.Stmt
+	listMemoriesStmt                               *sql.Stmt
+	listMessagesBySessionStmt                      *sql.Stmt
+	listPortsStmt                                  *sql.Stmt
+	listPortsByHostStmt                            *sql.Stmt
+	listScansBySessionStmt                         *sql.Stmt
+	listSessionsStmt                               *sql.Stmt
+	listTaskSessionsStmt                           *sql.Stmt
+	listUsageBySessionStmt                         *sql.Stmt
+	listVulnerabilityCPEsByProductStmt             *sql.Stmt
+	renameSessionStmt                              *sql.Stmt
+	searchExploitsStmt                             *sql.Stmt
+	searchMemoriesStmt                             *sql.Stmt
+	searchMessagesStmt                             *sql.Stmt
+	setSessionArchivedStmt                         *sql.Stmt
+	setSessionTagsStmt                             *sql.Stmt
+	updateMessageStmt                              *sql.Stmt
+	updateSessionStmt                              *sql.Stmt
+	updateSessionUsageStmt                         *sql.Stmt
+	upsertCredentialStmt                           *sql.Stmt
+	upsertEndpointStmt                             *sql.Stmt
+	upsertExploitStmt                              *sql.Stmt
+	upsertFindingStmt                              *sql.Stmt
+	upsertHostStmt                                 *sql.Stmt
+	upsertKnownExploitedVulnerabilityStmt          *sql.Stmt
+	upsertMemoryStmt                               *sql.Stmt
+	upsertPortStmt                                 *sql.Stmt
+	upsertVulnerabilityStmt                        *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                        tx,
-		tx:                        tx,
-		createMessageStmt:         q.createMessageStmt,
-		createSessionStmt:         q.createSessionStmt,
-		deleteMessageStmt:         q.deleteMessageStmt,
-		deleteSessionStmt:         q.deleteSessionStmt,
-		deleteSessionMessagesStmt: q.deleteSessionMessagesStmt,
-		getMessageStmt:            q.getMessageStmt,
-		getSessionByIDStmt:        q.getSessionByIDStmt,
-		listMessagesBySessionStmt: q.listMessagesBySessionStmt,
-		listSessionsStmt:          q.listSessionsStmt,
-		updateMessageStmt:         q.updateMessageStmt,
-		updateSessionStmt:         q.updateSessionStmt,
+		db:                                  tx,
+		tx:                                  tx,
+		appendAuditEntryStmt:                q.appendAuditEntryStmt,
+		createArtifactStmt:                  q.createArtifactStmt,
+		createExploitVulnerabilityStmt:      q.createExploitVulnerabilityStmt,
+		createMessageStmt:                   q.createMessageStmt,
+		createScanStmt:                      q.createScanStmt,
+		createSessionStmt:                   q.createSessionStmt,
+		createUsageStmt:                     q.createUsageStmt,
+		createVulnerabilityCPEStmt:          q.createVulnerabilityCPEStmt,
+		deleteExploitVulnerabilitiesStmt:    q.deleteExploitVulnerabilitiesStmt,
+		deleteMemoryStmt:                    q.deleteMemoryStmt,
+		deleteMessageStmt:                   q.deleteMessageStmt,
+		deleteSessionStmt:                   q.deleteSessionStmt,
+		deleteSessionMessagesStmt:           q.deleteSessionMessagesStmt,
+		deleteVulnerabilityCPEsStmt:         q.deleteVulnerabilityCPEsStmt,
+		forkSessionStmt:                     q.forkSessionStmt,
+		getArtifactStmt:                     q.getArtifactStmt,
+		getCredentialByRefStmt:              q.getCredentialByRefStmt,
+		getEngagementUsageStmt:              q.getEngagementUsageStmt,
+		getExploitStmt:                      q.getExploitStmt,
+		getFindingStmt:                      q.getFindingStmt,
+		getHostByAddressStmt:                q.getHostByAddressStmt,
+		getKnownExploitedVulnerabilityStmt:  q.getKnownExploitedVulnerabilityStmt,
+		getLastAuditEntryStmt:               q.getLastAuditEntryStmt,
+		getLastAuditEntryByKindStmt:         q.getLastAuditEntryByKindStmt,
+		getLastAuditEntryByMessageStmt:      q.getLastAuditEntryByMessageStmt,
+		getMemoryStmt:                       q.getMemoryStmt,
+		getMessageStmt:                      q.getMessageStmt,
+		getSessionByIDStmt:                  q.getSessionByIDStmt,
+		getSessionTreeUsageStmt:             q.getSessionTreeUsageStmt,
+		getVulnerabilityStmt:                q.getVulnerabilityStmt,
+		importArtifactStmt:                  q.importArtifactStmt,
+		importFindingStmt:                   q.importFindingStmt,
+		importMessageStmt:                   q.importMessageStmt,
+		importSessionStmt:                   q.importSessionStmt,
+		listAllSessionsStmt:                 q.listAllSessionsStmt,
+		listArtifactsBySessionStmt:          q.listArtifactsBySessionStmt,
+		listAuditEntriesStmt:                q.listAuditEntriesStmt,
+		listCredentialsStmt:                 q.listCredentialsStmt,
+		listEndpointsStmt:                   q.listEndpointsStmt,
+		listEndpointsByHostStmt:             q.listEndpointsByHostStmt,
+		listExploitVulnerabilitiesByCVEStmt: q.listExploitVulnerabilitiesByCVEStmt,
+		listFindingsStmt:                    q.listFindingsStmt,
+		listHostsStmt:                       q.listHostsStmt,
+		listKnownExploitedVulnerabilitiesByProductStmt: q.listKnownExploitedVulnerabilitiesByProductStmt,
+		listMemoriesStmt:                      q.listMemoriesStmt,
+		listMessagesBySessionStmt:             q.listMessagesBySessionStmt,
+		listPortsStmt:                         q.listPortsStmt,
+		listPortsByHostStmt:                   q.listPortsByHostStmt,
+		listScansBySessionStmt:                q.listScansBySessionStmt,
+		listSessionsStmt:                      q.listSessionsStmt,
+		listTaskSessionsStmt:                  q.listTaskSessionsStmt,
+		listUsageBySessionStmt:                q.listUsageBySessionStmt,
+		listVulnerabilityCPEsByProductStmt:    q.listVulnerabilityCPEsByProductStmt,
+		renameSessionStmt:                     q.renameSessionStmt,
+		searchExploitsStmt:                    q.searchExploitsStmt,
+		searchMemoriesStmt:                    q.searchMemoriesStmt,
+		searchMessagesStmt:                    q.searchMessagesStmt,
+		setSessionArchivedStmt:                q.setSessionArchivedStmt,
+		setSessionTagsStmt:                    q.setSessionTagsStmt,
+		updateMessageStmt:                     q.updateMessageStmt,
+		updateSessionStmt:                     q.updateSessionStmt,
+		updateSessionUsageStmt:                q.updateSessionUsageStmt,
+		upsertCredentialStmt:                  q.upsertCredentialStmt,
+		upsertEndpointStmt:                    q.upsertEndpointStmt,
+		upsertExploitStmt:                     q.upsertExploitStmt,
+		upsertFindingStmt:                     q.upsertFindingStmt,
+		upsertHostStmt:                        q.upsertHostStmt,
+		upsertKnownExploitedVulnerabilityStmt: q.upsertKnownExploitedVulnerabilityStmt,
+		upsertMemoryStmt:                      q.upsertMemoryStmt,
+		upsertPortStmt:                        q.upsertPortStmt,
+		upsertVulnerabilityStmt:               q.upsertVulnerabilityStmt,
 	}
 }
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/ncruces/go-sqlite3"
+	"github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/vfs/xts"
+	"github.com/yaydraco/tandem/internal/encryption"
+)
+
+// FileName is the file of the database in the data directory.
+const FileName = "tandem.db"
+
+// Open opens the SQLite database, encrypted with the key if any.
+func Open(path string, key *encryption.Key) (*sql.DB, error) {
+	if key == nil {
+		return sql.Open("sqlite3", path)
+	}
+	name, err := encryptedName(path, "")
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: the key is set with a PRAGMA on every connection rather than in the URI, for it not to be exposed along.
+	return driver.Open(name, func(c *sqlite3.Conn) error {
+		return c.Exec(fmt.Sprintf("PRAGMA hexkey='%s'; PRAGMA temp_store = memory;", key.DatabaseHexKey()))
+	})
+}
+
+// encryptedName returns the URI of the database encrypted by the xts VFS, with the key in hex if any.
+func encryptedName(path, hexKey string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{"vfs": {"xts"}}
+	if hexKey != "" {
+		query.Set("hexkey", hexKey)
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: query.Encode()}
+	return u.String(), nil
+}
+
+// Readable reports whether the database can be read with the key, nil if it's in plaintext.
+func Readable(ctx context.Context, path string, key *encryption.Key) bool {
+	conn, err := Open(path, key)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	var count int
+	return conn.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master").Scan(&count) == nil
+}
+
+// Reencrypt encrypts the database with the key to, decrypting it with the key from, nil if it's in plaintext.
+// The database is copied with VACUUM INTO and the copy replaces it, it must not be open elsewhere.
+// It's left as is if it's already encrypted with the key to.
+func Reencrypt(ctx context.Context, path string, from, to *encryption.Key) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if Readable(ctx, path, to) {
+		return nil
+	}
+	if !Readable(ctx, path, from) {
+		return fmt.Errorf("failed to read the database: wrong key")
+	}
+	conn, err := Open(path, from)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer conn.Close()
+
+	tmp := path + ".rekey"
+	for _, p := range []string{tmp, tmp + "-wal", tmp + "-shm"} {
+		os.Remove(p)
+	}
+	name, err := encryptedName(tmp, to.DatabaseHexKey())
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "VACUUM INTO ?", name); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to encrypt the database: %w", err)
+	}
+	if err := conn.Close(); err != nil {
+		return err
+	}
+	// NOTE: the WAL was checkpointed into the copy, the files of the former database are left behind.
+	for _, p := range []string{path + "-wal", path + "-shm"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
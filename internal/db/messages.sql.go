@@ -97,6 +97,46 @@ func (q *Queries) GetMessage(ctx context.Context, id string) (Message, error) {
 	return i, err
 }
 
+const importMessage = `-- name: ImportMessage :exec
+INSERT INTO messages (
+    id,
+    session_id,
+    role,
+    parts,
+    model,
+    created_at,
+    updated_at,
+    finished_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type ImportMessageParams struct {
+	ID         string         `json:"id"`
+	SessionID  string         `json:"session_id"`
+	Role       string         `json:"role"`
+	Parts      string         `json:"parts"`
+	Model      sql.NullString `json:"model"`
+	CreatedAt  int64          `json:"created_at"`
+	UpdatedAt  int64          `json:"updated_at"`
+	FinishedAt sql.NullInt64  `json:"finished_at"`
+}
+
+func (q *Queries) ImportMessage(ctx context.Context, arg ImportMessageParams) error {
+	_, err := q.exec(ctx, q.importMessageStmt, importMessage,
+		arg.ID,
+		arg.SessionID,
+		arg.Role,
+		arg.Parts,
+		arg.Model,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.FinishedAt,
+	)
+	return err
+}
+
 const listMessagesBySession = `-- name: ListMessagesBySession :many
 SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at
 FROM messages
@@ -136,6 +176,73 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID string) (
 	return items, nil
 }
 
+const searchMessages = `-- name: SearchMessages :many
+SELECT
+    m.id AS message_id,
+    m.session_id,
+    s.title AS session_title,
+    coalesce(p.id, s.id) AS root_session_id,
+    coalesce(p.title, s.title) AS root_session_title,
+    m.role,
+    m.created_at,
+    snippet(messages_fts, 0, char(2), char(3), '…', 16) AS snippet
+FROM messages_fts
+JOIN messages m ON m.id = messages_fts.message_id
+JOIN sessions s ON s.id = m.session_id
+LEFT JOIN sessions p ON p.id = s.parent_session_id
+WHERE messages_fts MATCH ?1
+ORDER BY rank
+LIMIT ?2
+`
+
+type SearchMessagesParams struct {
+	Query      string `json:"query"`
+	MaxResults int64  `json:"max_results"`
+}
+
+type SearchMessagesRow struct {
+	MessageID        string `json:"message_id"`
+	SessionID        string `json:"session_id"`
+	SessionTitle     string `json:"session_title"`
+	RootSessionID    string `json:"root_session_id"`
+	RootSessionTitle string `json:"root_session_title"`
+	Role             string `json:"role"`
+	CreatedAt        int64  `json:"created_at"`
+	Snippet          string `json:"snippet"`
+}
+
+func (q *Queries) SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error) {
+	rows, err := q.query(ctx, q.searchMessagesStmt, searchMessages, arg.Query, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchMessagesRow{}
+	for rows.Next() {
+		var i SearchMessagesRow
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.SessionID,
+			&i.SessionTitle,
+			&i.RootSessionID,
+			&i.RootSessionTitle,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateMessage = `-- name: UpdateMessage :exec
 UPDATE messages
 SET
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Artifacts, the files downloaded from the sandbox during the engagement
+CREATE TABLE IF NOT EXISTS artifacts (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    message_id TEXT,
+    name TEXT NOT NULL,
+    source_path TEXT NOT NULL,  -- Path within the sandbox
+    path TEXT NOT NULL,  -- Path on the host
+    size INTEGER NOT NULL DEFAULT 0 CHECK (size >= 0),
+    sha256 TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_session_id ON artifacts (session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_artifacts_session_id;
+DROP TABLE IF EXISTS artifacts;
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Inventory of the engagement, the hosts and ports discovered by the scans
+CREATE TABLE IF NOT EXISTS hosts (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,  -- Session the host was discovered in
+    address TEXT NOT NULL UNIQUE,
+    hostnames TEXT NOT NULL DEFAULT '',  -- Comma separated
+    os TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS ports (
+    id TEXT PRIMARY KEY,
+    host_id TEXT NOT NULL,
+    session_id TEXT,  -- Session the port was last scanned in
+    protocol TEXT NOT NULL,
+    port INTEGER NOT NULL CHECK (port >= 0 AND port <= 65535),
+    state TEXT NOT NULL,
+    service TEXT NOT NULL DEFAULT '',
+    product TEXT NOT NULL DEFAULT '',
+    version TEXT NOT NULL DEFAULT '',
+    extra_info TEXT NOT NULL DEFAULT '',
+    scripts TEXT NOT NULL DEFAULT '{}',  -- JSON object of the script outputs by script ID
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    UNIQUE (host_id, protocol, port),
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ports_host_id ON ports (host_id);
+
+-- Raw output of the scans the inventory was built from
+CREATE TABLE IF NOT EXISTS scans (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    tool TEXT NOT NULL,
+    command TEXT NOT NULL,
+    output TEXT NOT NULL,
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_scans_session_id ON scans (session_id);
+
+CREATE TRIGGER IF NOT EXISTS update_hosts_updated_at
+AFTER UPDATE ON hosts
+BEGIN
+UPDATE hosts SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_ports_updated_at
+AFTER UPDATE ON ports
+BEGIN
+UPDATE ports SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_ports_updated_at;
+DROP TRIGGER IF EXISTS update_hosts_updated_at;
+DROP INDEX IF EXISTS idx_scans_session_id;
+DROP TABLE IF EXISTS scans;
+DROP INDEX IF EXISTS idx_ports_host_id;
+DROP TABLE IF EXISTS ports;
+DROP TABLE IF EXISTS hosts;
+-- +goose StatementEnd
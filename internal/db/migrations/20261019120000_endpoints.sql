@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Web contents of the hosts of the inventory, discovered by fuzzing
+CREATE TABLE IF NOT EXISTS endpoints (
+    id TEXT PRIMARY KEY,
+    host_id TEXT NOT NULL,
+    session_id TEXT,  -- Session the endpoint was last discovered in
+    url TEXT NOT NULL UNIQUE,
+    status INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    words INTEGER NOT NULL,
+    lines INTEGER NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    redirect_location TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_endpoints_host_id ON endpoints (host_id);
+
+CREATE TRIGGER IF NOT EXISTS update_endpoints_updated_at
+AFTER UPDATE ON endpoints
+BEGIN
+UPDATE endpoints SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_endpoints_updated_at;
+DROP INDEX IF EXISTS idx_endpoints_host_id;
+DROP TABLE IF EXISTS endpoints;
+-- +goose StatementEnd
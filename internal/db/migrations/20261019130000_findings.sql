@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Findings of the scanners, normalized into a common format
+CREATE TABLE IF NOT EXISTS findings (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,  -- Session the finding was last reported in
+    host_id TEXT,
+    fingerprint TEXT NOT NULL UNIQUE,  -- Hash of the scanner, template and target, deduplicating the findings
+    scanner TEXT NOT NULL,
+    template_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('info', 'low', 'medium', 'high', 'critical')),
+    target TEXT NOT NULL,  -- Where the finding matched, e.g. a url
+    description TEXT NOT NULL DEFAULT '',
+    evidence TEXT NOT NULL DEFAULT '',
+    refs TEXT NOT NULL DEFAULT '',  -- Newline separated
+    occurrences INTEGER NOT NULL DEFAULT 1,  -- Times the finding was reported
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE SET NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_findings_host_id ON findings (host_id);
+
+CREATE TRIGGER IF NOT EXISTS update_findings_updated_at
+AFTER UPDATE ON findings
+BEGIN
+UPDATE findings SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_findings_updated_at;
+DROP INDEX IF EXISTS idx_findings_host_id;
+DROP TABLE IF EXISTS findings;
+-- +goose StatementEnd
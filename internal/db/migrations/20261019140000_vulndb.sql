@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+-- CVEs imported from the NVD JSON feeds
+CREATE TABLE IF NOT EXISTS vulnerabilities (
+    id TEXT PRIMARY KEY,  -- e.g. CVE-2021-41773
+    description TEXT NOT NULL DEFAULT '',
+    cvss_score REAL,  -- Base score of the latest CVSS version scored
+    cvss_severity TEXT NOT NULL DEFAULT '',
+    cvss_vector TEXT NOT NULL DEFAULT '',
+    published_at TEXT NOT NULL DEFAULT '',
+    updated_at INTEGER NOT NULL  -- Unix timestamp of the import
+);
+
+-- Products the CVEs apply to, out of the CPE matches of their configurations
+CREATE TABLE IF NOT EXISTS vulnerability_cpes (
+    cve_id TEXT NOT NULL,
+    vendor TEXT NOT NULL,
+    product TEXT NOT NULL,
+    version TEXT NOT NULL,  -- * for the versions within the range
+    version_start_including TEXT NOT NULL DEFAULT '',
+    version_start_excluding TEXT NOT NULL DEFAULT '',
+    version_end_including TEXT NOT NULL DEFAULT '',
+    version_end_excluding TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (cve_id) REFERENCES vulnerabilities (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_vulnerability_cpes_product ON vulnerability_cpes (product);
+CREATE INDEX IF NOT EXISTS idx_vulnerability_cpes_cve_id ON vulnerability_cpes (cve_id);
+
+-- Known exploited vulnerabilities imported from the CISA KEV catalog
+CREATE TABLE IF NOT EXISTS known_exploited_vulnerabilities (
+    cve_id TEXT PRIMARY KEY,
+    vendor TEXT NOT NULL,
+    product TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    required_action TEXT NOT NULL DEFAULT '',
+    ransomware TEXT NOT NULL DEFAULT '',  -- Known or Unknown use in ransomware campaigns
+    date_added TEXT NOT NULL DEFAULT '',
+    updated_at INTEGER NOT NULL  -- Unix timestamp of the import
+);
+
+-- Exploits imported from the index of Exploit-DB searchsploit reads, files_exploits.csv
+CREATE TABLE IF NOT EXISTS exploits (
+    id TEXT PRIMARY KEY,  -- EDB-ID
+    description TEXT NOT NULL,  -- Title of the exploit, e.g. vsftpd 2.3.4 - Backdoor Command Execution
+    path TEXT NOT NULL,  -- Path of the exploit within the Exploit-DB repository
+    type TEXT NOT NULL DEFAULT '',
+    platform TEXT NOT NULL DEFAULT '',
+    port INTEGER,
+    verified BOOLEAN NOT NULL DEFAULT FALSE,
+    codes TEXT NOT NULL DEFAULT '',  -- Semicolon separated CVE, OSVDB and other IDs
+    published_at TEXT NOT NULL DEFAULT '',
+    updated_at INTEGER NOT NULL  -- Unix timestamp of the import
+);
+
+CREATE TABLE IF NOT EXISTS exploit_vulnerabilities (
+    exploit_id TEXT NOT NULL,
+    cve_id TEXT NOT NULL,
+    PRIMARY KEY (exploit_id, cve_id),
+    FOREIGN KEY (exploit_id) REFERENCES exploits (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_exploit_vulnerabilities_cve_id ON exploit_vulnerabilities (cve_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_exploit_vulnerabilities_cve_id;
+DROP TABLE IF EXISTS exploit_vulnerabilities;
+DROP TABLE IF EXISTS exploits;
+DROP TABLE IF EXISTS known_exploited_vulnerabilities;
+DROP INDEX IF EXISTS idx_vulnerability_cpes_cve_id;
+DROP INDEX IF EXISTS idx_vulnerability_cpes_product;
+DROP TABLE IF EXISTS vulnerability_cpes;
+DROP TABLE IF EXISTS vulnerabilities;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Credentials discovered during the engagement, their secrets encrypted with the key of the vault
+CREATE TABLE IF NOT EXISTS credentials (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,  -- Session the credential was last stored in
+    ref TEXT NOT NULL UNIQUE,  -- Reference the agents use the credential by, e.g. ftp-admin
+    kind TEXT NOT NULL CHECK (kind IN ('password', 'hash', 'token', 'key', 'other')),
+    username TEXT NOT NULL DEFAULT '',
+    target TEXT NOT NULL DEFAULT '',  -- Where the credential applies, e.g. ftp://10.0.0.5
+    secret BLOB NOT NULL,  -- Nonce followed by the AES-GCM sealed secret
+    notes TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS update_credentials_updated_at
+AFTER UPDATE ON credentials
+BEGIN
+UPDATE credentials SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_credentials_updated_at;
+DROP TABLE IF EXISTS credentials;
+-- +goose StatementEnd
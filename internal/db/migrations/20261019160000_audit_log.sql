@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Append-only log of what the swarm did, every entry chained to the previous one by its hash
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL CHECK (kind IN ('prompt', 'response', 'tool_call', 'tool_result', 'deletion', 'approval', 'config')),
+    session_id TEXT NOT NULL DEFAULT '',  -- Not a foreign key, the entries outlive the sessions
+    message_id TEXT NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    prev_hash TEXT NOT NULL UNIQUE,  -- Hash of the previous entry, zeros for the first one
+    hash TEXT NOT NULL UNIQUE,  -- SHA-256 of the entry, its previous hash included
+    signature BLOB,  -- ed25519 signature of the hash, if the log is signed
+    created_at INTEGER NOT NULL  -- Unix timestamp in seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_message_id ON audit_log (message_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+BEFORE UPDATE ON audit_log
+BEGIN
+SELECT RAISE(ABORT, 'the audit log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+BEFORE DELETE ON audit_log
+BEGIN
+SELECT RAISE(ABORT, 'the audit log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
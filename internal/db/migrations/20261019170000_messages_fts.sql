@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Full-text index of the text parts and the tool results of the messages, kept in sync by the triggers below
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5 (
+    content,
+    message_id UNINDEXED,  -- Not the rowid of the messages, which VACUUM may change
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_insert
+AFTER INSERT ON messages
+BEGIN
+INSERT INTO messages_fts (content, message_id)
+SELECT group_concat(coalesce(json_extract(p.value, '$.data.text'), json_extract(p.value, '$.data.content')), char(10)), new.id
+FROM json_each(new.parts) AS p
+WHERE json_extract(p.value, '$.type') IN ('text', 'tool_result');
+END;
+
+-- NOTE: the messages of the agents are reindexed once finished only, not on every delta streamed.
+CREATE TRIGGER IF NOT EXISTS messages_fts_update
+AFTER UPDATE OF parts ON messages
+WHEN new.role != 'assistant' OR new.finished_at IS NOT NULL
+BEGIN
+DELETE FROM messages_fts WHERE message_id = old.id;
+INSERT INTO messages_fts (content, message_id)
+SELECT group_concat(coalesce(json_extract(p.value, '$.data.text'), json_extract(p.value, '$.data.content')), char(10)), new.id
+FROM json_each(new.parts) AS p
+WHERE json_extract(p.value, '$.type') IN ('text', 'tool_result');
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_delete
+AFTER DELETE ON messages
+BEGIN
+DELETE FROM messages_fts WHERE message_id = old.id;
+END;
+
+INSERT INTO messages_fts (content, message_id)
+SELECT group_concat(coalesce(json_extract(p.value, '$.data.text'), json_extract(p.value, '$.data.content')), char(10)), m.id
+FROM messages AS m, json_each(m.parts) AS p
+WHERE json_extract(p.value, '$.type') IN ('text', 'tool_result')
+GROUP BY m.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS messages_fts_delete;
+DROP TRIGGER IF EXISTS messages_fts_update;
+DROP TRIGGER IF EXISTS messages_fts_insert;
+DROP TABLE IF EXISTS messages_fts;
+-- +goose StatementEnd
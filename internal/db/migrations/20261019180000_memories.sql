@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Facts the agents learned over the engagement, shared with the agents of the later tasks
+CREATE TABLE IF NOT EXISTS memories (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,  -- Session the memory was written in
+    agent TEXT NOT NULL,  -- Agent which wrote the memory
+    subject TEXT NOT NULL DEFAULT '',  -- What the memory is about, e.g. a host, a service or a topic
+    content TEXT NOT NULL,
+    created_at INTEGER NOT NULL,  -- Unix timestamp in seconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in seconds
+    UNIQUE (subject, content),
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS update_memories_updated_at
+AFTER UPDATE ON memories
+BEGIN
+UPDATE memories SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+
+-- Full-text index of the memories, to find the ones relevant to a task
+CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5 (
+    subject,
+    content,
+    memory_id UNINDEXED,
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS memories_fts_insert
+AFTER INSERT ON memories
+BEGIN
+INSERT INTO memories_fts (subject, content, memory_id) VALUES (new.subject, new.content, new.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS memories_fts_delete
+AFTER DELETE ON memories
+BEGIN
+DELETE FROM memories_fts WHERE memory_id = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS memories_fts_delete;
+DROP TRIGGER IF EXISTS memories_fts_insert;
+DROP TABLE IF EXISTS memories_fts;
+DROP TRIGGER IF EXISTS update_memories_updated_at;
+DROP TABLE IF EXISTS memories;
+-- +goose StatementEnd
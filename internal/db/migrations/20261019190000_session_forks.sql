@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The session and the message of it a session was forked from, the messages up to the latter being copied into it
+ALTER TABLE sessions ADD COLUMN forked_from_session_id TEXT;
+ALTER TABLE sessions ADD COLUMN forked_from_message_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_forked_from_session_id ON sessions (forked_from_session_id);
+
+-- NOTE: the forks outlive the session they were forked from, and become sessions of their own.
+CREATE TRIGGER IF NOT EXISTS sessions_forked_from_delete
+AFTER DELETE ON sessions
+BEGIN
+UPDATE sessions SET forked_from_session_id = NULL, forked_from_message_id = NULL
+WHERE forked_from_session_id = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS sessions_forked_from_delete;
+DROP INDEX IF EXISTS idx_sessions_forked_from_session_id;
+ALTER TABLE sessions DROP COLUMN forked_from_message_id;
+ALTER TABLE sessions DROP COLUMN forked_from_session_id;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The tags of a session, a JSON array, and when it was archived, the archived sessions being left out of the lists
+ALTER TABLE sessions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE sessions ADD COLUMN archived_at INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN archived_at;
+ALTER TABLE sessions DROP COLUMN tags;
+-- +goose StatementEnd
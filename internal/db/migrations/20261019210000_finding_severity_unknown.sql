@@ -0,0 +1,86 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The findings of the scanners which don't rate them, e.g. nikto, have an unknown severity
+CREATE TABLE findings_new (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,  -- Session the finding was last reported in
+    host_id TEXT,
+    fingerprint TEXT NOT NULL UNIQUE,  -- Hash of the scanner, template and target, deduplicating the findings
+    scanner TEXT NOT NULL,
+    template_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('unknown', 'info', 'low', 'medium', 'high', 'critical')),
+    target TEXT NOT NULL,  -- Where the finding matched, e.g. a url
+    description TEXT NOT NULL DEFAULT '',
+    evidence TEXT NOT NULL DEFAULT '',
+    refs TEXT NOT NULL DEFAULT '',  -- Newline separated
+    occurrences INTEGER NOT NULL DEFAULT 1,  -- Times the finding was reported
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE SET NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+INSERT INTO findings_new SELECT
+    id, session_id, host_id, fingerprint, scanner, template_id, name,
+    CASE WHEN scanner = 'nikto' THEN 'unknown' ELSE severity END,
+    target, description, evidence, refs, occurrences, created_at, updated_at
+FROM findings;
+
+DROP TRIGGER IF EXISTS update_findings_updated_at;
+DROP INDEX IF EXISTS idx_findings_host_id;
+DROP TABLE findings;
+ALTER TABLE findings_new RENAME TO findings;
+
+CREATE INDEX IF NOT EXISTS idx_findings_host_id ON findings (host_id);
+
+CREATE TRIGGER IF NOT EXISTS update_findings_updated_at
+AFTER UPDATE ON findings
+BEGIN
+UPDATE findings SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE findings_old (
+    id TEXT PRIMARY KEY,
+    session_id TEXT,
+    host_id TEXT,
+    fingerprint TEXT NOT NULL UNIQUE,
+    scanner TEXT NOT NULL,
+    template_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('info', 'low', 'medium', 'high', 'critical')),
+    target TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    evidence TEXT NOT NULL DEFAULT '',
+    refs TEXT NOT NULL DEFAULT '',
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE SET NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE SET NULL
+);
+
+INSERT INTO findings_old SELECT
+    id, session_id, host_id, fingerprint, scanner, template_id, name,
+    CASE WHEN severity = 'unknown' THEN 'info' ELSE severity END,
+    target, description, evidence, refs, occurrences, created_at, updated_at
+FROM findings;
+
+DROP TRIGGER IF EXISTS update_findings_updated_at;
+DROP INDEX IF EXISTS idx_findings_host_id;
+DROP TABLE findings;
+ALTER TABLE findings_old RENAME TO findings;
+
+CREATE INDEX IF NOT EXISTS idx_findings_host_id ON findings (host_id);
+
+CREATE TRIGGER IF NOT EXISTS update_findings_updated_at
+AFTER UPDATE ON findings
+BEGIN
+UPDATE findings SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The ledger entries outlive their session, the spend of the engagement not going down as the sessions are deleted
+CREATE TABLE usage_ledger_new (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,  -- Session the call was made for, which may be deleted since
+    message_id TEXT,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    agent TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0 CHECK (input_tokens >= 0),
+    output_tokens INTEGER NOT NULL DEFAULT 0 CHECK (output_tokens >= 0),
+    cache_creation_tokens INTEGER NOT NULL DEFAULT 0 CHECK (cache_creation_tokens >= 0),
+    cache_read_tokens INTEGER NOT NULL DEFAULT 0 CHECK (cache_read_tokens >= 0),
+    cost REAL NOT NULL DEFAULT 0.0 CHECK (cost >= 0.0),
+    created_at INTEGER NOT NULL  -- Unix timestamp in milliseconds
+);
+
+INSERT INTO usage_ledger_new SELECT * FROM usage_ledger;
+
+DROP INDEX IF EXISTS idx_usage_ledger_session_id;
+DROP TABLE usage_ledger;
+ALTER TABLE usage_ledger_new RENAME TO usage_ledger;
+
+CREATE INDEX IF NOT EXISTS idx_usage_ledger_session_id ON usage_ledger (session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE usage_ledger_old (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    message_id TEXT,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    agent TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0 CHECK (input_tokens >= 0),
+    output_tokens INTEGER NOT NULL DEFAULT 0 CHECK (output_tokens >= 0),
+    cache_creation_tokens INTEGER NOT NULL DEFAULT 0 CHECK (cache_creation_tokens >= 0),
+    cache_read_tokens INTEGER NOT NULL DEFAULT 0 CHECK (cache_read_tokens >= 0),
+    cost REAL NOT NULL DEFAULT 0.0 CHECK (cost >= 0.0),
+    created_at INTEGER NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+INSERT INTO usage_ledger_old SELECT * FROM usage_ledger
+WHERE session_id IN (SELECT id FROM sessions);
+
+DROP INDEX IF EXISTS idx_usage_ledger_session_id;
+DROP TABLE usage_ledger;
+ALTER TABLE usage_ledger_old RENAME TO usage_ledger;
+
+CREATE INDEX IF NOT EXISTS idx_usage_ledger_session_id ON usage_ledger (session_id);
+-- +goose StatementEnd
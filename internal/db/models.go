@@ -8,6 +8,129 @@ import (
 	"database/sql"
 )
 
+type Artifact struct {
+	ID          string         `json:"id"`
+	SessionID   string         `json:"session_id"`
+	MessageID   sql.NullString `json:"message_id"`
+	Name        string         `json:"name"`
+	SourcePath  string         `json:"source_path"`
+	Path        string         `json:"path"`
+	Size        int64          `json:"size"`
+	Sha256      string         `json:"sha256"`
+	MimeType    string         `json:"mime_type"`
+	Description string         `json:"description"`
+	CreatedAt   int64          `json:"created_at"`
+}
+
+type AuditLogEntry struct {
+	Seq       int64  `json:"seq"`
+	Kind      string `json:"kind"`
+	SessionID string `json:"session_id"`
+	MessageID string `json:"message_id"`
+	Payload   string `json:"payload"`
+	PrevHash  string `json:"prev_hash"`
+	Hash      string `json:"hash"`
+	Signature []byte `json:"signature"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type Credential struct {
+	ID        string         `json:"id"`
+	SessionID sql.NullString `json:"session_id"`
+	Ref       string         `json:"ref"`
+	Kind      string         `json:"kind"`
+	Username  string         `json:"username"`
+	Target    string         `json:"target"`
+	Secret    []byte         `json:"secret"`
+	Notes     string         `json:"notes"`
+	CreatedAt int64          `json:"created_at"`
+	UpdatedAt int64          `json:"updated_at"`
+}
+
+type Endpoint struct {
+	ID               string         `json:"id"`
+	HostID           string         `json:"host_id"`
+	SessionID        sql.NullString `json:"session_id"`
+	Url              string         `json:"url"`
+	Status           int64          `json:"status"`
+	Size             int64          `json:"size"`
+	Words            int64          `json:"words"`
+	Lines            int64          `json:"lines"`
+	ContentType      string         `json:"content_type"`
+	RedirectLocation string         `json:"redirect_location"`
+	CreatedAt        int64          `json:"created_at"`
+	UpdatedAt        int64          `json:"updated_at"`
+}
+
+type Exploit struct {
+	ID          string        `json:"id"`
+	Description string        `json:"description"`
+	Path        string        `json:"path"`
+	Type        string        `json:"type"`
+	Platform    string        `json:"platform"`
+	Port        sql.NullInt64 `json:"port"`
+	Verified    bool          `json:"verified"`
+	Codes       string        `json:"codes"`
+	PublishedAt string        `json:"published_at"`
+	UpdatedAt   int64         `json:"updated_at"`
+}
+
+type ExploitVulnerability struct {
+	ExploitID string `json:"exploit_id"`
+	CveID     string `json:"cve_id"`
+}
+
+type Finding struct {
+	ID          string         `json:"id"`
+	SessionID   sql.NullString `json:"session_id"`
+	HostID      sql.NullString `json:"host_id"`
+	Fingerprint string         `json:"fingerprint"`
+	Scanner     string         `json:"scanner"`
+	TemplateID  string         `json:"template_id"`
+	Name        string         `json:"name"`
+	Severity    string         `json:"severity"`
+	Target      string         `json:"target"`
+	Description string         `json:"description"`
+	Evidence    string         `json:"evidence"`
+	Refs        string         `json:"refs"`
+	Occurrences int64          `json:"occurrences"`
+	CreatedAt   int64          `json:"created_at"`
+	UpdatedAt   int64          `json:"updated_at"`
+}
+
+type Host struct {
+	ID        string         `json:"id"`
+	SessionID sql.NullString `json:"session_id"`
+	Address   string         `json:"address"`
+	Hostnames string         `json:"hostnames"`
+	Os        string         `json:"os"`
+	Status    string         `json:"status"`
+	CreatedAt int64          `json:"created_at"`
+	UpdatedAt int64          `json:"updated_at"`
+}
+
+type KnownExploitedVulnerability struct {
+	CveID          string `json:"cve_id"`
+	Vendor         string `json:"vendor"`
+	Product        string `json:"product"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	RequiredAction string `json:"required_action"`
+	Ransomware     string `json:"ransomware"`
+	DateAdded      string `json:"date_added"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+type Memory struct {
+	ID        string         `json:"id"`
+	SessionID sql.NullString `json:"session_id"`
+	Agent     string         `json:"agent"`
+	Subject   string         `json:"subject"`
+	Content   string         `json:"content"`
+	CreatedAt int64          `json:"created_at"`
+	UpdatedAt int64          `json:"updated_at"`
+}
+
 type Message struct {
 	ID         string         `json:"id"`
 	SessionID  string         `json:"session_id"`
@@ -19,15 +142,82 @@ type Message struct {
 	FinishedAt sql.NullInt64  `json:"finished_at"`
 }
 
+type Port struct {
+	ID        string         `json:"id"`
+	HostID    string         `json:"host_id"`
+	SessionID sql.NullString `json:"session_id"`
+	Protocol  string         `json:"protocol"`
+	Port      int64          `json:"port"`
+	State     string         `json:"state"`
+	Service   string         `json:"service"`
+	Product   string         `json:"product"`
+	Version   string         `json:"version"`
+	ExtraInfo string         `json:"extra_info"`
+	Scripts   string         `json:"scripts"`
+	CreatedAt int64          `json:"created_at"`
+	UpdatedAt int64          `json:"updated_at"`
+}
+
+type Scan struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Tool      string `json:"tool"`
+	Command   string `json:"command"`
+	Output    string `json:"output"`
+	CreatedAt int64  `json:"created_at"`
+}
+
 type Session struct {
-	ID               string         `json:"id"`
-	SummaryMessageID sql.NullString `json:"summary_message_id"`
-	ParentSessionID  sql.NullString `json:"parent_session_id"`
-	Title            string         `json:"title"`
-	MessageCount     int64          `json:"message_count"`
-	PromptTokens     int64          `json:"prompt_tokens"`
-	CompletionTokens int64          `json:"completion_tokens"`
-	Cost             float64        `json:"cost"`
-	UpdatedAt        int64          `json:"updated_at"`
-	CreatedAt        int64          `json:"created_at"`
+	ID                  string         `json:"id"`
+	SummaryMessageID    sql.NullString `json:"summary_message_id"`
+	ParentSessionID     sql.NullString `json:"parent_session_id"`
+	Title               string         `json:"title"`
+	MessageCount        int64          `json:"message_count"`
+	PromptTokens        int64          `json:"prompt_tokens"`
+	CompletionTokens    int64          `json:"completion_tokens"`
+	Cost                float64        `json:"cost"`
+	UpdatedAt           int64          `json:"updated_at"`
+	CreatedAt           int64          `json:"created_at"`
+	ContextTokens       int64          `json:"context_tokens"`
+	ForkedFromSessionID sql.NullString `json:"forked_from_session_id"`
+	ForkedFromMessageID sql.NullString `json:"forked_from_message_id"`
+	Tags                string         `json:"tags"`
+	ArchivedAt          sql.NullInt64  `json:"archived_at"`
+}
+
+type UsageLedger struct {
+	ID                  string         `json:"id"`
+	SessionID           string         `json:"session_id"`
+	MessageID           sql.NullString `json:"message_id"`
+	Provider            string         `json:"provider"`
+	Model               string         `json:"model"`
+	Agent               string         `json:"agent"`
+	Kind                string         `json:"kind"`
+	InputTokens         int64          `json:"input_tokens"`
+	OutputTokens        int64          `json:"output_tokens"`
+	CacheCreationTokens int64          `json:"cache_creation_tokens"`
+	CacheReadTokens     int64          `json:"cache_read_tokens"`
+	Cost                float64        `json:"cost"`
+	CreatedAt           int64          `json:"created_at"`
+}
+
+type Vulnerability struct {
+	ID           string          `json:"id"`
+	Description  string          `json:"description"`
+	CvssScore    sql.NullFloat64 `json:"cvss_score"`
+	CvssSeverity string          `json:"cvss_severity"`
+	CvssVector   string          `json:"cvss_vector"`
+	PublishedAt  string          `json:"published_at"`
+	UpdatedAt    int64           `json:"updated_at"`
+}
+
+type VulnerabilityCpe struct {
+	CveID                 string `json:"cve_id"`
+	Vendor                string `json:"vendor"`
+	Product               string `json:"product"`
+	Version               string `json:"version"`
+	VersionStartIncluding string `json:"version_start_including"`
+	VersionStartExcluding string `json:"version_start_excluding"`
+	VersionEndIncluding   string `json:"version_end_including"`
+	VersionEndExcluding   string `json:"version_end_excluding"`
 }
@@ -6,20 +6,81 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
+	AppendAuditEntry(ctx context.Context, arg AppendAuditEntryParams) (AuditLogEntry, error)
+	CreateArtifact(ctx context.Context, arg CreateArtifactParams) (Artifact, error)
+	CreateExploitVulnerability(ctx context.Context, arg CreateExploitVulnerabilityParams) error
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreateScan(ctx context.Context, arg CreateScanParams) (Scan, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateUsage(ctx context.Context, arg CreateUsageParams) (UsageLedger, error)
+	CreateVulnerabilityCPE(ctx context.Context, arg CreateVulnerabilityCPEParams) error
+	DeleteExploitVulnerabilities(ctx context.Context, exploitID string) error
+	DeleteMemory(ctx context.Context, id string) error
 	DeleteMessage(ctx context.Context, id string) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	DeleteVulnerabilityCPEs(ctx context.Context, cveID string) error
+	ForkSession(ctx context.Context, arg ForkSessionParams) (Session, error)
+	GetArtifact(ctx context.Context, id string) (Artifact, error)
+	GetCredentialByRef(ctx context.Context, ref string) (Credential, error)
+	GetEngagementUsage(ctx context.Context) (GetEngagementUsageRow, error)
+	GetExploit(ctx context.Context, id string) (Exploit, error)
+	GetFinding(ctx context.Context, id string) (Finding, error)
+	GetHostByAddress(ctx context.Context, address string) (Host, error)
+	GetKnownExploitedVulnerability(ctx context.Context, cveID string) (KnownExploitedVulnerability, error)
+	GetLastAuditEntry(ctx context.Context) (AuditLogEntry, error)
+	GetLastAuditEntryByKind(ctx context.Context, kind string) (AuditLogEntry, error)
+	GetLastAuditEntryByMessage(ctx context.Context, messageID string) (AuditLogEntry, error)
+	GetMemory(ctx context.Context, id string) (Memory, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
 	GetSessionByID(ctx context.Context, id string) (Session, error)
+	GetSessionTreeUsage(ctx context.Context, id string) (GetSessionTreeUsageRow, error)
+	GetVulnerability(ctx context.Context, id string) (Vulnerability, error)
+	ImportArtifact(ctx context.Context, arg ImportArtifactParams) error
+	ImportFinding(ctx context.Context, arg ImportFindingParams) error
+	ImportMessage(ctx context.Context, arg ImportMessageParams) error
+	ImportSession(ctx context.Context, arg ImportSessionParams) error
+	ListAllSessions(ctx context.Context) ([]Session, error)
+	ListArtifactsBySession(ctx context.Context, sessionID string) ([]Artifact, error)
+	ListAuditEntries(ctx context.Context) ([]AuditLogEntry, error)
+	ListCredentials(ctx context.Context) ([]Credential, error)
+	ListEndpoints(ctx context.Context) ([]Endpoint, error)
+	ListEndpointsByHost(ctx context.Context, hostID string) ([]Endpoint, error)
+	ListExploitVulnerabilitiesByCVE(ctx context.Context, cveID string) ([]ExploitVulnerability, error)
+	ListFindings(ctx context.Context) ([]Finding, error)
+	ListHosts(ctx context.Context) ([]Host, error)
+	ListKnownExploitedVulnerabilitiesByProduct(ctx context.Context, product string) ([]ListKnownExploitedVulnerabilitiesByProductRow, error)
+	ListMemories(ctx context.Context) ([]Memory, error)
 	ListMessagesBySession(ctx context.Context, sessionID string) ([]Message, error)
+	ListPorts(ctx context.Context) ([]Port, error)
+	ListPortsByHost(ctx context.Context, hostID string) ([]Port, error)
+	ListScansBySession(ctx context.Context, sessionID string) ([]Scan, error)
 	ListSessions(ctx context.Context) ([]Session, error)
+	ListTaskSessions(ctx context.Context, parentSessionID sql.NullString) ([]Session, error)
+	ListUsageBySession(ctx context.Context, sessionID string) ([]UsageLedger, error)
+	ListVulnerabilityCPEsByProduct(ctx context.Context, product string) ([]ListVulnerabilityCPEsByProductRow, error)
+	RenameSession(ctx context.Context, arg RenameSessionParams) (Session, error)
+	SearchExploits(ctx context.Context, arg SearchExploitsParams) ([]Exploit, error)
+	SearchMemories(ctx context.Context, arg SearchMemoriesParams) ([]Memory, error)
+	SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error)
+	SetSessionArchived(ctx context.Context, arg SetSessionArchivedParams) (Session, error)
+	SetSessionTags(ctx context.Context, arg SetSessionTagsParams) (Session, error)
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error)
+	UpdateSessionUsage(ctx context.Context, arg UpdateSessionUsageParams) (Session, error)
+	UpsertCredential(ctx context.Context, arg UpsertCredentialParams) (Credential, error)
+	UpsertEndpoint(ctx context.Context, arg UpsertEndpointParams) (Endpoint, error)
+	UpsertExploit(ctx context.Context, arg UpsertExploitParams) error
+	UpsertFinding(ctx context.Context, arg UpsertFindingParams) (Finding, error)
+	UpsertHost(ctx context.Context, arg UpsertHostParams) (Host, error)
+	UpsertKnownExploitedVulnerability(ctx context.Context, arg UpsertKnownExploitedVulnerabilityParams) error
+	UpsertMemory(ctx context.Context, arg UpsertMemoryParams) (Memory, error)
+	UpsertPort(ctx context.Context, arg UpsertPortParams) (Port, error)
+	UpsertVulnerability(ctx context.Context, arg UpsertVulnerabilityParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -33,7 +33,7 @@ INSERT INTO sessions (
     null,
     strftime('%s', 'now'),
     strftime('%s', 'now')
-) RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+) RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
 `
 
 type CreateSessionParams struct {
@@ -68,6 +68,11 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.Cost,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
@@ -82,8 +87,61 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 	return err
 }
 
+const forkSession = `-- name: ForkSession :one
+INSERT INTO sessions (
+    id,
+    title,
+    forked_from_session_id,
+    forked_from_message_id,
+    updated_at,
+    created_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    strftime('%s', 'now'),
+    strftime('%s', 'now')
+) RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+`
+
+type ForkSessionParams struct {
+	ID                  string         `json:"id"`
+	Title               string         `json:"title"`
+	ForkedFromSessionID sql.NullString `json:"forked_from_session_id"`
+	ForkedFromMessageID sql.NullString `json:"forked_from_message_id"`
+}
+
+func (q *Queries) ForkSession(ctx context.Context, arg ForkSessionParams) (Session, error) {
+	row := q.queryRow(ctx, q.forkSessionStmt, forkSession,
+		arg.ID,
+		arg.Title,
+		arg.ForkedFromSessionID,
+		arg.ForkedFromMessageID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.SummaryMessageID,
+		&i.ParentSessionID,
+		&i.Title,
+		&i.MessageCount,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.Cost,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
 FROM sessions
 WHERE id = ? LIMIT 1
 `
@@ -102,12 +160,120 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.Cost,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
+const importSession = `-- name: ImportSession :exec
+INSERT INTO sessions (
+    id,
+    parent_session_id,
+    title,
+    prompt_tokens,
+    completion_tokens,
+    cost,
+    context_tokens,
+    summary_message_id,
+    forked_from_session_id,
+    forked_from_message_id,
+    tags,
+    archived_at,
+    updated_at,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type ImportSessionParams struct {
+	ID                  string         `json:"id"`
+	ParentSessionID     sql.NullString `json:"parent_session_id"`
+	Title               string         `json:"title"`
+	PromptTokens        int64          `json:"prompt_tokens"`
+	CompletionTokens    int64          `json:"completion_tokens"`
+	Cost                float64        `json:"cost"`
+	ContextTokens       int64          `json:"context_tokens"`
+	SummaryMessageID    sql.NullString `json:"summary_message_id"`
+	ForkedFromSessionID sql.NullString `json:"forked_from_session_id"`
+	ForkedFromMessageID sql.NullString `json:"forked_from_message_id"`
+	Tags                string         `json:"tags"`
+	ArchivedAt          sql.NullInt64  `json:"archived_at"`
+	UpdatedAt           int64          `json:"updated_at"`
+	CreatedAt           int64          `json:"created_at"`
+}
+
+func (q *Queries) ImportSession(ctx context.Context, arg ImportSessionParams) error {
+	_, err := q.exec(ctx, q.importSessionStmt, importSession,
+		arg.ID,
+		arg.ParentSessionID,
+		arg.Title,
+		arg.PromptTokens,
+		arg.CompletionTokens,
+		arg.Cost,
+		arg.ContextTokens,
+		arg.SummaryMessageID,
+		arg.ForkedFromSessionID,
+		arg.ForkedFromMessageID,
+		arg.Tags,
+		arg.ArchivedAt,
+		arg.UpdatedAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const listAllSessions = `-- name: ListAllSessions :many
+SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+FROM sessions
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListAllSessions(ctx context.Context) ([]Session, error) {
+	rows, err := q.query(ctx, q.listAllSessionsStmt, listAllSessions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.SummaryMessageID,
+			&i.ParentSessionID,
+			&i.Title,
+			&i.MessageCount,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.ContextTokens,
+			&i.ForkedFromSessionID,
+			&i.ForkedFromMessageID,
+			&i.Tags,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSessions = `-- name: ListSessions :many
-SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
 FROM sessions
 WHERE parent_session_id is NULL
 ORDER BY created_at DESC
@@ -133,6 +299,11 @@ func (q *Queries) ListSessions(ctx context.Context) ([]Session, error) {
 			&i.Cost,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.ContextTokens,
+			&i.ForkedFromSessionID,
+			&i.ForkedFromMessageID,
+			&i.Tags,
+			&i.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -147,6 +318,166 @@ func (q *Queries) ListSessions(ctx context.Context) ([]Session, error) {
 	return items, nil
 }
 
+const listTaskSessions = `-- name: ListTaskSessions :many
+SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+FROM sessions
+WHERE parent_session_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListTaskSessions(ctx context.Context, parentSessionID sql.NullString) ([]Session, error) {
+	rows, err := q.query(ctx, q.listTaskSessionsStmt, listTaskSessions, parentSessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.SummaryMessageID,
+			&i.ParentSessionID,
+			&i.Title,
+			&i.MessageCount,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.ContextTokens,
+			&i.ForkedFromSessionID,
+			&i.ForkedFromMessageID,
+			&i.Tags,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const renameSession = `-- name: RenameSession :one
+UPDATE sessions
+SET title = ?
+WHERE id = ?
+RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+`
+
+type RenameSessionParams struct {
+	Title string `json:"title"`
+	ID    string `json:"id"`
+}
+
+func (q *Queries) RenameSession(ctx context.Context, arg RenameSessionParams) (Session, error) {
+	row := q.queryRow(ctx, q.renameSessionStmt, renameSession,
+		arg.Title,
+		arg.ID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.SummaryMessageID,
+		&i.ParentSessionID,
+		&i.Title,
+		&i.MessageCount,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.Cost,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const setSessionArchived = `-- name: SetSessionArchived :one
+UPDATE sessions
+SET archived_at = ?
+WHERE id = ?
+RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+`
+
+type SetSessionArchivedParams struct {
+	ArchivedAt sql.NullInt64 `json:"archived_at"`
+	ID         string        `json:"id"`
+}
+
+func (q *Queries) SetSessionArchived(ctx context.Context, arg SetSessionArchivedParams) (Session, error) {
+	row := q.queryRow(ctx, q.setSessionArchivedStmt, setSessionArchived,
+		arg.ArchivedAt,
+		arg.ID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.SummaryMessageID,
+		&i.ParentSessionID,
+		&i.Title,
+		&i.MessageCount,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.Cost,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const setSessionTags = `-- name: SetSessionTags :one
+UPDATE sessions
+SET tags = ?
+WHERE id = ?
+RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+`
+
+type SetSessionTagsParams struct {
+	Tags string `json:"tags"`
+	ID   string `json:"id"`
+}
+
+func (q *Queries) SetSessionTags(ctx context.Context, arg SetSessionTagsParams) (Session, error) {
+	row := q.queryRow(ctx, q.setSessionTagsStmt, setSessionTags,
+		arg.Tags,
+		arg.ID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.SummaryMessageID,
+		&i.ParentSessionID,
+		&i.Title,
+		&i.MessageCount,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.Cost,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
 const updateSession = `-- name: UpdateSession :one
 UPDATE sessions
 SET
@@ -154,9 +485,10 @@ SET
     prompt_tokens = ?,
     completion_tokens = ?,
     summary_message_id = ?,
-    cost = ?
+    cost = ?,
+    context_tokens = ?
 WHERE id = ?
-RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
 `
 
 type UpdateSessionParams struct {
@@ -165,6 +497,7 @@ type UpdateSessionParams struct {
 	CompletionTokens int64          `json:"completion_tokens"`
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	Cost             float64        `json:"cost"`
+	ContextTokens    int64          `json:"context_tokens"`
 	ID               string         `json:"id"`
 }
 
@@ -175,6 +508,55 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		arg.CompletionTokens,
 		arg.SummaryMessageID,
 		arg.Cost,
+		arg.ContextTokens,
+		arg.ID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.SummaryMessageID,
+		&i.ParentSessionID,
+		&i.Title,
+		&i.MessageCount,
+		&i.PromptTokens,
+		&i.CompletionTokens,
+		&i.Cost,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const updateSessionUsage = `-- name: UpdateSessionUsage :one
+UPDATE sessions
+SET
+    prompt_tokens = ?,
+    completion_tokens = ?,
+    cost = ?,
+    context_tokens = COALESCE(?, context_tokens)
+WHERE id = ?
+RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, context_tokens, forked_from_session_id, forked_from_message_id, tags, archived_at
+`
+
+type UpdateSessionUsageParams struct {
+	PromptTokens     int64         `json:"prompt_tokens"`
+	CompletionTokens int64         `json:"completion_tokens"`
+	Cost             float64       `json:"cost"`
+	ContextTokens    sql.NullInt64 `json:"context_tokens"`
+	ID               string        `json:"id"`
+}
+
+func (q *Queries) UpdateSessionUsage(ctx context.Context, arg UpdateSessionUsageParams) (Session, error) {
+	row := q.queryRow(ctx, q.updateSessionUsageStmt, updateSessionUsage,
+		arg.PromptTokens,
+		arg.CompletionTokens,
+		arg.Cost,
+		arg.ContextTokens,
 		arg.ID,
 	)
 	var i Session
@@ -189,6 +571,11 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.Cost,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.ContextTokens,
+		&i.ForkedFromSessionID,
+		&i.ForkedFromMessageID,
+		&i.Tags,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
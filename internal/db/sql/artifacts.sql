@@ -0,0 +1,45 @@
+-- name: CreateArtifact :one
+INSERT INTO artifacts (
+    id,
+    session_id,
+    message_id,
+    name,
+    source_path,
+    path,
+    size,
+    sha256,
+    mime_type,
+    description,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: GetArtifact :one
+SELECT *
+FROM artifacts
+WHERE id = ? LIMIT 1;
+
+-- name: ListArtifactsBySession :many
+SELECT *
+FROM artifacts
+WHERE session_id = ?
+ORDER BY created_at ASC;
+
+-- name: ImportArtifact :exec
+INSERT INTO artifacts (
+    id,
+    session_id,
+    message_id,
+    name,
+    source_path,
+    path,
+    size,
+    sha256,
+    mime_type,
+    description,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
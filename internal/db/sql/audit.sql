@@ -0,0 +1,36 @@
+-- name: AppendAuditEntry :one
+INSERT INTO audit_log (
+    kind,
+    session_id,
+    message_id,
+    payload,
+    prev_hash,
+    hash,
+    signature,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetLastAuditEntry :one
+SELECT *
+FROM audit_log
+ORDER BY seq DESC LIMIT 1;
+
+-- name: GetLastAuditEntryByMessage :one
+SELECT *
+FROM audit_log
+WHERE message_id = ?
+ORDER BY seq DESC LIMIT 1;
+
+-- name: GetLastAuditEntryByKind :one
+SELECT *
+FROM audit_log
+WHERE kind = ?
+ORDER BY seq DESC LIMIT 1;
+
+-- name: ListAuditEntries :many
+SELECT *
+FROM audit_log
+ORDER BY seq ASC;
@@ -0,0 +1,33 @@
+-- name: UpsertCredential :one
+INSERT INTO credentials (
+    id,
+    session_id,
+    ref,
+    kind,
+    username,
+    target,
+    secret,
+    notes,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (ref) DO UPDATE SET
+    session_id = excluded.session_id,
+    kind = excluded.kind,
+    username = excluded.username,
+    target = excluded.target,
+    secret = excluded.secret,
+    notes = excluded.notes
+RETURNING *;
+
+-- name: GetCredentialByRef :one
+SELECT *
+FROM credentials
+WHERE ref = ? LIMIT 1;
+
+-- name: ListCredentials :many
+SELECT *
+FROM credentials
+ORDER BY created_at ASC;
@@ -0,0 +1,66 @@
+-- name: UpsertFinding :one
+INSERT INTO findings (
+    id,
+    session_id,
+    host_id,
+    fingerprint,
+    scanner,
+    template_id,
+    name,
+    severity,
+    target,
+    description,
+    evidence,
+    refs,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (fingerprint) DO UPDATE SET
+    session_id = excluded.session_id,
+    host_id = COALESCE(excluded.host_id, findings.host_id),
+    name = excluded.name,
+    severity = excluded.severity,
+    description = excluded.description,
+    evidence = excluded.evidence,
+    refs = excluded.refs,
+    occurrences = findings.occurrences + 1
+RETURNING *;
+
+-- name: GetFinding :one
+SELECT *
+FROM findings
+WHERE id = ? LIMIT 1;
+
+-- name: ListFindings :many
+SELECT *
+FROM findings
+ORDER BY CASE severity
+    WHEN 'critical' THEN 0
+    WHEN 'high' THEN 1
+    WHEN 'medium' THEN 2
+    WHEN 'low' THEN 3
+    ELSE 4
+END ASC, created_at ASC;
+
+-- name: ImportFinding :exec
+INSERT INTO findings (
+    id,
+    session_id,
+    host_id,
+    fingerprint,
+    scanner,
+    template_id,
+    name,
+    severity,
+    target,
+    description,
+    evidence,
+    refs,
+    occurrences,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
@@ -0,0 +1,124 @@
+-- name: UpsertHost :one
+INSERT INTO hosts (
+    id,
+    session_id,
+    address,
+    hostnames,
+    os,
+    status,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (address) DO UPDATE SET
+    hostnames = CASE WHEN excluded.hostnames != '' THEN excluded.hostnames ELSE hosts.hostnames END,
+    os = CASE WHEN excluded.os != '' THEN excluded.os ELSE hosts.os END,
+    status = CASE WHEN excluded.status != '' THEN excluded.status ELSE hosts.status END
+RETURNING *;
+
+-- name: GetHostByAddress :one
+SELECT *
+FROM hosts
+WHERE address = ? LIMIT 1;
+
+-- name: ListHosts :many
+SELECT *
+FROM hosts
+ORDER BY created_at ASC;
+
+-- name: UpsertPort :one
+INSERT INTO ports (
+    id,
+    host_id,
+    session_id,
+    protocol,
+    port,
+    state,
+    service,
+    product,
+    version,
+    extra_info,
+    scripts,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (host_id, protocol, port) DO UPDATE SET
+    session_id = excluded.session_id,
+    state = excluded.state,
+    service = CASE WHEN excluded.service != '' THEN excluded.service ELSE ports.service END,
+    product = CASE WHEN excluded.product != '' THEN excluded.product ELSE ports.product END,
+    version = CASE WHEN excluded.version != '' THEN excluded.version ELSE ports.version END,
+    extra_info = CASE WHEN excluded.extra_info != '' THEN excluded.extra_info ELSE ports.extra_info END,
+    scripts = json_patch(ports.scripts, excluded.scripts)
+RETURNING *;
+
+-- name: ListPorts :many
+SELECT *
+FROM ports
+ORDER BY host_id ASC, protocol ASC, port ASC;
+
+-- name: ListPortsByHost :many
+SELECT *
+FROM ports
+WHERE host_id = ?
+ORDER BY protocol ASC, port ASC;
+
+-- name: CreateScan :one
+INSERT INTO scans (
+    id,
+    session_id,
+    tool,
+    command,
+    output,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: ListScansBySession :many
+SELECT *
+FROM scans
+WHERE session_id = ?
+ORDER BY created_at ASC;
+
+-- name: UpsertEndpoint :one
+INSERT INTO endpoints (
+    id,
+    host_id,
+    session_id,
+    url,
+    status,
+    size,
+    words,
+    lines,
+    content_type,
+    redirect_location,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (url) DO UPDATE SET
+    session_id = excluded.session_id,
+    status = excluded.status,
+    size = excluded.size,
+    words = excluded.words,
+    lines = excluded.lines,
+    content_type = excluded.content_type,
+    redirect_location = excluded.redirect_location
+RETURNING *;
+
+-- name: ListEndpoints :many
+SELECT *
+FROM endpoints
+ORDER BY host_id ASC, url ASC;
+
+-- name: ListEndpointsByHost :many
+SELECT *
+FROM endpoints
+WHERE host_id = ?
+ORDER BY url ASC;
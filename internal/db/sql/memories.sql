@@ -0,0 +1,38 @@
+-- name: UpsertMemory :one
+INSERT INTO memories (
+    id,
+    session_id,
+    agent,
+    subject,
+    content,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (subject, content) DO UPDATE SET
+    session_id = excluded.session_id,
+    agent = excluded.agent
+RETURNING *;
+
+-- name: GetMemory :one
+SELECT *
+FROM memories
+WHERE id = ? LIMIT 1;
+
+-- name: ListMemories :many
+SELECT *
+FROM memories
+ORDER BY created_at ASC;
+
+-- name: DeleteMemory :exec
+DELETE FROM memories
+WHERE id = ?;
+
+-- name: SearchMemories :many
+SELECT memories.*
+FROM memories_fts
+JOIN memories ON memories.id = memories_fts.memory_id
+WHERE memories_fts MATCH sqlc.arg(query)
+ORDER BY rank
+LIMIT sqlc.arg(max_results);
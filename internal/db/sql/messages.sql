@@ -39,3 +39,35 @@ WHERE id = ?;
 -- name: DeleteSessionMessages :exec
 DELETE FROM messages
 WHERE session_id = ?;
+
+-- name: ImportMessage :exec
+INSERT INTO messages (
+    id,
+    session_id,
+    role,
+    parts,
+    model,
+    created_at,
+    updated_at,
+    finished_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: SearchMessages :many
+SELECT
+    m.id AS message_id,
+    m.session_id,
+    s.title AS session_title,
+    coalesce(p.id, s.id) AS root_session_id,
+    coalesce(p.title, s.title) AS root_session_title,
+    m.role,
+    m.created_at,
+    snippet(messages_fts, 0, char(2), char(3), '…', 16) AS snippet
+FROM messages_fts
+JOIN messages m ON m.id = messages_fts.message_id
+JOIN sessions s ON s.id = m.session_id
+LEFT JOIN sessions p ON p.id = s.parent_session_id
+WHERE messages_fts MATCH sqlc.arg(query)
+ORDER BY rank
+LIMIT sqlc.arg(max_results);
@@ -23,6 +23,23 @@ INSERT INTO sessions (
     strftime('%s', 'now')
 ) RETURNING *;
 
+-- name: ForkSession :one
+INSERT INTO sessions (
+    id,
+    title,
+    forked_from_session_id,
+    forked_from_message_id,
+    updated_at,
+    created_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    strftime('%s', 'now'),
+    strftime('%s', 'now')
+) RETURNING *;
+
 -- name: GetSessionByID :one
 SELECT *
 FROM sessions
@@ -34,6 +51,30 @@ FROM sessions
 WHERE parent_session_id is NULL
 ORDER BY created_at DESC;
 
+-- name: ListTaskSessions :many
+SELECT *
+FROM sessions
+WHERE parent_session_id = ?
+ORDER BY created_at ASC;
+
+-- name: RenameSession :one
+UPDATE sessions
+SET title = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: SetSessionTags :one
+UPDATE sessions
+SET tags = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: SetSessionArchived :one
+UPDATE sessions
+SET archived_at = ?
+WHERE id = ?
+RETURNING *;
+
 -- name: UpdateSession :one
 UPDATE sessions
 SET
@@ -41,7 +82,18 @@ SET
     prompt_tokens = ?,
     completion_tokens = ?,
     summary_message_id = ?,
-    cost = ?
+    cost = ?,
+    context_tokens = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateSessionUsage :one
+UPDATE sessions
+SET
+    prompt_tokens = ?,
+    completion_tokens = ?,
+    cost = ?,
+    context_tokens = COALESCE(sqlc.narg(context_tokens), context_tokens)
 WHERE id = ?
 RETURNING *;
 
@@ -49,3 +101,28 @@ RETURNING *;
 -- name: DeleteSession :exec
 DELETE FROM sessions
 WHERE id = ?;
+
+-- name: ListAllSessions :many
+SELECT *
+FROM sessions
+ORDER BY created_at ASC;
+
+-- name: ImportSession :exec
+INSERT INTO sessions (
+    id,
+    parent_session_id,
+    title,
+    prompt_tokens,
+    completion_tokens,
+    cost,
+    context_tokens,
+    summary_message_id,
+    forked_from_session_id,
+    forked_from_message_id,
+    tags,
+    archived_at,
+    updated_at,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
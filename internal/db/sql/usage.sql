@@ -0,0 +1,45 @@
+-- name: CreateUsage :one
+INSERT INTO usage_ledger (
+    id,
+    session_id,
+    message_id,
+    provider,
+    model,
+    agent,
+    kind,
+    input_tokens,
+    output_tokens,
+    cache_creation_tokens,
+    cache_read_tokens,
+    cost,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: ListUsageBySession :many
+SELECT *
+FROM usage_ledger
+WHERE session_id = ?
+ORDER BY created_at ASC;
+
+-- name: GetSessionTreeUsage :one
+WITH RECURSIVE session_tree(id) AS (
+    SELECT sessions.id FROM sessions WHERE sessions.id = ?
+    UNION ALL
+    SELECT sessions.id FROM sessions JOIN session_tree ON sessions.parent_session_id = session_tree.id
+)
+SELECT
+    CAST(COALESCE(SUM(input_tokens + cache_creation_tokens + cache_read_tokens), 0) AS INTEGER) AS prompt_tokens,
+    CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS completion_tokens,
+    CAST(COALESCE(SUM(cost), 0.0) AS REAL) AS cost
+FROM usage_ledger
+WHERE session_id IN (SELECT id FROM session_tree);
+
+-- name: GetEngagementUsage :one
+SELECT
+    CAST(COALESCE(SUM(input_tokens + cache_creation_tokens + cache_read_tokens), 0) AS INTEGER) AS prompt_tokens,
+    CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS completion_tokens,
+    CAST(COALESCE(SUM(cost), 0.0) AS REAL) AS cost
+FROM usage_ledger;
@@ -0,0 +1,148 @@
+-- name: UpsertVulnerability :exec
+INSERT INTO vulnerabilities (
+    id,
+    description,
+    cvss_score,
+    cvss_severity,
+    cvss_vector,
+    published_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+ON CONFLICT (id) DO UPDATE SET
+    description = excluded.description,
+    cvss_score = excluded.cvss_score,
+    cvss_severity = excluded.cvss_severity,
+    cvss_vector = excluded.cvss_vector,
+    published_at = excluded.published_at,
+    updated_at = excluded.updated_at;
+
+-- name: GetVulnerability :one
+SELECT *
+FROM vulnerabilities
+WHERE id = ? LIMIT 1;
+
+-- name: DeleteVulnerabilityCPEs :exec
+DELETE FROM vulnerability_cpes
+WHERE cve_id = ?;
+
+-- name: CreateVulnerabilityCPE :exec
+INSERT INTO vulnerability_cpes (
+    cve_id,
+    vendor,
+    product,
+    version,
+    version_start_including,
+    version_start_excluding,
+    version_end_including,
+    version_end_excluding
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListVulnerabilityCPEsByProduct :many
+-- The CPEs are listed along with what the CVEs are ranked by, so that only the CVEs looked up are read in full.
+SELECT
+    c.*,
+    CAST(COALESCE(v.cvss_score, 0) AS REAL) AS cvss_score,
+    EXISTS (SELECT 1 FROM known_exploited_vulnerabilities k WHERE k.cve_id = c.cve_id) AS known_exploited,
+    EXISTS (SELECT 1 FROM exploit_vulnerabilities e WHERE e.cve_id = c.cve_id) AS exploited
+FROM vulnerability_cpes c
+LEFT JOIN vulnerabilities v ON v.id = c.cve_id
+WHERE c.product = ?;
+
+-- name: UpsertKnownExploitedVulnerability :exec
+INSERT INTO known_exploited_vulnerabilities (
+    cve_id,
+    vendor,
+    product,
+    name,
+    description,
+    required_action,
+    ransomware,
+    date_added,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+ON CONFLICT (cve_id) DO UPDATE SET
+    vendor = excluded.vendor,
+    product = excluded.product,
+    name = excluded.name,
+    description = excluded.description,
+    required_action = excluded.required_action,
+    ransomware = excluded.ransomware,
+    date_added = excluded.date_added,
+    updated_at = excluded.updated_at;
+
+-- name: GetKnownExploitedVulnerability :one
+SELECT *
+FROM known_exploited_vulnerabilities
+WHERE cve_id = ? LIMIT 1;
+
+-- name: ListKnownExploitedVulnerabilitiesByProduct :many
+SELECT
+    k.cve_id,
+    CAST(COALESCE(v.cvss_score, 0) AS REAL) AS cvss_score,
+    EXISTS (SELECT 1 FROM exploit_vulnerabilities e WHERE e.cve_id = k.cve_id) AS exploited
+FROM known_exploited_vulnerabilities k
+LEFT JOIN vulnerabilities v ON v.id = k.cve_id
+WHERE k.product LIKE ?
+ORDER BY k.date_added DESC;
+
+-- name: UpsertExploit :exec
+INSERT INTO exploits (
+    id,
+    description,
+    path,
+    type,
+    platform,
+    port,
+    verified,
+    codes,
+    published_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+ON CONFLICT (id) DO UPDATE SET
+    description = excluded.description,
+    path = excluded.path,
+    type = excluded.type,
+    platform = excluded.platform,
+    port = excluded.port,
+    verified = excluded.verified,
+    codes = excluded.codes,
+    published_at = excluded.published_at,
+    updated_at = excluded.updated_at;
+
+-- name: GetExploit :one
+SELECT *
+FROM exploits
+WHERE id = ? LIMIT 1;
+
+-- name: SearchExploits :many
+SELECT *
+FROM exploits
+WHERE description LIKE ?
+ORDER BY published_at DESC
+LIMIT ?;
+
+-- name: DeleteExploitVulnerabilities :exec
+DELETE FROM exploit_vulnerabilities
+WHERE exploit_id = ?;
+
+-- name: CreateExploitVulnerability :exec
+INSERT INTO exploit_vulnerabilities (
+    exploit_id,
+    cve_id
+) VALUES (
+    ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: ListExploitVulnerabilitiesByCVE :many
+SELECT *
+FROM exploit_vulnerabilities
+WHERE cve_id = ?;
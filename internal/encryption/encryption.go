@@ -0,0 +1,194 @@
+// Package encryption encrypts the data of the engagement at rest, the database, the session log files and the artifacts,
+// with a key derived from a passphrase.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ParamsFile is the file of the data directory holding the parameters the key is derived with,
+// the data directory being encrypted when it exists.
+const ParamsFile = "encryption.json"
+
+// pendingParamsFile holds the parameters of a rekey until it's complete, for an interrupted one to be resumed.
+const pendingParamsFile = ParamsFile + ".rekey"
+
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	// the first 64 bytes of the derived key are the AES-256-XTS key of the database, the last 32 bytes the AES-GCM key of the files.
+	dbKeyLength   = 64
+	fileKeyLength = 32
+
+	checkMessage = "tandem encryption check"
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// Params are the parameters the key is derived with.
+type Params struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	// Check tells the right passphrase from the wrong ones.
+	Check []byte `json:"check"`
+}
+
+// Key is the key the data of the engagement is encrypted with.
+type Key struct {
+	db   []byte
+	aead cipher.AEAD
+}
+
+// Enabled reports whether the data directory is encrypted.
+func Enabled(dataDir string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, ParamsFile))
+	return err == nil
+}
+
+// NewParams derives a key from the passphrase with a new salt.
+func NewParams(passphrase string) (Params, *Key, error) {
+	if passphrase == "" {
+		return Params{}, nil, fmt.Errorf("the passphrase is empty")
+	}
+	params := Params{
+		Salt:    make([]byte, 16),
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+	}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return Params{}, nil, err
+	}
+	key, check, err := params.derive(passphrase)
+	if err != nil {
+		return Params{}, nil, err
+	}
+	params.Check = check
+	return params, key, nil
+}
+
+// ReadParams reads the parameters of the data directory.
+func ReadParams(dataDir string) (Params, error) {
+	return readParams(filepath.Join(dataDir, ParamsFile))
+}
+
+func readParams(path string) (Params, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Params{}, err
+	}
+	var params Params
+	if err := json.Unmarshal(data, &params); err != nil {
+		return Params{}, fmt.Errorf("invalid %s: %w", ParamsFile, err)
+	}
+	return params, nil
+}
+
+// WriteParams writes the parameters to the data directory, replacing the former ones atomically.
+func WriteParams(dataDir string, params Params) error {
+	return writeParams(filepath.Join(dataDir, ParamsFile), params)
+}
+
+func writeParams(path string, params Params) error {
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// PendingRekey returns the parameters of the rekey of the data directory which was interrupted, if any.
+func PendingRekey(dataDir string) (Params, bool, error) {
+	params, err := readParams(filepath.Join(dataDir, pendingParamsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return Params{}, false, nil
+	}
+	return params, err == nil, err
+}
+
+// BeginRekey keeps the parameters of the rekey of the data directory until CommitRekey, for the data encrypted
+// with their key not to be lost if the rekey is interrupted.
+func BeginRekey(dataDir string, params Params) error {
+	return writeParams(filepath.Join(dataDir, pendingParamsFile), params)
+}
+
+// CommitRekey replaces the parameters of the data directory with the ones of its rekey.
+func CommitRekey(dataDir string) error {
+	return os.Rename(filepath.Join(dataDir, pendingParamsFile), filepath.Join(dataDir, ParamsFile))
+}
+
+// Unlock derives the key from the passphrase, failing with ErrWrongPassphrase if it isn't the one of the parameters.
+func (p Params) Unlock(passphrase string) (*Key, error) {
+	key, check, err := p.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(check, p.Check) {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+func (p Params) derive(passphrase string) (*Key, []byte, error) {
+	derived := argon2.IDKey([]byte(passphrase), p.Salt, p.Time, p.Memory, p.Threads, dbKeyLength+fileKeyLength)
+	fileKey := derived[dbKeyLength:]
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	mac := hmac.New(sha256.New, fileKey)
+	mac.Write([]byte(checkMessage))
+	return &Key{db: derived[:dbKeyLength], aead: aead}, mac.Sum(nil), nil
+}
+
+// DatabaseHexKey returns the key of the database, for the hexkey parameter of the xts VFS of SQLite.
+func (k *Key) DatabaseHexKey() string {
+	return hex.EncodeToString(k.db)
+}
+
+var (
+	activeMu sync.RWMutex
+	active   *Key
+)
+
+// Activate makes the key the one the files of the engagement are encrypted with from now on.
+func Activate(key *Key) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	active = key
+}
+
+// Active returns the key the files of the engagement are encrypted with, nil if they're not.
+func Active() *Key {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	return active
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,281 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newTestKey(t *testing.T, passphrase string) (Params, *Key) {
+	t.Helper()
+	params, key, err := NewParams(passphrase)
+	if err != nil {
+		t.Fatalf("NewParams() failed: %v", err)
+	}
+	return params, key
+}
+
+func TestUnlock(t *testing.T) {
+	params, key := newTestKey(t, "correct horse")
+
+	unlocked, err := params.Unlock("correct horse")
+	if err != nil {
+		t.Fatalf("Unlock() failed: %v", err)
+	}
+	if unlocked.DatabaseHexKey() != key.DatabaseHexKey() {
+		t.Errorf("Unlock() derived another key")
+	}
+	if _, err := params.Unlock("wrong horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Unlock() error = %v, expected %v", err, ErrWrongPassphrase)
+	}
+	if _, _, err := NewParams(""); err == nil {
+		t.Errorf("NewParams() succeeded with an empty passphrase")
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	_, key := newTestKey(t, "correct horse")
+	_, otherKey := newTestKey(t, "correct horse")
+	data := []byte("the session log")
+
+	tests := []struct {
+		name string
+		// tamper alters the encrypted content, before it's decrypted.
+		tamper    func(content []byte) []byte
+		key       *Key
+		expectErr bool
+	}{
+		{
+			name: "round trip",
+		},
+		{
+			name: "tampered ciphertext",
+			tamper: func(content []byte) []byte {
+				content[len(content)-1] ^= 1
+				return content
+			},
+			expectErr: true,
+		},
+		{
+			name:      "truncated",
+			tamper:    func(content []byte) []byte { return content[:len(content)-1] },
+			expectErr: true,
+		},
+		{
+			name:      "not encrypted",
+			tamper:    func(content []byte) []byte { return data },
+			expectErr: true,
+		},
+		{
+			name:      "wrong key",
+			key:       otherKey,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := key.Encrypt(data)
+			if err != nil {
+				t.Fatalf("Encrypt() failed: %v", err)
+			}
+			if !IsEncrypted(content) || bytes.Contains(content, data) {
+				t.Fatalf("Encrypt() = %q, expected an encrypted file", content)
+			}
+			if tt.tamper != nil {
+				content = tt.tamper(content)
+			}
+			decryptKey := key
+			if tt.key != nil {
+				decryptKey = tt.key
+			}
+			decrypted, err := decryptKey.Decrypt(content)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Decrypt() = %q, expected an error", decrypted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decrypt() failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Errorf("Decrypt() = %q, expected %q", decrypted, data)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	_, key := newTestKey(t, "correct horse")
+	_, otherKey := newTestKey(t, "correct horse")
+	t.Cleanup(func() { Activate(nil) })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.log")
+
+	// A file written in plaintext is encrypted once a record is appended with a key.
+	Activate(nil)
+	if err := AppendFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("AppendFile() failed: %v", err)
+	}
+	Activate(key)
+	for _, line := range []string{"second\n", "third\n"} {
+		if err := AppendFile(path, []byte(line), 0o600); err != nil {
+			t.Fatalf("AppendFile() failed: %v", err)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(content) || bytes.Contains(content, []byte("first")) {
+		t.Fatalf("the file isn't encrypted")
+	}
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	if string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("ReadFile() = %q", data)
+	}
+
+	// The file is reencrypted with another key, and left as is if it already is.
+	if err := ReencryptFile(path, key, otherKey); err != nil {
+		t.Fatalf("ReencryptFile() failed: %v", err)
+	}
+	if err := ReencryptFile(path, key, otherKey); err != nil {
+		t.Fatalf("ReencryptFile() of a file already reencrypted failed: %v", err)
+	}
+	if _, err := ReadFile(path); err == nil {
+		t.Errorf("ReadFile() succeeded with the former key")
+	}
+	Activate(otherKey)
+	if data, err = ReadFile(path); err != nil || string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+
+	Activate(nil)
+	if _, err := ReadFile(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("ReadFile() error = %v, expected %v", err, ErrLocked)
+	}
+}
+
+// splitRecords splits the content of an encrypted file into its header and its records.
+func splitRecords(t *testing.T, content []byte) ([]byte, [][]byte) {
+	t.Helper()
+	var records [][]byte
+	rest := content[headerSize:]
+	for len(rest) > 0 {
+		n := 4 + int(binary.BigEndian.Uint32(rest))
+		records = append(records, rest[:n])
+		rest = rest[n:]
+	}
+	return content[:headerSize], records
+}
+
+func TestRecords(t *testing.T) {
+	_, key := newTestKey(t, "correct horse")
+	t.Cleanup(func() { Activate(nil) })
+	Activate(key)
+
+	dir := t.TempDir()
+	write := func(name string, lines ...string) []byte {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		for _, line := range lines {
+			if err := AppendFile(path, []byte(line), 0o600); err != nil {
+				t.Fatalf("AppendFile() failed: %v", err)
+			}
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	content := write("a.log", "first\n", "second\n", "third\n")
+	other := write("b.log", "other\n", "lines\n")
+
+	tests := []struct {
+		name string
+		// tamper returns the content of the file, out of its header and records.
+		tamper func(header []byte, records [][]byte) [][]byte
+	}{
+		{
+			name: "reordered records",
+			tamper: func(header []byte, records [][]byte) [][]byte {
+				return [][]byte{header, records[1], records[0], records[2]}
+			},
+		},
+		{
+			name: "final record dropped",
+			tamper: func(header []byte, records [][]byte) [][]byte {
+				return [][]byte{header, records[0], records[1]}
+			},
+		},
+		{
+			name: "every record dropped",
+			tamper: func(header []byte, records [][]byte) [][]byte {
+				return [][]byte{header}
+			},
+		},
+		{
+			name: "record appended after the final one",
+			tamper: func(header []byte, records [][]byte) [][]byte {
+				return [][]byte{header, records[0], records[1], records[2], records[2]}
+			},
+		},
+		{
+			name: "record of another file",
+			tamper: func(header []byte, records [][]byte) [][]byte {
+				_, others := splitRecords(t, other)
+				return [][]byte{header, others[0], records[1], records[2]}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, records := splitRecords(t, bytes.Clone(content))
+			tampered := bytes.Join(tt.tamper(header, records), nil)
+			if data, err := key.Decrypt(tampered); err == nil {
+				t.Errorf("Decrypt() = %q, expected an error", data)
+			}
+		})
+	}
+
+	if data, err := key.Decrypt(content); err != nil || string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("Decrypt() = %q, %v", data, err)
+	}
+}
+
+func TestAppendFileRewritten(t *testing.T) {
+	_, key := newTestKey(t, "correct horse")
+	t.Cleanup(func() { Activate(nil) })
+	Activate(key)
+
+	path := filepath.Join(t.TempDir(), "session.log")
+	for _, line := range []string{"first\n", "second\n"} {
+		if err := AppendFile(path, []byte(line), 0o600); err != nil {
+			t.Fatalf("AppendFile() failed: %v", err)
+		}
+	}
+	// The file is rewritten as a whole, e.g. as the secrets stored since are redacted from it.
+	if err := WriteFile(path, []byte("first\nsecond\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := AppendFile(path, []byte("third\n"), 0o600); err != nil {
+		t.Fatalf("AppendFile() failed: %v", err)
+	}
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if !slices.EqualFunc(lines, [][]byte{[]byte("first\n"), []byte("second\n"), []byte("third\n"), {}}, bytes.Equal) {
+		t.Errorf("ReadFile() = %q", data)
+	}
+}
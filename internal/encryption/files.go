@@ -0,0 +1,331 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// magic starts the encrypted files. It's followed by the random ID of the file, and by records appended one after
+// the other, each one being its length, on 4 bytes, followed by its nonce and its ciphertext.
+const (
+	magic      = "TANDEMENC2\n"
+	fileIDSize = 16
+	headerSize = len(magic) + fileIDSize
+)
+
+var (
+	// appendMu serializes the appends, the final record of a file being sealed again by each one.
+	appendMu sync.Mutex
+	// lastRecords caches the last record of the files appended to, for the records not to be read through each time.
+	lastRecords = map[string]recordPosition{}
+)
+
+// recordPosition is the position of the last record of an encrypted file, as long as the file is of the size.
+type recordPosition struct {
+	size   int64
+	offset int64
+	index  uint64
+}
+
+// ErrLocked is returned when reading an encrypted file without the key.
+var ErrLocked = errors.New("the file is encrypted and the key isn't unlocked")
+
+// IsEncrypted reports whether the data is the one of an encrypted file.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// recordAAD is the additional data a record is authenticated with: the ID of its file, its index within the file,
+// and whether it's the final record. Records can't be reordered, moved to another file, dropped or truncated away.
+func recordAAD(fileID []byte, index uint64, final bool) []byte {
+	aad := binary.BigEndian.AppendUint64(bytes.Clone(fileID), index)
+	if final {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+func (k *Key) record(data, fileID []byte, index uint64, final bool) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := k.aead.Seal(nonce, nonce, data, recordAAD(fileID, index, final))
+	record := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(sealed)), uint32(len(sealed)))
+	return append(record, sealed...), nil
+}
+
+func (k *Key) open(sealed, fileID []byte, index uint64, final bool) ([]byte, error) {
+	if len(sealed) < k.aead.NonceSize() {
+		return nil, fmt.Errorf("truncated encrypted file")
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, recordAAD(fileID, index, final))
+	if err != nil {
+		return nil, fmt.Errorf("wrong key or corrupted file: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newHeader() ([]byte, error) {
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	if _, err := rand.Read(header[len(magic):]); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// Encrypt returns the content of the encrypted file of the data.
+func (k *Key) Encrypt(data []byte) ([]byte, error) {
+	header, err := newHeader()
+	if err != nil {
+		return nil, err
+	}
+	record, err := k.record(data, header[len(magic):], 0, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, record...), nil
+}
+
+// Decrypt returns the data of the content of an encrypted file.
+func (k *Key) Decrypt(content []byte) ([]byte, error) {
+	if !IsEncrypted(content) {
+		return nil, fmt.Errorf("not an encrypted file")
+	}
+	if len(content) < headerSize {
+		return nil, fmt.Errorf("truncated encrypted file")
+	}
+	fileID := content[len(magic):headerSize]
+	var data []byte
+	rest := content[headerSize:]
+	// NOTE: every file has a record at least, for the file to be told from one all the records of which were dropped.
+	for index := uint64(0); index == 0 || len(rest) > 0; index++ {
+		if len(rest) < 4 {
+			return nil, fmt.Errorf("truncated encrypted file")
+		}
+		n := int(binary.BigEndian.Uint32(rest))
+		rest = rest[4:]
+		if n > len(rest) {
+			return nil, fmt.Errorf("truncated encrypted file")
+		}
+		plaintext, err := k.open(rest[:n], fileID, index, n == len(rest))
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, plaintext...)
+		rest = rest[n:]
+	}
+	return data, nil
+}
+
+// ReadFile reads the file, decrypting it with the active key if it's encrypted.
+func ReadFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil || !IsEncrypted(content) {
+		return content, err
+	}
+	key := Active()
+	if key == nil {
+		return nil, ErrLocked
+	}
+	return key.Decrypt(content)
+}
+
+// WriteFile writes the file, encrypted with the active key if any.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	appendMu.Lock()
+	defer appendMu.Unlock()
+	return writeFile(path, data, perm)
+}
+
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	delete(lastRecords, path)
+	if key := Active(); key != nil {
+		var err error
+		if data, err = key.Encrypt(data); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// AppendFile appends the data to the file, as a record encrypted with the active key if any.
+// A plaintext file is encrypted before the record is appended to it.
+func AppendFile(path string, data []byte, perm os.FileMode) error {
+	key := Active()
+	if key == nil {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write(data)
+		return err
+	}
+
+	appendMu.Lock()
+	defer appendMu.Unlock()
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if n == 0 {
+		content, err := key.Encrypt(data)
+		if err != nil {
+			return err
+		}
+		if _, err = f.Write(content); err != nil {
+			return err
+		}
+		lastRecords[path] = recordPosition{size: int64(len(content)), offset: int64(headerSize)}
+		return nil
+	}
+	if !IsEncrypted(header[:n]) {
+		// NOTE: the file was written before the data directory was encrypted.
+		f.Close()
+		plaintext, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return writeFile(path, append(plaintext, data...), perm)
+	}
+	if n < headerSize {
+		return fmt.Errorf("truncated encrypted file")
+	}
+	fileID := header[len(magic):]
+
+	// The final record is sealed again as a record which isn't, and the data is appended as the final one.
+	offset, index, last, err := lastRecord(f, lastRecords[path])
+	if err != nil {
+		return err
+	}
+	plaintext, err := key.open(last, fileID, index, true)
+	if err != nil {
+		return err
+	}
+	resealed, err := key.record(plaintext, fileID, index, false)
+	if err != nil {
+		return err
+	}
+	record, err := key.record(data, fileID, index+1, true)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(append(resealed, record...), offset); err != nil {
+		delete(lastRecords, path)
+		return err
+	}
+	next := offset + int64(len(resealed))
+	lastRecords[path] = recordPosition{size: next + int64(len(record)), offset: next, index: index + 1}
+	return nil
+}
+
+// lastRecord returns the offset, the index and the sealed content of the last record of the encrypted file,
+// reading the lengths of the records only unless the position cached is the one of the file.
+func lastRecord(f *os.File, cached recordPosition) (int64, uint64, []byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	var (
+		offset = int64(headerSize)
+		index  uint64
+		length = make([]byte, 4)
+	)
+	if cached.size == info.Size() && cached.offset != 0 {
+		offset, index = cached.offset, cached.index
+	}
+	for {
+		if _, err := f.ReadAt(length, offset); err != nil {
+			return 0, 0, nil, fmt.Errorf("truncated encrypted file")
+		}
+		n := int64(binary.BigEndian.Uint32(length))
+		next := offset + 4 + n
+		if next > info.Size() {
+			return 0, 0, nil, fmt.Errorf("truncated encrypted file")
+		}
+		if next == info.Size() {
+			sealed := make([]byte, n)
+			if _, err := f.ReadAt(sealed, offset+4); err != nil {
+				return 0, 0, nil, err
+			}
+			return offset, index, sealed, nil
+		}
+		offset = next
+		index++
+	}
+}
+
+// ReencryptFile encrypts the file with the key to, decrypting it with the key from first if it's encrypted.
+// The file is replaced atomically, and left as is if it's already encrypted with the key to.
+func ReencryptFile(path string, from, to *Key) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data := content
+	if IsEncrypted(content) {
+		if _, err := to.Decrypt(content); err == nil {
+			return nil
+		}
+		if from == nil {
+			return ErrLocked
+		}
+		if data, err = from.Decrypt(content); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+	}
+	encrypted, err := to.Encrypt(data)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encrypted, info.Mode().Perm()); err != nil {
+		return err
+	}
+	appendMu.Lock()
+	defer appendMu.Unlock()
+	delete(lastRecords, path)
+	return os.Rename(tmp, path)
+}
+
+// ReencryptDir encrypts the files of the directory and its subdirectories with ReencryptFile,
+// returning how many there are. A missing directory has none.
+func ReencryptDir(dir string, from, to *Key) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return filepath.SkipDir
+		}
+		if err != nil || !d.Type().IsRegular() || strings.HasSuffix(path, ".tmp") {
+			return err
+		}
+		if err := ReencryptFile(path, from, to); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
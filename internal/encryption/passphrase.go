@@ -0,0 +1,132 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PassphraseEnv is the environment variable the passphrase is taken from, before the OS keyring and the prompt.
+const PassphraseEnv = "TANDEM_DB_PASSPHRASE"
+
+const keyringService = "tandem"
+
+// Unlock unlocks the data directory with its passphrase, taken from PassphraseEnv, the OS keyring or a prompt,
+// in that order. The passphrase of the OS keyring is passed over if it doesn't unlock it, e.g. a stale one.
+func Unlock(dataDir string, params Params) (*Key, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return params.Unlock(passphrase)
+	}
+	if passphrase, err := KeyringGet(dataDir); err == nil && passphrase != "" {
+		key, err := params.Unlock(passphrase)
+		if !errors.Is(err, ErrWrongPassphrase) {
+			return key, err
+		}
+		fmt.Fprintln(os.Stderr, "The passphrase of the OS keyring doesn't unlock the database")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("the data directory is encrypted: set %s or store the passphrase in the OS keyring", PassphraseEnv)
+	}
+	passphrase, err := Prompt("Passphrase of the database: ")
+	if err != nil {
+		return nil, err
+	}
+	return params.Unlock(passphrase)
+}
+
+// Prompt reads a passphrase from the terminal, without echoing it.
+func Prompt(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// PromptNew reads a new passphrase from the terminal, twice for it to be confirmed.
+func PromptNew() (string, error) {
+	passphrase, err := Prompt("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	confirmation, err := Prompt("Confirm the new passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirmation {
+		return "", fmt.Errorf("the passphrases don't match")
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("the passphrase is empty")
+	}
+	return passphrase, nil
+}
+
+// keyringAccount is the account the passphrase of the data directory is stored under, its absolute path.
+func keyringAccount(dataDir string) string {
+	if abs, err := filepath.Abs(dataDir); err == nil {
+		return abs
+	}
+	return dataDir
+}
+
+// KeyringGet returns the passphrase of the data directory stored in the OS keyring, through secret-tool
+// on Linux and security on macOS.
+func KeyringGet(dataDir string) (string, error) {
+	account := keyringAccount(dataDir)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", account)
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	default:
+		return "", fmt.Errorf("the OS keyring isn't supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the OS keyring: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// KeyringSet stores the passphrase of the data directory in the OS keyring, replacing the former one if any.
+// NOTE: the passphrase is written to the standard input of the commands, never given on their command line.
+func KeyringSet(dataDir, passphrase string) error {
+	account := keyringAccount(dataDir)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "store", "--label", "tandem database "+account, "service", keyringService, "account", account)
+		cmd.Stdin = strings.NewReader(passphrase)
+	case "darwin":
+		// security takes the password as an argument only, thus the command is run by its interactive mode,
+		// which reads it from its input. The password is given hex-encoded, for it not to be quoted.
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+			keyringService, quoteSecurityArg(account), hex.EncodeToString([]byte(passphrase))))
+	default:
+		return fmt.Errorf("the OS keyring isn't supported on %s", runtime.GOOS)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store the passphrase in the OS keyring: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// quoteSecurityArg quotes the argument of a command of the interactive mode of security, the way a shell does.
+func quoteSecurityArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,281 @@
+// Package engagement manages the engagements, the workspaces of the clients, each one having its own RoE,
+// swarm.json overrides, data directory, database and sandbox, under ~/.tandem/engagements.
+package engagement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Env is the environment variable naming the engagement to open, taking precedence over the current one.
+const Env = "TANDEM_ENGAGEMENT"
+
+const (
+	metadataFile = "engagement.json"
+	currentFile  = "engagement"
+	// ConfigFile holds the swarm.json overrides of the engagement.
+	ConfigFile = "swarm.json"
+	// RoEFile holds the rules of engagement.
+	RoEFile = "RoE.md"
+)
+
+var (
+	ErrNotFound = errors.New("engagement not found")
+	ErrArchived = errors.New("engagement is archived")
+
+	// NOTE: the name of the engagement names its sandbox container as well, hence the constraints of docker.
+	namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// Engagement is the workspace of a client.
+type Engagement struct {
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Archived   bool      `json:"archived,omitempty"`
+	ArchivedAt time.Time `json:"archivedAt,omitzero"`
+}
+
+// Home returns the directory of tandem in the home directory, holding the engagements.
+func Home() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".tandem"), nil
+}
+
+func root() (string, error) {
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "engagements"), nil
+}
+
+// Dir returns the directory of the engagement.
+func Dir(name string) (string, error) {
+	root, err := root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, name), nil
+}
+
+// Dir returns the directory of the engagement.
+func (e Engagement) Dir() string {
+	dir, _ := Dir(e.Name)
+	return dir
+}
+
+// DataDir returns the data directory of the engagement, holding its database and session log files.
+func (e Engagement) DataDir() string {
+	return filepath.Join(e.Dir(), "data")
+}
+
+// Workspace returns the directory of the engagement shared with its sandbox, where its artifacts are downloaded.
+func (e Engagement) Workspace() string {
+	return filepath.Join(e.Dir(), "workspace")
+}
+
+// ConfigPath returns the swarm.json overrides of the engagement.
+func (e Engagement) ConfigPath() string {
+	return filepath.Join(e.Dir(), ConfigFile)
+}
+
+// RoEPath returns the rules of engagement of the engagement.
+func (e Engagement) RoEPath() string {
+	return filepath.Join(e.Dir(), RoEFile)
+}
+
+// Sandbox returns the name of the sandbox container of the engagement.
+func (e Engagement) Sandbox() string {
+	return "tandem-" + e.Name
+}
+
+// ValidateName rejects the names which can't name a directory and a docker container.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid engagement name %q: letters, digits, '_', '.' and '-' only, starting with a letter or a digit", name)
+	}
+	return nil
+}
+
+// New creates the engagement, with an empty swarm.json, the RoE read from roe if any, empty otherwise,
+// and its data directory and workspace.
+func New(name string, roe []byte) (Engagement, error) {
+	if err := ValidateName(name); err != nil {
+		return Engagement{}, err
+	}
+	dir, err := Dir(name)
+	if err != nil {
+		return Engagement{}, err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return Engagement{}, fmt.Errorf("engagement %s already exists", name)
+	}
+	e := Engagement{Name: name, CreatedAt: time.Now().UTC()}
+	for _, d := range []string{e.DataDir(), e.Workspace()} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			return Engagement{}, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(e.ConfigPath(), []byte("{}\n"), 0o600); err != nil {
+		return Engagement{}, fmt.Errorf("failed to write %s: %w", ConfigFile, err)
+	}
+	if err := os.WriteFile(e.RoEPath(), roe, 0o600); err != nil {
+		return Engagement{}, fmt.Errorf("failed to write %s: %w", RoEFile, err)
+	}
+	if err := e.save(); err != nil {
+		return Engagement{}, err
+	}
+	return e, nil
+}
+
+// Get returns the engagement, failing with ErrNotFound if there is none by the name.
+func Get(name string) (Engagement, error) {
+	dir, err := Dir(name)
+	if err != nil {
+		return Engagement{}, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, metadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return Engagement{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return Engagement{}, err
+	}
+	var e Engagement
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Engagement{}, fmt.Errorf("invalid %s of engagement %s: %w", metadataFile, name, err)
+	}
+	e.Name = name
+	return e, nil
+}
+
+// List returns the engagements, archived ones included, by name.
+func List() ([]Engagement, error) {
+	root, err := root()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	engagements := make([]Engagement, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		e, err := Get(entry.Name())
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		engagements = append(engagements, e)
+	}
+	slices.SortFunc(engagements, func(a, b Engagement) int { return strings.Compare(a.Name, b.Name) })
+	return engagements, nil
+}
+
+// Current returns the name of the engagement to open, Env if set, the one switched to otherwise,
+// empty when there is none and the configuration of the working directory is used.
+func Current() (string, error) {
+	if name := os.Getenv(Env); name != "" {
+		if err := ValidateName(name); err != nil {
+			return "", fmt.Errorf("%s: %w", Env, err)
+		}
+		return name, nil
+	}
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(home, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return "", nil
+	}
+	if err := ValidateName(name); err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Join(home, currentFile), err)
+	}
+	return name, nil
+}
+
+// Switch makes the engagement the current one, the configuration of the working directory being used
+// again when name is empty.
+func Switch(name string) error {
+	home, err := Home()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(home, currentFile)
+	if name == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	e, err := Get(name)
+	if err != nil {
+		return err
+	}
+	if e.Archived {
+		return fmt.Errorf("%w: %s", ErrArchived, name)
+	}
+	if err := os.MkdirAll(home, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0o600)
+}
+
+// Archive marks the engagement as archived, for it not to be switched to anymore. Its data is kept.
+// The current engagement is switched away from.
+func Archive(name string) (Engagement, error) {
+	e, err := Get(name)
+	if err != nil {
+		return Engagement{}, err
+	}
+	if e.Archived {
+		return e, nil
+	}
+	e.Archived, e.ArchivedAt = true, time.Now().UTC()
+	if err := e.save(); err != nil {
+		return Engagement{}, err
+	}
+	if current, err := Current(); err == nil && current == name {
+		if err := Switch(""); err != nil {
+			return Engagement{}, err
+		}
+	}
+	return e, nil
+}
+
+func (e Engagement) save() error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(e.Dir(), metadataFile), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", metadataFile, err)
+	}
+	return nil
+}
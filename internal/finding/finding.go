@@ -0,0 +1,176 @@
+package finding
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/pubsub"
+)
+
+type Severity string
+
+const (
+	// SeverityUnknown is the severity of the findings of the scanners which don't rate them, e.g. nikto.
+	SeverityUnknown  Severity = "unknown"
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Severities are ordered from the least to the most severe, the unknown one first as it's yet to be rated.
+var Severities = []Severity{SeverityUnknown, SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// ParseSeverity normalizes the severity reported by a scanner, the unrecognized ones being taken for info.
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "unknown":
+		return SeverityUnknown
+	case "low":
+		return SeverityLow
+	case "medium", "moderate":
+		return SeverityMedium
+	case "high":
+		return SeverityHigh
+	case "critical":
+		return SeverityCritical
+	default:
+		return SeverityInfo
+	}
+}
+
+// AtLeast reports whether the severity is as severe as the other one, or more.
+func (s Severity) AtLeast(other Severity) bool {
+	return slices.Index(Severities, s) >= slices.Index(Severities, other)
+}
+
+// Finding is an issue reported by a scanner, normalized into a format common to every scanner.
+type Finding struct {
+	ID          string
+	SessionID   string
+	HostID      string
+	Scanner     string
+	TemplateID  string
+	Name        string
+	Severity    Severity
+	Target      string
+	Description string
+	Evidence    string
+	References  []string
+	// Occurrences is how many times the finding was reported, by as many scans.
+	Occurrences int64
+	CreatedAt   int64
+	UpdatedAt   int64
+}
+
+type RecordFindingParams struct {
+	SessionID   string
+	HostID      string
+	Scanner     string
+	TemplateID  string
+	Name        string
+	Severity    Severity
+	Target      string
+	Description string
+	Evidence    string
+	References  []string
+}
+
+type Service interface {
+	pubsub.Subscriber[Finding]
+	// Record creates the finding, or updates it if the scanner already reported the template matching the target.
+	Record(ctx context.Context, params RecordFindingParams) (Finding, error)
+	Get(ctx context.Context, id string) (Finding, error)
+	// List returns every finding of the engagement, the most severe first.
+	List(ctx context.Context) ([]Finding, error)
+}
+
+type service struct {
+	*pubsub.Broker[Finding]
+	q db.Querier
+}
+
+func (s *service) Record(ctx context.Context, params RecordFindingParams) (Finding, error) {
+	fingerprint := sha256.Sum256([]byte(strings.Join([]string{params.Scanner, params.TemplateID, params.Target}, "\x00")))
+	dbFinding, err := s.q.UpsertFinding(ctx, db.UpsertFindingParams{
+		ID:          uuid.New().String(),
+		SessionID:   sql.NullString{String: params.SessionID, Valid: params.SessionID != ""},
+		HostID:      sql.NullString{String: params.HostID, Valid: params.HostID != ""},
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+		Scanner:     params.Scanner,
+		TemplateID:  params.TemplateID,
+		Name:        params.Name,
+		Severity:    string(params.Severity),
+		Target:      params.Target,
+		Description: params.Description,
+		Evidence:    params.Evidence,
+		Refs:        strings.Join(params.References, "\n"),
+	})
+	if err != nil {
+		return Finding{}, err
+	}
+	finding := s.fromDBItem(dbFinding)
+	if finding.Occurrences == 1 {
+		s.Publish(pubsub.CreatedEvent, finding)
+	} else {
+		s.Publish(pubsub.UpdatedEvent, finding)
+	}
+	return finding, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (Finding, error) {
+	dbFinding, err := s.q.GetFinding(ctx, id)
+	if err != nil {
+		return Finding{}, err
+	}
+	return s.fromDBItem(dbFinding), nil
+}
+
+func (s *service) List(ctx context.Context) ([]Finding, error) {
+	dbFindings, err := s.q.ListFindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]Finding, len(dbFindings))
+	for i, dbFinding := range dbFindings {
+		findings[i] = s.fromDBItem(dbFinding)
+	}
+	return findings, nil
+}
+
+func (s *service) fromDBItem(item db.Finding) Finding {
+	var references []string
+	if item.Refs != "" {
+		references = strings.Split(item.Refs, "\n")
+	}
+	return Finding{
+		ID:          item.ID,
+		SessionID:   item.SessionID.String,
+		HostID:      item.HostID.String,
+		Scanner:     item.Scanner,
+		TemplateID:  item.TemplateID,
+		Name:        item.Name,
+		Severity:    Severity(item.Severity),
+		Target:      item.Target,
+		Description: item.Description,
+		Evidence:    item.Evidence,
+		References:  references,
+		Occurrences: item.Occurrences,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Finding](),
+		q:      q,
+	}
+}
@@ -0,0 +1,341 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/pubsub"
+)
+
+// Host is a host of the engagement, along with its ports when listed.
+type Host struct {
+	ID        string
+	SessionID string
+	Address   string
+	Hostnames []string
+	OS        string
+	Status    string
+	Ports     []Port
+	Endpoints []Endpoint
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type Port struct {
+	ID        string
+	HostID    string
+	SessionID string
+	Protocol  string
+	Port      int64
+	State     string
+	Service   string
+	Product   string
+	Version   string
+	ExtraInfo string
+	// Scripts are the outputs of the scripts, e.g. nmap's NSE, by script ID.
+	Scripts   map[string]string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Endpoint is a web content of a host, e.g. a directory or a file discovered by fuzzing.
+type Endpoint struct {
+	ID               string
+	HostID           string
+	SessionID        string
+	URL              string
+	Status           int64
+	Size             int64
+	Words            int64
+	Lines            int64
+	ContentType      string
+	RedirectLocation string
+	CreatedAt        int64
+	UpdatedAt        int64
+}
+
+// Scan is the raw output of a scan the inventory was built from.
+type Scan struct {
+	ID        string
+	SessionID string
+	Tool      string
+	Command   string
+	Output    string
+	CreatedAt int64
+}
+
+// UpsertHostParams records a host, keeping what's known of it unless given anew.
+type UpsertHostParams struct {
+	SessionID string
+	Address   string
+	Hostnames []string
+	OS        string
+	Status    string
+}
+
+// UpsertPortParams records a port of a host, keeping what's known of it unless given anew.
+type UpsertPortParams struct {
+	SessionID string
+	Protocol  string
+	Port      int64
+	State     string
+	Service   string
+	Product   string
+	Version   string
+	ExtraInfo string
+	Scripts   map[string]string
+}
+
+// UpsertEndpointParams records a web content of a host, replacing what's known of it.
+type UpsertEndpointParams struct {
+	SessionID        string
+	URL              string
+	Status           int64
+	Size             int64
+	Words            int64
+	Lines            int64
+	ContentType      string
+	RedirectLocation string
+}
+
+type CreateScanParams struct {
+	SessionID string
+	Tool      string
+	Command   string
+	Output    string
+}
+
+type Service interface {
+	pubsub.Subscriber[Host]
+	UpsertHost(ctx context.Context, params UpsertHostParams) (Host, error)
+	UpsertPort(ctx context.Context, hostID string, params UpsertPortParams) (Port, error)
+	UpsertEndpoint(ctx context.Context, hostID string, params UpsertEndpointParams) (Endpoint, error)
+	GetHost(ctx context.Context, address string) (Host, error)
+	ListHosts(ctx context.Context) ([]Host, error)
+	CreateScan(ctx context.Context, params CreateScanParams) (Scan, error)
+	ListScans(ctx context.Context, sessionID string) ([]Scan, error)
+}
+
+type service struct {
+	*pubsub.Broker[Host]
+	q db.Querier
+}
+
+func (s *service) UpsertHost(ctx context.Context, params UpsertHostParams) (Host, error) {
+	dbHost, err := s.q.UpsertHost(ctx, db.UpsertHostParams{
+		ID:        uuid.New().String(),
+		SessionID: sql.NullString{String: params.SessionID, Valid: params.SessionID != ""},
+		Address:   params.Address,
+		Hostnames: strings.Join(params.Hostnames, ","),
+		Os:        params.OS,
+		Status:    params.Status,
+	})
+	if err != nil {
+		return Host{}, err
+	}
+	host := s.fromDBHost(dbHost)
+	s.Publish(pubsub.UpdatedEvent, host)
+	return host, nil
+}
+
+func (s *service) UpsertPort(ctx context.Context, hostID string, params UpsertPortParams) (Port, error) {
+	scripts, err := json.Marshal(params.Scripts)
+	if err != nil {
+		return Port{}, err
+	}
+	if params.Scripts == nil {
+		scripts = []byte("{}")
+	}
+	dbPort, err := s.q.UpsertPort(ctx, db.UpsertPortParams{
+		ID:        uuid.New().String(),
+		HostID:    hostID,
+		SessionID: sql.NullString{String: params.SessionID, Valid: params.SessionID != ""},
+		Protocol:  params.Protocol,
+		Port:      params.Port,
+		State:     params.State,
+		Service:   params.Service,
+		Product:   params.Product,
+		Version:   params.Version,
+		ExtraInfo: params.ExtraInfo,
+		Scripts:   string(scripts),
+	})
+	if err != nil {
+		return Port{}, err
+	}
+	return s.fromDBPort(dbPort), nil
+}
+
+func (s *service) UpsertEndpoint(ctx context.Context, hostID string, params UpsertEndpointParams) (Endpoint, error) {
+	dbEndpoint, err := s.q.UpsertEndpoint(ctx, db.UpsertEndpointParams{
+		ID:               uuid.New().String(),
+		HostID:           hostID,
+		SessionID:        sql.NullString{String: params.SessionID, Valid: params.SessionID != ""},
+		Url:              params.URL,
+		Status:           params.Status,
+		Size:             params.Size,
+		Words:            params.Words,
+		Lines:            params.Lines,
+		ContentType:      params.ContentType,
+		RedirectLocation: params.RedirectLocation,
+	})
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return s.fromDBEndpoint(dbEndpoint), nil
+}
+
+func (s *service) GetHost(ctx context.Context, address string) (Host, error) {
+	dbHost, err := s.q.GetHostByAddress(ctx, address)
+	if err != nil {
+		return Host{}, err
+	}
+	host := s.fromDBHost(dbHost)
+	dbPorts, err := s.q.ListPortsByHost(ctx, host.ID)
+	if err != nil {
+		return Host{}, err
+	}
+	for _, dbPort := range dbPorts {
+		host.Ports = append(host.Ports, s.fromDBPort(dbPort))
+	}
+	dbEndpoints, err := s.q.ListEndpointsByHost(ctx, host.ID)
+	if err != nil {
+		return Host{}, err
+	}
+	for _, dbEndpoint := range dbEndpoints {
+		host.Endpoints = append(host.Endpoints, s.fromDBEndpoint(dbEndpoint))
+	}
+	return host, nil
+}
+
+func (s *service) ListHosts(ctx context.Context) ([]Host, error) {
+	dbHosts, err := s.q.ListHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbPorts, err := s.q.ListPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ports := make(map[string][]Port)
+	for _, dbPort := range dbPorts {
+		ports[dbPort.HostID] = append(ports[dbPort.HostID], s.fromDBPort(dbPort))
+	}
+	dbEndpoints, err := s.q.ListEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make(map[string][]Endpoint)
+	for _, dbEndpoint := range dbEndpoints {
+		endpoints[dbEndpoint.HostID] = append(endpoints[dbEndpoint.HostID], s.fromDBEndpoint(dbEndpoint))
+	}
+	hosts := make([]Host, len(dbHosts))
+	for i, dbHost := range dbHosts {
+		hosts[i] = s.fromDBHost(dbHost)
+		hosts[i].Ports = ports[dbHost.ID]
+		hosts[i].Endpoints = endpoints[dbHost.ID]
+	}
+	return hosts, nil
+}
+
+func (s *service) CreateScan(ctx context.Context, params CreateScanParams) (Scan, error) {
+	dbScan, err := s.q.CreateScan(ctx, db.CreateScanParams{
+		ID:        uuid.New().String(),
+		SessionID: params.SessionID,
+		Tool:      params.Tool,
+		Command:   params.Command,
+		Output:    params.Output,
+	})
+	if err != nil {
+		return Scan{}, err
+	}
+	return s.fromDBScan(dbScan), nil
+}
+
+func (s *service) ListScans(ctx context.Context, sessionID string) ([]Scan, error) {
+	dbScans, err := s.q.ListScansBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	scans := make([]Scan, len(dbScans))
+	for i, dbScan := range dbScans {
+		scans[i] = s.fromDBScan(dbScan)
+	}
+	return scans, nil
+}
+
+func (s *service) fromDBHost(item db.Host) Host {
+	var hostnames []string
+	if item.Hostnames != "" {
+		hostnames = strings.Split(item.Hostnames, ",")
+	}
+	return Host{
+		ID:        item.ID,
+		SessionID: item.SessionID.String,
+		Address:   item.Address,
+		Hostnames: hostnames,
+		OS:        item.Os,
+		Status:    item.Status,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func (s *service) fromDBPort(item db.Port) Port {
+	var scripts map[string]string
+	// NOTE: the scripts are written by UpsertPort only, thus always valid.
+	_ = json.Unmarshal([]byte(item.Scripts), &scripts)
+	return Port{
+		ID:        item.ID,
+		HostID:    item.HostID,
+		SessionID: item.SessionID.String,
+		Protocol:  item.Protocol,
+		Port:      item.Port,
+		State:     item.State,
+		Service:   item.Service,
+		Product:   item.Product,
+		Version:   item.Version,
+		ExtraInfo: item.ExtraInfo,
+		Scripts:   scripts,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func (s *service) fromDBEndpoint(item db.Endpoint) Endpoint {
+	return Endpoint{
+		ID:               item.ID,
+		HostID:           item.HostID,
+		SessionID:        item.SessionID.String,
+		URL:              item.Url,
+		Status:           item.Status,
+		Size:             item.Size,
+		Words:            item.Words,
+		Lines:            item.Lines,
+		ContentType:      item.ContentType,
+		RedirectLocation: item.RedirectLocation,
+		CreatedAt:        item.CreatedAt,
+		UpdatedAt:        item.UpdatedAt,
+	}
+}
+
+func (s *service) fromDBScan(item db.Scan) Scan {
+	return Scan{
+		ID:        item.ID,
+		SessionID: item.SessionID,
+		Tool:      item.Tool,
+		Command:   item.Command,
+		Output:    item.Output,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Host](),
+		q:      q,
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"runtime/debug"
 	"sync"
 	"time"
+
+	"github.com/yaydraco/tandem/internal/encryption"
+	"github.com/yaydraco/tandem/internal/redact"
 )
 
 func Error(msg string, args ...any) {
@@ -117,20 +120,48 @@ func AppendToSessionLogFile(sessionId string, filename string, content string) s
 
 	filePath := fmt.Sprintf("%s/%s", sessionPath, filename)
 
-	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// Append chunk to file, encrypted if the data directory is
+	err := encryption.AppendFile(filePath, []byte(redact.String(content)), 0644)
 	if err != nil {
-		Error("Failed to open session log file", "filepath", filePath, "error", err)
+		Error("Failed to write chunk to session log file", "filepath", filePath, "error", err)
 		return ""
 	}
-	defer f.Close()
+	return filePath
+}
+
+// RedactSessionLogFiles rewrites the log files of the session with the secrets of the vault redacted,
+// the ones stored after the files were written included.
+func RedactSessionLogFiles(sessionId string) error {
+	if MessageDir == "" || sessionId == "" {
+		return nil
+	}
+	sessionLogMutex.Lock()
+	defer sessionLogMutex.Unlock()
 
-	// Append chunk to file
-	_, err = f.WriteString(content)
+	sessionPath := fmt.Sprintf("%s/%s", MessageDir, GetSessionPrefix(sessionId))
+	entries, err := os.ReadDir(sessionPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
-		Error("Failed to write chunk to session log file", "filepath", filePath, "error", err)
-		return ""
+		return err
 	}
-	return filePath
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		filePath := fmt.Sprintf("%s/%s", sessionPath, entry.Name())
+		content, err := encryption.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if redacted := redact.String(string(content)); redacted != string(content) {
+			if err := encryption.WriteFile(filePath, []byte(redacted), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func GetSessionPrefix(sessionId string) string {
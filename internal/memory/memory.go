@@ -0,0 +1,200 @@
+// Package memory keeps the long-term memory of the engagement: the facts the agents learned, which they share with the
+// agents of the later tasks.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/redact"
+)
+
+type Memory struct {
+	ID        string
+	SessionID string
+	// Agent is the name of the agent which wrote the memory.
+	Agent string
+	// Subject is what the memory is about, e.g. a host, a service or a topic.
+	Subject   string
+	Content   string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type RememberParams struct {
+	SessionID string
+	Agent     string
+	Subject   string
+	Content   string
+}
+
+type Service interface {
+	pubsub.Subscriber[Memory]
+	// Remember stores the memory, unless the same one is stored already.
+	Remember(ctx context.Context, params RememberParams) (Memory, error)
+	Forget(ctx context.Context, id string) error
+	List(ctx context.Context) ([]Memory, error)
+	// Search returns the memories matching any of the words of the query, the best matches first.
+	Search(ctx context.Context, query string, limit int) ([]Memory, error)
+	// Relevant returns up to limit memories for the agent of a task to know, the ones matching the task first and
+	// then the latest ones.
+	Relevant(ctx context.Context, task string, limit int) ([]Memory, error)
+}
+
+type service struct {
+	*pubsub.Broker[Memory]
+	q db.Querier
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Memory](),
+		q:      q,
+	}
+}
+
+func (s *service) Remember(ctx context.Context, params RememberParams) (Memory, error) {
+	content := strings.TrimSpace(redact.String(params.Content))
+	if content == "" {
+		return Memory{}, fmt.Errorf("the memory is empty")
+	}
+	item, err := s.q.UpsertMemory(ctx, db.UpsertMemoryParams{
+		ID:        uuid.New().String(),
+		SessionID: sql.NullString{String: params.SessionID, Valid: params.SessionID != ""},
+		Agent:     params.Agent,
+		Subject:   strings.TrimSpace(redact.String(params.Subject)),
+		Content:   content,
+	})
+	if err != nil {
+		return Memory{}, err
+	}
+	memory := s.fromDBItem(item)
+	s.Publish(pubsub.CreatedEvent, memory)
+	return memory, nil
+}
+
+func (s *service) Forget(ctx context.Context, id string) error {
+	item, err := s.q.GetMemory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.q.DeleteMemory(ctx, id); err != nil {
+		return err
+	}
+	s.Publish(pubsub.DeletedEvent, s.fromDBItem(item))
+	return nil
+}
+
+func (s *service) List(ctx context.Context) ([]Memory, error) {
+	items, err := s.q.ListMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.fromDBItems(items), nil
+}
+
+func (s *service) Search(ctx context.Context, query string, limit int) ([]Memory, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+	items, err := s.q.SearchMemories(ctx, db.SearchMemoriesParams{
+		Query:      match,
+		MaxResults: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.fromDBItems(items), nil
+}
+
+func (s *service) Relevant(ctx context.Context, task string, limit int) ([]Memory, error) {
+	all, err := s.List(ctx)
+	if err != nil || len(all) <= limit {
+		return all, err
+	}
+	relevant, err := s.Search(ctx, task, limit)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: the room left goes to the latest memories, the last ones listed.
+	seen := make(map[string]bool, len(relevant))
+	for _, m := range relevant {
+		seen[m.ID] = true
+	}
+	for i := len(all) - 1; i >= 0 && len(relevant) < limit; i-- {
+		if !seen[all[i].ID] {
+			relevant = append(relevant, all[i])
+		}
+	}
+	return relevant, nil
+}
+
+// Prompt returns the memories as the part of a system prompt telling the agent what the swarm learned so far, or
+// nothing without memories.
+func Prompt(memories []Memory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<memory>\nWhat the agents of the swarm learned earlier in the engagement. Build on it rather than rediscovering it, and mind that it may be outdated:\n")
+	for _, m := range memories {
+		sb.WriteString("- ")
+		sb.WriteString(Format(m))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</memory>")
+	return sb.String()
+}
+
+// Format returns the memory on a line, along with its subject and the agent which wrote it.
+func Format(m Memory) string {
+	var sb strings.Builder
+	if m.Subject != "" {
+		fmt.Fprintf(&sb, "[%s] ", m.Subject)
+	}
+	sb.WriteString(strings.Join(strings.Fields(m.Content), " "))
+	if m.Agent != "" {
+		fmt.Fprintf(&sb, " (%s)", m.Agent)
+	}
+	return sb.String()
+}
+
+func (s *service) fromDBItems(items []db.Memory) []Memory {
+	memories := make([]Memory, len(items))
+	for i, item := range items {
+		memories[i] = s.fromDBItem(item)
+	}
+	return memories
+}
+
+func (s *service) fromDBItem(item db.Memory) Memory {
+	return Memory{
+		ID:        item.ID,
+		SessionID: item.SessionID.String,
+		Agent:     item.Agent,
+		Subject:   item.Subject,
+		Content:   item.Content,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+// ftsQuery turns the words of the query into an FTS5 query matching the memories holding any of them, quoting them
+// for the punctuation of hostnames, paths and the like not to be taken for the syntax of FTS5.
+func ftsQuery(query string) string {
+	terms := strings.FieldsFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '*'
+	})
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"`
+	}
+	return strings.Join(quoted, " OR ")
+}
@@ -1,15 +1,19 @@
 package message
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/audit"
 	"github.com/yaydraco/tandem/internal/db"
 	"github.com/yaydraco/tandem/internal/models"
 	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/redact"
 )
 
 type Message struct {
@@ -28,13 +32,23 @@ type Service interface {
 	Update(ctx context.Context, message Message) error
 	Get(ctx context.Context, id string) (Message, error)
 	List(ctx context.Context, sessionID string) ([]Message, error)
+	// Copy stores a copy of the message into the session, along with its timestamps.
+	Copy(ctx context.Context, id, sessionID string) (Message, error)
 	Delete(ctx context.Context, id string) error
+	// DeleteFrom deletes the message of the session and the ones after it.
+	DeleteFrom(ctx context.Context, sessionID, id string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	// RedactSessionMessages redacts the secrets of the vault from the messages of the session persisted before they were stored.
+	RedactSessionMessages(ctx context.Context, sessionID string) error
+	// Search returns the messages of every session whose text or tool results hold all the words searched for,
+	// the best matches first.
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
 }
 
 type service struct {
 	*pubsub.Broker[Message]
-	q db.Querier
+	q     db.Querier
+	audit audit.Service
 }
 
 type CreateMessageParams struct {
@@ -49,7 +63,7 @@ func (s *service) Create(ctx context.Context, sessionID string, params CreateMes
 			Reason: BecauseStop,
 		})
 	}
-	partsJSON, err := marshallParts(params.Parts)
+	partsJSON, err := marshallParts(redactParts(params.Parts))
 	if err != nil {
 		return Message{}, err
 	}
@@ -67,6 +81,9 @@ func (s *service) Create(ctx context.Context, sessionID string, params CreateMes
 	if err != nil {
 		return Message{}, err
 	}
+	if err := s.record(ctx, message, dbMessage.Parts); err != nil {
+		return Message{}, err
+	}
 	s.Publish(pubsub.CreatedEvent, message)
 	return message, nil
 }
@@ -107,6 +124,30 @@ func marshallParts(parts []ContentPart) ([]byte, error) {
 	return json.Marshal(wrappedParts)
 }
 
+// redactParts redacts the secrets of the vault from the parts, before they're persisted and published.
+func redactParts(parts []ContentPart) []ContentPart {
+	redacted := make([]ContentPart, len(parts))
+	for i, part := range parts {
+		switch p := part.(type) {
+		case ReasoningContent:
+			p.Thinking = redact.String(p.Thinking)
+			part = p
+		case TextContent:
+			p.Text = redact.String(p.Text)
+			part = p
+		case ToolCall:
+			p.Input = redact.String(p.Input)
+			part = p
+		case ToolResult:
+			p.Content = redact.String(p.Content)
+			p.Metadata = redact.String(p.Metadata)
+			part = p
+		}
+		redacted[i] = part
+	}
+	return redacted
+}
+
 // NOTE: messages are stringified first and then stored in the db.
 func unmarshallParts(data []byte) ([]ContentPart, error) {
 	temp := []json.RawMessage{}
@@ -168,6 +209,7 @@ func unmarshallParts(data []byte) ([]ContentPart, error) {
 }
 
 func (s *service) Update(ctx context.Context, message Message) error {
+	message.Parts = redactParts(message.Parts)
 	parts, err := marshallParts(message.Parts)
 	if err != nil {
 		return err
@@ -185,10 +227,35 @@ func (s *service) Update(ctx context.Context, message Message) error {
 	if err != nil {
 		return err
 	}
+	if err := s.record(ctx, message, string(parts)); err != nil {
+		return err
+	}
 	s.Publish(pubsub.UpdatedEvent, message)
 	return nil
 }
 
+// record records the message in the audit log as it's stored, once it's finished for the messages of the agents,
+// which are updated as they're streamed until then.
+func (s *service) record(ctx context.Context, message Message, parts string) error {
+	var kind audit.Kind
+	switch {
+	case message.Role == User:
+		kind = audit.KindPrompt
+	case message.Role == Tool:
+		kind = audit.KindToolResult
+	case !message.IsFinished():
+		return nil
+	case len(message.ToolCalls()) > 0:
+		kind = audit.KindToolCall
+	default:
+		kind = audit.KindResponse
+	}
+	if err := s.audit.RecordMessage(ctx, kind, message.SessionID, message.ID, parts); err != nil {
+		return fmt.Errorf("failed to record the message in the audit log: %w", err)
+	}
+	return nil
+}
+
 func (m *Message) FinishPart() *Finish {
 	for _, part := range m.Parts {
 		if c, ok := part.(Finish); ok {
@@ -230,6 +297,36 @@ func (s *service) List(ctx context.Context, sessionID string) ([]Message, error)
 	return messages, nil
 }
 
+func (s *service) Copy(ctx context.Context, id, sessionID string) (Message, error) {
+	item, err := s.q.GetMessage(ctx, id)
+	if err != nil {
+		return Message{}, err
+	}
+	copyID := uuid.New().String()
+	err = s.q.ImportMessage(ctx, db.ImportMessageParams{
+		ID:         copyID,
+		SessionID:  sessionID,
+		Role:       item.Role,
+		Parts:      item.Parts,
+		Model:      item.Model,
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+		FinishedAt: item.FinishedAt,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	message, err := s.Get(ctx, copyID)
+	if err != nil {
+		return Message{}, err
+	}
+	if err := s.record(ctx, message, item.Parts); err != nil {
+		return Message{}, err
+	}
+	s.Publish(pubsub.CreatedEvent, message)
+	return message, nil
+}
+
 func (s *service) Delete(ctx context.Context, id string) error {
 	message, err := s.Get(ctx, id)
 	if err != nil {
@@ -239,10 +336,31 @@ func (s *service) Delete(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
+	if err := s.audit.Record(ctx, audit.KindDeletion, message.SessionID, message.ID, ""); err != nil {
+		return fmt.Errorf("failed to record the deletion in the audit log: %w", err)
+	}
 	s.Publish(pubsub.DeletedEvent, message)
 	return nil
 }
 
+func (s *service) DeleteFrom(ctx context.Context, sessionID, id string) error {
+	messages, err := s.List(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	from := slices.IndexFunc(messages, func(m Message) bool { return m.ID == id })
+	if from == -1 {
+		return fmt.Errorf("message %s not found in the session", id)
+	}
+	// NOTE: the latest first, for the session never to be left with a gap should the deletion fail midway.
+	for i := len(messages) - 1; i >= from; i-- {
+		if err := s.Delete(ctx, messages[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *service) DeleteSessionMessages(ctx context.Context, sessionID string) error {
 	messages, err := s.List(ctx, sessionID)
 	if err != nil {
@@ -259,6 +377,30 @@ func (s *service) DeleteSessionMessages(ctx context.Context, sessionID string) e
 	return nil
 }
 
+func (s *service) RedactSessionMessages(ctx context.Context, sessionID string) error {
+	messages, err := s.List(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		parts, err := marshallParts(message.Parts)
+		if err != nil {
+			return err
+		}
+		redacted, err := marshallParts(redactParts(message.Parts))
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(parts, redacted) {
+			continue
+		}
+		if err := s.Update(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *service) fromDBItem(item db.Message) (Message, error) {
 	parts, err := unmarshallParts([]byte(item.Parts))
 	if err != nil {
@@ -274,10 +416,11 @@ func (s *service) fromDBItem(item db.Message) (Message, error) {
 	}, nil
 }
 
-func NewService(q db.Querier) Service {
+func NewService(q db.Querier, auditLog audit.Service) Service {
 	return &service{
 		Broker: pubsub.NewBroker[Message](),
 		q:      q,
+		audit:  auditLog,
 	}
 }
 
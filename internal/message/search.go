@@ -0,0 +1,102 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yaydraco/tandem/internal/db"
+)
+
+// The markers of the terms matched within the snippets of the search results.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// SearchResult is a message matching a search, along with the session it's in and the root session of the latter,
+// the same one unless the message is of the task session of a subagent.
+type SearchResult struct {
+	MessageID        string
+	SessionID        string
+	SessionTitle     string
+	RootSessionID    string
+	RootSessionTitle string
+	Role             MessageRole
+	CreatedAt        int64
+	// Snippet is the part of the text matching the search, its terms enclosed in HighlightStart and HighlightEnd.
+	Snippet string
+}
+
+// PlainSnippet returns the snippet without the markers of the terms matched.
+func (r SearchResult) PlainSnippet() string {
+	return strings.NewReplacer(HighlightStart, "", HighlightEnd, "").Replace(r.Snippet)
+}
+
+// HighlightSnippet returns the snippet, the terms matched wrapped with the highlight function.
+func (r SearchResult) HighlightSnippet(highlight func(string) string) string {
+	var sb strings.Builder
+	rest := r.Snippet
+	for {
+		before, after, ok := strings.Cut(rest, HighlightStart)
+		sb.WriteString(before)
+		if !ok {
+			return sb.String()
+		}
+		term, after, _ := strings.Cut(after, HighlightEnd)
+		sb.WriteString(highlight(term))
+		rest = after
+	}
+}
+
+func (s *service) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, fmt.Errorf("nothing to search for")
+	}
+	rows, err := s.q.SearchMessages(ctx, db.SearchMessagesParams{
+		Query:      match,
+		MaxResults: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = SearchResult{
+			MessageID:        row.MessageID,
+			SessionID:        row.SessionID,
+			SessionTitle:     row.SessionTitle,
+			RootSessionID:    row.RootSessionID,
+			RootSessionTitle: row.RootSessionTitle,
+			Role:             MessageRole(row.Role),
+			CreatedAt:        row.CreatedAt,
+			Snippet:          strings.Join(strings.Fields(row.Snippet), " "),
+		}
+	}
+	return results, nil
+}
+
+// ftsQuery turns the words searched for into an FTS5 query matching the messages holding all of them, quoting them
+// for the punctuation of hostnames, paths and the like not to be taken for the syntax of FTS5. A word ending with *
+// matches the words it prefixes.
+func ftsQuery(query string) string {
+	terms := strings.FieldsFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"'
+	})
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+		term = `"` + term + `"`
+		if prefix {
+			term += "*"
+		}
+		quoted = append(quoted, term)
+	}
+	return strings.Join(quoted, " ")
+}
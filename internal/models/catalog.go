@@ -0,0 +1,200 @@
+package models
+
+//go:generate go run ./gen snapshot -out catalog.json
+//go:generate go run ./gen schema -out ../../swarm.schema.json
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// CatalogURL serves the catalog of models maintained by https://models.dev
+	CatalogURL = "https://models.dev/api.json"
+	// CatalogFile is the models.dev snapshot written to the data directory by `tandem models sync`.
+	CatalogFile = "models.dev.json"
+	// OverridesFile lists the models, e.g. private ones, that take precedence over the catalog in the data directory.
+	OverridesFile = "models.json"
+)
+
+// snapshot of the catalog at build time, refreshed by go generate.
+//
+//go:embed catalog.json
+var snapshot []byte
+
+// Catalog is the models.dev catalog, keyed by the models.dev provider ID.
+type Catalog map[string]CatalogProvider
+
+type CatalogProvider struct {
+	ID     string                  `json:"id"`
+	Name   string                  `json:"name"`
+	Models map[string]CatalogModel `json:"models"`
+}
+
+type CatalogModel struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Attachment bool   `json:"attachment"`
+	Reasoning  bool   `json:"reasoning"`
+	ToolCall   bool   `json:"tool_call"`
+	Cost       struct {
+		Input      float64 `json:"input"`
+		Output     float64 `json:"output"`
+		CacheRead  float64 `json:"cache_read"`
+		CacheWrite float64 `json:"cache_write"`
+	} `json:"cost"`
+	Limit struct {
+		Context int64 `json:"context"`
+		Output  int64 `json:"output"`
+	} `json:"limit"`
+}
+
+// catalogProviders maps the models.dev providers to the supported ones, along with the prefix of their model IDs
+// and the models known by another ID than the prefixed models.dev one, so that the configurations keep working.
+var catalogProviders = map[string]struct {
+	provider ModelProvider
+	prefix   string
+	aliases  map[ModelID]string
+}{
+	"anthropic":      {ProviderAnthropic, "", anthropicAliases},
+	"openai":         {ProviderOpenAI, "", nil},
+	"google":         {ProviderGemini, "", nil},
+	"groq":           {ProviderGROQ, "", groqAliases},
+	"xai":            {ProviderXAI, "", nil},
+	"openrouter":     {ProviderOpenRouter, "openrouter.", openRouterAliases},
+	"google-vertex":  {ProviderVertexAI, "vertexai.", vertexAIAliases},
+	"github-copilot": {ProviderCopilot, "copilot.", copilotAliases},
+}
+
+// FetchCatalog downloads the current models.dev catalog.
+func FetchCatalog(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, CatalogURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", CatalogURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", CatalogURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseCatalog(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ParseCatalog converts a models.dev catalog into the models of the supported providers.
+// Only the models able to call tools are kept since the agents can't do without.
+func ParseCatalog(data []byte) (map[ModelID]Model, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse the catalog: %w", err)
+	}
+	parsed := make(map[ModelID]Model)
+	for providerID, catalogProvider := range catalog {
+		supported, ok := catalogProviders[providerID]
+		if !ok {
+			continue
+		}
+		aliases := make(map[string]ModelID, len(supported.aliases))
+		for id, catalogID := range supported.aliases {
+			aliases[catalogID] = id
+		}
+		for _, catalogModel := range catalogProvider.Models {
+			if !catalogModel.ToolCall {
+				continue
+			}
+			id, ok := aliases[catalogModel.ID]
+			if !ok {
+				id = ModelID(supported.prefix + catalogModel.ID)
+			}
+			maxTokens := catalogModel.Limit.Output
+			if catalogModel.Limit.Context > 0 && maxTokens > catalogModel.Limit.Context/2 {
+				maxTokens = catalogModel.Limit.Context / 2
+			}
+			parsed[id] = Model{
+				ID:                  id,
+				Name:                catalogModel.Name,
+				Provider:            supported.provider,
+				APIModel:            catalogModel.ID,
+				CostPer1MIn:         catalogModel.Cost.Input,
+				CostPer1MOut:        catalogModel.Cost.Output,
+				CostPer1MInCached:   catalogModel.Cost.CacheWrite,
+				CostPer1MOutCached:  catalogModel.Cost.CacheRead,
+				ContextWindow:       catalogModel.Limit.Context,
+				DefaultMaxTokens:    maxTokens,
+				CanReason:           catalogModel.Reasoning,
+				SupportsAttachments: catalogModel.Attachment,
+			}
+		}
+	}
+	return parsed, nil
+}
+
+// LoadCatalog merges the catalog synced into the data directory and then the local overrides into SupportedModels,
+// built from the snapshot. The models gone from the synced catalog are kept, as the configurations may still use them.
+func LoadCatalog(dataDir string) error {
+	data, err := os.ReadFile(filepath.Join(dataDir, CatalogFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		synced, err := ParseCatalog(data)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", CatalogFile, err)
+		}
+		maps.Copy(SupportedModels, synced)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dataDir, OverridesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var overrides []Model
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("invalid %s: %w", OverridesFile, err)
+	}
+	for _, model := range overrides {
+		if model.ID == "" || model.Provider == "" {
+			return fmt.Errorf("invalid %s: the id and provider of every model are required", OverridesFile)
+		}
+		if model.APIModel == "" {
+			model.APIModel = string(model.ID)
+		}
+		SupportedModels[model.ID] = model
+	}
+	return nil
+}
+
+// SortedModelIDs returns the IDs of the supported models grouped by provider, as listed in the JSON schema.
+func SortedModelIDs() []ModelID {
+	ids := slices.Collect(maps.Keys(SupportedModels))
+	slices.SortFunc(ids, func(a, b ModelID) int {
+		pa, pb := SupportedModels[a].Provider, SupportedModels[b].Provider
+		if pa != pb {
+			return strings.Compare(string(pa), string(pb))
+		}
+		return strings.Compare(string(a), string(b))
+	})
+	return ids
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testCatalog = `{
+  "anthropic": {"id": "anthropic", "name": "Anthropic", "models": {
+    "claude-sonnet-4-20250514": {"id": "claude-sonnet-4-20250514", "name": "Claude Sonnet 4", "attachment": true, "reasoning": true, "tool_call": true,
+      "cost": {"input": 3, "output": 15, "cache_read": 0.3, "cache_write": 3.75}, "limit": {"context": 200000, "output": 64000}},
+    "claude-instant": {"id": "claude-instant", "name": "Claude Instant", "tool_call": false,
+      "cost": {"input": 1, "output": 2}, "limit": {"context": 100000, "output": 4096}}
+  }},
+  "openrouter": {"id": "openrouter", "name": "OpenRouter", "models": {
+    "mistralai/devstral": {"id": "mistralai/devstral", "name": "Devstral", "tool_call": true,
+      "cost": {"input": 0.1, "output": 0.3}, "limit": {"context": 128000, "output": 128000}}
+  }},
+  "unknown": {"id": "unknown", "name": "Unknown", "models": {
+    "model": {"id": "model", "name": "Model", "tool_call": true, "limit": {"context": 1000, "output": 100}}
+  }}
+}`
+
+func TestParseCatalog(t *testing.T) {
+	parsed, err := ParseCatalog([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("ParseCatalog() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       ModelID
+		expected Model
+	}{
+		{
+			name: "model known by another ID",
+			id:   Claude4Sonnet,
+			expected: Model{
+				ID:                  Claude4Sonnet,
+				Name:                "Claude Sonnet 4",
+				Provider:            ProviderAnthropic,
+				APIModel:            "claude-sonnet-4-20250514",
+				CostPer1MIn:         3,
+				CostPer1MOut:        15,
+				CostPer1MInCached:   3.75,
+				CostPer1MOutCached:  0.3,
+				ContextWindow:       200000,
+				DefaultMaxTokens:    64000,
+				CanReason:           true,
+				SupportsAttachments: true,
+			},
+		},
+		{
+			name: "prefixed model, max tokens capped to half the context",
+			id:   "openrouter.mistralai/devstral",
+			expected: Model{
+				ID:               "openrouter.mistralai/devstral",
+				Name:             "Devstral",
+				Provider:         ProviderOpenRouter,
+				APIModel:         "mistralai/devstral",
+				CostPer1MIn:      0.1,
+				CostPer1MOut:     0.3,
+				ContextWindow:    128000,
+				DefaultMaxTokens: 64000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if model := parsed[tt.id]; model != tt.expected {
+				t.Errorf("ParseCatalog()[%s] = %+v, expected %+v", tt.id, model, tt.expected)
+			}
+		})
+	}
+	if len(parsed) != len(tests) {
+		t.Errorf("ParseCatalog() = %v, expected the models without tool calls and of unknown providers to be dropped", slices.Collect(maps.Keys(parsed)))
+	}
+
+	if _, err := ParseCatalog([]byte("[]")); err == nil {
+		t.Errorf("ParseCatalog() succeeded with an invalid catalog")
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	snapshotModels := maps.Clone(SupportedModels)
+	t.Cleanup(func() { SupportedModels = snapshotModels })
+
+	tests := []struct {
+		name      string
+		synced    string
+		overrides string
+		// check checks the models merged.
+		check     func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "snapshot only",
+			check: func(t *testing.T) {
+				if !maps.Equal(SupportedModels, snapshotModels) {
+					t.Errorf("LoadCatalog() changed the models of the snapshot")
+				}
+			},
+		},
+		{
+			name:   "synced catalog",
+			synced: testCatalog,
+			check: func(t *testing.T) {
+				if model := SupportedModels[Claude4Sonnet]; model.DefaultMaxTokens != 64000 || model.Name != "Claude Sonnet 4" {
+					t.Errorf("the synced catalog didn't update %s: %+v", Claude4Sonnet, model)
+				}
+				if _, ok := SupportedModels["openrouter.mistralai/devstral"]; !ok {
+					t.Errorf("the model new in the synced catalog wasn't added")
+				}
+				if _, ok := SupportedModels[GPT41]; !ok {
+					t.Errorf("the model gone from the synced catalog was dropped")
+				}
+			},
+		},
+		{
+			name:      "overrides over the synced catalog",
+			synced:    testCatalog,
+			overrides: `[{"id": "claude-4-sonnet", "provider": "anthropic", "name": "Private Sonnet", "cost_per_1m_in": 1}, {"id": "local-model", "provider": "openai"}]`,
+			check: func(t *testing.T) {
+				if model := SupportedModels[Claude4Sonnet]; model.Name != "Private Sonnet" || model.CostPer1MIn != 1 || model.APIModel != "claude-4-sonnet" {
+					t.Errorf("the override didn't take precedence over the synced catalog: %+v", model)
+				}
+				if model := SupportedModels["local-model"]; model.APIModel != "local-model" || model.Provider != ProviderOpenAI {
+					t.Errorf("the private model wasn't added with its ID as API model: %+v", model)
+				}
+			},
+		},
+		{
+			name:      "override without provider",
+			overrides: `[{"id": "local-model"}]`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid synced catalog",
+			synced:    "[]",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SupportedModels = maps.Clone(snapshotModels)
+			dataDir := t.TempDir()
+			for file, content := range map[string]string{CatalogFile: tt.synced, OverridesFile: tt.overrides} {
+				if content == "" {
+					continue
+				}
+				if err := os.WriteFile(filepath.Join(dataDir, file), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := LoadCatalog(dataDir)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("LoadCatalog() succeeded, expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCatalog() failed: %v", err)
+			}
+			tt.check(t)
+		})
+	}
+}
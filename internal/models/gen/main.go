@@ -0,0 +1,91 @@
+// Command gen refreshes the models.dev snapshot embedded into the models package
+// and the model IDs enumerated by the JSON schema of the swarm configuration.
+//
+//	go run ./gen snapshot -out catalog.json
+//	go run ./gen snapshot -in api.json -out catalog.json
+//	go run ./gen schema -out ../../swarm.schema.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/models"
+)
+
+// modelEnum matches the enum of the model property of the agent definition.
+var modelEnum = regexp.MustCompile(`(?s)("description": "The AI model to use for this agent",\s*"enum": \[)(.*?)(\n(\s*)\])`)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: gen snapshot|schema -out <file>")
+		os.Exit(2)
+	}
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	out := flags.String("out", "", "file to write")
+	in := flags.String("in", "", "models.dev catalog to read, e.g. a downloaded api.json, instead of fetching it")
+	flags.Parse(os.Args[2:])
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "-out is required")
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "snapshot":
+		err = snapshot(*in, *out)
+	case "schema":
+		err = schema(*out)
+	default:
+		err = fmt.Errorf("unknown command %q", os.Args[1])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func snapshot(in, out string) error {
+	if in != "" {
+		data, err := os.ReadFile(in)
+		if err != nil {
+			return err
+		}
+		if _, err := models.ParseCatalog(data); err != nil {
+			return fmt.Errorf("invalid %s: %w", in, err)
+		}
+		return os.WriteFile(out, data, 0o644)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	data, err := models.FetchCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
+
+func schema(out string) error {
+	data, err := os.ReadFile(out)
+	if err != nil {
+		return err
+	}
+	match := modelEnum.FindSubmatch(data)
+	if match == nil {
+		return fmt.Errorf("model enum not found in %s", out)
+	}
+	indent := string(match[4]) + "  "
+	ids := models.SortedModelIDs()
+	items := make([]string, len(ids))
+	for i, id := range ids {
+		items[i] = fmt.Sprintf("\n%s%q", indent, id)
+	}
+	enum := string(match[1]) + strings.Join(items, ",") + string(match[3])
+	data = modelEnum.ReplaceAll(data, []byte(strings.ReplaceAll(enum, "$", "$$")))
+	return os.WriteFile(out, data, 0o644)
+}
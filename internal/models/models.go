@@ -1,6 +1,8 @@
 package models
 
-import "maps"
+import (
+	"fmt"
+)
 
 type (
 	ModelID       string
@@ -36,15 +38,14 @@ var ProviderPopularity = map[ModelProvider]int{
 	ProviderOpenRouter: 6,
 	ProviderVertexAI:   7,
 }
-var SupportedModels = make(map[ModelID]Model, 7)
+
+// SupportedModels are the models of the models.dev snapshot, along with the ones synced and overridden by LoadCatalog.
+var SupportedModels map[ModelID]Model
 
 func init() {
-	maps.Copy(SupportedModels, AnthropicModels)
-	maps.Copy(SupportedModels, OpenAIModels)
-	maps.Copy(SupportedModels, GeminiModels)
-	maps.Copy(SupportedModels, GroqModels)
-	maps.Copy(SupportedModels, OpenRouterModels)
-	maps.Copy(SupportedModels, XAIModels)
-	maps.Copy(SupportedModels, VertexAIGeminiModels)
-	maps.Copy(SupportedModels, CopilotModels)
+	var err error
+	SupportedModels, err = ParseCatalog(snapshot)
+	if err != nil {
+		panic(fmt.Sprintf("invalid models.dev snapshot: %v", err))
+	}
 }
@@ -8,31 +8,8 @@ const (
 	VertexAIGemini25      ModelID = "vertexai.gemini-2.5"
 )
 
-var VertexAIGeminiModels = map[ModelID]Model{
-	VertexAIGemini25Flash: {
-		ID:                  VertexAIGemini25Flash,
-		Name:                "VertexAI: Gemini 2.5 Flash",
-		Provider:            ProviderVertexAI,
-		APIModel:            "gemini-2.5-flash-preview-04-17",
-		CostPer1MIn:         GeminiModels[Gemini25Flash].CostPer1MIn,
-		CostPer1MInCached:   GeminiModels[Gemini25Flash].CostPer1MInCached,
-		CostPer1MOut:        GeminiModels[Gemini25Flash].CostPer1MOut,
-		CostPer1MOutCached:  GeminiModels[Gemini25Flash].CostPer1MOutCached,
-		ContextWindow:       GeminiModels[Gemini25Flash].ContextWindow,
-		DefaultMaxTokens:    GeminiModels[Gemini25Flash].DefaultMaxTokens,
-		SupportsAttachments: true,
-	},
-	VertexAIGemini25: {
-		ID:                  VertexAIGemini25,
-		Name:                "VertexAI: Gemini 2.5 Pro",
-		Provider:            ProviderVertexAI,
-		APIModel:            "gemini-2.5-pro-preview-03-25",
-		CostPer1MIn:         GeminiModels[Gemini25Pro].CostPer1MIn,
-		CostPer1MInCached:   GeminiModels[Gemini25Pro].CostPer1MInCached,
-		CostPer1MOut:        GeminiModels[Gemini25Pro].CostPer1MOut,
-		CostPer1MOutCached:  GeminiModels[Gemini25Pro].CostPer1MOutCached,
-		ContextWindow:       GeminiModels[Gemini25Pro].ContextWindow,
-		DefaultMaxTokens:    GeminiModels[Gemini25Pro].DefaultMaxTokens,
-		SupportsAttachments: true,
-	},
+// vertexAIAliases are the VertexAI models known by another ID than their models.dev one.
+var vertexAIAliases = map[ModelID]string{
+	VertexAIGemini25:      "gemini-2.5-pro-preview-03-25",
+	VertexAIGemini25Flash: "gemini-2.5-flash-preview-04-17",
 }
@@ -217,7 +217,7 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, a.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -376,7 +376,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 					}
 					close(eventChan)
 					return
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, a.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -402,7 +402,7 @@ func (a *anthropicClient) shouldRetry(attempts int, err error) (bool, int64, err
 	}
 
 	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+		return false, 0, fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 	}
 
 	retryMs := 0
@@ -416,6 +416,7 @@ func (a *anthropicClient) shouldRetry(attempts int, err error) (bool, int64, err
 			retryMs = retryMs * 1000
 		}
 	}
+	limiterFor(a.providerOptions.model.Provider).pause(time.Duration(retryMs) * time.Millisecond)
 	return true, int64(retryMs), nil
 }
 
@@ -346,7 +346,7 @@ func (c *copilotClient) send(ctx context.Context, messages []message.Message, to
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, c.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -524,6 +524,7 @@ func (c *copilotClient) stream(ctx context.Context, messages []message.Message,
 			if attempts > maxRetries {
 				logging.Warn("Maximum retry attempts reached for rate limit", "attempts", attempts, "max_retries", maxRetries)
 				retry = false
+				retryErr = fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 			}
 			if retry {
 				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d (paused for %d ms)", attempts, maxRetries, after), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
@@ -535,7 +536,7 @@ func (c *copilotClient) stream(ctx context.Context, messages []message.Message,
 					}
 					close(eventChan)
 					return
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, c.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -601,7 +602,7 @@ func (c *copilotClient) shouldRetry(attempts int, err error) (bool, int64, error
 	}
 
 	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+		return false, 0, fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 	}
 
 	retryMs := 0
@@ -615,6 +616,7 @@ func (c *copilotClient) shouldRetry(attempts int, err error) (bool, int64, error
 			retryMs = retryMs * 1000
 		}
 	}
+	limiterFor(c.providerOptions.model.Provider).pause(time.Duration(retryMs) * time.Millisecond)
 	return true, int64(retryMs), nil
 }
 
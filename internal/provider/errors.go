@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+)
+
+// ErrMaxRetries is returned once a provider keeps failing after all the retry attempts.
+var ErrMaxRetries = errors.New("maximum retry attempts reached")
+
+// IsContextLengthError reports whether the provider rejected the request for exceeding the context window of the model.
+// NOTE: the rate limits are counted in tokens too, e.g. "too many tokens per minute", hence the rejections with a 429 left out.
+func IsContextLengthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if code, ok := statusCode(err); ok && code == 429 {
+		return false
+	}
+	return contains(err.Error(),
+		"context_length_exceeded",
+		"maximum context length",
+		"exceeds the context window",
+		"prompt is too long",
+		"input token count",
+	)
+}
+
+// IsPersistentError reports whether retrying with the same provider is pointless, i.e. the request should rather fail over to another model.
+func IsPersistentError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrMaxRetries) || IsContextLengthError(err) {
+		return true
+	}
+
+	// NOTE: the provider is unreachable.
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	code, ok := statusCode(err)
+	if !ok {
+		return false
+	}
+	switch {
+	case code >= 500,
+		code == 429,
+		code == 401,
+		code == 403,
+		code == 404:
+		return true
+	}
+	return false
+}
+
+// statusCode returns the HTTP status code of the error of a provider, if it is one.
+func statusCode(err error) (int, bool) {
+	var anthropicErr *anthropic.Error
+	var openaiErr *openai.Error
+	var geminiErr genai.APIError
+	switch {
+	case errors.As(err, &anthropicErr):
+		return anthropicErr.StatusCode, true
+	case errors.As(err, &openaiErr):
+		return openaiErr.StatusCode, true
+	case errors.As(err, &geminiErr):
+		return geminiErr.Code, true
+	}
+	return 0, false
+}
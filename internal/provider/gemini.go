@@ -220,7 +220,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, g.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -320,7 +320,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 							}
 
 							return
-						case <-time.After(time.Duration(after) * time.Millisecond):
+						case <-retryAfter(ctx, g.providerOptions.model.Provider):
 							break
 						}
 					} else {
@@ -402,7 +402,7 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 func (g *geminiClient) shouldRetry(attempts int, err error) (bool, int64, error) {
 	// Check if error is a rate limit error
 	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+		return false, 0, fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 	}
 
 	// Gemini doesn't have a standard error type we can check against
@@ -428,6 +428,7 @@ func (g *geminiClient) shouldRetry(attempts int, err error) (bool, int64, error)
 	jitterMs := int(float64(backoffMs) * 0.2)
 	retryMs := backoffMs + jitterMs
 
+	limiterFor(g.providerOptions.model.Provider).pause(time.Duration(retryMs) * time.Millisecond)
 	return true, int64(retryMs), nil
 }
 
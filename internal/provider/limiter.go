@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/tools"
+)
+
+const rateLimitWindow = time.Minute
+
+// NOTE: the limiters are process wide so that every agent calling the same provider shares the same quota.
+var (
+	limiters   = make(map[models.ModelProvider]*limiter)
+	limitersMu sync.Mutex
+)
+
+// limiterFor returns the limiter of the provider, applying the currently configured rate limit.
+func limiterFor(providerName models.ModelProvider) *limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[providerName]
+	if !ok {
+		l = &limiter{queues: make(map[string][]*waiter)}
+		limiters[providerName] = l
+	}
+	var rateLimit config.RateLimit
+	if cfg := config.Get(); cfg != nil {
+		rateLimit = cfg.Providers[providerName].RateLimit
+	}
+	l.mu.Lock()
+	l.requestsPerMinute = rateLimit.RequestsPerMinute
+	l.tokensPerMinute = rateLimit.TokensPerMinute
+	l.mu.Unlock()
+	return l
+}
+
+type waiter struct {
+	key    string
+	tokens int64
+	ready  chan *grant
+}
+
+// grant is a request let through by the limiter. Its tokens are an estimate until settled.
+type grant struct {
+	at     time.Time
+	tokens int64
+}
+
+// limiter queues the requests to a provider within its requests and tokens per minute.
+// The queued requests are let through round-robin across the keys, i.e. the sessions, so that no agent starves the others.
+type limiter struct {
+	mu sync.Mutex
+
+	requestsPerMinute int
+	tokensPerMinute   int
+
+	// granted within the last window
+	granted []*grant
+	queues  map[string][]*waiter
+	order   []string
+	next    int
+
+	pausedUntil time.Time
+	timer       *time.Timer
+}
+
+// acquire blocks until the request is let through or the context is done.
+func (l *limiter) acquire(ctx context.Context, key string, tokens int64) (*grant, error) {
+	w := &waiter{key: key, tokens: tokens, ready: make(chan *grant, 1)}
+
+	l.mu.Lock()
+	if _, ok := l.queues[key]; !ok {
+		l.order = append(l.order, key)
+	}
+	l.queues[key] = append(l.queues[key], w)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case g := <-w.ready:
+		return g, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case g := <-w.ready:
+			// NOTE: let through meanwhile, thus giving its tokens back.
+			g.tokens = 0
+		default:
+			l.remove(w)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// settle replaces the estimated tokens of the request with the actual usage.
+func (l *limiter) settle(g *grant, tokens int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	g.tokens = tokens
+	l.dispatch()
+}
+
+// pause holds back every request to the provider for the given duration, e.g. as told by a Retry-After header.
+func (l *limiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.dispatch()
+}
+
+// retryAfter returns a channel closed once the limiter of the provider lets the retry of a request through, i.e. once
+// the pause set for the retry is over and within the rate limit, the retries being queued like any other request.
+// NOTE: the channel is never closed if the context is done first.
+func retryAfter(ctx context.Context, providerName models.ModelProvider) <-chan struct{} {
+	ready := make(chan struct{})
+	go func() {
+		// NOTE: the tokens of the request are already accounted for by the grant of its first attempt.
+		if _, err := limiterFor(providerName).acquire(ctx, limiterKey(ctx), 0); err == nil {
+			close(ready)
+		}
+	}()
+	return ready
+}
+
+// dispatch lets through the queued requests for which there's room. It must be called with the lock held.
+func (l *limiter) dispatch() {
+	for len(l.order) > 0 {
+		now := time.Now()
+		if now.Before(l.pausedUntil) {
+			l.wakeAt(l.pausedUntil)
+			return
+		}
+
+		l.prune(now)
+		if l.next >= len(l.order) {
+			l.next = 0
+		}
+		key := l.order[l.next]
+		w := l.queues[key][0]
+		if wait := l.wait(now, w.tokens); wait > 0 {
+			l.wakeAt(now.Add(wait))
+			return
+		}
+
+		g := &grant{at: now, tokens: w.tokens}
+		l.granted = append(l.granted, g)
+		w.ready <- g
+		l.remove(w)
+		if l.next < len(l.order) && l.order[l.next] == key {
+			l.next++
+		}
+	}
+}
+
+// wait returns how long a request of the given tokens has to wait for room within the window.
+func (l *limiter) wait(now time.Time, tokens int64) time.Duration {
+	if len(l.granted) == 0 {
+		// NOTE: letting through a request larger than the whole quota, otherwise it would never be.
+		return 0
+	}
+	var wait time.Duration
+	if l.requestsPerMinute > 0 && len(l.granted) >= l.requestsPerMinute {
+		wait = l.granted[len(l.granted)-l.requestsPerMinute].at.Add(rateLimitWindow).Sub(now)
+	}
+	if l.tokensPerMinute > 0 {
+		var used int64
+		for _, g := range l.granted {
+			used += g.tokens
+		}
+		for _, g := range l.granted {
+			if used+tokens <= int64(l.tokensPerMinute) {
+				break
+			}
+			used -= g.tokens
+			if gWait := g.at.Add(rateLimitWindow).Sub(now); gWait > wait {
+				wait = gWait
+			}
+		}
+	}
+	return wait
+}
+
+func (l *limiter) prune(now time.Time) {
+	i := 0
+	for i < len(l.granted) && now.Sub(l.granted[i].at) >= rateLimitWindow {
+		i++
+	}
+	l.granted = l.granted[i:]
+}
+
+func (l *limiter) remove(w *waiter) {
+	queue := l.queues[w.key]
+	for i, queued := range queue {
+		if queued == w {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) > 0 {
+		l.queues[w.key] = queue
+		return
+	}
+	delete(l.queues, w.key)
+	for i, key := range l.order {
+		if key == w.key {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			if i < l.next {
+				l.next--
+			}
+			break
+		}
+	}
+}
+
+func (l *limiter) wakeAt(at time.Time) {
+	d := time.Until(at)
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.timer = time.AfterFunc(d, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.dispatch()
+	})
+}
+
+// estimateTokens roughly estimates the prompt tokens of the messages, at about 4 characters per token.
+func estimateTokens(messages []message.Message) int64 {
+	var chars int
+	for _, msg := range messages {
+		chars += len(msg.Content().String())
+		for _, toolCall := range msg.ToolCalls() {
+			chars += len(toolCall.Input)
+		}
+		for _, toolResult := range msg.ToolResults() {
+			chars += len(toolResult.Content)
+		}
+	}
+	return int64(chars / 4)
+}
+
+func limiterKey(ctx context.Context) string {
+	sessionID, _ := tools.GetContextValues(ctx)
+	return sessionID
+}
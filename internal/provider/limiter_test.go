@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/tools"
+)
+
+func sessionContext(sessionID string) context.Context {
+	return context.WithValue(context.Background(), tools.SessionIDContextKey, sessionID)
+}
+
+func TestLimiterRetryAfterPause(t *testing.T) {
+	client := &openaiClient{providerOptions: providerClientOptions{model: models.Model{Provider: "test-paused"}}}
+	rateLimited := &openai.Error{StatusCode: http.StatusTooManyRequests, Response: &http.Response{Header: http.Header{"Retry-After": {"1"}}}}
+
+	pausedAt := time.Now()
+	retry, retryMs, err := client.shouldRetry(1, rateLimited)
+	if !retry || retryMs != 1000 || err != nil {
+		t.Fatalf("shouldRetry() = %v, %d, %v, expected a retry after 1000ms", retry, retryMs, err)
+	}
+
+	tests := []struct {
+		name         string
+		provider     models.ModelProvider
+		sessionID    string
+		expectPaused bool
+	}{
+		{name: "session rate limited", provider: "test-paused", sessionID: "s1", expectPaused: true},
+		{name: "other session of the provider", provider: "test-paused", sessionID: "s2", expectPaused: true},
+		{name: "session of another provider", provider: "test-other", sessionID: "s3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			if _, err := limiterFor(tt.provider).acquire(sessionContext(tt.sessionID), tt.sessionID, 0); err != nil {
+				t.Fatalf("acquire() failed: %v", err)
+			}
+			// NOTE: the pause is absolute, thus measured from when it was set.
+			paused := time.Since(pausedAt) >= 900*time.Millisecond
+			if !tt.expectPaused {
+				paused = time.Since(start) >= 500*time.Millisecond
+			}
+			if paused != tt.expectPaused {
+				t.Errorf("acquire() let through after %v, expected paused %v", time.Since(start), tt.expectPaused)
+			}
+		})
+	}
+}
+
+func TestLimiterRoundRobin(t *testing.T) {
+	tests := []struct {
+		name string
+		// arrivals are the sessions of the requests, in the order they're queued. The requests are numbered from 1.
+		arrivals []string
+		expected []int64
+	}{
+		{name: "single session", arrivals: []string{"a", "a", "a"}, expected: []int64{1, 2, 3}},
+		{name: "sessions one after the other", arrivals: []string{"a", "a", "a", "b", "c", "b"}, expected: []int64{1, 4, 5, 2, 6, 3}},
+		{name: "sessions interleaved", arrivals: []string{"a", "b", "a", "b"}, expected: []int64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &limiter{queues: make(map[string][]*waiter)}
+			// NOTE: holding back the requests until they're all queued.
+			l.pause(time.Hour)
+
+			var wg sync.WaitGroup
+			for i, key := range tt.arrivals {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := l.acquire(sessionContext(key), key, int64(i+1)); err != nil {
+						t.Errorf("acquire() failed: %v", err)
+					}
+				}()
+				for queued(l) < i+1 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			l.mu.Lock()
+			l.timer.Stop()
+			l.pausedUntil = time.Time{}
+			l.dispatch()
+			l.mu.Unlock()
+			wg.Wait()
+
+			var order []int64
+			for _, g := range l.granted {
+				order = append(order, g.tokens)
+			}
+			if !slices.Equal(order, tt.expected) {
+				t.Errorf("requests let through in order %v, expected %v", order, tt.expected)
+			}
+		})
+	}
+}
+
+// queued returns the number of requests queued by the limiter.
+func queued(l *limiter) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var n int
+	for _, queue := range l.queues {
+		n += len(queue)
+	}
+	return n
+}
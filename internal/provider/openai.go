@@ -228,7 +228,7 @@ func (o *openaiClient) send(ctx context.Context, messages []message.Message, too
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, o.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -344,7 +344,7 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 					}
 					close(eventChan)
 					return
-				case <-time.After(time.Duration(after) * time.Millisecond):
+				case <-retryAfter(ctx, o.providerOptions.model.Provider):
 					continue
 				}
 			}
@@ -368,7 +368,7 @@ func (o *openaiClient) shouldRetry(attempts int, err error) (bool, int64, error)
 	}
 
 	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+		return false, 0, fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 	}
 
 	retryMs := 0
@@ -382,6 +382,7 @@ func (o *openaiClient) shouldRetry(attempts int, err error) (bool, int64, error)
 			retryMs = retryMs * 1000
 		}
 	}
+	limiterFor(o.providerOptions.model.Provider).pause(time.Duration(retryMs) * time.Millisecond)
 	return true, int64(retryMs), nil
 }
 
@@ -33,6 +33,10 @@ type TokenUsage struct {
 	CacheReadTokens     int64
 }
 
+func (u TokenUsage) total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
 type ProviderResponse struct {
 	Content      string
 	ToolCalls    []message.ToolCall
@@ -88,6 +92,14 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 	for _, o := range opts {
 		o(&clientOptions)
 	}
+	tokenizer, err := redactionTokenizer()
+	if err != nil {
+		return nil, err
+	}
+	if tokenizer != nil {
+		// NOTE: the system message holds the RoE, the sensitive values of which are redacted as well.
+		clientOptions.systemMessage = tokenizer.Tokenize(clientOptions.systemMessage, false) + redactionNote
+	}
 	switch providerName {
 	case models.ProviderCopilot:
 		return &baseProvider[CopilotClient]{
@@ -155,14 +167,32 @@ func (p *baseProvider[C]) cleanMessages(messages []message.Message) (cleaned []m
 		if len(msg.Parts) == 0 {
 			continue
 		}
+		// The message is a note for the operator, e.g. a model fallback
+		if msg.Role == message.System {
+			continue
+		}
 		cleaned = append(cleaned, msg)
 	}
 	return
 }
 
 func (p *baseProvider[C]) SendMessages(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
-	messages = p.cleanMessages(messages)
-	return p.client.send(ctx, messages, tools)
+	tokenizer, err := redactionTokenizer()
+	if err != nil {
+		return nil, err
+	}
+	messages = tokenizeMessages(tokenizer, p.cleanMessages(messages))
+	l := limiterFor(p.options.model.Provider)
+	g, err := l.acquire(ctx, limiterKey(ctx), estimateTokens(messages))
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.client.send(ctx, messages, tools)
+	if err == nil {
+		l.settle(g, response.Usage.total())
+		detokenizeResponse(tokenizer, response)
+	}
+	return response, err
 }
 
 func (p *baseProvider[C]) Model() models.Model {
@@ -170,8 +200,49 @@ func (p *baseProvider[C]) Model() models.Model {
 }
 
 func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool, options ...GenerateContentConfigOption) <-chan ProviderEvent {
-	messages = p.cleanMessages(messages)
-	return p.client.stream(ctx, messages, tools, options...)
+	eventChan := make(chan ProviderEvent)
+	go func() {
+		defer close(eventChan)
+		// NOTE: the consumer stops reading once the context is done, so the events are dropped from then on.
+		send := func(event ProviderEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		tokenizer, err := redactionTokenizer()
+		if err != nil {
+			send(ProviderEvent{Type: EventError, Error: err})
+			return
+		}
+		messages = tokenizeMessages(tokenizer, p.cleanMessages(messages))
+		detokenizer := newStreamDetokenizer(tokenizer)
+		l := limiterFor(p.options.model.Provider)
+		g, err := l.acquire(ctx, limiterKey(ctx), estimateTokens(messages))
+		if err != nil {
+			send(ProviderEvent{Type: EventError, Error: err})
+			return
+		}
+		events := p.client.stream(ctx, messages, tools, options...)
+		// NOTE: draining the events of the client so that it isn't blocked sending them either.
+		defer func() {
+			for range events {
+			}
+		}()
+		for event := range events {
+			if event.Type == EventComplete && event.Response != nil {
+				l.settle(g, event.Response.Usage.total())
+			}
+			for _, event := range detokenizer.detokenize(event) {
+				if !send(event) {
+					return
+				}
+			}
+		}
+	}()
+	return eventChan
 }
 
 func WithAPIKey(apiKey string) ProviderClientOption {
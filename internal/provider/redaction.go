@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"sync"
+
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/redact"
+)
+
+const redactionNote = "\n\nSensitive values of the client are replaced with placeholders such as {{redacted:email-1}}. use the placeholders as is wherever the values are needed, tool parameters included, they're replaced with the values before the tools run."
+
+// redactionTokenizer returns the tokenizer of the engagement, nil if nothing is to be redacted. It's shared by
+// every provider, for a value to be sent with the same placeholder whichever agent sends it.
+var redactionTokenizer = sync.OnceValues(func() (*redact.Tokenizer, error) {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Redaction.Enabled() {
+		return nil, nil
+	}
+	return cfg.Redaction.Tokenizer()
+})
+
+// tokenizeMessages returns copies of the messages with their sensitive values replaced with placeholders.
+func tokenizeMessages(t *redact.Tokenizer, messages []message.Message) []message.Message {
+	if t == nil {
+		return messages
+	}
+	tokenized := make([]message.Message, len(messages))
+	for i, msg := range messages {
+		parts := make([]message.ContentPart, len(msg.Parts))
+		for j, part := range msg.Parts {
+			switch p := part.(type) {
+			case message.ReasoningContent:
+				p.Thinking = t.Tokenize(p.Thinking, false)
+				part = p
+			case message.TextContent:
+				p.Text = t.Tokenize(p.Text, false)
+				part = p
+			case message.ToolCall:
+				p.Input = t.Tokenize(p.Input, true)
+				part = p
+			case message.ToolResult:
+				p.Content = t.Tokenize(p.Content, false)
+				part = p
+			}
+			parts[j] = part
+		}
+		msg.Parts = parts
+		tokenized[i] = msg
+	}
+	return tokenized
+}
+
+// detokenizeResponse restores the values of the placeholders of the response, the inputs of its tool calls included.
+func detokenizeResponse(t *redact.Tokenizer, response *ProviderResponse) {
+	if t == nil || response == nil {
+		return
+	}
+	response.Content = t.Detokenize(response.Content, false)
+	for i := range response.ToolCalls {
+		response.ToolCalls[i].Input = t.Detokenize(response.ToolCalls[i].Input, true)
+	}
+}
+
+// streamDetokenizer restores the values of the placeholders of the events of a stream.
+type streamDetokenizer struct {
+	tokenizer *redact.Tokenizer
+	content   *redact.Stream
+	thinking  *redact.Stream
+}
+
+func newStreamDetokenizer(t *redact.Tokenizer) *streamDetokenizer {
+	if t == nil {
+		return nil
+	}
+	return &streamDetokenizer{
+		tokenizer: t,
+		content:   t.Stream(),
+		thinking:  t.Stream(),
+	}
+}
+
+// detokenize returns the events to send in place of the event, the deltas held back being sent
+// ahead of the events ending them.
+func (d *streamDetokenizer) detokenize(event ProviderEvent) []ProviderEvent {
+	if d == nil {
+		return []ProviderEvent{event}
+	}
+	switch event.Type {
+	case EventContentDelta:
+		if event.Content = d.content.Detokenize(event.Content); event.Content == "" {
+			return nil
+		}
+		return []ProviderEvent{event}
+	case EventThinkingDelta:
+		if event.Thinking = d.thinking.Detokenize(event.Thinking); event.Thinking == "" {
+			return nil
+		}
+		return []ProviderEvent{event}
+	}
+
+	var events []ProviderEvent
+	if thinking := d.thinking.Flush(); thinking != "" {
+		events = append(events, ProviderEvent{Type: EventThinkingDelta, Thinking: thinking})
+	}
+	if content := d.content.Flush(); content != "" {
+		events = append(events, ProviderEvent{Type: EventContentDelta, Content: content})
+	}
+	if event.ToolCall != nil {
+		event.ToolCall.Input = d.tokenizer.Detokenize(event.ToolCall.Input, true)
+	}
+	detokenizeResponse(d.tokenizer, event.Response)
+	return append(events, event)
+}
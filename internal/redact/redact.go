@@ -0,0 +1,158 @@
+// Package redact replaces the secrets known to tandem with placeholders, before they're persisted or logged.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// minSecretLength is the length under which the secrets aren't redacted, too likely to be words of anything else,
+// e.g. a password such as root or admin, which would be replaced throughout the conversation.
+const minSecretLength = 8
+
+var placeholderPattern = regexp.MustCompile(`\{\{vault:([A-Za-z0-9_.\-]+)\}\}`)
+
+// Placeholder returns the placeholder the secret of the reference is redacted into, e.g. {{vault:ftp-admin}}.
+func Placeholder(ref string) string {
+	return "{{vault:" + ref + "}}"
+}
+
+// Redactable reports whether the secret is long enough to be redacted.
+func Redactable(secret string) bool {
+	return len(secret) >= minSecretLength
+}
+
+// Redactor replaces the secrets it holds with the placeholders of their references, and back.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  map[string]string
+	redactor *strings.Replacer
+}
+
+func New() *Redactor {
+	return &Redactor{secrets: make(map[string]string)}
+}
+
+// Add adds the secret of the reference, replacing the former secret of the reference if any.
+func (r *Redactor) Add(ref, secret string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets[ref] = secret
+	r.redactor = nil
+}
+
+// Redact replaces the secrets within the text with their placeholders.
+func (r *Redactor) Redact(text string) string {
+	r.mu.RLock()
+	redactor := r.redactor
+	r.mu.RUnlock()
+	if redactor == nil {
+		redactor = r.build()
+	}
+	return redactor.Replace(text)
+}
+
+func (r *Redactor) build() *strings.Replacer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.redactor != nil {
+		return r.redactor
+	}
+	refs := make([]string, 0, len(r.secrets))
+	for ref, secret := range r.secrets {
+		if Redactable(secret) {
+			refs = append(refs, ref)
+		}
+	}
+	// NOTE: the longest secrets go first, for a secret containing another one to be redacted as a whole.
+	slices.SortFunc(refs, func(a, b string) int {
+		return len(r.secrets[b]) - len(r.secrets[a])
+	})
+	pairs := make([]string, 0, 4*len(refs))
+	for _, ref := range refs {
+		secret := r.secrets[ref]
+		pairs = append(pairs, secret, Placeholder(ref))
+		// NOTE: the secrets are redacted from JSON documents too, such as the inputs of the tools, escaped as they are in there.
+		if escaped := escapeJSON(secret); escaped != secret {
+			pairs = append(pairs, escaped, Placeholder(ref))
+		}
+	}
+	r.redactor = strings.NewReplacer(pairs...)
+	return r.redactor
+}
+
+// Restore replaces the placeholders within the text with their secrets, failing on the ones of unknown references.
+// The secrets are escaped for a JSON string if escape is set, the text being a JSON document.
+func (r *Redactor) Restore(text string, escape bool) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var unknown []string
+	restored := placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		ref := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		secret, ok := r.secrets[ref]
+		if !ok {
+			unknown = append(unknown, ref)
+			return placeholder
+		}
+		if escape {
+			return escapeJSON(secret)
+		}
+		return secret
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown credential references: %s", strings.Join(unknown, ", "))
+	}
+	return restored, nil
+}
+
+func escapeJSON(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+func unescapeJSON(s string) string {
+	var unescaped string
+	if err := json.Unmarshal([]byte(`"`+s+`"`), &unescaped); err != nil {
+		return s
+	}
+	return unescaped
+}
+
+// Default is the redactor of the secrets of the engagement, filled in by the credential vault.
+var Default = New()
+
+// Add adds the secret of the reference to the default redactor.
+func Add(ref, secret string) {
+	Default.Add(ref, secret)
+}
+
+// String redacts the secrets of the default redactor from the text.
+func String(text string) string {
+	return Default.Redact(text)
+}
+
+// RestoreJSON replaces the placeholders of the default redactor within the JSON document with their secrets.
+func RestoreJSON(text string) (string, error) {
+	return Default.Restore(text, true)
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer redacting the secrets of the default redactor from what's written to w.
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
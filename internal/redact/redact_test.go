@@ -0,0 +1,83 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	r := New()
+	r.Add("ftp-admin", "s3cr3t-passw0rd")
+	r.Add("ftp-admin-long", "s3cr3t-passw0rd-and-more")
+	r.Add("quoted", `pa"ss\word`)
+	r.Add("short", "root")
+
+	tests := []struct {
+		name     string
+		text     string
+		escape   bool
+		expected string
+	}{
+		{
+			name:     "secret",
+			text:     "logged in with s3cr3t-passw0rd on the FTP server",
+			expected: "logged in with {{vault:ftp-admin}} on the FTP server",
+		},
+		{
+			name:     "secret containing another one",
+			text:     "s3cr3t-passw0rd-and-more",
+			expected: "{{vault:ftp-admin-long}}",
+		},
+		{
+			name:     "secret escaped in a JSON document",
+			text:     `{"password":"pa\"ss\\word"}`,
+			escape:   true,
+			expected: `{"password":"{{vault:quoted}}"}`,
+		},
+		{
+			name:     "secret too short to be redacted",
+			text:     "logged in as root",
+			expected: "logged in as root",
+		},
+		{
+			name:     "no secret",
+			text:     "nothing to redact",
+			expected: "nothing to redact",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := r.Redact(tt.text)
+			if redacted != tt.expected {
+				t.Fatalf("Redact(%q) = %q, expected %q", tt.text, redacted, tt.expected)
+			}
+			restored, err := r.Restore(redacted, tt.escape)
+			if err != nil {
+				t.Fatalf("Restore(%q) failed: %v", redacted, err)
+			}
+			if restored != tt.text {
+				t.Errorf("Restore(%q) = %q, expected %q", redacted, restored, tt.text)
+			}
+		})
+	}
+}
+
+func TestRedactorReplacedSecret(t *testing.T) {
+	r := New()
+	r.Add("ssh", "first-password")
+	r.Add("ssh", "second-password")
+
+	if redacted := r.Redact("first-password second-password"); redacted != "first-password {{vault:ssh}}" {
+		t.Errorf("Redact() = %q, expected the former secret of the reference to be kept", redacted)
+	}
+}
+
+func TestRedactorRestoreUnknownReference(t *testing.T) {
+	r := New()
+	r.Add("ftp-admin", "s3cr3t-passw0rd")
+
+	_, err := r.Restore("{{vault:ftp-admin}} {{vault:unknown}}", false)
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Restore() error = %v, expected an error naming the unknown reference", err)
+	}
+}
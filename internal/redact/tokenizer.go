@@ -0,0 +1,223 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	tokenPrefix = "{{redacted:"
+	// maxTokenLength bounds the placeholders held back by a Stream, waiting for the rest of them.
+	maxTokenLength = 64
+	deniedName     = "denied"
+)
+
+var (
+	tokenPattern        = regexp.MustCompile(`\{\{redacted:([a-z0-9_]+-[0-9]+)\}\}`)
+	partialTokenPattern = regexp.MustCompile(`^\{\{redacted:[a-z0-9_]*(-[0-9]*)?\}?$`)
+
+	// NamePattern is the pattern the names of the patterns of a Tokenizer match, they name their placeholders.
+	NamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Builtins are the patterns a Tokenizer is configured with by their names only.
+var Builtins = map[string]string{
+	"email":          `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	"ipv4":           `\b(?:(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\b`,
+	"private_ipv4":   `\b(?:10(?:\.(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])){3}|172\.(?:1[6-9]|2[0-9]|3[01])(?:\.(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])){2}|192\.168(?:\.(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])){2})\b`,
+	"aws_access_key": `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	"jwt":            `\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`,
+	"ntlm_hash":      `\b[0-9a-fA-F]{32}:[0-9a-fA-F]{32}\b`,
+	"private_key":    `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+}
+
+// Pattern is a pattern of the values a Tokenizer replaces with placeholders, the builtin one of its name if Regexp is empty.
+type Pattern struct {
+	Name   string
+	Regexp string
+}
+
+type tokenizerPattern struct {
+	name   string
+	regexp *regexp.Regexp
+}
+
+// Tokenizer replaces the sensitive values with placeholders, e.g. {{redacted:email-1}}, and back. A value is replaced
+// with the same placeholder for the lifetime of the Tokenizer, for them to be restored in what refers to them.
+type Tokenizer struct {
+	patterns []tokenizerPattern
+	// denied maps the values of the deny list, lowercased and escaped for a JSON string or not, to the values.
+	denied map[string]string
+	allow  map[string]bool
+
+	mu     sync.Mutex
+	tokens map[string]string
+	values map[string]string
+	counts map[string]int
+}
+
+// NewTokenizer returns a tokenizer replacing the values matching the patterns and the ones of the deny list,
+// matched case insensitively, but the ones of the allow list.
+func NewTokenizer(patterns []Pattern, deny, allow []string) (*Tokenizer, error) {
+	t := &Tokenizer{
+		denied: make(map[string]string),
+		allow:  make(map[string]bool),
+		tokens: make(map[string]string),
+		values: make(map[string]string),
+		counts: make(map[string]int),
+	}
+	var alternatives []string
+	for _, value := range deny {
+		if value == "" {
+			continue
+		}
+		for _, variant := range []string{value, escapeJSON(value)} {
+			if _, ok := t.denied[strings.ToLower(variant)]; !ok {
+				t.denied[strings.ToLower(variant)] = value
+				alternatives = append(alternatives, variant)
+			}
+		}
+	}
+	if len(alternatives) > 0 {
+		// NOTE: the longest values go first, for a value containing another one to be replaced as a whole.
+		slices.SortFunc(alternatives, func(a, b string) int { return len(b) - len(a) })
+		for i, alternative := range alternatives {
+			alternatives[i] = regexp.QuoteMeta(alternative)
+		}
+		t.patterns = append(t.patterns, tokenizerPattern{
+			name:   deniedName,
+			regexp: regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|")),
+		})
+	}
+	for _, p := range patterns {
+		if !NamePattern.MatchString(p.Name) {
+			return nil, fmt.Errorf("invalid redaction pattern name %q: lowercase letters, digits and underscores only", p.Name)
+		}
+		expr := p.Regexp
+		if expr == "" {
+			builtin, ok := Builtins[p.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown builtin redaction pattern: %s", p.Name)
+			}
+			expr = builtin
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", p.Name, err)
+		}
+		t.patterns = append(t.patterns, tokenizerPattern{name: p.Name, regexp: re})
+	}
+	for _, value := range allow {
+		t.allow[strings.ToLower(value)] = true
+	}
+	return t, nil
+}
+
+// Tokenize replaces the sensitive values within the text with their placeholders. The values are escaped for
+// a JSON string if escaped is set, the text being a JSON document.
+func (t *Tokenizer) Tokenize(text string, escaped bool) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range t.patterns {
+		text = replaceOutsideTokens(text, func(segment string) string {
+			return p.regexp.ReplaceAllStringFunc(segment, func(match string) string {
+				if p.name == deniedName {
+					return t.token(p.name, t.denied[strings.ToLower(match)])
+				}
+				value := match
+				if escaped {
+					value = unescapeJSON(match)
+				}
+				if t.allow[strings.ToLower(value)] {
+					return match
+				}
+				return t.token(p.name, value)
+			})
+		})
+	}
+	return text
+}
+
+// replaceOutsideTokens replaces the segments of the text between its placeholders, for the patterns
+// not to match the placeholders of the values matched by the previous ones.
+func replaceOutsideTokens(text string, replace func(string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range tokenPattern.FindAllStringIndex(text, -1) {
+		sb.WriteString(replace(text[last:loc[0]]))
+		sb.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(replace(text[last:]))
+	return sb.String()
+}
+
+func (t *Tokenizer) token(name, value string) string {
+	if token, ok := t.tokens[value]; ok {
+		return token
+	}
+	t.counts[name]++
+	token := fmt.Sprintf("%s%s-%d}}", tokenPrefix, name, t.counts[name])
+	t.tokens[value] = token
+	t.values[token] = value
+	return token
+}
+
+// Detokenize replaces the placeholders within the text with their values, escaped for a JSON string if escape is set,
+// the text being a JSON document. The placeholders the Tokenizer didn't replace any value with are left as is.
+func (t *Tokenizer) Detokenize(text string, escape bool) string {
+	if !strings.Contains(text, tokenPrefix) {
+		return text
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		value, ok := t.values[token]
+		if !ok {
+			return token
+		}
+		if escape {
+			return escapeJSON(value)
+		}
+		return value
+	})
+}
+
+// Stream returns a Stream restoring the placeholders of the deltas of a streamed text.
+func (t *Tokenizer) Stream() *Stream {
+	return &Stream{tokenizer: t}
+}
+
+// Stream restores the placeholders of the deltas of a streamed text, holding back the placeholders split across deltas
+// until they're complete.
+type Stream struct {
+	tokenizer *Tokenizer
+	pending   string
+}
+
+// Detokenize returns the delta with its placeholders restored, without what may be the beginning of a placeholder.
+func (s *Stream) Detokenize(delta string) string {
+	text := s.pending + delta
+	s.pending = ""
+	for i := max(0, len(text)-maxTokenLength); i < len(text); i++ {
+		if text[i] != '{' {
+			continue
+		}
+		if tail := text[i:]; strings.HasPrefix(tokenPrefix, tail) || partialTokenPattern.MatchString(tail) {
+			s.pending = tail
+			text = text[:i]
+			break
+		}
+	}
+	return s.tokenizer.Detokenize(text, false)
+}
+
+// Flush returns what was held back, at the end of the stream.
+func (s *Stream) Flush() string {
+	text := s.pending
+	s.pending = ""
+	return s.tokenizer.Detokenize(text, false)
+}
@@ -0,0 +1,132 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []Pattern
+		deny     []string
+		allow    []string
+		text     string
+		escaped  bool
+		expected string
+	}{
+		{
+			name:     "builtin pattern",
+			patterns: []Pattern{{Name: "email"}},
+			text:     "contact alice@example.com or bob@example.com",
+			expected: "contact {{redacted:email-1}} or {{redacted:email-2}}",
+		},
+		{
+			name:     "same value, same placeholder",
+			patterns: []Pattern{{Name: "email"}},
+			text:     "alice@example.com, alice@example.com",
+			expected: "{{redacted:email-1}}, {{redacted:email-1}}",
+		},
+		{
+			name:     "custom pattern",
+			patterns: []Pattern{{Name: "ticket", Regexp: `TICKET-[0-9]+`}},
+			text:     "see TICKET-42",
+			expected: "see {{redacted:ticket-1}}",
+		},
+		{
+			name:     "deny list, case insensitively",
+			deny:     []string{"Acme Corp"},
+			text:     "the network of ACME CORP",
+			expected: "the network of {{redacted:denied-1}}",
+		},
+		{
+			name:     "allow list",
+			patterns: []Pattern{{Name: "ipv4"}},
+			allow:    []string{"127.0.0.1"},
+			text:     "127.0.0.1 and 10.0.0.5",
+			expected: "127.0.0.1 and {{redacted:ipv4-1}}",
+		},
+		{
+			name:     "value escaped in a JSON document",
+			deny:     []string{`acme"corp`},
+			text:     `{"name":"acme\"corp"}`,
+			escaped:  true,
+			expected: `{"name":"{{redacted:denied-1}}"}`,
+		},
+		{
+			name:     "placeholders of the previous patterns left alone",
+			patterns: []Pattern{{Name: "private_ipv4"}, {Name: "ipv4"}},
+			text:     "10.0.0.5 and 8.8.8.8",
+			expected: "{{redacted:private_ipv4-1}} and {{redacted:ipv4-1}}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer, err := NewTokenizer(tt.patterns, tt.deny, tt.allow)
+			if err != nil {
+				t.Fatalf("NewTokenizer() failed: %v", err)
+			}
+			tokenized := tokenizer.Tokenize(tt.text, tt.escaped)
+			if tokenized != tt.expected {
+				t.Fatalf("Tokenize(%q) = %q, expected %q", tt.text, tokenized, tt.expected)
+			}
+			if tt.escaped {
+				return
+			}
+			if restored := tokenizer.Detokenize(tokenized, false); !strings.EqualFold(restored, tt.text) {
+				t.Errorf("Detokenize(%q) = %q, expected %q", tokenized, restored, tt.text)
+			}
+		})
+	}
+}
+
+func TestTokenizerInvalidPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern Pattern
+	}{
+		{name: "invalid name", pattern: Pattern{Name: "Not-Valid", Regexp: "x"}},
+		{name: "unknown builtin", pattern: Pattern{Name: "unknown"}},
+		{name: "invalid regexp", pattern: Pattern{Name: "broken", Regexp: "("}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTokenizer([]Pattern{tt.pattern}, nil, nil); err == nil {
+				t.Errorf("NewTokenizer() succeeded, expected an error")
+			}
+		})
+	}
+}
+
+func TestTokenizerUnknownPlaceholder(t *testing.T) {
+	tokenizer, err := NewTokenizer([]Pattern{{Name: "email"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenizer() failed: %v", err)
+	}
+	text := "{{redacted:email-7}}"
+	if restored := tokenizer.Detokenize(text, false); restored != text {
+		t.Errorf("Detokenize(%q) = %q, expected the placeholder to be left as is", text, restored)
+	}
+}
+
+func TestStream(t *testing.T) {
+	tokenizer, err := NewTokenizer([]Pattern{{Name: "email"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenizer() failed: %v", err)
+	}
+	tokenized := tokenizer.Tokenize("write to alice@example.com now", false)
+
+	// The placeholder is split across every delta.
+	for split := 1; split < len(tokenized); split++ {
+		stream := tokenizer.Stream()
+		restored := stream.Detokenize(tokenized[:split]) + stream.Detokenize(tokenized[split:]) + stream.Flush()
+		if restored != "write to alice@example.com now" {
+			t.Fatalf("split at %d: restored %q", split, restored)
+		}
+	}
+
+	stream := tokenizer.Stream()
+	if restored := stream.Detokenize("a { that isn't a placeholder") + stream.Flush(); restored != "a { that isn't a placeholder" {
+		t.Errorf("restored %q, expected the text as is", restored)
+	}
+}
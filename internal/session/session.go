@@ -3,9 +3,18 @@ package session
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/audit"
 	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/message"
 	"github.com/yaydraco/tandem/internal/pubsub"
 )
 
@@ -16,10 +25,37 @@ type Session struct {
 	MessageCount     int64
 	PromptTokens     int64
 	CompletionTokens int64
+	ContextTokens    int64
 	SummaryMessageID string
+	// ForkedFromSessionID and ForkedFromMessageID are the session and the message of it the session was forked
+	// from, empty unless it's a fork.
+	ForkedFromSessionID string
+	ForkedFromMessageID string
+	// Tags are the lowercase tags the operator sorts the sessions with.
+	Tags []string
+	// ArchivedAt is when the session was archived, 0 unless it is.
+	ArchivedAt int64
+	Cost       float64
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+// Archived reports whether the session is archived.
+func (s Session) Archived() bool {
+	return s.ArchivedAt != 0
+}
+
+// HasTag reports whether the session is tagged with the tag.
+func (s Session) HasTag(tag string) bool {
+	return slices.Contains(s.Tags, strings.ToLower(strings.TrimSpace(tag)))
+}
+
+type UpdateUsageParams struct {
+	PromptTokens     int64
+	CompletionTokens int64
 	Cost             float64
-	CreatedAt        int64
-	UpdatedAt        int64
+	// ContextTokens is left untouched when nil.
+	ContextTokens *int64
 }
 
 type Service interface {
@@ -27,15 +63,27 @@ type Service interface {
 	Create(ctx context.Context, title string) (Session, error)
 	CreateTitleSession(ctx context.Context, parentSessionID string) (Session, error)
 	CreateTaskSession(ctx context.Context, toolCallID, parentSessionID, title string) (Session, error)
+	// Fork copies the messages of the session up to the message into a new session, which records its origin.
+	Fork(ctx context.Context, sessionID, messageID string) (Session, error)
 	Get(ctx context.Context, id string) (Session, error)
 	List(ctx context.Context) ([]Session, error)
 	Save(ctx context.Context, session Session) (Session, error)
+	UpdateUsage(ctx context.Context, id string, params UpdateUsageParams) (Session, error)
+	// Rename only updates the title of the session, so that it doesn't race with the usage updates.
+	Rename(ctx context.Context, id, title string) (Session, error)
+	// SetTags replaces the tags of the session, lowercased, deduplicated and sorted.
+	SetTags(ctx context.Context, id string, tags []string) (Session, error)
+	// Archive archives the session, or restores it if not archived.
+	Archive(ctx context.Context, id string, archived bool) (Session, error)
+	// Delete deletes the session along with its messages and the task sessions of its agents.
 	Delete(ctx context.Context, id string) error
 }
 
 type service struct {
 	*pubsub.Broker[Session]
-	q db.Querier
+	q        db.Querier
+	audit    audit.Service
+	messages message.Service
 }
 
 func (s *service) Create(ctx context.Context, title string) (Session, error) {
@@ -79,15 +127,81 @@ func (s *service) CreateTitleSession(ctx context.Context, parentSessionID string
 	return session, nil
 }
 
+func (s *service) Fork(ctx context.Context, sessionID, messageID string) (Session, error) {
+	origin, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	messages, err := s.messages.List(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	end := -1
+	for i, msg := range messages {
+		if msg.ID == messageID {
+			end = i + 1
+			break
+		}
+	}
+	if end < 0 {
+		return Session{}, fmt.Errorf("message %s isn't of the session %s", messageID, sessionID)
+	}
+	// NOTE: the results of the tool calls of the last message go along with it, the models refusing the calls without.
+	if len(messages[end-1].ToolCalls()) > 0 && end < len(messages) && messages[end].Role == message.Tool {
+		end++
+	}
+
+	dbSession, err := s.q.ForkSession(ctx, db.ForkSessionParams{
+		ID:                  uuid.New().String(),
+		Title:               strings.TrimSuffix(origin.Title, " (fork)") + " (fork)",
+		ForkedFromSessionID: sql.NullString{String: origin.ID, Valid: true},
+		ForkedFromMessageID: sql.NullString{String: messageID, Valid: true},
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	fork := s.fromDBItem(dbSession)
+	s.Publish(pubsub.CreatedEvent, fork)
+
+	for _, msg := range messages[:end] {
+		copied, err := s.messages.Copy(ctx, msg.ID, fork.ID)
+		if err != nil {
+			if err := s.Delete(ctx, fork.ID); err != nil {
+				return Session{}, fmt.Errorf("failed to delete the partial fork %s: %w", fork.ID, err)
+			}
+			return Session{}, fmt.Errorf("failed to copy the message %s: %w", msg.ID, err)
+		}
+		if msg.ID == origin.SummaryMessageID {
+			fork.SummaryMessageID = copied.ID
+		}
+	}
+	if fork.SummaryMessageID != "" {
+		return s.Save(ctx, fork)
+	}
+	return s.Get(ctx, fork.ID)
+}
+
 func (s *service) Delete(ctx context.Context, id string) error {
 	session, err := s.Get(ctx, id)
 	if err != nil {
 		return err
 	}
+	tasks, err := s.q.ListTaskSessions(ctx, sql.NullString{String: session.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := s.Delete(ctx, task.ID); err != nil {
+			return fmt.Errorf("failed to delete the task session %s: %w", task.ID, err)
+		}
+	}
 	err = s.q.DeleteSession(ctx, session.ID)
 	if err != nil {
 		return err
 	}
+	if err := s.audit.Record(ctx, audit.KindDeletion, session.ID, "", ""); err != nil {
+		return fmt.Errorf("failed to record the deletion in the audit log: %w", err)
+	}
 	s.Publish(pubsub.DeletedEvent, session)
 	return nil
 }
@@ -110,7 +224,8 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 			String: session.SummaryMessageID,
 			Valid:  session.SummaryMessageID != "",
 		},
-		Cost: session.Cost,
+		Cost:          session.Cost,
+		ContextTokens: session.ContextTokens,
 	})
 	if err != nil {
 		return Session{}, err
@@ -120,6 +235,90 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 	return session, nil
 }
 
+// UpdateUsage only updates the token and cost totals so that it doesn't race with the title generation.
+func (s *service) UpdateUsage(ctx context.Context, id string, params UpdateUsageParams) (Session, error) {
+	contextTokens := sql.NullInt64{}
+	if params.ContextTokens != nil {
+		contextTokens = sql.NullInt64{Int64: *params.ContextTokens, Valid: true}
+	}
+	dbSession, err := s.q.UpdateSessionUsage(ctx, db.UpdateSessionUsageParams{
+		ID:               id,
+		PromptTokens:     params.PromptTokens,
+		CompletionTokens: params.CompletionTokens,
+		Cost:             params.Cost,
+		ContextTokens:    contextTokens,
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session := s.fromDBItem(dbSession)
+	s.Publish(pubsub.UpdatedEvent, session)
+	return session, nil
+}
+
+func (s *service) Rename(ctx context.Context, id, title string) (Session, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Session{}, fmt.Errorf("the title is empty")
+	}
+	dbSession, err := s.q.RenameSession(ctx, db.RenameSessionParams{
+		ID:    id,
+		Title: title,
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session := s.fromDBItem(dbSession)
+	s.Publish(pubsub.UpdatedEvent, session)
+	return session, nil
+}
+
+func (s *service) SetTags(ctx context.Context, id string, tags []string) (Session, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if strings.ContainsFunc(tag, unicode.IsSpace) {
+			return Session{}, fmt.Errorf("invalid tag %q, tags are single words", tag)
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	data, err := json.Marshal(slices.Compact(normalized))
+	if err != nil {
+		return Session{}, err
+	}
+	dbSession, err := s.q.SetSessionTags(ctx, db.SetSessionTagsParams{
+		ID:   id,
+		Tags: string(data),
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session := s.fromDBItem(dbSession)
+	s.Publish(pubsub.UpdatedEvent, session)
+	return session, nil
+}
+
+func (s *service) Archive(ctx context.Context, id string, archived bool) (Session, error) {
+	archivedAt := sql.NullInt64{}
+	if archived {
+		archivedAt = sql.NullInt64{Int64: time.Now().Unix(), Valid: true}
+	}
+	dbSession, err := s.q.SetSessionArchived(ctx, db.SetSessionArchivedParams{
+		ID:         id,
+		ArchivedAt: archivedAt,
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session := s.fromDBItem(dbSession)
+	s.Publish(pubsub.UpdatedEvent, session)
+	return session, nil
+}
+
 func (s *service) List(ctx context.Context) ([]Session, error) {
 	dbSessions, err := s.q.ListSessions(ctx)
 	if err != nil {
@@ -133,24 +332,35 @@ func (s *service) List(ctx context.Context) ([]Session, error) {
 }
 
 func (s service) fromDBItem(item db.Session) Session {
+	var tags []string
+	if err := json.Unmarshal([]byte(item.Tags), &tags); err != nil {
+		logging.Warn("invalid tags of session", "session", item.ID, "error", err)
+	}
 	return Session{
-		ID:               item.ID,
-		ParentSessionID:  item.ParentSessionID.String,
-		Title:            item.Title,
-		MessageCount:     item.MessageCount,
-		PromptTokens:     item.PromptTokens,
-		CompletionTokens: item.CompletionTokens,
-		SummaryMessageID: item.SummaryMessageID.String,
-		Cost:             item.Cost,
-		CreatedAt:        item.CreatedAt,
-		UpdatedAt:        item.UpdatedAt,
+		ID:                  item.ID,
+		ParentSessionID:     item.ParentSessionID.String,
+		Title:               item.Title,
+		MessageCount:        item.MessageCount,
+		PromptTokens:        item.PromptTokens,
+		CompletionTokens:    item.CompletionTokens,
+		ContextTokens:       item.ContextTokens,
+		SummaryMessageID:    item.SummaryMessageID.String,
+		ForkedFromSessionID: item.ForkedFromSessionID.String,
+		ForkedFromMessageID: item.ForkedFromMessageID.String,
+		Tags:                tags,
+		ArchivedAt:          item.ArchivedAt.Int64,
+		Cost:                item.Cost,
+		CreatedAt:           item.CreatedAt,
+		UpdatedAt:           item.UpdatedAt,
 	}
 }
 
-func NewService(q db.Querier) Service {
+func NewService(q db.Querier, auditLog audit.Service, messages message.Service) Service {
 	broker := pubsub.NewBroker[Session]()
 	return &service{
 		broker,
 		q,
+		auditLog,
+		messages,
 	}
 }
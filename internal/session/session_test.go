@@ -0,0 +1,136 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/audit"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/message"
+)
+
+// newTestServices returns the services of the sessions and of their messages, over a database migrated in a
+// temporary data directory.
+func newTestServices(t *testing.T) (Service, message.Service) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	if _, err := config.Load(".", false); err != nil {
+		t.Fatalf("config.Load() failed: %v", err)
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		t.Fatalf("db.Connect() failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	auditLog := audit.NewService(q, nil)
+	messages := message.NewService(q, auditLog)
+	return NewService(q, auditLog, messages), messages
+}
+
+// describe returns the text, the tool calls or the tool results of the message.
+func describe(msg message.Message) string {
+	for _, call := range msg.ToolCalls() {
+		return "call " + call.Name
+	}
+	for _, result := range msg.ToolResults() {
+		return "result " + result.Content
+	}
+	return msg.Content().String()
+}
+
+func TestFork(t *testing.T) {
+	ctx := context.Background()
+	sessions, messages := newTestServices(t)
+	origin, err := sessions.Create(ctx, "scan")
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	var ids []string
+	for _, params := range []message.CreateMessageParams{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "scan 10.0.0.5"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: "call-1", Name: "nmap_scan", Input: `{"targets":["10.0.0.5"]}`, Finished: true}}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-1", Name: "nmap_scan", Content: "80/tcp open"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "port 80 is open"}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "and 10.0.0.6"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "done"}}},
+	} {
+		msg, err := messages.Create(ctx, origin.ID, params)
+		if err != nil {
+			t.Fatalf("message Create() failed: %v", err)
+		}
+		ids = append(ids, msg.ID)
+	}
+	// The response summing up the scan is the summary of the session.
+	origin.SummaryMessageID = ids[3]
+	if origin, err = sessions.Save(ctx, origin); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		messageID string
+		expected  []string
+		// expectedSummary is the content of the summary copied, if any.
+		expectedSummary string
+		expectErr       bool
+	}{
+		{name: "prompt", messageID: ids[0], expected: []string{"scan 10.0.0.5"}},
+		{name: "tool calls along with their results", messageID: ids[1], expected: []string{"scan 10.0.0.5", "call nmap_scan", "result 80/tcp open"}},
+		{name: "tool results", messageID: ids[2], expected: []string{"scan 10.0.0.5", "call nmap_scan", "result 80/tcp open"}},
+		{name: "summary", messageID: ids[3], expected: []string{"scan 10.0.0.5", "call nmap_scan", "result 80/tcp open", "port 80 is open"}, expectedSummary: "port 80 is open"},
+		{name: "last message", messageID: ids[5], expected: []string{"scan 10.0.0.5", "call nmap_scan", "result 80/tcp open", "port 80 is open", "and 10.0.0.6", "done"}, expectedSummary: "port 80 is open"},
+		{name: "message of another session", messageID: "unknown", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fork, err := sessions.Fork(ctx, origin.ID, tt.messageID)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Fork() succeeded, expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fork() failed: %v", err)
+			}
+			if fork.Title != "scan (fork)" || fork.ForkedFromSessionID != origin.ID || fork.ForkedFromMessageID != tt.messageID {
+				t.Errorf("Fork() = %+v, expected a fork of %s from %s", fork, origin.ID, tt.messageID)
+			}
+
+			copied, err := messages.List(ctx, fork.ID)
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			var described []string
+			for _, msg := range copied {
+				if slices.Contains(ids, msg.ID) {
+					t.Errorf("Fork() moved the message %s rather than copying it", msg.ID)
+				}
+				described = append(described, describe(msg))
+			}
+			if !slices.Equal(described, tt.expected) {
+				t.Errorf("messages of the fork = %q, expected %q", described, tt.expected)
+			}
+
+			var summary string
+			if fork.SummaryMessageID != "" {
+				msg, err := messages.Get(ctx, fork.SummaryMessageID)
+				if err != nil || msg.SessionID != fork.ID {
+					t.Fatalf("the summary %s of the fork isn't one of its messages: %v", fork.SummaryMessageID, err)
+				}
+				summary = describe(msg)
+			}
+			if summary != tt.expectedSummary {
+				t.Errorf("summary of the fork = %q, expected %q", summary, tt.expectedSummary)
+			}
+		})
+	}
+
+	if kept, err := messages.List(ctx, origin.ID); err != nil || len(kept) != len(ids) {
+		t.Errorf("List() of the forked session = %d messages, %v, expected them all kept", len(kept), err)
+	}
+}
@@ -0,0 +1,449 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/inventory"
+)
+
+const (
+	ContentDiscoveryToolName = "content_discovery"
+
+	// fuzzKeyword is the placeholder of the url replaced by the words of the wordlist.
+	fuzzKeyword = "FUZZ"
+
+	defaultWordlist       = "common"
+	defaultFuzzThreads    = 40
+	maxFuzzThreads        = 200
+	defaultFuzzMaxTime    = 600
+	maxFuzzMaxTime        = 3600
+	maxFuzzOutputSize     = 50 << 20
+	maxDiscoveryHitsShown = 200
+	// maxSimilarHits is how many hits responding alike are listed before they're taken for a catch-all and summed up.
+	maxSimilarHits = 10
+)
+
+var (
+	// namedWordlists are the wordlists of the sandbox by name, along with the paths they may be found at.
+	namedWordlists = map[string][]string{
+		"common": {
+			"/share/wordlists/seclists/Discovery/Web-Content/common.txt",
+			"/usr/share/seclists/Discovery/Web-Content/common.txt",
+			"/share/dirb/wordlists/common.txt",
+			"/usr/share/dirb/wordlists/common.txt",
+		},
+		"big": {
+			"/share/wordlists/seclists/Discovery/Web-Content/big.txt",
+			"/usr/share/seclists/Discovery/Web-Content/big.txt",
+			"/share/dirb/wordlists/big.txt",
+			"/usr/share/dirb/wordlists/big.txt",
+		},
+		"directories": {
+			"/share/wordlists/seclists/Discovery/Web-Content/raft-medium-directories.txt",
+			"/usr/share/seclists/Discovery/Web-Content/raft-medium-directories.txt",
+		},
+		"files": {
+			"/share/wordlists/seclists/Discovery/Web-Content/raft-medium-files.txt",
+			"/usr/share/seclists/Discovery/Web-Content/raft-medium-files.txt",
+		},
+	}
+
+	fuzzNumbersPattern   = regexp.MustCompile(`^[0-9]+(-[0-9]+)?(,[0-9]+(-[0-9]+)?)*$`)
+	fuzzExtensionPattern = regexp.MustCompile(`^\.?[A-Za-z0-9_~\-]+$`)
+)
+
+type ContentDiscoveryArgs struct {
+	URL          string   `json:"url"`
+	Wordlist     string   `json:"wordlist,omitempty"`
+	Extensions   []string `json:"extensions,omitempty"`
+	Headers      []string `json:"headers,omitempty"`
+	MatchStatus  string   `json:"match_status,omitempty"`
+	FilterStatus string   `json:"filter_status,omitempty"`
+	FilterSize   string   `json:"filter_size,omitempty"`
+	FilterWords  string   `json:"filter_words,omitempty"`
+	Rate         int      `json:"rate,omitempty"`
+	Threads      int      `json:"threads,omitempty"`
+	MaxTime      int      `json:"max_time,omitempty"`
+}
+
+// ContentDiscoveryHit is a web content found by the fuzzing engine.
+type ContentDiscoveryHit struct {
+	URL              string `json:"url"`
+	Status           int64  `json:"status"`
+	Length           int64  `json:"length"`
+	Words            int64  `json:"words"`
+	Lines            int64  `json:"lines"`
+	ContentType      string `json:"content-type"`
+	RedirectLocation string `json:"redirectlocation"`
+}
+
+type contentDiscoveryTool struct {
+	inventory inventory.Service
+}
+
+// NewContentDiscoveryTool returns the tool fuzzing web contents with ffuf within the sandbox, recording the hits in the inventory.
+func NewContentDiscoveryTool(inventory inventory.Service) BaseTool {
+	return &contentDiscoveryTool{inventory: inventory}
+}
+
+func (t *contentDiscoveryTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ContentDiscoveryToolName,
+		Description: "Discovers the web contents of a target within the scope of the engagement by fuzzing the url with the words of a wordlist, using ffuf in the docker container. responses alike the ones to random words are filtered out. returns the deduplicated hits, which are recorded in the engagement inventory.",
+		Parameters: map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("url to fuzz, with the %s keyword where the words go, e.g. http://example.com/%s or http://example.com/api/%s.json. /%s is appended if missing", fuzzKeyword, fuzzKeyword, fuzzKeyword, fuzzKeyword),
+			},
+			"wordlist": map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("one of %s, or the path of a wordlist in the docker container. defaults to %s", strings.Join(slices.Sorted(maps.Keys(namedWordlists)), ", "), defaultWordlist),
+			},
+			"extensions": map[string]any{
+				"type":        "array",
+				"description": "extensions appended to every word, e.g. php, bak",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "extension",
+				},
+			},
+			"headers": map[string]any{
+				"type":        "array",
+				"description": "request headers",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "header as `Name: value`",
+				},
+			},
+			"match_status": map[string]any{
+				"type":        "string",
+				"description": "status codes of the hits, e.g. 200,204,301-399. defaults to 200-299,301,302,307,401,403,405,500",
+			},
+			"filter_status": map[string]any{
+				"type":        "string",
+				"description": "status codes to filter out, e.g. 404,500-599",
+			},
+			"filter_size": map[string]any{
+				"type":        "string",
+				"description": "response sizes in bytes to filter out, e.g. 0,4242",
+			},
+			"filter_words": map[string]any{
+				"type":        "string",
+				"description": "response word counts to filter out, e.g. 12,57-60",
+			},
+			"rate": map[string]any{
+				"type":        "integer",
+				"description": "requests per second at most. unlimited by default",
+			},
+			"threads": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("concurrent requests. defaults to %d, at most %d", defaultFuzzThreads, maxFuzzThreads),
+			},
+			"max_time": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("seconds after which the fuzzing stops. defaults to %d, at most %d", defaultFuzzMaxTime, maxFuzzMaxTime),
+			},
+		},
+		Required: []string{"url"},
+	}
+}
+
+func (t *contentDiscoveryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ContentDiscoveryArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse content_discovery parameters: " + err.Error()), nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+	target, err := fuzzURL(args.URL)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if err := checkScope(ctx, target.Hostname()); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if err := checkHostHeaders(ctx, args.Headers); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	wordlist, err := resolveWordlist(ctx, args.Wordlist)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	output := path.Join("/tmp", "tandem-ffuf-"+uuid.New().String()+".json")
+	cmd, err := ffufCommand(args, target, wordlist, output)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	defer sandboxExec(context.Background(), "rm", "-f", output)
+	if _, err := sandboxExec(ctx, cmd...); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	data, err := readSandboxFile(ctx, output, maxFuzzOutputSize)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	var results struct {
+		Results []ContentDiscoveryHit `json:"results"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return NewTextErrorResponse("failed to parse the ffuf output: " + err.Error()), nil
+	}
+	hits := dedupeHits(results.Results)
+
+	if _, err := t.inventory.CreateScan(ctx, inventory.CreateScanParams{
+		SessionID: sessionID,
+		Tool:      ContentDiscoveryToolName,
+		Command:   strings.Join(cmd, " "),
+		Output:    string(data),
+	}); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to record the scan: %w", err)
+	}
+	if err := t.record(ctx, sessionID, target, hits); err != nil {
+		return ToolResponse{}, err
+	}
+	return WithResponseMetadata(NewTextResponse(discoverySummary(target.String(), wordlist, hits)), hits), nil
+}
+
+// record upserts the host and port of the target, along with the hits as its endpoints.
+func (t *contentDiscoveryTool) record(ctx context.Context, sessionID string, target *url.URL, hits []ContentDiscoveryHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	// NOTE: the hosts targeted by name are recorded by it, nmap recording them by address.
+	host, err := t.inventory.UpsertHost(ctx, inventory.UpsertHostParams{
+		SessionID: sessionID,
+		Address:   target.Hostname(),
+		Status:    "up",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record the host %s: %w", target.Hostname(), err)
+	}
+	port := target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+	portNumber, _ := strconv.ParseInt(port, 10, 64)
+	if _, err := t.inventory.UpsertPort(ctx, host.ID, inventory.UpsertPortParams{
+		SessionID: sessionID,
+		Protocol:  "tcp",
+		Port:      portNumber,
+		State:     "open",
+		Service:   target.Scheme,
+	}); err != nil {
+		return fmt.Errorf("failed to record the port %s of %s: %w", port, host.Address, err)
+	}
+	for _, hit := range hits {
+		if _, err := t.inventory.UpsertEndpoint(ctx, host.ID, inventory.UpsertEndpointParams{
+			SessionID:        sessionID,
+			URL:              hit.URL,
+			Status:           hit.Status,
+			Size:             hit.Length,
+			Words:            hit.Words,
+			Lines:            hit.Lines,
+			ContentType:      hit.ContentType,
+			RedirectLocation: hit.RedirectLocation,
+		}); err != nil {
+			return fmt.Errorf("failed to record the endpoint %s: %w", hit.URL, err)
+		}
+	}
+	return nil
+}
+
+// fuzzURL parses the url to fuzz, appending the keyword to its path if it's missing.
+func fuzzURL(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, fuzzKeyword) {
+		rawURL = strings.TrimSuffix(rawURL, "/") + "/" + fuzzKeyword
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid url: the scheme must be http or https")
+	}
+	if strings.Contains(u.Host, fuzzKeyword) {
+		return nil, fmt.Errorf("invalid url: the host can't be fuzzed, only the path and query")
+	}
+	return u, nil
+}
+
+// checkHostHeaders checks the virtual hosts the Host headers send ffuf to against the scope, as for the host of the url.
+func checkHostHeaders(ctx context.Context, headers []string) error {
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "host") {
+			continue
+		}
+		host := strings.TrimSpace(value)
+		if strings.Contains(host, fuzzKeyword) {
+			return fmt.Errorf("invalid header: the host can't be fuzzed, only the path and query")
+		}
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if err := checkScope(ctx, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveWordlist returns the path of the wordlist within the sandbox, looking the named ones up.
+func resolveWordlist(ctx context.Context, wordlist string) (string, error) {
+	if wordlist == "" {
+		wordlist = defaultWordlist
+	}
+	candidates, ok := namedWordlists[wordlist]
+	if !ok {
+		if !path.IsAbs(wordlist) {
+			wordlist = path.Join(SandboxWorkdir, wordlist)
+		}
+		candidates = []string{path.Clean(wordlist)}
+	}
+	script := `for p in "$@"; do if [ -f "$p" ]; then echo "$p"; exit 0; fi; done; exit 1`
+	found, err := sandboxExec(ctx, append([]string{"sh", "-c", script, "sh"}, candidates...)...)
+	if err != nil {
+		return "", fmt.Errorf("wordlist %s not found in the docker container", wordlist)
+	}
+	return strings.TrimSpace(found), nil
+}
+
+// ffufCommand builds the ffuf command line out of the arguments, writing the results as json to the output.
+func ffufCommand(args ContentDiscoveryArgs, target *url.URL, wordlist, output string) ([]string, error) {
+	threads := defaultFuzzThreads
+	if args.Threads > 0 {
+		threads = min(args.Threads, maxFuzzThreads)
+	}
+	maxTime := defaultFuzzMaxTime
+	if args.MaxTime > 0 {
+		maxTime = min(args.MaxTime, maxFuzzMaxTime)
+	}
+	cmd := []string{
+		"ffuf", "-noninteractive", "-s",
+		"-u", target.String(),
+		"-w", wordlist,
+		"-t", strconv.Itoa(threads),
+		"-maxtime", strconv.Itoa(maxTime),
+		// NOTE: calibrating against random words filters out the catch-all responses of the target.
+		"-ac",
+		"-o", output, "-of", "json",
+	}
+
+	if len(args.Extensions) > 0 {
+		extensions := make([]string, len(args.Extensions))
+		for i, extension := range args.Extensions {
+			if !fuzzExtensionPattern.MatchString(extension) {
+				return nil, fmt.Errorf("invalid extension: %q", extension)
+			}
+			extensions[i] = "." + strings.TrimPrefix(extension, ".")
+		}
+		cmd = append(cmd, "-e", strings.Join(extensions, ","))
+	}
+	for _, header := range args.Headers {
+		if name, _, ok := strings.Cut(header, ":"); !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header: %q", header)
+		}
+		cmd = append(cmd, "-H", header)
+	}
+	for _, filter := range []struct {
+		flag, name, value string
+	}{
+		{"-mc", "match_status", args.MatchStatus},
+		{"-fc", "filter_status", args.FilterStatus},
+		{"-fs", "filter_size", args.FilterSize},
+		{"-fw", "filter_words", args.FilterWords},
+	} {
+		if filter.value == "" {
+			continue
+		}
+		if !fuzzNumbersPattern.MatchString(filter.value) {
+			return nil, fmt.Errorf("invalid %s: %q", filter.name, filter.value)
+		}
+		cmd = append(cmd, filter.flag, filter.value)
+	}
+	if args.Rate > 0 {
+		cmd = append(cmd, "-rate", strconv.Itoa(args.Rate))
+	}
+	return cmd, nil
+}
+
+// dedupeHits drops the hits of the urls already hit, e.g. both with and without a trailing slash.
+func dedupeHits(hits []ContentDiscoveryHit) []ContentDiscoveryHit {
+	seen := make(map[string]bool, len(hits))
+	deduped := make([]ContentDiscoveryHit, 0, len(hits))
+	for _, hit := range hits {
+		key := strings.TrimSuffix(hit.URL, "/")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, hit)
+	}
+	return deduped
+}
+
+// discoverySummary lists the hits for the agents, summing up the ones responding alike past a few of them.
+func discoverySummary(target, wordlist string, hits []ContentDiscoveryHit) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d hits fuzzing %s with %s\n", len(hits), target, wordlist)
+
+	type signature struct{ status, length, words int64 }
+	similar := make(map[signature][]ContentDiscoveryHit)
+	var order []signature
+	for _, hit := range hits {
+		sig := signature{hit.Status, hit.Length, hit.Words}
+		if _, ok := similar[sig]; !ok {
+			order = append(order, sig)
+		}
+		similar[sig] = append(similar[sig], hit)
+	}
+
+	var distinct []ContentDiscoveryHit
+	for _, hit := range hits {
+		if len(similar[signature{hit.Status, hit.Length, hit.Words}]) <= maxSimilarHits {
+			distinct = append(distinct, hit)
+		}
+	}
+	for i, hit := range distinct {
+		if i == maxDiscoveryHitsShown {
+			fmt.Fprintf(&sb, "  ... %d more hits recorded in the inventory\n", len(distinct)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "  %d %s (%d bytes, %d words", hit.Status, hit.URL, hit.Length, hit.Words)
+		if hit.ContentType != "" {
+			fmt.Fprintf(&sb, ", %s", hit.ContentType)
+		}
+		sb.WriteString(")")
+		if hit.RedirectLocation != "" {
+			fmt.Fprintf(&sb, " -> %s", hit.RedirectLocation)
+		}
+		sb.WriteString("\n")
+	}
+	for _, sig := range order {
+		group := similar[sig]
+		if len(group) <= maxSimilarHits {
+			continue
+		}
+		examples := make([]string, 0, 5)
+		for _, hit := range group[:5] {
+			examples = append(examples, hit.URL)
+		}
+		fmt.Fprintf(&sb, "  %d hits responding %d with %d bytes and %d words alike, likely a catch-all, e.g. %s\n",
+			len(group), sig.status, sig.length, sig.words, strings.Join(examples, ", "))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/yaydraco/tandem/internal/logging"
 )
@@ -110,42 +109,11 @@ func (cli *DockerCli) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 	// NOTE: when using tty in normal mode, input is line buffered, implying you need to press enter to send the command. thus appending a newline.
 	commandLine += "\n"
 
-	if cli.containerId == "" {
-		summaries, err := cli.client.ContainerList(ctx, container.ListOptions{
-			Filters: filters.NewArgs(filters.Arg("ancestor", DockerImage)),
-		})
-		if err != nil {
-			return NewTextErrorResponse("Failed to list containers: " + err.Error()), nil
-		}
-
-		for _, summary := range summaries {
-			if summary.Image == DockerImage && summary.State == container.StateRunning {
-				cli.containerId = summary.ID
-				break
-			}
-
-			if summary.Image == DockerImage {
-				cli.containerId = summary.ID
-				break
-			}
-		}
-	}
-
-	// NOTE: we are not creating a container if not found in the summaries because it should be created during the installation.
-	if cli.containerId == "" {
-		return NewTextErrorResponse(fmt.Sprintf("couldn't find a container using %s image.", DockerImage)), nil
-	}
-
-	inspectRes, err := cli.client.ContainerInspect(ctx, cli.containerId)
+	containerId, err := sandboxContainer(ctx)
 	if err != nil {
-		return NewTextErrorResponse("Failed to inspect container: " + err.Error()), nil
-	}
-
-	if !inspectRes.State.Running {
-		if err := cli.GetRunning(ctx, cli.containerId, inspectRes.State.Status); err != nil {
-			return NewTextErrorResponse(fmt.Sprintf("couldn't get the container: %s running.", cli.containerId)), nil
-		}
+		return NewTextErrorResponse(err.Error()), nil
 	}
+	cli.containerId = containerId
 
 	if cli.hijackedResponse == nil {
 		hijackedResp, err := cli.client.ContainerAttach(ctx, cli.containerId, container.AttachOptions{
@@ -0,0 +1,406 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yaydraco/tandem/internal/artifact"
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/encryption"
+)
+
+const (
+	ReadFileToolName         = "read_file"
+	WriteFileToolName        = "write_file"
+	ListDirToolName          = "list_dir"
+	DownloadArtifactToolName = "download_artifact"
+
+	// maxReadFileSize bounds the files read into the context of the agents, base64 encoded ones being a quarter of it.
+	maxReadFileSize = 1 << 20
+	// maxWriteFileSize bounds the files written into the sandbox, e.g. wordlists.
+	maxWriteFileSize = 50 << 20
+	// maxArtifactSize bounds the files downloaded from the sandbox.
+	maxArtifactSize = 100 << 20
+
+	defaultReadLines = 2000
+	maxListDepth     = 3
+	maxListEntries   = 500
+
+	encodingUTF8   = "utf-8"
+	encodingBase64 = "base64"
+)
+
+type ReadFileArgs struct {
+	Path     string `json:"path"`
+	Offset   int    `json:"offset,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type WriteFileArgs struct {
+	Path     string `json:"path"`
+	Content  string `json:"content,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+type ListDirArgs struct {
+	Path  string `json:"path,omitempty"`
+	Depth int    `json:"depth,omitempty"`
+}
+
+type DownloadArtifactArgs struct {
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+}
+
+type readFileTool struct{}
+
+type writeFileTool struct{}
+
+type listDirTool struct{}
+
+type downloadArtifactTool struct {
+	artifacts artifact.Service
+}
+
+// NewFileTools returns the tools to work with the files of the engagement directory within the sandbox.
+func NewFileTools() []BaseTool {
+	return []BaseTool{
+		&readFileTool{},
+		&writeFileTool{},
+		&listDirTool{},
+	}
+}
+
+func NewDownloadArtifactTool(artifacts artifact.Service) BaseTool {
+	return &downloadArtifactTool{artifacts: artifacts}
+}
+
+var sandboxPathParameter = map[string]any{
+	"type":        "string",
+	"description": fmt.Sprintf("path of the file in the docker container, relative to the engagement directory %s unless absolute. paths outside of it are rejected.", SandboxWorkdir),
+}
+
+func (t *readFileTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReadFileToolName,
+		Description: fmt.Sprintf("Reads a file of the engagement directory in the docker container. text files are returned by lines, binary files have to be read base64 encoded. files over %d bytes can't be read, use download_artifact instead.", maxReadFileSize),
+		Parameters: map[string]any{
+			"path": sandboxPathParameter,
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "line to start reading from, 0 based",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("count of lines to read. defaults to %d", defaultReadLines),
+			},
+			"encoding": map[string]any{
+				"type":        "string",
+				"description": "utf-8, by default, or base64 for binary files",
+				"enum":        []string{encodingUTF8, encodingBase64},
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (t *readFileTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ReadFileArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse read_file parameters: " + err.Error()), nil
+	}
+	p, err := sandboxPath(ctx, args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	if args.Encoding == encodingBase64 {
+		data, err := readSandboxFile(ctx, p, maxReadFileSize/4)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		return NewTextResponse(base64.StdEncoding.EncodeToString(data)), nil
+	}
+
+	data, err := readSandboxFile(ctx, p, maxReadFileSize)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return NewTextErrorResponse(fmt.Sprintf("%s is a binary file, read it with the base64 encoding or download it as an artifact", p)), nil
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultReadLines
+	}
+	offset := min(max(0, args.Offset), len(lines))
+	end := min(offset+limit, len(lines))
+	content := strings.Join(lines[offset:end], "")
+	// NOTE: keeping the output within the context of the agents, the same as the shell sessions.
+	if len(content) > maxShellOutput {
+		content = truncateText(content, maxShellOutput)
+		end = offset + strings.Count(content, "\n")
+	}
+	if end < len(lines) {
+		content += fmt.Sprintf("\n[%d more lines, read on from offset %d]", len(lines)-end, end)
+	}
+	return NewTextResponse(content), nil
+}
+
+func (t *writeFileTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        WriteFileToolName,
+		Description: fmt.Sprintf("Writes a file of the engagement directory in the docker container, creating its directories. the content is either given or copied from a file of the engagement directory of the host, e.g. a wordlist or a payload. files are limited to %d bytes.", maxWriteFileSize),
+		Parameters: map[string]any{
+			"path": sandboxPathParameter,
+			"content": map[string]any{
+				"type":        "string",
+				"description": "content of the file",
+			},
+			"encoding": map[string]any{
+				"type":        "string",
+				"description": "encoding of the content, utf-8 by default or base64 for binary content",
+				"enum":        []string{encodingUTF8, encodingBase64},
+			},
+			"source": map[string]any{
+				"type":        "string",
+				"description": "path of the file to copy from the engagement directory of the host instead of the content, relative to it unless absolute",
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (t *writeFileTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args WriteFileArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse write_file parameters: " + err.Error()), nil
+	}
+	p, err := sandboxPath(ctx, args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var data []byte
+	switch {
+	case args.Source != "":
+		source, err := hostPath(args.Source)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		info, err := os.Stat(source)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		if !info.Mode().IsRegular() {
+			return NewTextErrorResponse(fmt.Sprintf("%s is not a regular file", source)), nil
+		}
+		if info.Size() > maxWriteFileSize {
+			return NewTextErrorResponse(fmt.Sprintf("%s is %d bytes, over the limit of %d bytes", source, info.Size(), maxWriteFileSize)), nil
+		}
+		if data, err = os.ReadFile(source); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	case args.Encoding == encodingBase64:
+		if data, err = base64.StdEncoding.DecodeString(args.Content); err != nil {
+			return NewTextErrorResponse("invalid base64 content: " + err.Error()), nil
+		}
+	default:
+		data = []byte(args.Content)
+	}
+	if len(data) > maxWriteFileSize {
+		return NewTextErrorResponse(fmt.Sprintf("the content is %d bytes, over the limit of %d bytes", len(data), maxWriteFileSize)), nil
+	}
+
+	if err := writeSandboxFile(ctx, p, data); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return NewTextResponse(fmt.Sprintf("wrote %d bytes to %s", len(data), p)), nil
+}
+
+func (t *listDirTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ListDirToolName,
+		Description: "Lists a directory of the engagement directory in the docker container, with the type (f for files, d for directories, l for links), size and modification time of the entries.",
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("path of the directory, relative to the engagement directory %s unless absolute. defaults to the engagement directory", SandboxWorkdir),
+			},
+			"depth": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("how deep to list the subdirectories. defaults to 1, at most %d", maxListDepth),
+			},
+		},
+	}
+}
+
+func (t *listDirTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ListDirArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse list_dir parameters: " + err.Error()), nil
+	}
+	p, err := sandboxPath(ctx, args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	depth := min(max(1, args.Depth), maxListDepth)
+
+	if p == SandboxWorkdir {
+		if _, err := sandboxExec(ctx, "mkdir", "-p", SandboxWorkdir); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+	output, err := sandboxExec(ctx, "find", p, "-mindepth", "1", "-maxdepth", fmt.Sprint(depth), "-printf", "%y %s %TY-%Tm-%Td %TH:%TM %P\n")
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	entries := strings.Split(strings.TrimSpace(output), "\n")
+	if len(entries) == 1 && entries[0] == "" {
+		return NewTextResponse(fmt.Sprintf("%s is empty", p)), nil
+	}
+	slices.SortFunc(entries, func(a, b string) int {
+		return strings.Compare(strings.SplitN(a, " ", 5)[4], strings.SplitN(b, " ", 5)[4])
+	})
+	content := strings.Join(entries[:min(len(entries), maxListEntries)], "\n")
+	if len(entries) > maxListEntries {
+		content += fmt.Sprintf("\n[%d more entries]", len(entries)-maxListEntries)
+	}
+	return NewTextResponse(content), nil
+}
+
+func (t *downloadArtifactTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        DownloadArtifactToolName,
+		Description: fmt.Sprintf("Downloads a file, e.g. loot, from the engagement directory in the docker container to the engagement directory of the host, and records it as an artifact of the engagement. files are limited to %d bytes.", maxArtifactSize),
+		Parameters: map[string]any{
+			"path": sandboxPathParameter,
+			"description": map[string]any{
+				"type":        "string",
+				"description": "what the file is and where it was found",
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (t *downloadArtifactTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args DownloadArtifactArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse download_artifact parameters: " + err.Error()), nil
+	}
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+	p, err := sandboxPath(ctx, args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	data, err := readSandboxFile(ctx, p, maxArtifactSize)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	dir := filepath.Join(config.EngagementDirectory(), "artifacts", sessionID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to create the artifacts directory: %w", err)
+	}
+	name := path.Base(p)
+	dest, err := createArtifactFile(dir, name)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	content := data
+	if key := encryption.Active(); key != nil {
+		if content, err = key.Encrypt(data); err != nil {
+			dest.Close()
+			return ToolResponse{}, fmt.Errorf("failed to encrypt the artifact: %w", err)
+		}
+	}
+	if _, err := dest.Write(content); err != nil {
+		dest.Close()
+		return ToolResponse{}, fmt.Errorf("failed to write the artifact: %w", err)
+	}
+	if err := dest.Close(); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to write the artifact: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	a, err := t.artifacts.Create(ctx, artifact.CreateArtifactParams{
+		SessionID:   sessionID,
+		MessageID:   messageID,
+		Name:        name,
+		SourcePath:  p,
+		Path:        dest.Name(),
+		Size:        int64(len(data)),
+		Sha256:      hex.EncodeToString(sum[:]),
+		MimeType:    http.DetectContentType(data),
+		Description: args.Description,
+	})
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to record the artifact: %w", err)
+	}
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("downloaded %s (%d bytes, %s, sha256 %s) to %s", p, a.Size, a.MimeType, a.Sha256, a.Path)),
+		a,
+	), nil
+}
+
+// createArtifactFile creates a file of the given name in the directory, suffixing the name if it's already taken.
+func createArtifactFile(dir, name string) (*os.File, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create the artifact: %w", err)
+		}
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// truncateText cuts the text down to maxLength bytes at most, without splitting a rune.
+func truncateText(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	for maxLength > 0 && !utf8.RuneStart(text[maxLength]) {
+		maxLength--
+	}
+	return text[:maxLength]
+}
+
+// tailText keeps the last maxLength bytes at most of the text, without splitting a rune.
+func tailText(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	start := len(text) - maxLength
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	return text[start:]
+}
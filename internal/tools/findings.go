@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/finding"
+	"github.com/yaydraco/tandem/internal/inventory"
+)
+
+const (
+	ListFindingsToolName = "list_findings"
+
+	maxFindingsShown  = 100
+	maxEvidenceLength = 300
+)
+
+var severityNames = func() []string {
+	names := make([]string, len(finding.Severities))
+	for i, severity := range finding.Severities {
+		names[i] = string(severity)
+	}
+	return names
+}()
+
+type ListFindingsArgs struct {
+	MinSeverity string `json:"min_severity,omitempty"`
+	Host        string `json:"host,omitempty"`
+	Scanner     string `json:"scanner,omitempty"`
+}
+
+type listFindingsTool struct {
+	findings finding.Service
+}
+
+func NewListFindingsTool(findings finding.Service) BaseTool {
+	return &listFindingsTool{findings: findings}
+}
+
+func (t *listFindingsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ListFindingsToolName,
+		Description: "Lists the findings of the scanners recorded over the engagement, deduplicated and the most severe first, along with their evidence and references.",
+		Parameters: map[string]any{
+			"min_severity": map[string]any{
+				"type":        "string",
+				"description": "least severity of the findings to list. defaults to unknown, the severity of the findings which aren't rated, i.e. every finding",
+				"enum":        severityNames,
+			},
+			"host": map[string]any{
+				"type":        "string",
+				"description": "lists the findings of the targets containing it only, e.g. a hostname or an address",
+			},
+			"scanner": map[string]any{
+				"type":        "string",
+				"description": "lists the findings of the scanner only, e.g. nuclei",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *listFindingsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ListFindingsArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse list_findings parameters: " + err.Error()), nil
+	}
+	minSeverity := finding.SeverityUnknown
+	if args.MinSeverity != "" {
+		if !slices.Contains(severityNames, args.MinSeverity) {
+			return NewTextErrorResponse("invalid min_severity: " + args.MinSeverity), nil
+		}
+		minSeverity = finding.Severity(args.MinSeverity)
+	}
+	all, err := t.findings.List(ctx)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to list the findings: %w", err)
+	}
+	var findings []finding.Finding
+	for _, f := range all {
+		if !f.Severity.AtLeast(minSeverity) ||
+			(args.Host != "" && !strings.Contains(f.Target, args.Host)) ||
+			(args.Scanner != "" && f.Scanner != args.Scanner) {
+			continue
+		}
+		findings = append(findings, f)
+	}
+	if len(findings) == 0 {
+		return NewTextResponse("no findings"), nil
+	}
+
+	var sb strings.Builder
+	for i, f := range findings {
+		if i == maxFindingsShown {
+			fmt.Fprintf(&sb, "... %d more findings, narrow them down with the parameters\n", len(findings)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "[%s] %s (%s %s) at %s\n", f.Severity, f.Name, f.Scanner, f.TemplateID, f.Target)
+		if f.Description != "" && f.Description != f.Name {
+			fmt.Fprintf(&sb, "  %s\n", compactText(f.Description, maxEvidenceLength))
+		}
+		if f.Evidence != "" {
+			fmt.Fprintf(&sb, "  evidence: %s\n", compactText(f.Evidence, maxEvidenceLength))
+		}
+		if len(f.References) > 0 {
+			fmt.Fprintf(&sb, "  references: %s\n", strings.Join(f.References, ", "))
+		}
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), findings), nil
+}
+
+// recordFindings records the findings of a scanner, along with the hosts they were found on into the inventory.
+func recordFindings(ctx context.Context, findings finding.Service, hosts inventory.Service, params []finding.RecordFindingParams) ([]finding.Finding, error) {
+	hostIDs := make(map[string]string)
+	recorded := make([]finding.Finding, 0, len(params))
+	for _, p := range params {
+		if address := targetHost(p.Target); address != "" {
+			if _, ok := hostIDs[address]; !ok {
+				host, err := hosts.UpsertHost(ctx, inventory.UpsertHostParams{
+					SessionID: p.SessionID,
+					Address:   address,
+					Status:    "up",
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to record the host %s: %w", address, err)
+				}
+				hostIDs[address] = host.ID
+			}
+			p.HostID = hostIDs[address]
+		}
+		f, err := findings.Record(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record the finding %s: %w", p.TemplateID, err)
+		}
+		recorded = append(recorded, f)
+	}
+	return recorded, nil
+}
+
+// findingsSummary lists the findings of a scan for the agents, the most severe first.
+func findingsSummary(scanner string, findings []finding.Finding) string {
+	findings = slices.Clone(findings)
+	slices.SortStableFunc(findings, func(a, b finding.Finding) int {
+		return slices.Index(finding.Severities, b.Severity) - slices.Index(finding.Severities, a.Severity)
+	})
+	created := 0
+	for _, f := range findings {
+		if f.Occurrences == 1 {
+			created++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d findings of %s, %d of them new, recorded in the findings store", len(findings), scanner, created)
+	for i, f := range findings {
+		if i == maxFindingsShown {
+			fmt.Fprintf(&sb, "\n... %d more findings, see list_findings", len(findings)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n[%s] %s (%s) at %s", f.Severity, f.Name, f.TemplateID, f.Target)
+		if f.Evidence != "" {
+			fmt.Fprintf(&sb, "\n  evidence: %s", compactText(f.Evidence, maxEvidenceLength))
+		}
+	}
+	return sb.String()
+}
+
+// targetHost returns the host of the target of a finding, be it a url or an address along with a port.
+func targetHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return target
+}
@@ -0,0 +1,316 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptrace"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+const (
+	HTTPRequestToolName = "http_request"
+
+	defaultHTTPTimeout = 30 * time.Second
+	maxHTTPTimeout     = 5 * time.Minute
+	maxHTTPRedirects   = 10
+	// maxHTTPBodySize bounds the bodies read off the responses, the rest being discarded.
+	maxHTTPBodySize = 10 << 20
+	// defaultHTTPBodyExcerpt is how much of the body is returned to the agents, unless asked otherwise.
+	defaultHTTPBodyExcerpt = 10000
+	maxHTTPBodyExcerpt     = 100000
+)
+
+var (
+	httpMethodPattern = regexp.MustCompile(`^[A-Z]+$`)
+
+	// httpCookieJars are the cookie jars of the engagements, each shared by every agent of its engagement
+	// so that a session established by one of them is carried on by the others, but not by another engagement.
+	httpCookieJars   = make(map[string]http.CookieJar)
+	httpCookieJarsMu sync.Mutex
+)
+
+// httpCookieJar returns the cookie jar of the current engagement.
+func httpCookieJar() http.CookieJar {
+	httpCookieJarsMu.Lock()
+	defer httpCookieJarsMu.Unlock()
+	engagement := config.Get().Engagement
+	jar, ok := httpCookieJars[engagement]
+	if !ok {
+		jar, _ = cookiejar.New(nil)
+		httpCookieJars[engagement] = jar
+	}
+	return jar
+}
+
+type HTTPRequestArgs struct {
+	URL             string   `json:"url"`
+	Method          string   `json:"method,omitempty"`
+	Headers         []string `json:"headers,omitempty"`
+	Body            string   `json:"body,omitempty"`
+	Cookies         []string `json:"cookies,omitempty"`
+	FollowRedirects bool     `json:"follow_redirects,omitempty"`
+	Proxy           string   `json:"proxy,omitempty"`
+	Timeout         int      `json:"timeout,omitempty"`
+	MaxBody         int      `json:"max_body,omitempty"`
+}
+
+type HTTPResponseMetadata struct {
+	URL        string   `json:"url"`
+	StatusCode int      `json:"status_code"`
+	Redirects  []string `json:"redirects,omitempty"`
+	Size       int      `json:"size"`
+	Duration   int64    `json:"duration_ms"`
+}
+
+type httpRequestTool struct{}
+
+func NewHTTPRequestTool() BaseTool {
+	return &httpRequestTool{}
+}
+
+func (t *httpRequestTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        HTTPRequestToolName,
+		Description: "Sends an HTTP request to a target within the scope of the engagement and returns the status, headers, timing and an excerpt of the body of the response. cookies set by the responses are kept in the cookie jar of the engagement and sent along with the later requests. TLS certificates are not verified.",
+		Parameters: map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "http or https url to request",
+			},
+			"method": map[string]any{
+				"type":        "string",
+				"description": "request method. defaults to GET",
+			},
+			"headers": map[string]any{
+				"type":        "array",
+				"description": "request headers",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "header as `Name: value`",
+				},
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "request body, set the Content-Type header along with it",
+			},
+			"cookies": map[string]any{
+				"type":        "array",
+				"description": "cookies to put into the cookie jar for the url before sending the request",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "cookie as `name=value`",
+				},
+			},
+			"follow_redirects": map[string]any{
+				"type":        "boolean",
+				"description": fmt.Sprintf("follows up to %d redirects within the scope. by default, the redirect itself is returned", maxHTTPRedirects),
+			},
+			"proxy": map[string]any{
+				"type":        "string",
+				"description": "proxy url to send the request through, e.g. http://127.0.0.1:8080 or socks5://127.0.0.1:1080",
+			},
+			"timeout": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("timeout in seconds. defaults to %d, at most %d", int(defaultHTTPTimeout.Seconds()), int(maxHTTPTimeout.Seconds())),
+			},
+			"max_body": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("count of bytes of the body to return. defaults to %d, at most %d", defaultHTTPBodyExcerpt, maxHTTPBodyExcerpt),
+			},
+		},
+		Required: []string{"url"},
+	}
+}
+
+func (t *httpRequestTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args HTTPRequestArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse http_request parameters: " + err.Error()), nil
+	}
+	req, client, err := newHTTPRequest(ctx, args)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	defer client.CloseIdleConnections()
+
+	var redirects []string
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if !args.FollowRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxHTTPRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+		}
+		redirects = append(redirects, next.URL.String())
+		return nil
+	}
+
+	var firstByte time.Duration
+	start := time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			firstByte = time.Since(start)
+		},
+	}))
+	resp, err := client.Do(req)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	if err != nil {
+		return NewTextErrorResponse("failed to read the response body: " + err.Error()), nil
+	}
+	duration := time.Since(start)
+
+	maxBody := args.MaxBody
+	if maxBody <= 0 {
+		maxBody = defaultHTTPBodyExcerpt
+	}
+	maxBody = min(maxBody, maxHTTPBodyExcerpt)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", req.Method, req.URL)
+	for _, redirect := range redirects {
+		fmt.Fprintf(&sb, "redirected to %s\n", redirect)
+	}
+	fmt.Fprintf(&sb, "%s %s (%dms, first byte after %dms)\n", resp.Proto, resp.Status, duration.Milliseconds(), firstByte.Milliseconds())
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	sb.WriteString("\n")
+	sb.WriteString(httpBodyExcerpt(body, maxBody))
+
+	return WithResponseMetadata(NewTextResponse(sb.String()), HTTPResponseMetadata{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Redirects:  redirects,
+		Size:       len(body),
+		Duration:   duration.Milliseconds(),
+	}), nil
+}
+
+// newHTTPRequest builds the request along with a client checking every url it's sent to against the scope.
+func newHTTPRequest(ctx context.Context, args HTTPRequestArgs) (*http.Request, *http.Client, error) {
+	u, err := url.Parse(args.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, nil, fmt.Errorf("invalid url: the scheme must be http or https")
+	}
+	method := strings.ToUpper(args.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !httpMethodPattern.MatchString(method) {
+		return nil, nil, fmt.Errorf("invalid method: %q", args.Method)
+	}
+	timeout := defaultHTTPTimeout
+	if args.Timeout > 0 {
+		timeout = min(time.Duration(args.Timeout)*time.Second, maxHTTPTimeout)
+	}
+
+	var body io.Reader
+	if args.Body != "" {
+		body = strings.NewReader(args.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
+	}
+	for _, header := range args.Headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, nil, fmt.Errorf("invalid header: %q", header)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Add(name, value)
+	}
+
+	jar := httpCookieJar()
+	if len(args.Cookies) > 0 {
+		cookies := make([]*http.Cookie, 0, len(args.Cookies))
+		for _, cookie := range args.Cookies {
+			name, value, ok := strings.Cut(cookie, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, nil, fmt.Errorf("invalid cookie: %q", cookie)
+			}
+			cookies = append(cookies, &http.Cookie{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+		}
+		jar.SetCookies(u, cookies)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// NOTE: the targets are rarely serving trusted certificates, and the tool isn't meant to vouch for them.
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	transport.Proxy = nil
+	if args.Proxy != "" {
+		proxy, err := url.Parse(args.Proxy)
+		if err != nil || !slices.Contains([]string{"http", "https", "socks5", "socks5h"}, proxy.Scheme) {
+			return nil, nil, fmt.Errorf("invalid proxy: %q", args.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	client := &http.Client{
+		Transport: &scopedTransport{transport},
+		Jar:       jar,
+		Timeout:   timeout,
+	}
+	return req, client, nil
+}
+
+// scopedTransport refuses to send the requests to the hosts out of the scope, redirects included.
+type scopedTransport struct {
+	*http.Transport
+}
+
+func (t *scopedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkScope(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return t.Transport.RoundTrip(req)
+}
+
+// httpBodyExcerpt returns the beginning of a text body, binary bodies being left out.
+func httpBodyExcerpt(body []byte, maxBody int) string {
+	if len(body) == 0 {
+		return "(empty body)"
+	}
+	excerpt := body[:min(len(body), maxBody)]
+	// NOTE: cutting the excerpt may split a multibyte character at its end.
+	for i := 0; i < utf8.UTFMax && !utf8.Valid(excerpt) && len(excerpt) > 0; i++ {
+		excerpt = excerpt[:len(excerpt)-1]
+	}
+	if !utf8.Valid(excerpt) {
+		return fmt.Sprintf("(binary body of %d bytes, %s)", len(body), http.DetectContentType(body))
+	}
+	if len(excerpt) < len(body) {
+		return fmt.Sprintf("%s\n... (%d of %d bytes shown)", excerpt, len(excerpt), len(body))
+	}
+	return string(excerpt)
+}
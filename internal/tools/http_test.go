@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+func TestHTTPRequestScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			// NOTE: 127.0.0.2 is a loopback address as well, but out of the scope.
+			http.Redirect(w, r, strings.Replace("http://"+r.Host+"/", "127.0.0.1", "127.0.0.2", 1), http.StatusFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	setTestScope(t, config.Scope{Include: []string{"127.0.0.1", "10.0.0.0/24"}, Exclude: []string{"10.0.0.13"}})
+
+	tests := []struct {
+		name            string
+		args            HTTPRequestArgs
+		expectedContent string
+		expectErr       bool
+	}{
+		{name: "target in scope", args: HTTPRequestArgs{URL: server.URL}, expectedContent: "200 OK"},
+		{name: "redirect out of scope not followed", args: HTTPRequestArgs{URL: server.URL + "/redirect"}, expectedContent: "302 Found"},
+		{name: "redirect out of scope followed", args: HTTPRequestArgs{URL: server.URL + "/redirect", FollowRedirects: true}, expectedContent: "out of scope: 127.0.0.2", expectErr: true},
+		{name: "target out of scope", args: HTTPRequestArgs{URL: "http://10.0.1.5/"}, expectedContent: "out of scope: 10.0.1.5", expectErr: true},
+		{name: "target excluded", args: HTTPRequestArgs{URL: "http://10.0.0.13/"}, expectedContent: "out of scope: 10.0.0.13", expectErr: true},
+		{name: "host header of another target", args: HTTPRequestArgs{URL: "http://10.0.1.5/", Headers: []string{"Host: 127.0.0.1"}}, expectedContent: "out of scope: 10.0.1.5", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := json.Marshal(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := NewHTTPRequestTool().Run(context.Background(), ToolCall{Input: string(input)})
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if response.IsError != tt.expectErr {
+				t.Errorf("Run() error = %v, expected %v: %s", response.IsError, tt.expectErr, response.Content)
+			}
+			if !strings.Contains(response.Content, tt.expectedContent) {
+				t.Errorf("Run() = %q, expected it to contain %q", response.Content, tt.expectedContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/memory"
+)
+
+const (
+	MemoryToolName = "memory"
+
+	maxRecalledMemories = 50
+)
+
+type MemoryArgs struct {
+	Action  string `json:"action"`
+	Subject string `json:"subject,omitempty"`
+	Content string `json:"content,omitempty"`
+	Query   string `json:"query,omitempty"`
+	ID      string `json:"id,omitempty"`
+}
+
+type memoryTool struct {
+	memories memory.Service
+	agent    string
+}
+
+// NewMemoryTool returns the tool the agent remembers the facts it learned with, for the agents of the later tasks
+// to know them, and recalls the ones remembered.
+func NewMemoryTool(memories memory.Service, agent string) BaseTool {
+	return &memoryTool{
+		memories: memories,
+		agent:    agent,
+	}
+}
+
+func (t *memoryTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        MemoryToolName,
+		Description: "The long-term memory of the engagement, shared by the agents of the swarm. remember the facts worth knowing for the later tasks as soon as you learn them, one fact per memory: the hosts and services found, the versions and vulnerabilities confirmed, the footholds gained, what was tried and failed. the memories relevant to a task are given to the agent it's assigned to, recall the others when needed. forget the memories which turned out wrong. never remember secrets, store them in the vault.",
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "remember a fact, recall the memories matching a query or all of them, or forget a memory",
+				"enum":        []string{"remember", "recall", "forget"},
+			},
+			"subject": map[string]any{
+				"type":        "string",
+				"description": "what the fact is about, e.g. 10.10.10.5, 10.10.10.5:8080 tomcat or domain users",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "the fact to remember, self-contained, e.g. tomcat 9.0.30 manager app accessible with default credentials, shell obtained as tomcat",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "words to recall the memories holding any of, all the memories are recalled without",
+			},
+			"id": map[string]any{
+				"type":        "string",
+				"description": "ID of the memory to forget",
+			},
+		},
+		Required: []string{"action"},
+	}
+}
+
+func (t *memoryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args MemoryArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse memory parameters: " + err.Error()), nil
+	}
+
+	switch args.Action {
+	case "remember":
+		sessionID, _ := GetContextValues(ctx)
+		m, err := t.memories.Remember(ctx, memory.RememberParams{
+			SessionID: sessionID,
+			Agent:     t.agent,
+			Subject:   args.Subject,
+			Content:   args.Content,
+		})
+		if err != nil {
+			return NewTextErrorResponse("failed to remember: " + err.Error()), nil
+		}
+		return WithResponseMetadata(NewTextResponse("remembered as "+m.ID), m), nil
+	case "recall":
+		return t.recall(ctx, args.Query)
+	case "forget":
+		err := t.memories.Forget(ctx, args.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewTextErrorResponse("no memory " + args.ID), nil
+		}
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to forget the memory: %w", err)
+		}
+		return NewTextResponse("forgot " + args.ID), nil
+	default:
+		return NewTextErrorResponse("invalid action: " + args.Action), nil
+	}
+}
+
+func (t *memoryTool) recall(ctx context.Context, query string) (ToolResponse, error) {
+	var memories []memory.Memory
+	var err error
+	if strings.TrimSpace(query) == "" {
+		memories, err = t.memories.List(ctx)
+		// NOTE: the latest ones, should there be too many of them.
+		memories = memories[max(0, len(memories)-maxRecalledMemories):]
+	} else {
+		memories, err = t.memories.Search(ctx, query, maxRecalledMemories)
+	}
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to recall the memories: %w", err)
+	}
+	if len(memories) == 0 {
+		return NewTextResponse("nothing remembered"), nil
+	}
+	lines := make([]string, len(memories))
+	for i, m := range memories {
+		lines[i] = m.ID + " " + memory.Format(m)
+	}
+	return WithResponseMetadata(NewTextResponse(strings.Join(lines, "\n")), memories), nil
+}
@@ -0,0 +1,513 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	MetasploitToolName = "metasploit"
+
+	metasploitActionSearch       = "search"
+	metasploitActionInfo         = "info"
+	metasploitActionRun          = "run"
+	metasploitActionSessions     = "sessions"
+	metasploitActionSessionWrite = "session_write"
+	metasploitActionSessionRead  = "session_read"
+
+	defaultMetasploitTimeout = 5 * 60
+	maxMetasploitTimeout     = 30 * 60
+	maxMetasploitModules     = 50
+)
+
+var (
+	metasploitActions = []string{
+		metasploitActionSearch,
+		metasploitActionInfo,
+		metasploitActionRun,
+		metasploitActionSessions,
+		metasploitActionSessionWrite,
+		metasploitActionSessionRead,
+	}
+	// NOTE: the modules and their options are run through msfconsole, thus only letting through what can't be taken for another command.
+	metasploitModulePattern = regexp.MustCompile(`^(exploit|auxiliary|post|payload|encoder|evasion|nop)/[A-Za-z0-9_/\-.]+$`)
+	metasploitOptionPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	// metasploitTargetSchemes are the schemes of the targets metasploit expands itself, into targets which can't be checked.
+	metasploitTargetSchemes = []string{"file", "cidr"}
+	// metasploitTargetOptions are the options of the modules holding their targets, checked against the scope.
+	metasploitTargetOptions = []string{"RHOSTS", "RHOST"}
+	// metasploitHostOptions are the options of the modules holding a host the requests are sent to, e.g. a virtual host.
+	metasploitHostOptions = []string{"VHOST"}
+	// metasploitURLOptions are the options of the modules which may hold a url, of a host to check against the scope.
+	metasploitURLOptions = []string{"TARGETURI", "URI", "URL"}
+)
+
+type MetasploitArgs struct {
+	Action    string   `json:"action"`
+	Query     string   `json:"query,omitempty"`
+	Module    string   `json:"module,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	SessionID string   `json:"session_id,omitempty"`
+	Input     string   `json:"input,omitempty"`
+	Timeout   int      `json:"timeout,omitempty"`
+}
+
+type MetasploitResponseMetadata struct {
+	Module   string   `json:"module,omitempty"`
+	Sessions []string `json:"sessions,omitempty"`
+}
+
+type metasploitTool struct{}
+
+// NewMetasploitTool returns the tool driving metasploit through its RPC server within the sandbox.
+func NewMetasploitTool() BaseTool {
+	return &metasploitTool{}
+}
+
+func (t *metasploitTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: MetasploitToolName,
+		Description: `Drives metasploit in the docker container through its rpc server, started on the first call.
+- search: searches the modules, e.g. query "cve:2021-44228" or "type:exploit name:smb".
+- info: shows the description, targets and options of the module.
+- run: runs the exploit, auxiliary or post module with the options, returning the console output along with the sessions it opened. the targets of the module (RHOSTS, VHOST, the host of a url, the host of the SESSION of a post module) must be within the scope of the engagement.
+- sessions: lists the sessions opened.
+- session_write: writes the input, e.g. a command, to the shell or meterpreter session, the host of which must be within the scope.
+- session_read: reads the output of the session written since the last read.`,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "action to perform",
+				"enum":        metasploitActions,
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "search query of the search action",
+			},
+			"module": map[string]any{
+				"type":        "string",
+				"description": "full name of the module of the info and run actions, e.g. exploit/unix/ftp/vsftpd_234_backdoor",
+			},
+			"options": map[string]any{
+				"type":        "array",
+				"description": "options of the module to run",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "option in the NAME=value form, e.g. RHOSTS=10.0.0.5 or PAYLOAD=cmd/unix/interact",
+				},
+			},
+			"session_id": map[string]any{
+				"type":        "string",
+				"description": "id of the session of the session_write and session_read actions",
+			},
+			"input": map[string]any{
+				"type":        "string",
+				"description": "input of the session_write action",
+			},
+			"timeout": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("timeout of the run action in seconds. defaults to %d, up to %d", defaultMetasploitTimeout, maxMetasploitTimeout),
+			},
+		},
+		Required: []string{"action"},
+	}
+}
+
+func (t *metasploitTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args MetasploitArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse metasploit parameters: " + err.Error()), nil
+	}
+
+	var (
+		response ToolResponse
+		err      error
+	)
+	switch args.Action {
+	case metasploitActionSearch:
+		response, err = t.search(ctx, args)
+	case metasploitActionInfo:
+		response, err = t.info(ctx, args)
+	case metasploitActionRun:
+		response, err = t.run(ctx, args)
+	case metasploitActionSessions:
+		response, err = t.sessions(ctx)
+	case metasploitActionSessionWrite:
+		response, err = t.sessionWrite(ctx, args)
+	case metasploitActionSessionRead:
+		response, err = t.sessionRead(ctx, args)
+	default:
+		return NewTextErrorResponse("invalid action: " + args.Action), nil
+	}
+	if err != nil {
+		// NOTE: the errors of metasploit are the agent's to handle, such as an unknown module.
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return response, nil
+}
+
+func (t *metasploitTool) search(ctx context.Context, args MetasploitArgs) (ToolResponse, error) {
+	if args.Query == "" {
+		return ToolResponse{}, fmt.Errorf("query is required")
+	}
+	res, err := metasploit.call(ctx, "module.search", args.Query)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	modules, _ := res.([]any)
+	if len(modules) == 0 {
+		return NewTextResponse("no modules found"), nil
+	}
+	var sb strings.Builder
+	for i, m := range modules {
+		if i == maxMetasploitModules {
+			fmt.Fprintf(&sb, "... %d more modules, narrow the query down\n", len(modules)-i)
+			break
+		}
+		module, _ := m.(map[string]any)
+		fmt.Fprintf(&sb, "%v (%v, %v)", module["fullname"], module["rank"], module["disclosuredate"])
+		if name := fmt.Sprint(module["name"]); name != "" {
+			fmt.Fprintf(&sb, ": %s", name)
+		}
+		sb.WriteString("\n")
+	}
+	return NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), nil
+}
+
+func (t *metasploitTool) info(ctx context.Context, args MetasploitArgs) (ToolResponse, error) {
+	moduleType, moduleName, err := metasploitModule(args.Module)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	info, err := metasploit.callMap(ctx, "module.info", moduleType, moduleName)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	options, err := metasploit.callMap(ctx, "module.options", moduleType, moduleName)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %v\n", args.Module, info["name"])
+	fmt.Fprintf(&sb, "rank: %v\n", info["rank"])
+	if description, ok := info["description"].(string); ok {
+		fmt.Fprintf(&sb, "description: %s\n", compactText(description, maxEvidenceLength*3))
+	}
+	if targets, ok := info["targets"].(map[string]any); ok && len(targets) > 0 {
+		sb.WriteString("targets:\n")
+		for _, id := range slices.Sorted(maps.Keys(targets)) {
+			fmt.Fprintf(&sb, "  %s: %v\n", id, targets[id])
+		}
+	}
+	if references, ok := info["references"].([]any); ok && len(references) > 0 {
+		sb.WriteString("references:")
+		for _, ref := range references {
+			if ref, ok := ref.([]any); ok && len(ref) == 2 {
+				fmt.Fprintf(&sb, " %v-%v", ref[0], ref[1])
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("options:\n")
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		option, _ := options[name].(map[string]any)
+		if option["advanced"] == true || option["evasion"] == true {
+			continue
+		}
+		required := ""
+		if option["required"] == true {
+			required = ", required"
+		}
+		fmt.Fprintf(&sb, "  %s (%v%s)", name, option["type"], required)
+		if def, ok := option["default"]; ok && def != nil && def != "" {
+			fmt.Fprintf(&sb, " = %v", def)
+		}
+		fmt.Fprintf(&sb, ": %v\n", option["desc"])
+	}
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimSuffix(sb.String(), "\n")),
+		MetasploitResponseMetadata{Module: args.Module},
+	), nil
+}
+
+func (t *metasploitTool) run(ctx context.Context, args MetasploitArgs) (ToolResponse, error) {
+	moduleType, _, err := metasploitModule(args.Module)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	if moduleType != "exploit" && moduleType != "auxiliary" && moduleType != "post" {
+		return ToolResponse{}, fmt.Errorf("only exploit, auxiliary and post modules can be run")
+	}
+	commands := []string{"use " + args.Module}
+	var targets []string
+	for _, option := range args.Options {
+		name, value, ok := strings.Cut(option, "=")
+		if !ok || !metasploitOptionPattern.MatchString(name) {
+			return ToolResponse{}, fmt.Errorf("invalid option: %s, expected NAME=value", option)
+		}
+		// NOTE: msfconsole chains the commands separated by semicolons.
+		if strings.ContainsAny(value, "\r\n;") {
+			return ToolResponse{}, fmt.Errorf("invalid value of %s: line breaks and semicolons are not allowed", name)
+		}
+		optionTargets, err := metasploitOptionTargets(ctx, strings.ToUpper(name), value)
+		if err != nil {
+			return ToolResponse{}, err
+		}
+		targets = append(targets, optionTargets...)
+		commands = append(commands, fmt.Sprintf("set %s %s", name, value))
+	}
+	if moduleType == "exploit" && len(targets) == 0 {
+		return ToolResponse{}, fmt.Errorf("the targets of the exploit are required, set RHOSTS")
+	}
+	if err := checkScopeTargets(ctx, targets...); err != nil {
+		return ToolResponse{}, err
+	}
+	if moduleType == "exploit" {
+		// NOTE: the sessions are left in the background, to be interacted with through session_write and session_read.
+		commands = append(commands, "exploit -z")
+	} else {
+		commands = append(commands, "run")
+	}
+
+	timeout := defaultMetasploitTimeout
+	if args.Timeout > 0 {
+		timeout = min(args.Timeout, maxMetasploitTimeout)
+	}
+	before, err := metasploit.callMap(ctx, "session.list")
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	output, err := t.console(ctx, commands, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	after, err := metasploit.callMap(ctx, "session.list")
+	if err != nil {
+		return ToolResponse{}, err
+	}
+
+	metadata := MetasploitResponseMetadata{Module: args.Module}
+	var sb strings.Builder
+	sb.WriteString(tailOutput(strings.TrimSpace(output)))
+	for _, id := range slices.Sorted(maps.Keys(after)) {
+		if _, ok := before[id]; ok {
+			continue
+		}
+		session, _ := after[id].(map[string]any)
+		fmt.Fprintf(&sb, "\nsession %s opened: %v %v %v", id, session["type"], session["tunnel_peer"], session["info"])
+		metadata.Sessions = append(metadata.Sessions, id)
+	}
+	return WithResponseMetadata(NewTextResponse(sb.String()), metadata), nil
+}
+
+// console runs the commands in a console of its own, returning their output once it's idle or the timeout is reached.
+func (t *metasploitTool) console(ctx context.Context, commands []string, timeout time.Duration) (string, error) {
+	console, err := metasploit.callMap(ctx, "console.create")
+	if err != nil {
+		return "", err
+	}
+	id := fmt.Sprint(console["id"])
+	defer metasploit.call(context.Background(), "console.destroy", id)
+
+	// NOTE: the banner of the console is discarded.
+	if _, err := metasploit.call(ctx, "console.read", id); err != nil {
+		return "", err
+	}
+	if _, err := metasploit.call(ctx, "console.write", id, strings.Join(commands, "\n")+"\n"); err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	deadline := time.Now().Add(timeout)
+	idle := 0
+	for idle < 3 {
+		if time.Now().After(deadline) {
+			fmt.Fprintf(&output, "\ntimed out after %s, the module may still be running", timeout)
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+		}
+		res, err := metasploit.callMap(ctx, "console.read", id)
+		if err != nil {
+			return "", err
+		}
+		data := fmt.Sprint(res["data"])
+		output.WriteString(data)
+		if res["busy"] == true || data != "" {
+			idle = 0
+		} else {
+			idle++
+		}
+	}
+	return output.String(), nil
+}
+
+func (t *metasploitTool) sessions(ctx context.Context) (ToolResponse, error) {
+	sessions, err := metasploit.callMap(ctx, "session.list")
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	if len(sessions) == 0 {
+		return NewTextResponse("no sessions"), nil
+	}
+	var sb strings.Builder
+	for _, id := range slices.Sorted(maps.Keys(sessions)) {
+		session, _ := sessions[id].(map[string]any)
+		fmt.Fprintf(&sb, "%s: %v %v via %v", id, session["type"], session["tunnel_peer"], session["via_exploit"])
+		if info := fmt.Sprint(session["info"]); info != "" {
+			fmt.Fprintf(&sb, " (%s)", info)
+		}
+		sb.WriteString("\n")
+	}
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimSuffix(sb.String(), "\n")),
+		MetasploitResponseMetadata{Sessions: slices.Sorted(maps.Keys(sessions))},
+	), nil
+}
+
+func (t *metasploitTool) sessionWrite(ctx context.Context, args MetasploitArgs) (ToolResponse, error) {
+	sessionType, err := t.sessionType(ctx, args.SessionID)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	input := args.Input
+	if !strings.HasSuffix(input, "\n") {
+		input += "\n"
+	}
+	if _, err := metasploit.call(ctx, "session."+sessionType+"_write", args.SessionID, input); err != nil {
+		return ToolResponse{}, err
+	}
+	return NewTextResponse(fmt.Sprintf("written to session %s, read its output with session_read", args.SessionID)), nil
+}
+
+func (t *metasploitTool) sessionRead(ctx context.Context, args MetasploitArgs) (ToolResponse, error) {
+	sessionType, err := t.sessionType(ctx, args.SessionID)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	res, err := metasploit.callMap(ctx, "session."+sessionType+"_read", args.SessionID)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	data := fmt.Sprint(res["data"])
+	if data == "" {
+		return NewTextResponse("no output yet"), nil
+	}
+	return NewTextResponse(tailOutput(data)), nil
+}
+
+// sessionType returns the type of the session, shell or meterpreter, its methods being named after it.
+// The host of the session must be within the scope.
+func (t *metasploitTool) sessionType(ctx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+	sessions, err := metasploit.callMap(ctx, "session.list")
+	if err != nil {
+		return "", err
+	}
+	session, ok := sessions[id].(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("session %s not found", id)
+	}
+	// NOTE: the sessions are opened by the modules run within the scope, but the scope may have shrunk since.
+	if err := checkScope(ctx, metasploitSessionHost(session)); err != nil {
+		return "", err
+	}
+	if session["type"] == "meterpreter" {
+		return "meterpreter", nil
+	}
+	return "shell", nil
+}
+
+// metasploitModule splits the full name of the module into its type and name.
+func metasploitModule(module string) (string, string, error) {
+	if module == "" {
+		return "", "", fmt.Errorf("module is required")
+	}
+	if !metasploitModulePattern.MatchString(module) || strings.Contains(module, "..") {
+		return "", "", fmt.Errorf("invalid module: %s", module)
+	}
+	moduleType, name, _ := strings.Cut(module, "/")
+	return moduleType, name, nil
+}
+
+// metasploitOptionTargets returns the hosts targeted by the option of a module, to be checked against the scope.
+func metasploitOptionTargets(ctx context.Context, name, value string) ([]string, error) {
+	switch {
+	case slices.Contains(metasploitTargetOptions, name):
+		var targets []string
+		for _, target := range strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' }) {
+			// NOTE: besides the hosts, networks and ranges, metasploit takes urls and reads the targets from files.
+			if strings.Contains(target, "://") {
+				u, err := url.Parse(target)
+				if err != nil || u.Hostname() == "" {
+					return nil, fmt.Errorf("invalid target of %s: %s", name, target)
+				}
+				targets = append(targets, u.Hostname())
+				continue
+			}
+			if scheme, _, ok := strings.Cut(target, ":"); ok && slices.Contains(metasploitTargetSchemes, strings.ToLower(scheme)) {
+				return nil, fmt.Errorf("%s can't be given as %s:, list the targets instead", name, scheme)
+			}
+			targets = append(targets, target)
+		}
+		return targets, nil
+	case slices.Contains(metasploitHostOptions, name):
+		host := strings.TrimSpace(value)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return []string{host}, nil
+	case slices.Contains(metasploitURLOptions, name):
+		if u, err := url.Parse(strings.TrimSpace(value)); err == nil && u.Hostname() != "" {
+			return []string{u.Hostname()}, nil
+		}
+	case name == "SESSION":
+		// NOTE: the post modules run through a session, thus against its host.
+		sessions, err := metasploit.callMap(ctx, "session.list")
+		if err != nil {
+			return nil, err
+		}
+		session, ok := sessions[strings.TrimSpace(value)].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("session %s not found", value)
+		}
+		return []string{metasploitSessionHost(session)}, nil
+	}
+	return nil, nil
+}
+
+// metasploitSessionHost returns the host a session is opened on.
+func metasploitSessionHost(session map[string]any) string {
+	for _, field := range []string{"target_host", "session_host"} {
+		if host, ok := session[field].(string); ok && host != "" {
+			return host
+		}
+	}
+	peer := fmt.Sprint(session["tunnel_peer"])
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		return host
+	}
+	return peer
+}
+
+// tailOutput keeps the end of the output of the console or a session, as the shell sessions do.
+func tailOutput(output string) string {
+	if len(output) > maxShellOutput {
+		tail := tailText(output, maxShellOutput)
+		return fmt.Sprintf("[%d characters truncated]\n%s", len(output)-len(tail), tail)
+	}
+	return output
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	msfRPCUser = "tandem"
+	msfRPCPort = 55553
+	// msfRPCCommand runs the console serving the RPC, without the database and the banner.
+	msfRPCCommand = "msfconsole -q -n"
+	// msfRPCStartTimeout is how long the RPC server is waited for, loading the modules taking a while.
+	msfRPCStartTimeout = 3 * time.Minute
+)
+
+var (
+	errMsfRPCUnavailable = errors.New("the metasploit RPC server is not available")
+	errMsfRPCAuth        = errors.New("the metasploit RPC server rejected the token")
+)
+
+// msfRPC is a client of the RPC server of metasploit, the msgrpc plugin of an msfconsole running within the sandbox.
+// NOTE: the server only listens within the sandbox, thus its calls are sent by curl in there.
+type msfRPC struct {
+	mu       sync.Mutex
+	password string
+	token    string
+}
+
+// metasploit is shared by every agent, along with the RPC server it starts.
+var metasploit = &msfRPC{}
+
+// call calls the method of the RPC with the token, starting the RPC server and logging in first if need be.
+func (m *msfRPC) call(ctx context.Context, method string, args ...any) (any, error) {
+	for retry := 0; ; retry++ {
+		token, err := m.login(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res, err := m.send(ctx, method, append([]any{token}, args...)...)
+		if err == nil || !(errors.Is(err, errMsfRPCUnavailable) || errors.Is(err, errMsfRPCAuth)) {
+			return res, err
+		}
+		// NOTE: the token expires once idle for a while, or along with the server, e.g. when the sandbox is recreated.
+		m.mu.Lock()
+		m.token = ""
+		m.mu.Unlock()
+		if retry == 1 {
+			return nil, err
+		}
+	}
+}
+
+// callMap calls the method of the RPC, the result of which is a map.
+func (m *msfRPC) callMap(ctx context.Context, method string, args ...any) (map[string]any, error) {
+	res, err := m.call(ctx, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := res.(map[string]any); ok {
+		return res, nil
+	}
+	return nil, fmt.Errorf("unexpected result of %s: %v", method, res)
+}
+
+func (m *msfRPC) login(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token != "" {
+		return m.token, nil
+	}
+	if m.password != "" {
+		if token, err := m.authenticate(ctx); err == nil {
+			m.token = token
+			return m.token, nil
+		}
+	}
+
+	// NOTE: the server of a former run can't be logged into without its password, thus it's restarted.
+	secret := make([]byte, 16)
+	rand.Read(secret)
+	m.password = hex.EncodeToString(secret)
+	// NOTE: the pattern is bracketed so that it doesn't match the shell running pkill, the command line of which holds it.
+	if _, err := sandboxExec(ctx, "sh", "-c", "pkill -f '["+msfRPCCommand[:1]+"]"+msfRPCCommand[1:]+"'; true"); err != nil {
+		return "", err
+	}
+	// NOTE: the password is written to the standard input of the console rather than given on its command line,
+	// which every process of the sandbox, and of the host, can read. The console is kept running with its input open.
+	script := fmt.Sprintf(`read -r password
+({ echo "load msgrpc ServerHost=127.0.0.1 ServerPort=%d User=%s Pass=$password SSL=false"; exec tail -f /dev/null; } | exec %s) </dev/null >/dev/null 2>&1 &`,
+		msfRPCPort, msfRPCUser, msfRPCCommand)
+	if _, err := sandboxExecInput(ctx, []byte(m.password+"\n"), "sh", "-c", script); err != nil {
+		return "", fmt.Errorf("failed to start the metasploit RPC server: %w", err)
+	}
+	deadline := time.Now().Add(msfRPCStartTimeout)
+	for {
+		token, err := m.authenticate(ctx)
+		if err == nil {
+			m.token = token
+			return m.token, nil
+		}
+		if !errors.Is(err, errMsfRPCUnavailable) || time.Now().After(deadline) {
+			return "", err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func (m *msfRPC) authenticate(ctx context.Context) (string, error) {
+	res, err := m.send(ctx, "auth.login", msfRPCUser, m.password)
+	if err != nil {
+		return "", err
+	}
+	if res, ok := res.(map[string]any); ok && res["result"] == "success" {
+		return fmt.Sprint(res["token"]), nil
+	}
+	return "", fmt.Errorf("failed to log into the metasploit RPC server: %v", res)
+}
+
+// send posts the call, encoded as MessagePack, to the RPC server and decodes its result.
+func (m *msfRPC) send(ctx context.Context, method string, args ...any) (any, error) {
+	body, err := encodeMsgpack(append([]any{method}, args...))
+	if err != nil {
+		return nil, err
+	}
+	output, err := sandboxExecInput(ctx, body,
+		"curl", "-s", "-S", "--fail-with-body", "-X", "POST",
+		"-H", "Content-Type: binary/message-pack",
+		"--data-binary", "@-",
+		fmt.Sprintf("http://127.0.0.1:%d/api/", msfRPCPort),
+	)
+	if err != nil && output == "" {
+		return nil, fmt.Errorf("%w: %w", errMsfRPCUnavailable, err)
+	}
+	decoded, decodeErr := decodeMsgpack([]byte(output))
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode the result of %s: %w", method, decodeErr)
+	}
+	if res, ok := decoded.(map[string]any); ok && res["error"] == true {
+		if res["error_class"] == "Msf::RPC::Exception" && res["error_code"] == int64(401) {
+			return nil, fmt.Errorf("%w: %v", errMsfRPCAuth, res["error_message"])
+		}
+		return nil, fmt.Errorf("%s failed: %v", method, res["error_message"])
+	}
+	return decoded, nil
+}
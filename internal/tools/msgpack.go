@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// NOTE: a minimal MessagePack codec, as much as the RPC of metasploit takes.
+
+var errMsgpackTruncated = errors.New("msgpack: truncated data")
+
+// encodeMsgpack encodes nil, booleans, integers, strings and the slices and string keyed maps of them.
+func encodeMsgpack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMsgpack(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMsgpack(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		writeMsgpackInt(buf, int64(v))
+	case int64:
+		writeMsgpackInt(buf, v)
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.Write([]byte{0xd9, byte(n)})
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(0xdb)
+			binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+		buf.WriteString(v)
+	case []string:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return writeMsgpack(buf, items)
+	case []any:
+		writeMsgpackLength(buf, len(v), 0x90, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		writeMsgpackLength(buf, len(v), 0x80, 0xde, 0xdf)
+		for key, value := range v {
+			if err := writeMsgpack(buf, key); err != nil {
+				return err
+			}
+			if err := writeMsgpack(buf, value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+func writeMsgpackInt(buf *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0 && v < 128:
+		buf.WriteByte(byte(v))
+	case v < 0 && v >= -32:
+		buf.WriteByte(byte(v))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+func writeMsgpackLength(buf *bytes.Buffer, n int, fix, b16, b32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(b32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// decodeMsgpack decodes the data into nil, bool, int64, uint64, float64, string, []any and map[string]any values.
+// NOTE: binaries are decoded into strings, metasploit sending most of its strings as such, and extensions are dropped.
+func decodeMsgpack(data []byte) (any, error) {
+	d := &msgpackDecoder{data: data}
+	return d.decode()
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errMsgpackTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *msgpackDecoder) decode() (any, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		return d.decodeSizedString(1)
+	case 0xc5, 0xda:
+		return d.decodeSizedString(2)
+	case 0xc6, 0xdb:
+		return d.decodeSizedString(4)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		_, err = d.read(int(n) + 1)
+		return nil, err
+	case 0xca:
+		v, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce:
+		v, err := d.uint(1 << (c - 0xcc))
+		return int64(v), err
+	case 0xcf:
+		return d.uint(8)
+	case 0xd0:
+		v, err := d.uint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := d.uint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := d.uint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := d.uint(8)
+		return int64(v), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		_, err := d.read(1 + 1<<(c-0xd4))
+		return nil, err
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+	return nil, fmt.Errorf("msgpack: unknown type 0x%x", c)
+}
+
+func (d *msgpackDecoder) decodeSizedString(size int) (any, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeString(int(n))
+}
+
+func (d *msgpackDecoder) decodeString(n int) (any, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	items := make([]any, n)
+	for i := range items {
+		item, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	m := make(map[string]any, n)
+	for range n {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(key)] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,397 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/inventory"
+)
+
+const (
+	NmapScanToolName = "nmap_scan"
+
+	scanTypeSyn     = "syn"
+	scanTypeConnect = "connect"
+	scanTypeUDP     = "udp"
+	scanTypePing    = "ping"
+
+	// maxScriptOutput bounds the output of every script in the summary returned to the agents,
+	// the full output being kept in the inventory.
+	maxScriptOutput = 300
+)
+
+var (
+	nmapScanTypes = map[string]string{
+		scanTypeSyn:     "-sS",
+		scanTypeConnect: "-sT",
+		scanTypeUDP:     "-sU",
+		scanTypePing:    "-sn",
+	}
+	// NOTE: the arguments are passed to nmap as is, thus only letting through what can't be taken for an option.
+	nmapTargetPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:/\-_\[\]]*$`)
+	nmapPortsPattern  = regexp.MustCompile(`^[0-9TUS:,\-]+$`)
+	nmapScriptPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_\-*]*$`)
+)
+
+type NmapScanArgs struct {
+	Targets        []string `json:"targets"`
+	Ports          string   `json:"ports,omitempty"`
+	TopPorts       int      `json:"top_ports,omitempty"`
+	ScanType       string   `json:"scan_type,omitempty"`
+	ServiceVersion bool     `json:"service_version,omitempty"`
+	OSDetection    bool     `json:"os_detection,omitempty"`
+	Scripts        []string `json:"scripts,omitempty"`
+	Timing         *int     `json:"timing,omitempty"`
+}
+
+type nmapScanTool struct {
+	inventory inventory.Service
+}
+
+// NewNmapScanTool returns the tool running nmap within the sandbox, recording what it finds in the inventory.
+func NewNmapScanTool(inventory inventory.Service) BaseTool {
+	return &nmapScanTool{inventory: inventory}
+}
+
+func (t *nmapScanTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        NmapScanToolName,
+		Description: "Scans the targets with nmap in the docker container. returns a summary of the hosts up and their open ports, the full results being recorded in the engagement inventory.",
+		Parameters: map[string]any{
+			"targets": map[string]any{
+				"type":        "array",
+				"description": "hosts to scan",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "ip address, hostname, cidr or range, e.g. 10.0.0.1, example.com, 10.0.0.0/24 or 10.0.0.1-20",
+				},
+			},
+			"ports": map[string]any{
+				"type":        "string",
+				"description": "ports to scan, e.g. 22,80,443, 1-1024 or U:53,T:80. defaults to the top 1000 ports",
+			},
+			"top_ports": map[string]any{
+				"type":        "integer",
+				"description": "scans the given count of the most common ports instead",
+			},
+			"scan_type": map[string]any{
+				"type":        "string",
+				"description": "syn, by default, connect, udp, or ping to discover the hosts up without scanning their ports",
+				"enum":        []string{scanTypeSyn, scanTypeConnect, scanTypeUDP, scanTypePing},
+			},
+			"service_version": map[string]any{
+				"type":        "boolean",
+				"description": "probes the open ports for their service and version",
+			},
+			"os_detection": map[string]any{
+				"type":        "boolean",
+				"description": "detects the operating system of the hosts",
+			},
+			"scripts": map[string]any{
+				"type":        "array",
+				"description": "nse scripts or categories to run, e.g. default, vuln or http-title",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "nse script or category",
+				},
+			},
+			"timing": map[string]any{
+				"type":        "integer",
+				"description": "timing template, from 0 (paranoid) to 5 (insane). defaults to 3",
+			},
+		},
+		Required: []string{"targets"},
+	}
+}
+
+func (t *nmapScanTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args NmapScanArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse nmap_scan parameters: " + err.Error()), nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+	cmd, err := nmapCommand(args)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if err := checkScopeTargets(ctx, args.Targets...); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	output, err := sandboxExec(ctx, cmd...)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	var run nmapRun
+	if err := xml.Unmarshal([]byte(output), &run); err != nil {
+		return NewTextErrorResponse("failed to parse the nmap output: " + err.Error()), nil
+	}
+
+	if _, err := t.inventory.CreateScan(ctx, inventory.CreateScanParams{
+		SessionID: sessionID,
+		Tool:      NmapScanToolName,
+		Command:   strings.Join(cmd, " "),
+		Output:    output,
+	}); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to record the scan: %w", err)
+	}
+	hosts, err := t.record(ctx, sessionID, run)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	return WithResponseMetadata(NewTextResponse(nmapSummary(run)), hosts), nil
+}
+
+// record upserts the hosts up and their ports into the inventory.
+func (t *nmapScanTool) record(ctx context.Context, sessionID string, run nmapRun) ([]inventory.Host, error) {
+	var hosts []inventory.Host
+	for _, h := range run.Hosts {
+		if h.Status.State != "up" {
+			continue
+		}
+		host, err := t.inventory.UpsertHost(ctx, inventory.UpsertHostParams{
+			SessionID: sessionID,
+			Address:   h.address(),
+			Hostnames: h.hostnames(),
+			OS:        h.os(),
+			Status:    h.Status.State,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record the host %s: %w", h.address(), err)
+		}
+		for _, p := range h.Ports {
+			var scripts map[string]string
+			if len(p.Scripts) > 0 {
+				scripts = make(map[string]string, len(p.Scripts))
+				for _, s := range p.Scripts {
+					scripts[s.ID] = s.Output
+				}
+			}
+			port, err := t.inventory.UpsertPort(ctx, host.ID, inventory.UpsertPortParams{
+				SessionID: sessionID,
+				Protocol:  p.Protocol,
+				Port:      int64(p.PortID),
+				State:     p.State.State,
+				Service:   p.Service.Name,
+				Product:   p.Service.Product,
+				Version:   p.Service.Version,
+				ExtraInfo: p.Service.ExtraInfo,
+				Scripts:   scripts,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to record the port %d/%s of %s: %w", p.PortID, p.Protocol, host.Address, err)
+			}
+			host.Ports = append(host.Ports, port)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// nmapCommand builds the nmap command line out of the arguments, rejecting anything nmap could take for an option.
+func nmapCommand(args NmapScanArgs) ([]string, error) {
+	if len(args.Targets) == 0 {
+		return nil, fmt.Errorf("targets are required")
+	}
+	for _, target := range args.Targets {
+		if !nmapTargetPattern.MatchString(target) {
+			return nil, fmt.Errorf("invalid target: %q", target)
+		}
+	}
+
+	cmd := []string{"nmap", "-oX", "-", "--noninteractive"}
+	scanType := args.ScanType
+	if scanType == "" {
+		scanType = scanTypeSyn
+	}
+	flag, ok := nmapScanTypes[scanType]
+	if !ok {
+		return nil, fmt.Errorf("invalid scan_type: %q", args.ScanType)
+	}
+	cmd = append(cmd, flag)
+
+	if scanType != scanTypePing {
+		switch {
+		case args.Ports != "" && args.TopPorts > 0:
+			return nil, fmt.Errorf("ports and top_ports are mutually exclusive")
+		case args.Ports != "":
+			if !nmapPortsPattern.MatchString(args.Ports) {
+				return nil, fmt.Errorf("invalid ports: %q", args.Ports)
+			}
+			cmd = append(cmd, "-p", args.Ports)
+		case args.TopPorts > 0:
+			cmd = append(cmd, "--top-ports", strconv.Itoa(args.TopPorts))
+		}
+		if args.ServiceVersion {
+			cmd = append(cmd, "-sV")
+		}
+	}
+	if args.OSDetection {
+		cmd = append(cmd, "-O")
+	}
+	if len(args.Scripts) > 0 {
+		for _, script := range args.Scripts {
+			if !nmapScriptPattern.MatchString(script) {
+				return nil, fmt.Errorf("invalid script: %q", script)
+			}
+		}
+		cmd = append(cmd, "--script", strings.Join(args.Scripts, ","))
+	}
+	if args.Timing != nil {
+		if *args.Timing < 0 || *args.Timing > 5 {
+			return nil, fmt.Errorf("timing must be between 0 and 5")
+		}
+		cmd = append(cmd, fmt.Sprintf("-T%d", *args.Timing))
+	}
+	return append(cmd, args.Targets...), nil
+}
+
+// nmapSummary sums the results up for the agents: the hosts up, their open ports and the scripts output.
+func nmapSummary(run nmapRun) string {
+	var sb strings.Builder
+	up := 0
+	for _, h := range run.Hosts {
+		if h.Status.State != "up" {
+			continue
+		}
+		up++
+		sb.WriteString(h.address())
+		if hostnames := h.hostnames(); len(hostnames) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(hostnames, ", "))
+		}
+		sb.WriteString(" up")
+		if guess := h.os(); guess != "" {
+			fmt.Fprintf(&sb, ", os: %s", guess)
+		}
+		sb.WriteString("\n")
+
+		closed := 0
+		for _, p := range h.Ports {
+			if !strings.HasPrefix(p.State.State, "open") {
+				closed++
+				continue
+			}
+			line := strings.Join(strings.Fields(strings.Join([]string{
+				fmt.Sprintf("%d/%s", p.PortID, p.Protocol),
+				p.State.State,
+				p.Service.Name,
+				p.Service.Product,
+				p.Service.Version,
+				p.Service.ExtraInfo,
+			}, " ")), " ")
+			fmt.Fprintf(&sb, "  %s\n", line)
+			for _, s := range p.Scripts {
+				fmt.Fprintf(&sb, "    %s: %s\n", s.ID, compactText(s.Output, maxScriptOutput))
+			}
+		}
+		if closed > 0 {
+			fmt.Fprintf(&sb, "  not shown: %d closed or filtered ports\n", closed)
+		}
+		for _, s := range h.Scripts {
+			fmt.Fprintf(&sb, "  %s: %s\n", s.ID, compactText(s.Output, maxScriptOutput))
+		}
+	}
+	fmt.Fprintf(&sb, "%d of %d hosts up", up, len(run.Hosts))
+	if run.Stats.Finished.Elapsed != "" {
+		fmt.Fprintf(&sb, ", scanned in %ss", run.Stats.Finished.Elapsed)
+	}
+	return sb.String()
+}
+
+// compactText joins the lines of the text, truncating it past maxLength.
+func compactText(text string, maxLength int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxLength {
+		return truncateText(text, maxLength) + "..."
+	}
+	return text
+}
+
+// nmapRun is the part of nmap's XML output the inventory is built from.
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+	Stats struct {
+		Finished struct {
+			Elapsed string `xml:"elapsed,attr"`
+		} `xml:"finished"`
+	} `xml:"runstats"`
+}
+
+type nmapHost struct {
+	Status struct {
+		State string `xml:"state,attr"`
+	} `xml:"status"`
+	Addresses []struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"hostnames>hostname"`
+	Ports   []nmapPort `xml:"ports>port"`
+	OSMatch []struct {
+		Name     string `xml:"name,attr"`
+		Accuracy string `xml:"accuracy,attr"`
+	} `xml:"os>osmatch"`
+	Scripts []nmapScript `xml:"hostscript>script"`
+}
+
+type nmapPort struct {
+	Protocol string `xml:"protocol,attr"`
+	PortID   int    `xml:"portid,attr"`
+	State    struct {
+		State string `xml:"state,attr"`
+	} `xml:"state"`
+	Service struct {
+		Name      string `xml:"name,attr"`
+		Product   string `xml:"product,attr"`
+		Version   string `xml:"version,attr"`
+		ExtraInfo string `xml:"extrainfo,attr"`
+	} `xml:"service"`
+	Scripts []nmapScript `xml:"script"`
+}
+
+type nmapScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+// address prefers the ip address of the host over its mac address.
+func (h nmapHost) address() string {
+	for _, a := range h.Addresses {
+		if a.AddrType != "mac" {
+			return a.Addr
+		}
+	}
+	if len(h.Addresses) > 0 {
+		return h.Addresses[0].Addr
+	}
+	return ""
+}
+
+func (h nmapHost) hostnames() []string {
+	var hostnames []string
+	for _, hn := range h.Hostnames {
+		if hn.Name != "" && !slices.Contains(hostnames, hn.Name) {
+			hostnames = append(hostnames, hn.Name)
+		}
+	}
+	return hostnames
+}
+
+// os returns the best guess of the operating system of the host, if any.
+func (h nmapHost) os() string {
+	if len(h.OSMatch) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s%%)", h.OSMatch[0].Name, h.OSMatch[0].Accuracy)
+}
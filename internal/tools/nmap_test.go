@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+func TestNmapScanScope(t *testing.T) {
+	setTestScope(t, config.Scope{Include: []string{"10.0.0.0/24", "*.corp.test"}, Exclude: []string{"10.0.0.13"}})
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "s1")
+
+	// NOTE: the targets are all refused before nmap is run.
+	tests := []struct {
+		name            string
+		targets         []string
+		expectedContent string
+	}{
+		{name: "one target out of scope", targets: []string{"10.0.0.5", "10.0.1.5"}, expectedContent: "out of scope: 10.0.1.5"},
+		{name: "network over an excluded address", targets: []string{"10.0.0.0/28"}, expectedContent: "out of scope: 10.0.0.13 of 10.0.0.0/28"},
+		{name: "range over an excluded address", targets: []string{"10.0.0.10-15"}, expectedContent: "out of scope: 10.0.0.13 of 10.0.0.10-15"},
+		{name: "host out of scope", targets: []string{"corp.test"}, expectedContent: "out of scope: corp.test"},
+		{name: "network too large", targets: []string{"10.0.0.0/8"}, expectedContent: "too large"},
+		{name: "target taken for an option", targets: []string{"-iL/etc/hosts"}, expectedContent: "invalid target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := json.Marshal(NmapScanArgs{Targets: tt.targets})
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := NewNmapScanTool(nil).Run(ctx, ToolCall{Input: string(input)})
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if !response.IsError || !strings.Contains(response.Content, tt.expectedContent) {
+				t.Errorf("Run(%v) = %q, expected an error containing %q", tt.targets, response.Content, tt.expectedContent)
+			}
+		})
+	}
+}
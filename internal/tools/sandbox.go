@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+// SandboxWorkdir is the engagement directory within the sandbox. The host's Data.BindMount is mounted there in the sandboxes
+// of the engagements, created by tandem, while the one created during the installation has to be run with the mount.
+const SandboxWorkdir = "/engagement"
+
+// NOTE: the sandbox is the container of the DockerImage shared by every tool running inside of it,
+// the one of the engagement when an engagement is opened.
+var (
+	sandboxContainerId string
+	sandboxMu          sync.Mutex
+)
+
+// sandboxContainer looks up the sandbox container and gets it running.
+func sandboxContainer(ctx context.Context) (string, error) {
+	sandboxMu.Lock()
+	defer sandboxMu.Unlock()
+
+	if dockerCli == nil {
+		NewDockerCli()
+	}
+	if err := dockerCli.initialise(); err != nil {
+		return "", err
+	}
+	cli := dockerCli.client
+
+	if name := config.Get().Sandbox; sandboxContainerId == "" && name != "" {
+		id, err := engagementSandbox(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		sandboxContainerId = id
+	}
+
+	if sandboxContainerId == "" {
+		summaries, err := cli.ContainerList(ctx, container.ListOptions{
+			All:     true,
+			Filters: filters.NewArgs(filters.Arg("ancestor", DockerImage)),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		for _, summary := range summaries {
+			if summary.Image == DockerImage && summary.State == container.StateRunning {
+				sandboxContainerId = summary.ID
+				break
+			}
+
+			if summary.Image == DockerImage {
+				sandboxContainerId = summary.ID
+			}
+		}
+	}
+
+	// NOTE: we are not creating a container if not found in the summaries because it should be created during the installation.
+	if sandboxContainerId == "" {
+		return "", fmt.Errorf("couldn't find a container using %s image", DockerImage)
+	}
+
+	inspectRes, err := cli.ContainerInspect(ctx, sandboxContainerId)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	if !inspectRes.State.Running {
+		if err := dockerCli.GetRunning(ctx, sandboxContainerId, inspectRes.State.Status); err != nil {
+			return "", fmt.Errorf("couldn't get the container: %s running", sandboxContainerId)
+		}
+	}
+	return sandboxContainerId, nil
+}
+
+// engagementSandbox looks up the sandbox container of the engagement by its name, creating it from the DockerImage
+// with the engagement directory mounted at SandboxWorkdir if it doesn't exist yet.
+func engagementSandbox(ctx context.Context, name string) (string, error) {
+	cli := dockerCli.client
+	inspect, err := cli.ContainerInspect(ctx, name)
+	if err == nil {
+		return inspect.ID, nil
+	}
+	if !cerrdefs.IsNotFound(err) {
+		return "", fmt.Errorf("failed to inspect container %s: %w", name, err)
+	}
+
+	dir := config.EngagementDirectory()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	// NOTE: the shell is kept running with a tty and an open stdin, as for the container created during the installation.
+	created, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:      DockerImage,
+		Cmd:        []string{"/bin/bash"},
+		Tty:        true,
+		OpenStdin:  true,
+		WorkingDir: SandboxWorkdir,
+		Labels:     map[string]string{"tandem.engagement": config.Get().Engagement},
+	}, &container.HostConfig{
+		Binds: []string{dir + ":" + SandboxWorkdir},
+	}, nil, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container %s from %s image: %w", name, DockerImage, err)
+	}
+	return created.ID, nil
+}
+
+// StopSandbox stops the sandbox container by its name, if it exists.
+func StopSandbox(ctx context.Context, name string) error {
+	if dockerCli == nil {
+		NewDockerCli()
+	}
+	if err := dockerCli.initialise(); err != nil {
+		return err
+	}
+	if err := dockerCli.client.ContainerStop(ctx, name, container.StopOptions{}); err != nil && !cerrdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to stop container %s: %w", name, err)
+	}
+	return nil
+}
+
+// sandboxPath resolves the path, relative to the engagement directory of the sandbox if not absolute, and keeps it within.
+func sandboxPath(ctx context.Context, p string) (string, error) {
+	if !path.IsAbs(p) {
+		p = path.Join(SandboxWorkdir, p)
+	}
+	p = path.Clean(p)
+	if !inSandboxWorkdir(p) {
+		return "", fmt.Errorf("%s is outside of the engagement directory %s", p, SandboxWorkdir)
+	}
+	// NOTE: following the symlinks, if any, within the sandbox so that they can't lead out of the engagement directory.
+	resolved, err := sandboxExec(ctx, "realpath", "-m", "--", p)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", p, err)
+	}
+	resolved = strings.TrimSuffix(resolved, "\n")
+	if !inSandboxWorkdir(resolved) {
+		return "", fmt.Errorf("%s leads to %s, outside of the engagement directory %s", p, resolved, SandboxWorkdir)
+	}
+	return resolved, nil
+}
+
+func inSandboxWorkdir(p string) bool {
+	return p == SandboxWorkdir || strings.HasPrefix(p, SandboxWorkdir+"/")
+}
+
+// hostPath resolves the path, relative to the engagement directory of the host if not absolute, and keeps it within.
+func hostPath(p string) (string, error) {
+	dir := config.EngagementDirectory()
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	p = filepath.Clean(p)
+	// NOTE: following the symlinks, if any, so that they can't lead out of the engagement directory.
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err == nil {
+		if dir, err = filepath.EvalSymlinks(dir); err != nil {
+			return "", err
+		}
+		p = resolved
+	}
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of the engagement directory %s", p, dir)
+	}
+	return p, nil
+}
+
+// sandboxExec runs the command in the sandbox and returns its output, failing if it exits with a non zero code.
+func sandboxExec(ctx context.Context, cmd ...string) (string, error) {
+	return sandboxExecInput(ctx, nil, cmd...)
+}
+
+// sandboxExecInput runs the command in the sandbox like sandboxExec, writing the input, if any, to its standard input.
+func sandboxExecInput(ctx context.Context, input []byte, cmd ...string) (string, error) {
+	containerId, err := sandboxContainer(ctx)
+	if err != nil {
+		return "", err
+	}
+	cli := Client()
+	exec, err := cli.ContainerExecCreate(ctx, containerId, container.ExecOptions{
+		AttachStdin:  input != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+	resp, err := cli.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer resp.Close()
+	if input != nil {
+		if _, err := resp.Conn.Write(input); err != nil {
+			return "", fmt.Errorf("failed to write the input of %s: %w", cmd[0], err)
+		}
+		if err := resp.CloseWrite(); err != nil {
+			return "", fmt.Errorf("failed to write the input of %s: %w", cmd[0], err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read the output of %s: %w", cmd[0], err)
+	}
+	inspect, err := cli.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return stdout.String(), fmt.Errorf("%s exited with code %d: %s", cmd[0], inspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// readSandboxFile copies a regular file of the sandbox out of it, as long as it's at most maxSize bytes.
+func readSandboxFile(ctx context.Context, p string, maxSize int64) ([]byte, error) {
+	containerId, err := sandboxContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cli := Client()
+	stat, err := cli.ContainerStatPath(ctx, containerId, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", p, err)
+	}
+	if !stat.Mode.IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", p)
+	}
+	if stat.Size > maxSize {
+		return nil, fmt.Errorf("%s is %d bytes, over the limit of %d bytes", p, stat.Size, maxSize)
+	}
+
+	content, _, err := cli.CopyFromContainer(ctx, containerId, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy %s: %w", p, err)
+	}
+	defer content.Close()
+	archive := tar.NewReader(content)
+	header, err := archive.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy %s: %w", p, err)
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("%s is not a regular file", p)
+	}
+	data, err := io.ReadAll(io.LimitReader(archive, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy %s: %w", p, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%s is over the limit of %d bytes", p, maxSize)
+	}
+	return data, nil
+}
+
+// writeSandboxFile copies the data into a file of the sandbox, creating its parent directories.
+func writeSandboxFile(ctx context.Context, p string, data []byte) error {
+	containerId, err := sandboxContainer(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := sandboxExec(ctx, "mkdir", "-p", path.Dir(p)); err != nil {
+		return err
+	}
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Base(p),
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := Client().CopyToContainer(ctx, containerId, path.Dir(p), &archive, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy into %s: %w", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+// maxScopeTargetSize bounds the addresses of a network or range checked one by one against the scope.
+const maxScopeTargetSize = 1 << 16
+
+// ErrOutOfScope is returned for the targets the scope of the engagement doesn't contain.
+var ErrOutOfScope = errors.New("out of scope")
+
+// checkScope checks the host against the scope of the engagement, along with the addresses it resolves to.
+// NOTE: nothing is within the scope until one is configured, the RoE itself being free form.
+func checkScope(ctx context.Context, host string) error {
+	scope := config.Get().Scope
+	if len(scope.Include) == 0 {
+		return fmt.Errorf("%w: %s, no scope is configured for the engagement", ErrOutOfScope, host)
+	}
+	var addrs []netip.Addr
+	if _, err := netip.ParseAddr(strings.Trim(host, "[]")); err != nil {
+		// NOTE: a host which doesn't resolve, e.g. one only reachable through a proxy, is checked by its name only.
+		addrs, _ = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	}
+	if !scope.Contains(host, addrs...) {
+		return fmt.Errorf("%w: %s", ErrOutOfScope, host)
+	}
+	return nil
+}
+
+// checkScopeTargets checks the targets of a scanner against the scope of the engagement. Besides the hosts,
+// the targets may be CIDRs or ranges of addresses such as 10.0.0.1-20, every address of which has to be within it.
+func checkScopeTargets(ctx context.Context, targets ...string) error {
+	for _, target := range targets {
+		addrs, err := expandScopeTarget(target)
+		if err != nil {
+			return err
+		}
+		if addrs == nil {
+			if err := checkScope(ctx, target); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, addr := range addrs {
+			if err := checkScope(ctx, addr.String()); err != nil {
+				return fmt.Errorf("%w: %s of %s", ErrOutOfScope, addr, target)
+			}
+		}
+	}
+	return nil
+}
+
+// expandScopeTarget returns the addresses of the network or range, or nil for a single host.
+func expandScopeTarget(target string) ([]netip.Addr, error) {
+	var first, last netip.Addr
+	if prefix, err := netip.ParsePrefix(target); err == nil {
+		prefix = prefix.Masked()
+		if prefix.Addr().BitLen()-prefix.Bits() > 16 {
+			return nil, fmt.Errorf("%s is too large to be checked against the scope, split it up", target)
+		}
+		first = prefix.Addr()
+		last = first
+		for next := first.Next(); next.IsValid() && prefix.Contains(next); next = next.Next() {
+			last = next
+		}
+	} else if from, to, ok := strings.Cut(target, "-"); ok && isAddr(from) {
+		first = netip.MustParseAddr(from)
+		if last, err = netip.ParseAddr(to); err != nil {
+			// NOTE: 10.0.0.1-20 ranges over the last byte of the address.
+			n, convErr := strconv.ParseUint(to, 10, 8)
+			if convErr != nil || !first.Is4() {
+				return nil, fmt.Errorf("invalid target: %s", target)
+			}
+			b := first.As4()
+			b[3] = byte(n)
+			last = netip.AddrFrom4(b)
+		}
+		if last.Less(first) || first.BitLen() != last.BitLen() {
+			return nil, fmt.Errorf("invalid target: %s", target)
+		}
+	} else {
+		return nil, nil
+	}
+
+	var addrs []netip.Addr
+	for addr := first; addr.IsValid() && !last.Less(addr); addr = addr.Next() {
+		if len(addrs) == maxScopeTargetSize {
+			return nil, fmt.Errorf("%s is too large to be checked against the scope, split it up", target)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func isAddr(s string) bool {
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
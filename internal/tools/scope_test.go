@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/config"
+)
+
+// setTestScope loads the default configuration, without any config file, along with the scope given.
+func setTestScope(t *testing.T, scope config.Scope) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() failed: %v", err)
+	}
+	previous := cfg.Scope
+	cfg.Scope = scope
+	t.Cleanup(func() { cfg.Scope = previous })
+}
+
+func TestCheckScopeTargets(t *testing.T) {
+	scope := config.Scope{
+		Include: []string{"10.0.0.0/24", "*.corp.test"},
+		Exclude: []string{"10.0.0.13", "vpn.corp.test"},
+	}
+
+	tests := []struct {
+		name    string
+		scope   config.Scope
+		targets []string
+		// expectOutOfScope is set for the targets out of the scope, any other error being for the invalid ones.
+		expectOutOfScope bool
+		expectErr        bool
+	}{
+		{name: "address in scope", scope: scope, targets: []string{"10.0.0.5"}},
+		{name: "subdomain in scope", scope: scope, targets: []string{"app.corp.test"}},
+		{name: "network in scope", scope: scope, targets: []string{"10.0.0.0/29"}},
+		{name: "range in scope", scope: scope, targets: []string{"10.0.0.1-12"}},
+		{name: "address out of scope", scope: scope, targets: []string{"10.0.0.5", "10.0.1.5"}, expectOutOfScope: true},
+		{name: "address excluded", scope: scope, targets: []string{"10.0.0.13"}, expectOutOfScope: true},
+		{name: "subdomain excluded", scope: scope, targets: []string{"vpn.corp.test"}, expectOutOfScope: true},
+		{name: "network over an excluded address", scope: scope, targets: []string{"10.0.0.8/29"}, expectOutOfScope: true},
+		{name: "range over an excluded address", scope: scope, targets: []string{"10.0.0.10-20"}, expectOutOfScope: true},
+		{name: "range beyond the scope", scope: scope, targets: []string{"10.0.0.250-10.0.1.2"}, expectOutOfScope: true},
+		{name: "no scope configured", targets: []string{"10.0.0.5"}, expectOutOfScope: true},
+		{name: "network too large", scope: scope, targets: []string{"10.0.0.0/8"}, expectErr: true},
+		{name: "range backwards", scope: scope, targets: []string{"10.0.0.20-10"}, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestScope(t, tt.scope)
+			err := checkScopeTargets(context.Background(), tt.targets...)
+			if outOfScope := errors.Is(err, ErrOutOfScope); outOfScope != tt.expectOutOfScope {
+				t.Errorf("checkScopeTargets(%v) = %v, expected out of scope %v", tt.targets, err, tt.expectOutOfScope)
+			}
+			if (err != nil) != (tt.expectOutOfScope || tt.expectErr) {
+				t.Errorf("checkScopeTargets(%v) = %v, expected error %v", tt.targets, err, tt.expectOutOfScope || tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yaydraco/tandem/internal/message"
+)
+
+const (
+	SearchHistoryToolName = "search_history"
+
+	defaultSearchResults = 20
+	maxSearchResults     = 50
+)
+
+type SearchHistoryArgs struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type searchHistoryTool struct {
+	messages message.Service
+}
+
+// NewSearchHistoryTool returns the tool searching the messages and tool results of the earlier sessions of the engagement.
+func NewSearchHistoryTool(messages message.Service) BaseTool {
+	return &searchHistoryTool{messages: messages}
+}
+
+func (t *searchHistoryTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        SearchHistoryToolName,
+		Description: "Searches the messages and tool results of every session of the engagement, the task sessions of the agents included, to recall what was found or done earlier, e.g. the credentials of a service or the output of a scan of a host. returns the matching excerpts, the best matches first, along with the sessions they're in. the current session isn't searched.",
+		Parameters: map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "words the messages must all hold, e.g. tomcat manager password. a word ending with * matches the words it prefixes",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("maximum number of results. defaults to %d, at most %d", defaultSearchResults, maxSearchResults),
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+func (t *searchHistoryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args SearchHistoryArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse search_history parameters: " + err.Error()), nil
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return NewTextErrorResponse("query is required"), nil
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	limit = min(limit, maxSearchResults)
+
+	sessionID, _ := GetContextValues(ctx)
+	// NOTE: one more result is searched for in case one of them is of the current session, which is left out.
+	found, err := t.messages.Search(ctx, args.Query, limit+1)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to search the history: %w", err)
+	}
+	var results []message.SearchResult
+	for _, r := range found {
+		if r.SessionID != sessionID && len(results) < limit {
+			results = append(results, r)
+		}
+	}
+	if len(results) == 0 {
+		return NewTextResponse("no matches"), nil
+	}
+
+	var sb strings.Builder
+	for _, r := range results {
+		session := fmt.Sprintf("%q", r.SessionTitle)
+		if r.SessionID != r.RootSessionID {
+			session += fmt.Sprintf(" (task of %q)", r.RootSessionTitle)
+		}
+		fmt.Fprintf(&sb, "[%s] %s, session %s, %s message:\n  %s\n",
+			time.Unix(r.CreatedAt, 0).UTC().Format(time.DateTime), session, r.SessionID, r.Role, r.PlainSnippet())
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), results), nil
+}
@@ -0,0 +1,312 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/pubsub"
+)
+
+const (
+	// maxTranscriptSize bounds the output kept for every shell session, the oldest output is dropped first.
+	maxTranscriptSize = 1 << 20
+	// shellIdleTime is how long the output has to stay quiet for a read without expect to return.
+	shellIdleTime = 500 * time.Millisecond
+
+	defaultShellRows = 40
+	defaultShellCols = 160
+)
+
+var ErrShellSessionNotFound = errors.New("shell session not found")
+
+// ShellSessionEvent is published whenever a shell session is opened, outputs or closes.
+type ShellSessionEvent struct {
+	Name   string
+	Output string
+}
+
+// ShellSession is a named PTY session running inside the sandbox. It outlives the tool calls
+// so that stateful and interactive programs can be driven across the agent turns, and the operator can attach to it.
+type ShellSession struct {
+	Name      string
+	Command   string
+	CreatedAt time.Time
+
+	execID  string
+	conn    types.HijackedResponse
+	manager *ShellSessionManager
+
+	mu         sync.Mutex
+	transcript []byte
+	// dropped is the count of the oldest output bytes dropped from the transcript.
+	dropped int
+	// cursor is the offset of the output not read by the agents yet.
+	cursor  int
+	closed  bool
+	updated chan struct{}
+}
+
+// Write sends the input to the session as if typed in.
+func (s *ShellSession) Write(input []byte) error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return fmt.Errorf("shell session %s is closed", s.Name)
+	}
+	_, err := s.conn.Conn.Write(input)
+	return err
+}
+
+// Read returns the output of the session not read yet, waiting until it matches expect, if any,
+// or otherwise until the output goes quiet. It gives up waiting after the timeout, returning what's been output meanwhile.
+func (s *ShellSession) Read(ctx context.Context, expect *regexp.Regexp, timeout time.Duration) (output string, matched bool, err error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		s.mu.Lock()
+		output = CleanTerminalOutput(string(s.transcript[s.cursor:]))
+		matched = expect != nil && expect.MatchString(output)
+		closed, updated := s.closed, s.updated
+		s.mu.Unlock()
+
+		if matched || closed {
+			break
+		}
+		var idle <-chan time.Time
+		if expect == nil {
+			idle = time.After(shellIdleTime)
+		}
+		select {
+		case <-updated:
+			continue
+		case <-idle:
+		case <-deadline.C:
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+		break
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	output = CleanTerminalOutput(string(s.transcript[s.cursor:]))
+	s.cursor = len(s.transcript)
+	return output, expect != nil && expect.MatchString(output), nil
+}
+
+// Transcript returns the whole output of the session kept so far.
+func (s *ShellSession) Transcript() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.transcript)
+}
+
+// Offset returns the count of bytes output by the session so far.
+func (s *ShellSession) Offset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped + len(s.transcript)
+}
+
+// Since returns the raw output of the session from the offset on, as far as it's still kept.
+func (s *ShellSession) Since(offset int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return string(s.transcript[min(max(0, offset-s.dropped), len(s.transcript)):])
+}
+
+func (s *ShellSession) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Resize resizes the PTY of the session, e.g. to the size of the operator's terminal.
+func (s *ShellSession) Resize(ctx context.Context, rows, cols uint) error {
+	return Client().ContainerExecResize(ctx, s.execID, container.ResizeOptions{Height: rows, Width: cols})
+}
+
+func (s *ShellSession) readLoop() {
+	defer logging.RecoverPanic("shell-session-"+s.Name, nil)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.conn.Reader.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			s.transcript = append(s.transcript, buf[:n]...)
+			if overflow := len(s.transcript) - maxTranscriptSize; overflow > 0 {
+				s.transcript = s.transcript[overflow:]
+				s.dropped += overflow
+				s.cursor = max(0, s.cursor-overflow)
+			}
+			if !s.closed {
+				close(s.updated)
+				s.updated = make(chan struct{})
+			}
+			s.mu.Unlock()
+			s.manager.Publish(pubsub.UpdatedEvent, ShellSessionEvent{Name: s.Name, Output: string(buf[:n])})
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				logging.Debug("shell session read failed", "name", s.Name, "error", err)
+			}
+			s.manager.remove(s)
+			return
+		}
+	}
+}
+
+// ShellSessionManager keeps track of the shell sessions opened within the sandbox.
+type ShellSessionManager struct {
+	*pubsub.Broker[ShellSessionEvent]
+
+	mu       sync.Mutex
+	sessions map[string]*ShellSession
+}
+
+// ShellSessions are shared by every agent and the TUI.
+var ShellSessions = &ShellSessionManager{
+	Broker:   pubsub.NewBroker[ShellSessionEvent](),
+	sessions: make(map[string]*ShellSession),
+}
+
+// Open starts the command in a new PTY session within the sandbox.
+func (m *ShellSessionManager) Open(ctx context.Context, name, command string) (*ShellSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[name]; ok {
+		return nil, fmt.Errorf("shell session %s is already open", name)
+	}
+
+	containerId, err := sandboxContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cli := Client()
+	exec, err := cli.ContainerExecCreate(ctx, containerId, container.ExecOptions{
+		Tty:          true,
+		ConsoleSize:  &[2]uint{defaultShellRows, defaultShellCols},
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm-256color"},
+		Cmd:          []string{"/bin/bash", "-c", command},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+	// NOTE: the session outlives the tool call, thus not attaching with its context.
+	conn, err := cli.ContainerExecAttach(context.Background(), exec.ID, container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: &[2]uint{defaultShellRows, defaultShellCols},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+
+	session := &ShellSession{
+		Name:      name,
+		Command:   command,
+		CreatedAt: time.Now(),
+		execID:    exec.ID,
+		conn:      conn,
+		manager:   m,
+		updated:   make(chan struct{}),
+	}
+	m.sessions[name] = session
+	go session.readLoop()
+	m.Publish(pubsub.CreatedEvent, ShellSessionEvent{Name: name})
+	return session, nil
+}
+
+func (m *ShellSessionManager) Get(name string) (*ShellSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrShellSessionNotFound, name)
+	}
+	return session, nil
+}
+
+// List returns the open shell sessions, oldest first.
+func (m *ShellSessionManager) List() []*ShellSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sessions := make([]*ShellSession, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	slices.SortFunc(sessions, func(a, b *ShellSession) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return sessions
+}
+
+// Close ends the shell session along with the command running in it.
+func (m *ShellSessionManager) Close(name string) error {
+	session, err := m.Get(name)
+	if err != nil {
+		return err
+	}
+	session.conn.Close()
+	m.remove(session)
+	return nil
+}
+
+func (m *ShellSessionManager) remove(session *ShellSession) {
+	session.mu.Lock()
+	if session.closed {
+		session.mu.Unlock()
+		return
+	}
+	session.closed = true
+	close(session.updated)
+	session.mu.Unlock()
+
+	m.mu.Lock()
+	if m.sessions[session.Name] == session {
+		delete(m.sessions, session.Name)
+	}
+	m.mu.Unlock()
+	m.Publish(pubsub.DeletedEvent, ShellSessionEvent{Name: session.Name})
+}
+
+// CleanTerminalOutput turns the raw output of a PTY into plain text: the escape sequences are stripped
+// and the lines overwritten by carriage returns or backspaces only keep their final content.
+func CleanTerminalOutput(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(ansi.Strip(raw), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if j := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); j >= 0 {
+			line = line[j+1:]
+		}
+		line = strings.TrimRight(line, "\r")
+		if strings.Contains(line, "\b") {
+			var runes []rune
+			for _, r := range line {
+				if r == '\b' {
+					if len(runes) > 0 {
+						runes = runes[:len(runes)-1]
+					}
+					continue
+				}
+				runes = append(runes, r)
+			}
+			line = string(runes)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
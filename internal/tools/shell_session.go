@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ShellSessionOpenToolName  = "shell_session_open"
+	ShellSessionWriteToolName = "shell_session_write"
+	ShellSessionReadToolName  = "shell_session_read"
+	ShellSessionCloseToolName = "shell_session_close"
+	// OperatorShellToolName names the tool calls recording the commands the operator typed into the shell sessions.
+	OperatorShellToolName = "operator_shell"
+
+	defaultShellTimeout = 10 * time.Second
+	maxShellTimeout     = 5 * time.Minute
+	// maxShellOutput bounds the output returned to the agent, keeping the latest.
+	maxShellOutput = 30000
+)
+
+type ShellSessionOpenArgs struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty"`
+	Expect  string `json:"expect,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+type ShellSessionWriteArgs struct {
+	Name    string `json:"name"`
+	Input   string `json:"input"`
+	Enter   *bool  `json:"enter,omitempty"`
+	Expect  string `json:"expect,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+type ShellSessionReadArgs struct {
+	Name    string `json:"name"`
+	Expect  string `json:"expect,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+type ShellSessionCloseArgs struct {
+	Name string `json:"name"`
+}
+
+type OperatorShellArgs struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+var shellSessionWaitParameters = map[string]any{
+	"expect": map[string]any{
+		"type":        "string",
+		"description": "regular expression to wait for in the output, e.g. the prompt of the program. without it, the output is returned once it goes quiet.",
+	},
+	"timeout": map[string]any{
+		"type":        "integer",
+		"description": fmt.Sprintf("seconds to wait for the output before returning what's been output so far. defaults to %d, at most %d.", int(defaultShellTimeout.Seconds()), int(maxShellTimeout.Seconds())),
+	},
+}
+
+func shellSessionParameters(parameters map[string]any) map[string]any {
+	for name, parameter := range shellSessionWaitParameters {
+		parameters[name] = parameter
+	}
+	return parameters
+}
+
+type shellSessionOpenTool struct{}
+
+type shellSessionWriteTool struct{}
+
+type shellSessionReadTool struct{}
+
+type shellSessionCloseTool struct{}
+
+// NewShellSessionTools returns the tools to drive the interactive programs through the shell sessions of the sandbox.
+func NewShellSessionTools() []BaseTool {
+	return []BaseTool{
+		&shellSessionOpenTool{},
+		&shellSessionWriteTool{},
+		&shellSessionReadTool{},
+		&shellSessionCloseTool{},
+	}
+}
+
+func (t *shellSessionOpenTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ShellSessionOpenToolName,
+		Description: "Opens a named interactive PTY session in the docker container, running a long-lived or stateful program such as msfconsole, evil-winrm or a reverse shell listener. the session persists until closed and is shared with the other agents and the operator.",
+		Parameters: shellSessionParameters(map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "unique name of the session, e.g. msf or listener-4444",
+			},
+			"command": map[string]any{
+				"type":        "string",
+				"description": "command to run in the session. defaults to bash",
+			},
+		}),
+		Required: []string{"name"},
+	}
+}
+
+func (t *shellSessionOpenTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ShellSessionOpenArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse shell session parameters: " + err.Error()), nil
+	}
+	if args.Name == "" {
+		return NewTextErrorResponse("name is required"), nil
+	}
+	if args.Command == "" {
+		args.Command = "bash"
+	}
+	expect, timeout, err := shellSessionWait(args.Expect, args.Timeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	session, err := ShellSessions.Open(ctx, args.Name, args.Command)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to open shell session: %s. open sessions: %s", err, shellSessionNames())), nil
+	}
+	return shellSessionRead(ctx, session, expect, timeout)
+}
+
+func (t *shellSessionWriteTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ShellSessionWriteToolName,
+		Description: "Types the input into a shell session and returns the output it produces. control characters can be sent as escapes, e.g. \\u0003 for ctrl+c.",
+		Parameters: shellSessionParameters(map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "name of the session",
+			},
+			"input": map[string]any{
+				"type":        "string",
+				"description": "input to type into the session",
+			},
+			"enter": map[string]any{
+				"type":        "boolean",
+				"description": "whether to press enter after the input. defaults to true",
+			},
+		}),
+		Required: []string{"name", "input"},
+	}
+}
+
+func (t *shellSessionWriteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ShellSessionWriteArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse shell session parameters: " + err.Error()), nil
+	}
+	expect, timeout, err := shellSessionWait(args.Expect, args.Timeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	session, err := ShellSessions.Get(args.Name)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s. open sessions: %s", err, shellSessionNames())), nil
+	}
+
+	input := args.Input
+	if args.Enter == nil || *args.Enter {
+		input += "\n"
+	}
+	if err := session.Write([]byte(input)); err != nil {
+		return NewTextErrorResponse("failed to write to shell session: " + err.Error()), nil
+	}
+	return shellSessionRead(ctx, session, expect, timeout)
+}
+
+func (t *shellSessionReadTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ShellSessionReadToolName,
+		Description: "Reads the output of a shell session since it was last read, e.g. to wait for a long running command or a connection back to a listener.",
+		Parameters: shellSessionParameters(map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "name of the session",
+			},
+		}),
+		Required: []string{"name"},
+	}
+}
+
+func (t *shellSessionReadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ShellSessionReadArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse shell session parameters: " + err.Error()), nil
+	}
+	expect, timeout, err := shellSessionWait(args.Expect, args.Timeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	session, err := ShellSessions.Get(args.Name)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s. open sessions: %s", err, shellSessionNames())), nil
+	}
+	return shellSessionRead(ctx, session, expect, timeout)
+}
+
+func (t *shellSessionCloseTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ShellSessionCloseToolName,
+		Description: "Closes a shell session, ending the program running in it.",
+		Parameters: map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "name of the session",
+			},
+		},
+		Required: []string{"name"},
+	}
+}
+
+func (t *shellSessionCloseTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ShellSessionCloseArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse shell session parameters: " + err.Error()), nil
+	}
+	if err := ShellSessions.Close(args.Name); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return NewTextResponse(fmt.Sprintf("shell session %s closed", args.Name)), nil
+}
+
+func shellSessionWait(expect string, timeout int) (*regexp.Regexp, time.Duration, error) {
+	var expectRe *regexp.Regexp
+	if expect != "" {
+		var err error
+		if expectRe, err = regexp.Compile(expect); err != nil {
+			return nil, 0, fmt.Errorf("invalid expect pattern: %w", err)
+		}
+	}
+	wait := defaultShellTimeout
+	if timeout > 0 {
+		wait = min(time.Duration(timeout)*time.Second, maxShellTimeout)
+	}
+	return expectRe, wait, nil
+}
+
+func shellSessionRead(ctx context.Context, session *ShellSession, expect *regexp.Regexp, timeout time.Duration) (ToolResponse, error) {
+	output, matched, err := session.Read(ctx, expect, timeout)
+	if err != nil {
+		return NewTextErrorResponse("failed to read from shell session: " + err.Error()), nil
+	}
+	if len(output) > maxShellOutput {
+		tail := tailText(output, maxShellOutput)
+		output = fmt.Sprintf("[%d characters truncated]\n%s", len(output)-len(tail), tail)
+	}
+
+	var notes []string
+	if expect != nil && !matched {
+		notes = append(notes, fmt.Sprintf("timed out waiting for %q", expect.String()))
+	}
+	if session.Closed() {
+		notes = append(notes, "the session has ended")
+	}
+	if len(notes) > 0 {
+		output += "\n[" + strings.Join(notes, ", ") + "]"
+	}
+	return NewTextResponse(output), nil
+}
+
+func shellSessionNames() string {
+	var names []string
+	for _, session := range ShellSessions.List() {
+		names = append(names, session.Name)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
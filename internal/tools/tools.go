@@ -85,6 +85,8 @@ func GetContextValues(ctx context.Context) (string, string) {
 }
 
 // NOTE: concatenate it with the role specific tools
-var PenetrationTestingAgentTools = []BaseTool{
+var PenetrationTestingAgentTools = append([]BaseTool{
 	NewDockerCli(),
-}
+	NewHTTPRequestTool(),
+	NewMetasploitTool(),
+}, append(NewShellSessionTools(), NewFileTools()...)...)
@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/redact"
+	"github.com/yaydraco/tandem/internal/session"
+	"github.com/yaydraco/tandem/internal/vault"
+)
+
+const VaultToolName = "vault"
+
+type VaultArgs struct {
+	Action   string `json:"action"`
+	Ref      string `json:"ref,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Username string `json:"username,omitempty"`
+	Target   string `json:"target,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+type vaultTool struct {
+	vault    vault.Service
+	sessions session.Service
+	messages message.Service
+}
+
+// NewVaultTool returns the tool storing the credentials discovered during the engagement in the vault.
+func NewVaultTool(vault vault.Service, sessions session.Service, messages message.Service) BaseTool {
+	return &vaultTool{
+		vault:    vault,
+		sessions: sessions,
+		messages: messages,
+	}
+}
+
+func (t *vaultTool) Info() ToolInfo {
+	kinds := make([]string, len(vault.Kinds))
+	for i, kind := range vault.Kinds {
+		kinds[i] = string(kind)
+	}
+	return ToolInfo{
+		Name:        VaultToolName,
+		Description: "Stores the credentials discovered during the engagement (passwords, hashes, tokens, keys) in the encrypted vault, as soon as they're discovered, and gets or lists the ones stored. a stored secret is replaced by its placeholder, e.g. {{vault:ftp-admin}}, in the conversation and the logs. use the placeholder in the parameters of the other tools wherever the secret is needed, it's replaced by the secret when the tool runs. never write the secrets themselves in your responses.",
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "store a credential, get the stored credential of a reference, or list the stored ones",
+				"enum":        []string{"store", "get", "list"},
+			},
+			"ref": map[string]any{
+				"type":        "string",
+				"description": "reference of the credential, e.g. ftp-admin. letters, digits, dots, dashes and underscores. storing a credential of an existing reference replaces it",
+			},
+			"kind": map[string]any{
+				"type":        "string",
+				"description": "kind of the secret",
+				"enum":        kinds,
+			},
+			"username": map[string]any{
+				"type":        "string",
+				"description": "username the secret goes with, if any",
+			},
+			"target": map[string]any{
+				"type":        "string",
+				"description": "host, service or URL the credential is for, e.g. ftp://10.10.10.5:21",
+			},
+			"secret": map[string]any{
+				"type":        "string",
+				"description": "the secret to store",
+			},
+			"notes": map[string]any{
+				"type":        "string",
+				"description": "where the credential was found and what it grants",
+			},
+		},
+		Required: []string{"action"},
+	}
+}
+
+func (t *vaultTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args VaultArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse vault parameters: " + err.Error()), nil
+	}
+
+	switch args.Action {
+	case "store":
+		return t.store(ctx, args)
+	case "get":
+		credential, err := t.vault.Get(ctx, args.Ref)
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewTextErrorResponse("no credential stored for " + args.Ref), nil
+		}
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to get the credential: %w", err)
+		}
+		return WithResponseMetadata(NewTextResponse(formatCredential(credential)), credential), nil
+	case "list":
+		return t.list(ctx)
+	default:
+		return NewTextErrorResponse("invalid action: " + args.Action), nil
+	}
+}
+
+func (t *vaultTool) store(ctx context.Context, args VaultArgs) (ToolResponse, error) {
+	if args.Kind == "" {
+		args.Kind = string(vault.KindPassword)
+	}
+	sessionID, _ := GetContextValues(ctx)
+	credential, err := t.vault.Store(ctx, vault.StoreParams{
+		SessionID: sessionID,
+		Ref:       args.Ref,
+		Kind:      vault.Kind(args.Kind),
+		Username:  args.Username,
+		Target:    args.Target,
+		Secret:    args.Secret,
+		Notes:     args.Notes,
+	})
+	if err != nil {
+		return NewTextErrorResponse("failed to store the credential: " + err.Error()), nil
+	}
+
+	// NOTE: the secret went through the conversation before it was stored, it's redacted from what was persisted of it.
+	for id := sessionID; id != ""; {
+		if err := t.messages.RedactSessionMessages(ctx, id); err != nil {
+			logging.Warn("failed to redact the session messages", "session_id", id, "error", err)
+		}
+		if err := logging.RedactSessionLogFiles(id); err != nil {
+			logging.Warn("failed to redact the session log files", "session_id", id, "error", err)
+		}
+		s, err := t.sessions.Get(ctx, id)
+		if err != nil {
+			break
+		}
+		id = s.ParentSessionID
+	}
+	response := fmt.Sprintf("stored the credential %s, use %s in place of its secret", credential.Ref, credential.Placeholder)
+	if !redact.Redactable(args.Secret) {
+		response += ", which is too short to be redacted from the conversation"
+	}
+	return WithResponseMetadata(NewTextResponse(response), credential), nil
+}
+
+func (t *vaultTool) list(ctx context.Context) (ToolResponse, error) {
+	credentials, err := t.vault.List(ctx)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to list the credentials: %w", err)
+	}
+	if len(credentials) == 0 {
+		return NewTextResponse("no credentials stored in the vault"), nil
+	}
+	lines := make([]string, len(credentials))
+	for i, c := range credentials {
+		lines[i] = formatCredential(c)
+	}
+	return WithResponseMetadata(NewTextResponse(strings.Join(lines, "\n")), credentials), nil
+}
+
+func formatCredential(c vault.Credential) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s)", c.Placeholder, c.Kind)
+	if c.Username != "" {
+		fmt.Fprintf(&sb, " user %s", c.Username)
+	}
+	if c.Target != "" {
+		fmt.Fprintf(&sb, " for %s", c.Target)
+	}
+	if c.Notes != "" {
+		fmt.Fprintf(&sb, ": %s", c.Notes)
+	}
+	return sb.String()
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/vulndb"
+)
+
+const (
+	VulnLookupToolName = "vuln_lookup"
+
+	maxVulnerabilityDescription = 300
+)
+
+type VulnLookupArgs struct {
+	Product string `json:"product,omitempty"`
+	Vendor  string `json:"vendor,omitempty"`
+	Version string `json:"version,omitempty"`
+	CPE     string `json:"cpe,omitempty"`
+	CVE     string `json:"cve,omitempty"`
+}
+
+type vulnLookupTool struct {
+	vulnerabilities vulndb.Service
+}
+
+// NewVulnLookupTool returns the tool looking up the vulnerabilities of a product in the offline vulnerability database.
+func NewVulnLookupTool(vulnerabilities vulndb.Service) BaseTool {
+	return &vulnLookupTool{vulnerabilities: vulnerabilities}
+}
+
+func (t *vulnLookupTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        VulnLookupToolName,
+		Description: "Looks up the CVEs of a product and version in the offline vulnerability database, imported from the NVD, the CISA KEV catalog and Exploit-DB. returns the matching CVEs with their CVSS scores, whether they're known to be exploited and their known exploits, along with the exploits of Exploit-DB mentioning the product. prefer it over what you remember of the vulnerabilities. the exploits are copied into the docker container with searchsploit -m <EDB-ID>.",
+		Parameters: map[string]any{
+			"product": map[string]any{
+				"type":        "string",
+				"description": "product as named by nmap or its CPE, e.g. vsftpd, Apache httpd or http_server",
+			},
+			"vendor": map[string]any{
+				"type":        "string",
+				"description": "vendor of the product as named in its CPE, e.g. apache, to tell the products of the same name apart",
+			},
+			"version": map[string]any{
+				"type":        "string",
+				"description": "version of the product, e.g. 2.4.49. every version of the product if omitted",
+			},
+			"cpe": map[string]any{
+				"type":        "string",
+				"description": "CPE of the product instead, as reported by nmap, e.g. cpe:/a:apache:http_server:2.4.49",
+			},
+			"cve": map[string]any{
+				"type":        "string",
+				"description": "looks up the CVE instead, e.g. CVE-2021-41773",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *vulnLookupTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args VulnLookupArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse vuln_lookup parameters: " + err.Error()), nil
+	}
+
+	if args.CVE != "" {
+		v, err := t.vulnerabilities.Get(ctx, args.CVE)
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewTextErrorResponse(fmt.Sprintf("%s is not in the vulnerability database", args.CVE)), nil
+		}
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to look up %s: %w", args.CVE, err)
+		}
+		var sb strings.Builder
+		writeVulnerability(&sb, v, 0)
+		return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), v), nil
+	}
+
+	params := vulndb.LookupParams{
+		Vendor:  args.Vendor,
+		Product: args.Product,
+		Version: args.Version,
+	}
+	if args.CPE != "" {
+		var err error
+		if params, err = parseCPE(args.CPE); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+	if params.Product == "" {
+		return NewTextErrorResponse("product, cpe or cve is required"), nil
+	}
+	result, err := t.vulnerabilities.Lookup(ctx, params)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to look up the vulnerabilities: %w", err)
+	}
+	if len(result.Vulnerabilities) == 0 && len(result.Exploits) == 0 {
+		return NewTextResponse("no known vulnerabilities found in the vulnerability database. it may not have been imported with tandem vulndb import, or the product may go by another name"), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d CVEs of %s, known exploited and exploitable first:\n", len(result.Vulnerabilities), strings.TrimSpace(params.Product+" "+params.Version))
+	for _, v := range result.Vulnerabilities {
+		writeVulnerability(&sb, v, maxVulnerabilityDescription)
+	}
+	if len(result.Exploits) > 0 {
+		sb.WriteString("exploits of Exploit-DB mentioning the product:\n")
+		for _, e := range result.Exploits {
+			writeExploit(&sb, e)
+		}
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), result), nil
+}
+
+func writeVulnerability(sb *strings.Builder, v vulndb.Vulnerability, maxDescription int) {
+	fmt.Fprintf(sb, "%s", v.ID)
+	if v.CVSSScore > 0 {
+		fmt.Fprintf(sb, " [CVSS %.1f %s]", v.CVSSScore, v.CVSSSeverity)
+	}
+	if v.KnownExploited != nil {
+		fmt.Fprintf(sb, " [known exploited since %s", v.KnownExploited.DateAdded)
+		if v.KnownExploited.Ransomware == "Known" {
+			sb.WriteString(", by ransomware")
+		}
+		sb.WriteString("]")
+	}
+	description := v.Description
+	if maxDescription > 0 {
+		description = compactText(description, maxDescription)
+	}
+	fmt.Fprintf(sb, ": %s\n", description)
+	if maxDescription == 0 && v.CVSSVector != "" {
+		fmt.Fprintf(sb, "  vector: %s\n", v.CVSSVector)
+	}
+	for _, e := range v.Exploits {
+		writeExploit(sb, e)
+	}
+}
+
+func writeExploit(sb *strings.Builder, e vulndb.Exploit) {
+	fmt.Fprintf(sb, "  EDB-%s (%s, %s", e.ID, e.Type, e.Platform)
+	if e.Verified {
+		sb.WriteString(", verified")
+	}
+	fmt.Fprintf(sb, "): %s, %s\n", e.Description, e.Path)
+}
+
+// parseCPE parses the vendor, product and version out of a CPE, be it a 2.2 URI as reported by nmap,
+// e.g. cpe:/a:apache:http_server:2.4.49, or a 2.3 formatted string, e.g. cpe:2.3:a:apache:http_server:2.4.49:*:*.
+func parseCPE(cpe string) (vulndb.LookupParams, error) {
+	var parts []string
+	switch {
+	case strings.HasPrefix(cpe, "cpe:2.3:"):
+		parts = strings.Split(strings.TrimPrefix(cpe, "cpe:2.3:"), ":")
+	case strings.HasPrefix(cpe, "cpe:/"):
+		parts = strings.Split(strings.TrimPrefix(cpe, "cpe:/"), ":")
+	}
+	if len(parts) < 3 {
+		return vulndb.LookupParams{}, fmt.Errorf("invalid cpe: %s", cpe)
+	}
+	params := vulndb.LookupParams{Vendor: parts[1], Product: parts[2]}
+	if len(parts) > 3 && parts[3] != "*" && parts[3] != "-" {
+		params.Version = parts[3]
+	}
+	return params, nil
+}
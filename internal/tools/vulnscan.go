@@ -0,0 +1,443 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/finding"
+	"github.com/yaydraco/tandem/internal/inventory"
+)
+
+const (
+	NiktoScanToolName  = "nikto_scan"
+	NucleiScanToolName = "nuclei_scan"
+
+	defaultNiktoMaxTime   = 900
+	maxNiktoMaxTime       = 3600
+	maxScannerOutputSize  = 50 << 20
+	maxFindingNameLength  = 120
+	defaultNucleiRate     = 150
+	nucleiRequestTimeout  = 10
+	maxNucleiTemplateArgs = 50
+)
+
+var (
+	niktoTuningPattern     = regexp.MustCompile(`^[0-9a-cx]+$`)
+	nucleiTemplatePattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_\-./]*$`)
+	nucleiHostPortPattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.\-_:\[\]]*$`)
+	niktoReferenceSplitter = regexp.MustCompile(`[\s,]+`)
+)
+
+type NiktoScanArgs struct {
+	URL     string `json:"url"`
+	Tuning  string `json:"tuning,omitempty"`
+	MaxTime int    `json:"max_time,omitempty"`
+}
+
+type NucleiScanArgs struct {
+	Targets   []string `json:"targets"`
+	Templates []string `json:"templates,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Severity  []string `json:"severity,omitempty"`
+	Rate      int      `json:"rate,omitempty"`
+}
+
+type niktoScanTool struct {
+	inventory inventory.Service
+	findings  finding.Service
+}
+
+type nucleiScanTool struct {
+	inventory inventory.Service
+	findings  finding.Service
+}
+
+// NewVulnerabilityScanTools returns the tools running the vulnerability scanners within the sandbox,
+// normalizing what they find into the findings store.
+func NewVulnerabilityScanTools(inventory inventory.Service, findings finding.Service) []BaseTool {
+	return []BaseTool{
+		&niktoScanTool{inventory: inventory, findings: findings},
+		&nucleiScanTool{inventory: inventory, findings: findings},
+	}
+}
+
+func (t *niktoScanTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        NiktoScanToolName,
+		Description: "Scans a web server within the scope of the engagement with nikto in the docker container, for dangerous files, outdated software and misconfigurations. the findings are deduplicated and recorded in the findings store.",
+		Parameters: map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "http or https url of the web server, e.g. https://example.com:8443/app/",
+			},
+			"tuning": map[string]any{
+				"type":        "string",
+				"description": "nikto tuning, the classes of tests to run, e.g. 123b. all of them by default",
+			},
+			"max_time": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("seconds after which the scan stops. defaults to %d, at most %d", defaultNiktoMaxTime, maxNiktoMaxTime),
+			},
+		},
+		Required: []string{"url"},
+	}
+}
+
+func (t *niktoScanTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args NiktoScanArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse nikto_scan parameters: " + err.Error()), nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+	target, err := url.Parse(args.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return NewTextErrorResponse("invalid url: " + args.URL), nil
+	}
+	if err := checkScope(ctx, target.Hostname()); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if args.Tuning != "" && !niktoTuningPattern.MatchString(args.Tuning) {
+		return NewTextErrorResponse("invalid tuning: " + args.Tuning), nil
+	}
+	maxTime := defaultNiktoMaxTime
+	if args.MaxTime > 0 {
+		maxTime = min(args.MaxTime, maxNiktoMaxTime)
+	}
+
+	output := path.Join("/tmp", "tandem-nikto-"+uuid.New().String()+".json")
+	cmd := []string{
+		"nikto", "-h", target.String(),
+		"-Format", "json", "-o", output,
+		"-ask", "no", "-nointeractive",
+		"-maxtime", fmt.Sprintf("%ds", maxTime),
+	}
+	if args.Tuning != "" {
+		cmd = append(cmd, "-Tuning", args.Tuning)
+	}
+	data, err := runScanner(ctx, cmd, output)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	params, err := parseNiktoOutput(data, target, sessionID)
+	if err != nil {
+		return NewTextErrorResponse("failed to parse the nikto output: " + err.Error()), nil
+	}
+	return recordScan(ctx, t.inventory, t.findings, sessionID, NiktoScanToolName, "nikto", cmd, data, params)
+}
+
+func (t *nucleiScanTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        NucleiScanToolName,
+		Description: "Scans the targets within the scope of the engagement with nuclei templates in the docker container, for known vulnerabilities, exposures and misconfigurations. the findings are deduplicated and recorded in the findings store.",
+		Parameters: map[string]any{
+			"targets": map[string]any{
+				"type":        "array",
+				"description": "targets to scan",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "url, or host and port for the network templates, e.g. https://example.com or 10.0.0.1:22",
+				},
+			},
+			"templates": map[string]any{
+				"type":        "array",
+				"description": "templates or directories of templates to run, e.g. http/cves/ or http/exposures/configs/git-config.yaml. the default templates otherwise",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "template or directory",
+				},
+			},
+			"tags": map[string]any{
+				"type":        "array",
+				"description": "runs the templates of the tags only, e.g. cve, wordpress or tech",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "tag",
+				},
+			},
+			"severity": map[string]any{
+				"type":        "array",
+				"description": "runs the templates of the severities only",
+				"items": map[string]any{
+					"type": "string",
+					"enum": severityNames,
+				},
+			},
+			"rate": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("requests per second at most. defaults to %d", defaultNucleiRate),
+			},
+		},
+		Required: []string{"targets"},
+	}
+}
+
+func (t *nucleiScanTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args NucleiScanArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse nuclei_scan parameters: " + err.Error()), nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+	output := path.Join("/tmp", "tandem-nuclei-"+uuid.New().String()+".jsonl")
+	cmd, err := nucleiCommand(ctx, args, output)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	data, err := runScanner(ctx, cmd, output)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	params, err := parseNucleiOutput(data, sessionID)
+	if err != nil {
+		return NewTextErrorResponse("failed to parse the nuclei output: " + err.Error()), nil
+	}
+	return recordScan(ctx, t.inventory, t.findings, sessionID, NucleiScanToolName, "nuclei", cmd, data, params)
+}
+
+// nucleiCommand builds the nuclei command line out of the arguments, checking the targets against the scope.
+func nucleiCommand(ctx context.Context, args NucleiScanArgs, output string) ([]string, error) {
+	if len(args.Targets) == 0 {
+		return nil, fmt.Errorf("targets are required")
+	}
+	cmd := []string{"nuclei", "-silent", "-no-color", "-disable-update-check", "-jsonl", "-omit-raw", "-o", output}
+	for _, target := range args.Targets {
+		u, err := url.Parse(target)
+		isURL := err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+		if !isURL && !nucleiHostPortPattern.MatchString(target) {
+			return nil, fmt.Errorf("invalid target: %q", target)
+		}
+		if err := checkScope(ctx, targetHost(target)); err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, "-u", target)
+	}
+	if len(args.Templates)+len(args.Tags) > maxNucleiTemplateArgs {
+		return nil, fmt.Errorf("at most %d templates and tags can be given", maxNucleiTemplateArgs)
+	}
+	for _, template := range args.Templates {
+		if !nucleiTemplatePattern.MatchString(template) || strings.Contains(template, "..") {
+			return nil, fmt.Errorf("invalid template: %q", template)
+		}
+		cmd = append(cmd, "-t", template)
+	}
+	if len(args.Tags) > 0 {
+		for _, tag := range args.Tags {
+			if !nucleiTemplatePattern.MatchString(tag) {
+				return nil, fmt.Errorf("invalid tag: %q", tag)
+			}
+		}
+		cmd = append(cmd, "-tags", strings.Join(args.Tags, ","))
+	}
+	if len(args.Severity) > 0 {
+		for _, severity := range args.Severity {
+			if finding.ParseSeverity(severity) != finding.Severity(severity) {
+				return nil, fmt.Errorf("invalid severity: %q", severity)
+			}
+		}
+		cmd = append(cmd, "-severity", strings.Join(args.Severity, ","))
+	}
+	rate := defaultNucleiRate
+	if args.Rate > 0 {
+		rate = args.Rate
+	}
+	return append(cmd, "-rl", strconv.Itoa(rate), "-timeout", strconv.Itoa(nucleiRequestTimeout)), nil
+}
+
+// runScanner runs the scanner and returns the output it wrote to the file.
+// NOTE: the scanners may exit with a non zero code for having found something, thus only failing without an output.
+func runScanner(ctx context.Context, cmd []string, output string) ([]byte, error) {
+	defer sandboxExec(context.Background(), "rm", "-f", output)
+	_, execErr := sandboxExec(ctx, cmd...)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	data, err := readSandboxFile(ctx, output, maxScannerOutputSize)
+	if err != nil {
+		if execErr != nil {
+			return nil, execErr
+		}
+		// NOTE: nuclei doesn't write the output when it finds nothing.
+		return nil, nil
+	}
+	return data, nil
+}
+
+// recordScan records the raw output of the scan and its findings, returning their summary.
+func recordScan(ctx context.Context, hosts inventory.Service, findings finding.Service, sessionID, tool, scanner string, cmd []string, output []byte, params []finding.RecordFindingParams) (ToolResponse, error) {
+	if _, err := hosts.CreateScan(ctx, inventory.CreateScanParams{
+		SessionID: sessionID,
+		Tool:      tool,
+		Command:   strings.Join(cmd, " "),
+		Output:    string(output),
+	}); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to record the scan: %w", err)
+	}
+	recorded, err := recordFindings(ctx, findings, hosts, params)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	return WithResponseMetadata(NewTextResponse(findingsSummary(scanner, recorded)), recorded), nil
+}
+
+type niktoHost struct {
+	Host            string `json:"host"`
+	IP              string `json:"ip"`
+	Port            string `json:"port"`
+	Vulnerabilities []struct {
+		ID         string `json:"id"`
+		OSVDB      string `json:"OSVDB"`
+		References string `json:"references"`
+		Method     string `json:"method"`
+		URL        string `json:"url"`
+		Msg        string `json:"msg"`
+	} `json:"vulnerabilities"`
+}
+
+// parseNiktoOutput normalizes the findings of nikto, which outputs either a host or a list of them.
+// NOTE: nikto doesn't rate its findings, thus their severity is unknown.
+func parseNiktoOutput(data []byte, target *url.URL, sessionID string) ([]finding.RecordFindingParams, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var hosts []niktoHost
+	if data[0] == '{' {
+		var host niktoHost
+		if err := json.Unmarshal(data, &host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	} else if err := json.Unmarshal(data, &hosts); err != nil {
+		return nil, err
+	}
+
+	var params []finding.RecordFindingParams
+	for _, host := range hosts {
+		for _, vuln := range host.Vulnerabilities {
+			u := *target
+			u.Path, u.RawQuery, u.Fragment = "", "", ""
+			var references []string
+			for _, reference := range niktoReferenceSplitter.Split(vuln.References, -1) {
+				if reference != "" {
+					references = append(references, reference)
+				}
+			}
+			if vuln.OSVDB != "" && vuln.OSVDB != "0" {
+				references = append(references, "OSVDB-"+vuln.OSVDB)
+			}
+			name := vuln.Msg
+			if len(name) > maxFindingNameLength {
+				name = truncateText(name, maxFindingNameLength) + "..."
+			}
+			params = append(params, finding.RecordFindingParams{
+				SessionID:   sessionID,
+				Scanner:     "nikto",
+				TemplateID:  "nikto-" + vuln.ID,
+				Name:        name,
+				Severity:    finding.SeverityUnknown,
+				Target:      u.String() + vuln.URL,
+				Description: vuln.Msg,
+				Evidence:    strings.TrimSpace(vuln.Method + " " + vuln.URL),
+				References:  references,
+			})
+		}
+	}
+	return params, nil
+}
+
+// stringList is a list of strings which nuclei outputs as a single string when there's only one.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != "" {
+			*l = stringList{s}
+		}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+type nucleiResult struct {
+	TemplateID string `json:"template-id"`
+	Info       struct {
+		Name           string     `json:"name"`
+		Severity       string     `json:"severity"`
+		Description    string     `json:"description"`
+		Reference      stringList `json:"reference"`
+		Classification struct {
+			CVEID stringList `json:"cve-id"`
+			CWEID stringList `json:"cwe-id"`
+		} `json:"classification"`
+	} `json:"info"`
+	MatcherName      string   `json:"matcher-name"`
+	ExtractedResults []string `json:"extracted-results"`
+	Host             string   `json:"host"`
+	MatchedAt        string   `json:"matched-at"`
+}
+
+// parseNucleiOutput normalizes the findings of nuclei, output as json lines.
+func parseNucleiOutput(data []byte, sessionID string) ([]finding.RecordFindingParams, error) {
+	var params []finding.RecordFindingParams
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScannerOutputSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var result nucleiResult
+		if err := json.Unmarshal(line, &result); err != nil {
+			return nil, err
+		}
+		templateID := result.TemplateID
+		if result.MatcherName != "" {
+			templateID += ":" + result.MatcherName
+		}
+		target := result.MatchedAt
+		if target == "" {
+			target = result.Host
+		}
+		var evidence []string
+		if result.MatcherName != "" {
+			evidence = append(evidence, "matched "+result.MatcherName)
+		}
+		if len(result.ExtractedResults) > 0 {
+			evidence = append(evidence, "extracted "+strings.Join(result.ExtractedResults, ", "))
+		}
+		references := append(append([]string{}, result.Info.Classification.CVEID...), result.Info.Classification.CWEID...)
+		references = append(references, result.Info.Reference...)
+		params = append(params, finding.RecordFindingParams{
+			SessionID:   sessionID,
+			Scanner:     "nuclei",
+			TemplateID:  templateID,
+			Name:        result.Info.Name,
+			Severity:    finding.ParseSeverity(result.Info.Severity),
+			Target:      target,
+			Description: strings.TrimSpace(result.Info.Description),
+			Evidence:    strings.Join(evidence, "; "),
+			References:  references,
+		})
+	}
+	return params, scanner.Err()
+}
@@ -15,6 +15,15 @@ import (
 type SendMsg struct {
 	Text        string
 	Attachments []message.Attachment
+	// Edits is the ID of the prompt edited, which is replaced along with the messages after it, or forked from if Fork.
+	Edits string
+	Fork  bool
+}
+
+// EditMsg loads the prompt into the editor, to resubmit it edited, in a fork of the session if Fork.
+type EditMsg struct {
+	Message message.Message
+	Fork    bool
 }
 
 type SessionSelectedMsg = session.Session
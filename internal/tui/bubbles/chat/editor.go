@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"unicode"
 
@@ -29,6 +30,9 @@ type editorCmp struct {
 	textarea    textarea.Model
 	attachments []message.Attachment
 	deleteMode  bool
+	// editing is the prompt being edited, if any.
+	editing  *message.Message
+	editFork bool
 }
 
 type EditorKeyMaps struct {
@@ -106,13 +110,55 @@ func (m *editorCmp) openEditor() tea.Cmd {
 		os.Remove(tmpfile.Name())
 		attachments := m.attachments
 		m.attachments = nil
-		return SendMsg{
-			Text:        string(content),
-			Attachments: attachments,
-		}
+		return m.sendMsg(string(content), attachments)
 	})
 }
 
+// sendMsg is the message sending the prompt, the edit of the prompt being edited if any.
+func (m *editorCmp) sendMsg(text string, attachments []message.Attachment) SendMsg {
+	msg := SendMsg{
+		Text:        text,
+		Attachments: attachments,
+	}
+	if m.editing != nil {
+		msg.Edits = m.editing.ID
+		msg.Fork = m.editFork
+		m.editing = nil
+	}
+	return msg
+}
+
+// edit loads the prompt into the editor, along with its attachments.
+func (m *editorCmp) edit(msg EditMsg) tea.Cmd {
+	m.editing = &msg.Message
+	m.editFork = msg.Fork
+	m.deleteMode = false
+	m.textarea.SetValue(msg.Message.Content().String())
+	m.attachments = nil
+	for _, bc := range msg.Message.BinaryContent() {
+		m.attachments = append(m.attachments, message.Attachment{
+			FilePath: bc.Path,
+			FileName: filepath.Base(bc.Path),
+			MimeType: bc.MIMEType,
+			Content:  bc.Data,
+		})
+	}
+	if msg.Fork {
+		return utils.ReportInfo("Editing the prompt in a fork of the session, esc to cancel")
+	}
+	return utils.ReportInfo("Editing the prompt, the messages after it will be replaced, esc to cancel")
+}
+
+// cancelEdit drops the edit of the prompt being edited.
+func (m *editorCmp) cancelEdit() {
+	if m.editing == nil {
+		return
+	}
+	m.editing = nil
+	m.textarea.Reset()
+	m.attachments = nil
+}
+
 func (m *editorCmp) Init() tea.Cmd {
 	return textarea.Blink
 }
@@ -131,10 +177,7 @@ func (m *editorCmp) send() tea.Cmd {
 		return nil
 	}
 	return tea.Batch(
-		utils.CmdHandler(SendMsg{
-			Text:        value,
-			Attachments: attachments,
-		}),
+		utils.CmdHandler(m.sendMsg(value, attachments)),
 	)
 }
 
@@ -144,8 +187,14 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case SessionSelectedMsg:
 		if msg.ID != m.session.ID {
 			m.session = msg
+			m.cancelEdit()
 		}
 		return m, nil
+	case SessionClearedMsg:
+		m.cancelEdit()
+		return m, nil
+	case EditMsg:
+		return m, m.edit(msg)
 	case dialog.AttachmentAddedMsg:
 		if len(m.attachments) >= maxAttachments {
 			logging.ErrorPersist(fmt.Sprintf("cannot add more than %d images", maxAttachments))
@@ -181,6 +230,9 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, m.openEditor()
 		case key.Matches(msg, DeleteKeyMaps.Escape):
+			if !m.deleteMode {
+				m.cancelEdit()
+			}
 			m.deleteMode = false
 			return m, nil
 		case m.textarea.Focused() && key.Matches(msg, editorMaps.Send):
@@ -211,6 +263,9 @@ func (m *editorCmp) View() string {
 		Height(m.textarea.Height()).
 		Background(t.Background()).
 		Foreground(t.Primary())
+	if m.editing != nil {
+		style = style.Foreground(t.Warning())
+	}
 
 	if len(m.attachments) == 0 {
 		return lipgloss.JoinHorizontal(lipgloss.Top, style.Render(">"), m.textarea.View())
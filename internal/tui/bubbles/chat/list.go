@@ -147,6 +147,15 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
+		} else if msg.Type == pubsub.DeletedEvent && msg.Payload.SessionID == m.session.ID {
+			for i, v := range m.messages {
+				if v.ID == msg.Payload.ID {
+					m.messages = append(m.messages[:i], m.messages[i+1:]...)
+					delete(m.cachedContent, msg.Payload.ID)
+					needsRerender = true
+					break
+				}
+			}
 		}
 		if needsRerender {
 			m.renderView()
@@ -220,6 +229,10 @@ func (m *messagesCmp) renderView() {
 				width:   width,
 				content: assistantMessages,
 			}
+		case message.System:
+			systemMsg := renderSystemMessage(msg, width, pos)
+			m.uiMessages = append(m.uiMessages, systemMsg)
+			pos += systemMsg.height + 1 // + 1 for spacing
 		}
 	}
 
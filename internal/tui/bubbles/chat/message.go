@@ -22,6 +22,7 @@ const (
 	userMessageType uiMessageType = iota
 	assistantMessageType
 	toolMessageType
+	systemMessageType
 
 	maxResultHeight = 10
 )
@@ -117,6 +118,27 @@ func renderUserMessage(msg message.Message, width int, position int) uiMessage {
 	return userMsg
 }
 
+// renderSystemMessage renders the notes for the operator, e.g. a model fallback.
+func renderSystemMessage(msg message.Message, width int, position int) uiMessage {
+	t := theme.CurrentTheme()
+	content := styles.BaseStyle().
+		Width(width - 1).
+		BorderLeft(true).
+		BorderForeground(t.Warning()).
+		BorderStyle(lipgloss.ThickBorder()).
+		Foreground(t.TextMuted()).
+		PaddingLeft(1).
+		MarginBottom(1).
+		Render(msg.Content().String())
+	return uiMessage{
+		ID:          msg.ID,
+		messageType: systemMessageType,
+		position:    position,
+		height:      lipgloss.Height(content),
+		content:     content,
+	}
+}
+
 // Returns multiple uiMessages because of the tool calls
 func renderAssistantMessage(
 	msg message.Message,
@@ -227,6 +249,38 @@ func getToolAction(name string) string {
 	// 	return "Preparing prompt..."
 	case tools.DockerCliToolName:
 		return "Executing command..."
+	case tools.ShellSessionOpenToolName:
+		return "Opening shell session..."
+	case tools.ShellSessionWriteToolName:
+		return "Typing into shell session..."
+	case tools.ShellSessionReadToolName:
+		return "Reading shell session..."
+	case tools.ReadFileToolName:
+		return "Reading file..."
+	case tools.WriteFileToolName:
+		return "Writing file..."
+	case tools.DownloadArtifactToolName:
+		return "Downloading artifact..."
+	case tools.NmapScanToolName:
+		return "Scanning..."
+	case tools.HTTPRequestToolName:
+		return "Sending request..."
+	case tools.ContentDiscoveryToolName:
+		return "Fuzzing..."
+	case tools.NiktoScanToolName, tools.NucleiScanToolName:
+		return "Scanning for vulnerabilities..."
+	case tools.ListFindingsToolName:
+		return "Listing findings..."
+	case tools.MetasploitToolName:
+		return "Running metasploit..."
+	case tools.VulnLookupToolName:
+		return "Looking up vulnerabilities..."
+	case tools.VaultToolName:
+		return "Accessing the vault..."
+	case tools.SearchHistoryToolName:
+		return "Searching the history..."
+	case tools.MemoryToolName:
+		return "Remembering..."
 		// TODO: Impl the edit tool. used by project manager.
 		// case tools.EditToolName:
 		// 	return "Preparing edit..."
@@ -290,6 +344,74 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		command := strings.ReplaceAll(params.Command, "\n", " ")
 		return renderParams(paramWidth, command)
+	case tools.ShellSessionOpenToolName:
+		var params tools.ShellSessionOpenArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Name, "command", params.Command)
+	case tools.ShellSessionWriteToolName:
+		var params tools.ShellSessionWriteArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		input := strings.ReplaceAll(params.Input, "\n", " ")
+		return renderParams(paramWidth, input, "session", params.Name)
+	case tools.OperatorShellToolName:
+		var params tools.OperatorShellArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		command := strings.ReplaceAll(params.Command, "\n", " ")
+		return renderParams(paramWidth, command, "session", params.Name)
+	case tools.ReadFileToolName, tools.WriteFileToolName, tools.ListDirToolName, tools.DownloadArtifactToolName:
+		var params tools.ReadFileArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Path)
+	case tools.NmapScanToolName:
+		var params tools.NmapScanArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, strings.Join(params.Targets, " "), "ports", params.Ports, "scan", params.ScanType, "scripts", strings.Join(params.Scripts, ","))
+	case tools.HTTPRequestToolName:
+		var params tools.HTTPRequestArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.URL, "method", params.Method, "proxy", params.Proxy)
+	case tools.ContentDiscoveryToolName:
+		var params tools.ContentDiscoveryArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.URL, "wordlist", params.Wordlist, "extensions", strings.Join(params.Extensions, ","))
+	case tools.NiktoScanToolName:
+		var params tools.NiktoScanArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.URL, "tuning", params.Tuning)
+	case tools.NucleiScanToolName:
+		var params tools.NucleiScanArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, strings.Join(params.Targets, " "), "templates", strings.Join(params.Templates, ","), "tags", strings.Join(params.Tags, ","))
+	case tools.MetasploitToolName:
+		var params tools.MetasploitArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Action, "module", params.Module, "query", params.Query, "session", params.SessionID)
+	case tools.VulnLookupToolName:
+		var params tools.VulnLookupArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		if params.CVE != "" {
+			return renderParams(paramWidth, params.CVE)
+		}
+		if params.CPE != "" {
+			return renderParams(paramWidth, params.CPE)
+		}
+		return renderParams(paramWidth, strings.TrimSpace(params.Product+" "+params.Version), "vendor", params.Vendor)
+	case tools.VaultToolName:
+		var params tools.VaultArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Action, "ref", params.Ref, "target", params.Target)
+	case tools.SearchHistoryToolName:
+		var params tools.SearchHistoryArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Query)
+	case tools.MemoryToolName:
+		var params tools.MemoryArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Action, "subject", params.Subject, "query", params.Query, "id", params.ID)
+	case tools.ShellSessionReadToolName, tools.ShellSessionCloseToolName:
+		var params tools.ShellSessionCloseArgs
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Name)
 	// case tools.EditToolName:
 	// 	var params tools.EditParams
 	// 	json.Unmarshal([]byte(toolCall.Input), &params)
@@ -331,7 +453,7 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 	// 		toMarkdown(resultContent, false, width),
 	// 		t.Background(),
 	// 	)
-	case tools.DockerCliToolName:
+	case tools.DockerCliToolName, tools.ShellSessionOpenToolName, tools.ShellSessionWriteToolName, tools.ShellSessionReadToolName, tools.OperatorShellToolName:
 		// NOTE: by default, we are going to get a bash shell but then dependending on the type of shell to be used, as configured by the user, it should be mentioned in here.
 		resultContent = fmt.Sprintf("```bash\n%s\n```", resultContent)
 		return styles.ForceReplaceBackgroundWithLipgloss(
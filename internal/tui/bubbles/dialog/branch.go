@@ -0,0 +1,232 @@
+package dialog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yaydraco/tandem/internal/session"
+	"github.com/yaydraco/tandem/internal/tui/layout"
+	"github.com/yaydraco/tandem/internal/tui/styles"
+	"github.com/yaydraco/tandem/internal/tui/theme"
+	"github.com/yaydraco/tandem/internal/utils"
+)
+
+// BranchSelectedMsg is sent when a branch is selected
+type BranchSelectedMsg struct {
+	Session session.Session
+}
+
+// CloseBranchDialogMsg is sent when the branch dialog is closed
+type CloseBranchDialogMsg struct{}
+
+// BranchDialog interface for the dialog browsing the branches of a session
+type BranchDialog interface {
+	tea.Model
+	layout.Bindings
+	// SetSessions sets the sessions the branches of the session are among.
+	SetSessions(sessions []session.Session, sessionID string)
+}
+
+// branch is a session of the tree of forks, along with its depth in it.
+type branch struct {
+	session session.Session
+	depth   int
+}
+
+type branchDialogCmp struct {
+	branches    []branch
+	sessionID   string
+	selectedIdx int
+	width       int
+	height      int
+}
+
+type branchKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Enter  key.Binding
+	Escape key.Binding
+	J      key.Binding
+	K      key.Binding
+}
+
+var branchKeys = branchKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous branch"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next branch"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "switch to the branch"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+	J: key.NewBinding(
+		key.WithKeys("j"),
+		key.WithHelp("j", "next branch"),
+	),
+	K: key.NewBinding(
+		key.WithKeys("k"),
+		key.WithHelp("k", "previous branch"),
+	),
+}
+
+func (b *branchDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (b *branchDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, branchKeys.Up) || key.Matches(msg, branchKeys.K):
+			if b.selectedIdx > 0 {
+				b.selectedIdx--
+			}
+			return b, nil
+		case key.Matches(msg, branchKeys.Down) || key.Matches(msg, branchKeys.J):
+			if b.selectedIdx < len(b.branches)-1 {
+				b.selectedIdx++
+			}
+			return b, nil
+		case key.Matches(msg, branchKeys.Enter):
+			if len(b.branches) > 0 {
+				return b, utils.CmdHandler(BranchSelectedMsg{
+					Session: b.branches[b.selectedIdx].session,
+				})
+			}
+		case key.Matches(msg, branchKeys.Escape):
+			return b, utils.CmdHandler(CloseBranchDialogMsg{})
+		}
+	case tea.WindowSizeMsg:
+		b.width = msg.Width
+		b.height = msg.Height
+	}
+	return b, nil
+}
+
+func (b *branchDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	maxWidth := max(40, min(80, b.width-15))
+
+	maxVisible := min(10, len(b.branches))
+	startIdx := 0
+	if len(b.branches) > maxVisible {
+		halfVisible := maxVisible / 2
+		if b.selectedIdx >= halfVisible && b.selectedIdx < len(b.branches)-halfVisible {
+			startIdx = b.selectedIdx - halfVisible
+		} else if b.selectedIdx >= len(b.branches)-halfVisible {
+			startIdx = len(b.branches) - maxVisible
+		}
+	}
+	endIdx := min(startIdx+maxVisible, len(b.branches))
+
+	items := make([]string, 0, maxVisible)
+	for i := startIdx; i < endIdx; i++ {
+		br := b.branches[i]
+		indent := ""
+		if br.depth > 0 {
+			indent = strings.Repeat("  ", br.depth-1) + "└ "
+		}
+		current := "  "
+		if br.session.ID == b.sessionID {
+			current = "● "
+		}
+		line := current + indent + br.session.Title + "  " +
+			time.Unix(br.session.CreatedAt, 0).Local().Format(time.DateTime)
+
+		itemStyle := baseStyle.Width(maxWidth).MaxWidth(maxWidth).Inline(true).Padding(0, 1)
+		if i == b.selectedIdx {
+			itemStyle = itemStyle.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+		}
+		items = append(items, itemStyle.Render(line))
+	}
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("Session Branches")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, items...)),
+	)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.NormalBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+func (b *branchDialogCmp) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(branchKeys)
+}
+
+// SetSessions lays out the tree of forks the session is in, from the session all of them were forked from, every
+// fork below the session it was forked from, and selects the session.
+func (b *branchDialogCmp) SetSessions(sessions []session.Session, sessionID string) {
+	b.sessionID = sessionID
+	b.branches = nil
+	b.selectedIdx = 0
+
+	byID := make(map[string]session.Session, len(sessions))
+	forks := make(map[string][]session.Session)
+	for _, s := range sessions {
+		byID[s.ID] = s
+	}
+	// NOTE: the sessions are listed the latest first, the forks are laid out the oldest first.
+	for i := len(sessions) - 1; i >= 0; i-- {
+		s := sessions[i]
+		if _, ok := byID[s.ForkedFromSessionID]; ok {
+			forks[s.ForkedFromSessionID] = append(forks[s.ForkedFromSessionID], s)
+		}
+	}
+
+	root, ok := byID[sessionID]
+	if !ok {
+		return
+	}
+	seen := map[string]bool{root.ID: true}
+	for {
+		origin, ok := byID[root.ForkedFromSessionID]
+		if !ok || seen[origin.ID] {
+			break
+		}
+		seen[origin.ID] = true
+		root = origin
+	}
+
+	var walk func(s session.Session, depth int)
+	walk = func(s session.Session, depth int) {
+		if s.ID == sessionID {
+			b.selectedIdx = len(b.branches)
+		}
+		b.branches = append(b.branches, branch{session: s, depth: depth})
+		for _, fork := range forks[s.ID] {
+			walk(fork, depth+1)
+		}
+	}
+	walk(root, 0)
+}
+
+// NewBranchDialogCmp creates a new dialog browsing the branches of a session
+func NewBranchDialogCmp() BranchDialog {
+	return &branchDialogCmp{}
+}
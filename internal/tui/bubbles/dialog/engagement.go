@@ -0,0 +1,211 @@
+package dialog
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yaydraco/tandem/internal/engagement"
+	"github.com/yaydraco/tandem/internal/tui/layout"
+	"github.com/yaydraco/tandem/internal/tui/styles"
+	"github.com/yaydraco/tandem/internal/tui/theme"
+	"github.com/yaydraco/tandem/internal/utils"
+)
+
+// EngagementSelectedMsg is sent when an engagement is selected, one without a name
+// standing for the configuration of the working directory
+type EngagementSelectedMsg struct {
+	Engagement engagement.Engagement
+}
+
+// CloseEngagementDialogMsg is sent when the engagement dialog is closed
+type CloseEngagementDialogMsg struct{}
+
+// EngagementDialog interface for the engagement picker
+type EngagementDialog interface {
+	tea.Model
+	layout.Bindings
+	SetEngagements(engagements []engagement.Engagement, current string)
+}
+
+type engagementDialogCmp struct {
+	engagements []engagement.Engagement
+	current     string
+	selectedIdx int
+	width       int
+	height      int
+}
+
+type engagementKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Enter  key.Binding
+	Escape key.Binding
+	J      key.Binding
+	K      key.Binding
+}
+
+var engagementKeys = engagementKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous engagement"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next engagement"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open engagement"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+	J: key.NewBinding(
+		key.WithKeys("j"),
+		key.WithHelp("j", "next engagement"),
+	),
+	K: key.NewBinding(
+		key.WithKeys("k"),
+		key.WithHelp("k", "previous engagement"),
+	),
+}
+
+func (e *engagementDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (e *engagementDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, engagementKeys.Up) || key.Matches(msg, engagementKeys.K):
+			if e.selectedIdx > 0 {
+				e.selectedIdx--
+			}
+			return e, nil
+		case key.Matches(msg, engagementKeys.Down) || key.Matches(msg, engagementKeys.J):
+			if e.selectedIdx < len(e.engagements)-1 {
+				e.selectedIdx++
+			}
+			return e, nil
+		case key.Matches(msg, engagementKeys.Enter):
+			return e, utils.CmdHandler(EngagementSelectedMsg{
+				Engagement: e.engagements[e.selectedIdx],
+			})
+		case key.Matches(msg, engagementKeys.Escape):
+			return e, utils.CmdHandler(CloseEngagementDialogMsg{})
+		}
+	case tea.WindowSizeMsg:
+		e.width = msg.Width
+		e.height = msg.Height
+	}
+	return e, nil
+}
+
+func engagementTitle(e engagement.Engagement) string {
+	if e.Name == "" {
+		return "(working directory)"
+	}
+	return e.Name
+}
+
+func (e *engagementDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	maxWidth := 40
+	for _, eng := range e.engagements {
+		if len(eng.Name)+6 > maxWidth {
+			maxWidth = len(eng.Name) + 6
+		}
+	}
+	maxWidth = max(30, min(maxWidth, e.width-15))
+
+	maxVisible := min(10, len(e.engagements))
+	startIdx := 0
+	if len(e.engagements) > maxVisible {
+		halfVisible := maxVisible / 2
+		if e.selectedIdx >= halfVisible && e.selectedIdx < len(e.engagements)-halfVisible {
+			startIdx = e.selectedIdx - halfVisible
+		} else if e.selectedIdx >= len(e.engagements)-halfVisible {
+			startIdx = len(e.engagements) - maxVisible
+		}
+	}
+	endIdx := min(startIdx+maxVisible, len(e.engagements))
+
+	items := make([]string, 0, maxVisible)
+	for i := startIdx; i < endIdx; i++ {
+		eng := e.engagements[i]
+		itemStyle := baseStyle.Width(maxWidth)
+		if i == e.selectedIdx {
+			itemStyle = itemStyle.
+				Background(t.Primary()).
+				Foreground(t.Background()).
+				Bold(true)
+		}
+		marker := "  "
+		if eng.Name == e.current {
+			marker = "* "
+		}
+		items = append(items, itemStyle.Padding(0, 1).Render(marker+engagementTitle(eng)))
+	}
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("Switch Engagement")
+
+	hint := baseStyle.
+		Foreground(t.TextMuted()).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("tandem restarts in the engagement")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, items...)),
+		baseStyle.Width(maxWidth).Render(""),
+		hint,
+	)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.NormalBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+func (e *engagementDialogCmp) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(engagementKeys)
+}
+
+// SetEngagements lists the engagements which aren't archived, after the configuration of the working directory,
+// selecting the current one.
+func (e *engagementDialogCmp) SetEngagements(engagements []engagement.Engagement, current string) {
+	e.engagements = []engagement.Engagement{{}}
+	for _, eng := range engagements {
+		if !eng.Archived {
+			e.engagements = append(e.engagements, eng)
+		}
+	}
+	e.current = current
+	e.selectedIdx = 0
+	for i, eng := range e.engagements {
+		if eng.Name == current {
+			e.selectedIdx = i
+		}
+	}
+}
+
+// NewEngagementDialogCmp creates a new engagement picker
+func NewEngagementDialogCmp() EngagementDialog {
+	return &engagementDialogCmp{
+		engagements: []engagement.Engagement{{}},
+	}
+}
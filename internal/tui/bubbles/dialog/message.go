@@ -0,0 +1,267 @@
+package dialog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/tui/layout"
+	"github.com/yaydraco/tandem/internal/tui/styles"
+	"github.com/yaydraco/tandem/internal/tui/theme"
+	"github.com/yaydraco/tandem/internal/utils"
+)
+
+// MessageAction is what is done from the message selected
+type MessageAction string
+
+const (
+	// MessageActionFork forks the session from the message.
+	MessageActionFork MessageAction = "fork"
+	// MessageActionEdit edits the prompt and resubmits it, deleting the messages after it.
+	MessageActionEdit MessageAction = "edit"
+	// MessageActionEditFork edits the prompt and resubmits it in a fork of the session.
+	MessageActionEditFork MessageAction = "edit_fork"
+	// MessageActionRegenerate generates the response anew from the message.
+	MessageActionRegenerate MessageAction = "regenerate"
+	// MessageActionRegenerateModel generates the response anew from the message with another model.
+	MessageActionRegenerateModel MessageAction = "regenerate_model"
+	// MessageActionContinue carries on the turn of the session which was cancelled or failed.
+	MessageActionContinue MessageAction = "continue"
+)
+
+// MessageSelectedMsg is sent when a message is selected to act on
+type MessageSelectedMsg struct {
+	Message message.Message
+	Action  MessageAction
+}
+
+// CloseMessageDialogMsg is sent when the message dialog is closed
+type CloseMessageDialogMsg struct{}
+
+// MessageDialog interface for the dialog picking a message of the session to act on
+type MessageDialog interface {
+	tea.Model
+	layout.Bindings
+	SetMessages(messages []message.Message)
+}
+
+type messageDialogCmp struct {
+	messages    []message.Message
+	selectedIdx int
+	width       int
+	height      int
+}
+
+type messageKeyMap struct {
+	Up              key.Binding
+	Down            key.Binding
+	Enter           key.Binding
+	Edit            key.Binding
+	EditFork        key.Binding
+	Regenerate      key.Binding
+	RegenerateModel key.Binding
+	Continue        key.Binding
+	Escape          key.Binding
+	J               key.Binding
+	K               key.Binding
+}
+
+var messageKeys = messageKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous message"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next message"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter", "f"),
+		key.WithHelp("enter/f", "fork the session from the message"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit the prompt, deleting the messages after it"),
+	),
+	EditFork: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "edit the prompt in a fork"),
+	),
+	Regenerate: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "regenerate the response"),
+	),
+	RegenerateModel: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "regenerate the response with another model"),
+	),
+	Continue: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "continue the cancelled or failed turn"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+	J: key.NewBinding(
+		key.WithKeys("j"),
+		key.WithHelp("j", "next message"),
+	),
+	K: key.NewBinding(
+		key.WithKeys("k"),
+		key.WithHelp("k", "previous message"),
+	),
+}
+
+func (m *messageDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (m *messageDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, messageKeys.Up) || key.Matches(msg, messageKeys.K):
+			if m.selectedIdx > 0 {
+				m.selectedIdx--
+			}
+			return m, nil
+		case key.Matches(msg, messageKeys.Down) || key.Matches(msg, messageKeys.J):
+			if m.selectedIdx < len(m.messages)-1 {
+				m.selectedIdx++
+			}
+			return m, nil
+		case key.Matches(msg, messageKeys.Enter):
+			return m, m.selected(MessageActionFork)
+		case key.Matches(msg, messageKeys.Edit) || key.Matches(msg, messageKeys.EditFork):
+			if len(m.messages) > 0 && m.messages[m.selectedIdx].Role != message.User {
+				return m, utils.ReportWarn("Only your prompts can be edited")
+			}
+			if key.Matches(msg, messageKeys.EditFork) {
+				return m, m.selected(MessageActionEditFork)
+			}
+			return m, m.selected(MessageActionEdit)
+		case key.Matches(msg, messageKeys.Regenerate):
+			return m, m.selected(MessageActionRegenerate)
+		case key.Matches(msg, messageKeys.RegenerateModel):
+			return m, m.selected(MessageActionRegenerateModel)
+		case key.Matches(msg, messageKeys.Continue):
+			return m, m.selected(MessageActionContinue)
+		case key.Matches(msg, messageKeys.Escape):
+			return m, utils.CmdHandler(CloseMessageDialogMsg{})
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m *messageDialogCmp) selected(action MessageAction) tea.Cmd {
+	if len(m.messages) == 0 {
+		return nil
+	}
+	return utils.CmdHandler(MessageSelectedMsg{
+		Message: m.messages[m.selectedIdx],
+		Action:  action,
+	})
+}
+
+func (m *messageDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	maxWidth := max(40, min(80, m.width-15))
+
+	maxVisible := min(10, len(m.messages))
+	startIdx := 0
+	if len(m.messages) > maxVisible {
+		halfVisible := maxVisible / 2
+		if m.selectedIdx >= halfVisible && m.selectedIdx < len(m.messages)-halfVisible {
+			startIdx = m.selectedIdx - halfVisible
+		} else if m.selectedIdx >= len(m.messages)-halfVisible {
+			startIdx = len(m.messages) - maxVisible
+		}
+	}
+	endIdx := min(startIdx+maxVisible, len(m.messages))
+
+	items := make([]string, 0, maxVisible)
+	for i := startIdx; i < endIdx; i++ {
+		msg := m.messages[i]
+		who := "You"
+		if msg.Role == message.Assistant {
+			who = "Orchestrator"
+		}
+		line := time.Unix(msg.CreatedAt, 0).Local().Format(time.TimeOnly) + "  " + who + ": " + messagePreview(msg)
+
+		itemStyle := baseStyle.Width(maxWidth).MaxWidth(maxWidth).Inline(true).Padding(0, 1)
+		if i == m.selectedIdx {
+			itemStyle = itemStyle.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+		}
+		items = append(items, itemStyle.Render(line))
+	}
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("Message Actions")
+
+	hint := "enter fork · e edit · E edit in a fork · r regenerate · m regenerate with a model · c continue"
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Foreground(t.TextMuted()).Padding(0, 1).Render(hint),
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, items...)),
+	)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.NormalBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+// messagePreview returns the first line of the text of the message, or the tools it calls if it has no text.
+func messagePreview(msg message.Message) string {
+	if text := strings.TrimSpace(msg.Content().String()); text != "" {
+		line, _, _ := strings.Cut(text, "\n")
+		return line
+	}
+	names := make([]string, 0, len(msg.ToolCalls()))
+	for _, call := range msg.ToolCalls() {
+		names = append(names, call.Name)
+	}
+	if len(names) > 0 {
+		return "calls " + strings.Join(names, ", ")
+	}
+	return "(empty)"
+}
+
+func (m *messageDialogCmp) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(messageKeys)
+}
+
+// SetMessages sets the messages to pick from, leaving out the tool results, which go along with their calls, and the
+// system messages, and selects the last one.
+func (m *messageDialogCmp) SetMessages(messages []message.Message) {
+	m.messages = m.messages[:0]
+	for _, msg := range messages {
+		if msg.Role != message.Tool && msg.Role != message.System {
+			m.messages = append(m.messages, msg)
+		}
+	}
+	m.selectedIdx = max(0, len(m.messages)-1)
+}
+
+// NewMessageDialogCmp creates a new dialog picking a message of the session to act on
+func NewMessageDialogCmp() MessageDialog {
+	return &messageDialogCmp{}
+}
@@ -0,0 +1,223 @@
+package dialog
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/tui/layout"
+	"github.com/yaydraco/tandem/internal/tui/styles"
+	"github.com/yaydraco/tandem/internal/tui/theme"
+	"github.com/yaydraco/tandem/internal/utils"
+)
+
+const maxSearchResults = 50
+
+// SearchResultSelectedMsg is sent when a search result is selected
+type SearchResultSelectedMsg struct {
+	Result message.SearchResult
+}
+
+// CloseSearchDialogMsg is sent when the search dialog is closed
+type CloseSearchDialogMsg struct{}
+
+// SearchDialog interface for the search of the history of the sessions
+type SearchDialog interface {
+	tea.Model
+	layout.Bindings
+	// Reset clears the search and focuses its input.
+	Reset() tea.Cmd
+}
+
+type searchDialogCmp struct {
+	messages    message.Service
+	input       textinput.Model
+	searched    string
+	results     []message.SearchResult
+	err         error
+	selectedIdx int
+	width       int
+	height      int
+}
+
+type searchKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Enter  key.Binding
+	Escape key.Binding
+}
+
+var searchKeys = searchKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous result"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next result"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "search, or open the session of the result"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+func (s *searchDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (s *searchDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, searchKeys.Up):
+			if s.selectedIdx > 0 {
+				s.selectedIdx--
+			}
+			return s, nil
+		case key.Matches(msg, searchKeys.Down):
+			if s.selectedIdx < len(s.results)-1 {
+				s.selectedIdx++
+			}
+			return s, nil
+		case key.Matches(msg, searchKeys.Enter):
+			// NOTE: enter searches for what's typed, and opens the result selected once it's searched for.
+			if s.input.Value() != s.searched {
+				s.search()
+				return s, nil
+			}
+			if len(s.results) > 0 {
+				return s, utils.CmdHandler(SearchResultSelectedMsg{
+					Result: s.results[s.selectedIdx],
+				})
+			}
+			return s, nil
+		case key.Matches(msg, searchKeys.Escape):
+			return s, utils.CmdHandler(CloseSearchDialogMsg{})
+		}
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		return s, cmd
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+	}
+	return s, nil
+}
+
+func (s *searchDialogCmp) search() {
+	s.searched = s.input.Value()
+	s.selectedIdx = 0
+	s.results, s.err = nil, nil
+	if s.searched == "" {
+		return
+	}
+	s.results, s.err = s.messages.Search(context.Background(), s.searched, maxSearchResults)
+}
+
+func (s *searchDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	maxWidth := max(40, min(80, s.width-15))
+	s.input.Width = maxWidth - 4
+
+	var items []string
+	switch {
+	case s.err != nil:
+		items = append(items, baseStyle.Foreground(t.Error()).Width(maxWidth).Padding(0, 1).Render(s.err.Error()))
+	case s.searched == "":
+		items = append(items, baseStyle.Foreground(t.TextMuted()).Width(maxWidth).Padding(0, 1).Render("Type the words to search for, and press enter"))
+	case len(s.results) == 0:
+		items = append(items, baseStyle.Foreground(t.TextMuted()).Width(maxWidth).Padding(0, 1).Render("No matches"))
+	}
+
+	// Every result takes two lines, its session and its snippet
+	maxVisible := min(6, len(s.results))
+	startIdx := 0
+	if len(s.results) > maxVisible {
+		halfVisible := maxVisible / 2
+		if s.selectedIdx >= halfVisible && s.selectedIdx < len(s.results)-halfVisible {
+			startIdx = s.selectedIdx - halfVisible
+		} else if s.selectedIdx >= len(s.results)-halfVisible {
+			startIdx = len(s.results) - maxVisible
+		}
+	}
+	endIdx := min(startIdx+maxVisible, len(s.results))
+
+	for i := startIdx; i < endIdx; i++ {
+		r := s.results[i]
+		session := r.SessionTitle
+		if r.SessionID != r.RootSessionID {
+			session = r.RootSessionTitle + " › " + session
+		}
+		header := time.Unix(r.CreatedAt, 0).Local().Format(time.DateTime) + "  " + session
+
+		itemStyle := baseStyle.Width(maxWidth).MaxWidth(maxWidth).Inline(true).Padding(0, 1)
+		headerStyle := itemStyle.Foreground(t.TextMuted())
+		snippet := r.HighlightSnippet(func(term string) string {
+			return baseStyle.Foreground(t.Primary()).Bold(true).Render(term)
+		})
+		if i == s.selectedIdx {
+			itemStyle = itemStyle.Background(t.Primary()).Foreground(t.Background()).Bold(true)
+			headerStyle = itemStyle
+			snippet = r.PlainSnippet()
+		}
+		items = append(items, headerStyle.Render(header), itemStyle.Render(snippet))
+	}
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render("Search History")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Padding(0, 1).Render(s.input.View()),
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, items...)),
+	)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.NormalBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+func (s *searchDialogCmp) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(searchKeys)
+}
+
+func (s *searchDialogCmp) Reset() tea.Cmd {
+	s.input.SetValue("")
+	s.searched = ""
+	s.results, s.err = nil, nil
+	s.selectedIdx = 0
+	return s.input.Focus()
+}
+
+// NewSearchDialogCmp creates a new dialog searching the messages and tool results of every session
+func NewSearchDialogCmp(messages message.Service) SearchDialog {
+	input := textinput.New()
+	input.Placeholder = "tomcat manager creds"
+	input.CharLimit = 200
+	input.Prompt = "> "
+	return &searchDialogCmp{
+		messages: messages,
+		input:    input,
+	}
+}
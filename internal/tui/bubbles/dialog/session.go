@@ -1,7 +1,11 @@
 package dialog
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/yaydraco/tandem/internal/session"
@@ -16,6 +20,29 @@ type SessionSelectedMsg struct {
 	Session session.Session
 }
 
+// RenameSessionMsg is sent when a session is renamed
+type RenameSessionMsg struct {
+	Session session.Session
+	Title   string
+}
+
+// TagSessionMsg is sent when the tags of a session are edited
+type TagSessionMsg struct {
+	Session session.Session
+	Tags    []string
+}
+
+// ArchiveSessionMsg is sent when a session is archived, or restored if not Archived
+type ArchiveSessionMsg struct {
+	Session  session.Session
+	Archived bool
+}
+
+// DeleteSessionMsg is sent when the deletion of a session is confirmed
+type DeleteSessionMsg struct {
+	Session session.Session
+}
+
 // CloseSessionDialogMsg is sent when the session dialog is closed
 type CloseSessionDialogMsg struct{}
 
@@ -27,21 +54,47 @@ type SessionDialog interface {
 	SetSelectedSession(sessionID string)
 }
 
+// sessionMode is what the keys typed into the session dialog do.
+type sessionMode int
+
+const (
+	sessionModeList sessionMode = iota
+	sessionModeRename
+	sessionModeTags
+	sessionModeFilter
+	sessionModeDelete
+)
+
 type sessionDialogCmp struct {
+	// sessions are all the sessions, the ones listed being the ones matching the filters.
 	sessions          []session.Session
+	listed            []session.Session
 	selectedIdx       int
 	width             int
 	height            int
 	selectedSessionID string
+
+	mode  sessionMode
+	input textinput.Model
+	// tag is the tag the sessions listed are filtered by, if any.
+	tag string
+	// showArchived lists the archived sessions along with the others.
+	showArchived bool
 }
 
 type sessionKeyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Enter  key.Binding
-	Escape key.Binding
-	J      key.Binding
-	K      key.Binding
+	Up           key.Binding
+	Down         key.Binding
+	Enter        key.Binding
+	Rename       key.Binding
+	Tags         key.Binding
+	Filter       key.Binding
+	Archive      key.Binding
+	ShowArchived key.Binding
+	Delete       key.Binding
+	Escape       key.Binding
+	J            key.Binding
+	K            key.Binding
 }
 
 var sessionKeys = sessionKeyMap{
@@ -57,6 +110,30 @@ var sessionKeys = sessionKeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "select session"),
 	),
+	Rename: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename the session"),
+	),
+	Tags: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "edit the tags of the session"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter the sessions by tag"),
+	),
+	Archive: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "archive or restore the session"),
+	),
+	ShowArchived: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "show or hide the archived sessions"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete the session"),
+	),
 	Escape: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "close"),
@@ -71,6 +148,20 @@ var sessionKeys = sessionKeyMap{
 	),
 }
 
+var sessionConfirmKeys = struct {
+	Yes key.Binding
+	No  key.Binding
+}{
+	Yes: key.NewBinding(
+		key.WithKeys("y", "Y"),
+		key.WithHelp("y", "delete"),
+	),
+	No: key.NewBinding(
+		key.WithKeys("n", "N", "esc"),
+		key.WithHelp("n", "keep"),
+	),
+}
+
 func (s *sessionDialogCmp) Init() tea.Cmd {
 	return nil
 }
@@ -78,6 +169,12 @@ func (s *sessionDialogCmp) Init() tea.Cmd {
 func (s *sessionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		switch s.mode {
+		case sessionModeDelete:
+			return s, s.confirmDelete(msg)
+		case sessionModeRename, sessionModeTags, sessionModeFilter:
+			return s, s.updateInput(msg)
+		}
 		switch {
 		case key.Matches(msg, sessionKeys.Up) || key.Matches(msg, sessionKeys.K):
 			if s.selectedIdx > 0 {
@@ -85,16 +182,42 @@ func (s *sessionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return s, nil
 		case key.Matches(msg, sessionKeys.Down) || key.Matches(msg, sessionKeys.J):
-			if s.selectedIdx < len(s.sessions)-1 {
+			if s.selectedIdx < len(s.listed)-1 {
 				s.selectedIdx++
 			}
 			return s, nil
 		case key.Matches(msg, sessionKeys.Enter):
-			if len(s.sessions) > 0 {
+			if sess, ok := s.selected(); ok {
 				return s, utils.CmdHandler(SessionSelectedMsg{
-					Session: s.sessions[s.selectedIdx],
+					Session: sess,
 				})
 			}
+		case key.Matches(msg, sessionKeys.Rename):
+			if sess, ok := s.selected(); ok {
+				return s, s.edit(sessionModeRename, sess.Title)
+			}
+		case key.Matches(msg, sessionKeys.Tags):
+			if sess, ok := s.selected(); ok {
+				return s, s.edit(sessionModeTags, strings.Join(sess.Tags, " "))
+			}
+		case key.Matches(msg, sessionKeys.Filter):
+			return s, s.edit(sessionModeFilter, s.tag)
+		case key.Matches(msg, sessionKeys.Archive):
+			if sess, ok := s.selected(); ok {
+				return s, utils.CmdHandler(ArchiveSessionMsg{
+					Session:  sess,
+					Archived: !sess.Archived(),
+				})
+			}
+		case key.Matches(msg, sessionKeys.ShowArchived):
+			s.showArchived = !s.showArchived
+			s.filter()
+			return s, nil
+		case key.Matches(msg, sessionKeys.Delete):
+			if _, ok := s.selected(); ok {
+				s.mode = sessionModeDelete
+			}
+			return s, nil
 		case key.Matches(msg, sessionKeys.Escape):
 			return s, utils.CmdHandler(CloseSessionDialogMsg{})
 		}
@@ -105,6 +228,94 @@ func (s *sessionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return s, nil
 }
 
+// edit starts typing into the input, for the mode, from the value.
+func (s *sessionDialogCmp) edit(mode sessionMode, value string) tea.Cmd {
+	s.mode = mode
+	s.input.SetValue(value)
+	s.input.CursorEnd()
+	return s.input.Focus()
+}
+
+func (s *sessionDialogCmp) updateInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		s.mode = sessionModeList
+		s.input.Blur()
+		return nil
+	case "enter":
+		mode := s.mode
+		value := strings.TrimSpace(s.input.Value())
+		s.mode = sessionModeList
+		s.input.Blur()
+		if mode == sessionModeFilter {
+			s.tag = strings.ToLower(value)
+			s.filter()
+			return nil
+		}
+		sess, ok := s.selected()
+		if !ok {
+			return nil
+		}
+		if mode == sessionModeRename {
+			if value == "" || value == sess.Title {
+				return nil
+			}
+			return utils.CmdHandler(RenameSessionMsg{
+				Session: sess,
+				Title:   value,
+			})
+		}
+		return utils.CmdHandler(TagSessionMsg{
+			Session: sess,
+			Tags:    strings.Fields(value),
+		})
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return cmd
+}
+
+func (s *sessionDialogCmp) confirmDelete(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, sessionConfirmKeys.Yes):
+		s.mode = sessionModeList
+		if sess, ok := s.selected(); ok {
+			return utils.CmdHandler(DeleteSessionMsg{
+				Session: sess,
+			})
+		}
+	case key.Matches(msg, sessionConfirmKeys.No):
+		s.mode = sessionModeList
+	}
+	return nil
+}
+
+func (s *sessionDialogCmp) selected() (session.Session, bool) {
+	if s.selectedIdx < 0 || s.selectedIdx >= len(s.listed) {
+		return session.Session{}, false
+	}
+	return s.listed[s.selectedIdx], true
+}
+
+// filter lists the sessions matching the filters, keeping the one selected if it's still listed.
+func (s *sessionDialogCmp) filter() {
+	selectedID := s.selectedSessionID
+	if sess, ok := s.selected(); ok {
+		selectedID = sess.ID
+	}
+	s.listed = s.listed[:0]
+	for _, sess := range s.sessions {
+		if sess.Archived() && !s.showArchived {
+			continue
+		}
+		if s.tag != "" && !sess.HasTag(s.tag) {
+			continue
+		}
+		s.listed = append(s.listed, sess)
+	}
+	s.selectedIdx = max(0, slices.IndexFunc(s.listed, func(sess session.Session) bool { return sess.ID == selectedID }))
+}
+
 func (s *sessionDialogCmp) View() string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
@@ -119,37 +330,37 @@ func (s *sessionDialogCmp) View() string {
 	}
 
 	// Calculate max width needed for session titles
-	maxWidth := 40 // Minimum width
-	for _, sess := range s.sessions {
-		if len(sess.Title) > maxWidth-4 { // Account for padding
-			maxWidth = len(sess.Title) + 4
+	maxWidth := 70 // Minimum width, for the keys to fit
+	for _, sess := range s.listed {
+		if width := lipgloss.Width(sessionItem(sess)); width > maxWidth-4 { // Account for padding
+			maxWidth = width + 4
 		}
 	}
 
 	maxWidth = max(30, min(maxWidth, s.width-15)) // Limit width to avoid overflow
 
 	// Limit height to avoid taking up too much screen space
-	maxVisibleSessions := min(10, len(s.sessions))
+	maxVisibleSessions := min(10, len(s.listed))
 
 	// Build the session list
 	sessionItems := make([]string, 0, maxVisibleSessions)
 	startIdx := 0
 
 	// If we have more sessions than can be displayed, adjust the start index
-	if len(s.sessions) > maxVisibleSessions {
+	if len(s.listed) > maxVisibleSessions {
 		// Center the selected item when possible
 		halfVisible := maxVisibleSessions / 2
-		if s.selectedIdx >= halfVisible && s.selectedIdx < len(s.sessions)-halfVisible {
+		if s.selectedIdx >= halfVisible && s.selectedIdx < len(s.listed)-halfVisible {
 			startIdx = s.selectedIdx - halfVisible
-		} else if s.selectedIdx >= len(s.sessions)-halfVisible {
-			startIdx = len(s.sessions) - maxVisibleSessions
+		} else if s.selectedIdx >= len(s.listed)-halfVisible {
+			startIdx = len(s.listed) - maxVisibleSessions
 		}
 	}
 
-	endIdx := min(startIdx+maxVisibleSessions, len(s.sessions))
+	endIdx := min(startIdx+maxVisibleSessions, len(s.listed))
 
 	for i := startIdx; i < endIdx; i++ {
-		sess := s.sessions[i]
+		sess := s.listed[i]
 		itemStyle := baseStyle.Width(maxWidth)
 
 		if i == s.selectedIdx {
@@ -157,9 +368,14 @@ func (s *sessionDialogCmp) View() string {
 				Background(t.Primary()).
 				Foreground(t.Background()).
 				Bold(true)
+		} else if sess.Archived() {
+			itemStyle = itemStyle.Foreground(t.TextMuted())
 		}
 
-		sessionItems = append(sessionItems, itemStyle.Padding(0, 1).Render(sess.Title))
+		sessionItems = append(sessionItems, itemStyle.Padding(0, 1).Render(sessionItem(sess)))
+	}
+	if len(sessionItems) == 0 {
+		sessionItems = append(sessionItems, baseStyle.Width(maxWidth).Padding(0, 1).Foreground(t.TextMuted()).Render("No sessions match"))
 	}
 
 	title := baseStyle.
@@ -169,12 +385,38 @@ func (s *sessionDialogCmp) View() string {
 		Padding(0, 1).
 		Render("Switch Session")
 
+	var filters []string
+	if s.tag != "" {
+		filters = append(filters, "tagged "+s.tag)
+	}
+	if s.showArchived {
+		filters = append(filters, "archived shown")
+	}
+	mutedStyle := baseStyle.Width(maxWidth).Padding(0, 1).Foreground(t.TextMuted())
+
+	var footer string
+	switch s.mode {
+	case sessionModeRename:
+		footer = baseStyle.Width(maxWidth).Padding(0, 1).Render("Title " + s.input.View())
+	case sessionModeTags:
+		footer = baseStyle.Width(maxWidth).Padding(0, 1).Render("Tags " + s.input.View())
+	case sessionModeFilter:
+		footer = baseStyle.Width(maxWidth).Padding(0, 1).Render("Tag " + s.input.View())
+	case sessionModeDelete:
+		sess, _ := s.selected()
+		footer = baseStyle.Width(maxWidth).Padding(0, 1).Foreground(t.Error()).
+			Render("Delete " + sess.Title + " and its messages? y/n")
+	default:
+		footer = mutedStyle.Render("r rename · t tags · / filter · a archive · A archived · d delete")
+	}
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
-		baseStyle.Width(maxWidth).Render(""),
+		mutedStyle.Render(strings.Join(filters, ", ")),
 		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, sessionItems...)),
 		baseStyle.Width(maxWidth).Render(""),
+		footer,
 	)
 
 	return baseStyle.Padding(1, 2).
@@ -185,33 +427,37 @@ func (s *sessionDialogCmp) View() string {
 		Render(content)
 }
 
+// sessionItem is the line of the session in the list, its title followed by its tags.
+func sessionItem(sess session.Session) string {
+	item := sess.Title
+	for _, tag := range sess.Tags {
+		item += " #" + tag
+	}
+	if sess.Archived() {
+		item += " (archived)"
+	}
+	return item
+}
+
 func (s *sessionDialogCmp) BindingKeys() []key.Binding {
 	return utils.KeyMapToSlice(sessionKeys)
 }
 
 func (s *sessionDialogCmp) SetSessions(sessions []session.Session) {
 	s.sessions = sessions
+	s.mode = sessionModeList
+	s.input.Blur()
 
-	// If we have a selected session ID, find its index
-	if s.selectedSessionID != "" {
-		for i, sess := range sessions {
-			if sess.ID == s.selectedSessionID {
-				s.selectedIdx = i
-				return
-			}
-		}
-	}
-
-	// Default to first session if selected not found
-	s.selectedIdx = 0
+	// NOTE: the session highlighted stays highlighted as the sessions are updated.
+	s.filter()
 }
 
 func (s *sessionDialogCmp) SetSelectedSession(sessionID string) {
 	s.selectedSessionID = sessionID
 
 	// Update the selected index if sessions are already loaded
-	if len(s.sessions) > 0 {
-		for i, sess := range s.sessions {
+	if len(s.listed) > 0 {
+		for i, sess := range s.listed {
 			if sess.ID == sessionID {
 				s.selectedIdx = i
 				return
@@ -222,9 +468,13 @@ func (s *sessionDialogCmp) SetSelectedSession(sessionID string) {
 
 // NewSessionDialogCmp creates a new session switching dialog
 func NewSessionDialogCmp() SessionDialog {
+	input := textinput.New()
+	input.CharLimit = 200
+	input.Prompt = "> "
 	return &sessionDialogCmp{
 		sessions:          []session.Session{},
 		selectedIdx:       0,
 		selectedSessionID: "",
+		input:             input,
 	}
 }
@@ -81,7 +81,7 @@ func getHelpWidget() string {
 		Render(helpText)
 }
 
-func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
+func formatTokensAndCost(tokens, contextWindow int64, cost, budget float64) string {
 	// Format tokens in human-readable format (e.g., 110K, 1.2M)
 	var formattedTokens string
 	switch {
@@ -103,6 +103,9 @@ func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
 
 	// Format cost with $ symbol and 2 decimal places
 	formattedCost := fmt.Sprintf("$%.2f", cost)
+	if budget > 0 {
+		formattedCost = fmt.Sprintf("$%.2f/$%.2f", cost, budget)
+	}
 
 	percentage := (float64(tokens) / float64(contextWindow)) * 100
 	if percentage > 80 {
@@ -123,13 +126,14 @@ func (m statusCmp) View() string {
 
 	tokenInfoWidth := 0
 	if m.session.ID != "" {
-		totalTokens := m.session.PromptTokens + m.session.CompletionTokens
-		tokens := formatTokensAndCost(totalTokens, model.ContextWindow, m.session.Cost)
+		totalTokens := m.session.ContextTokens
+		budget := config.Get().Budgets.Session
+		tokens := formatTokensAndCost(totalTokens, model.ContextWindow, m.session.Cost, budget.Limit)
 		tokensStyle := styles.Padded().
 			Background(t.Text()).
 			Foreground(t.BackgroundSecondary())
 		percentage := (float64(totalTokens) / float64(model.ContextWindow)) * 100
-		if percentage > 80 {
+		if _, crossed := budget.Crossed(m.session.Cost); percentage > 80 || crossed {
 			tokensStyle = tokensStyle.Background(t.Warning())
 		}
 		tokenInfoWidth = lipgloss.Width(tokens) + 2
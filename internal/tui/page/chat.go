@@ -6,7 +6,6 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/yaydraco/tandem/internal/app"
-	"github.com/yaydraco/tandem/internal/message"
 	"github.com/yaydraco/tandem/internal/session"
 	"github.com/yaydraco/tandem/internal/tui/bubbles/chat"
 	"github.com/yaydraco/tandem/internal/tui/layout"
@@ -52,7 +51,7 @@ func (cp *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd := cp.layout.SetSize(msg.Width, msg.Height)
 		cmds = append(cmds, cmd)
 	case chat.SendMsg:
-		cmd := cp.sendMessage(msg.Text, msg.Attachments)
+		cmd := cp.sendMessage(msg)
 		if cmd != nil {
 			return cp, cmd
 		}
@@ -64,6 +63,12 @@ func (cp *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		cp.session = msg
+	case chat.SessionClearedMsg:
+		// NOTE: the session is cleared as it's deleted too, the sidebar of which is left otherwise.
+		if cp.session.ID != "" {
+			cp.session = session.Session{}
+			cmds = append(cmds, cp.clearSidebar())
+		}
 	case tea.KeyMsg:
 		switch {
 		// Continue sending keys to layout->chat
@@ -74,7 +79,8 @@ func (cp *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				utils.CmdHandler(chat.SessionClearedMsg{}),
 			)
 		case key.Matches(msg, keyMap.Cancel):
-			if cp.session.ID != "" {
+			// NOTE: the editor gets the key when the agent isn't working, to cancel the edit of a prompt.
+			if cp.session.ID != "" && cp.app.Orchestrator.IsSessionBusy(cp.session.ID) {
 				// Cancel the current session's generation process
 				// This allows users to interrupt long-running operations
 				cp.app.Orchestrator.Cancel(cp.session.ID)
@@ -101,8 +107,15 @@ func (cp *chatPage) clearSidebar() tea.Cmd {
 	return cp.layout.ClearRightPanel()
 }
 
-func (p *chatPage) sendMessage(text string, attachments []message.Attachment) tea.Cmd {
+func (p *chatPage) sendMessage(msg chat.SendMsg) tea.Cmd {
 	var cmds []tea.Cmd
+	if msg.Edits != "" {
+		cmd, err := p.replacePrompt(msg.Edits, msg.Fork)
+		if err != nil {
+			return utils.ReportError(err)
+		}
+		cmds = append(cmds, cmd)
+	}
 	if p.session.ID == "" {
 		session, err := p.app.Sessions.Create(context.Background(), "New Session")
 		if err != nil {
@@ -117,12 +130,41 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 		cmds = append(cmds, utils.CmdHandler(chat.SessionSelectedMsg(session)))
 	}
 
-	_, err := p.app.Orchestrator.Run(context.Background(), p.session.ID, text, attachments...)
+	_, err := p.app.Orchestrator.Run(context.Background(), p.session.ID, msg.Text, msg.Attachments...)
 	if err != nil {
 		return utils.ReportError(err)
 	}
 	return tea.Batch(cmds...)
 }
+
+// replacePrompt deletes the prompt edited and the messages after it, for the edit to be sent in its place. In a fork
+// of the session if fork, which is selected, the session keeping them.
+func (p *chatPage) replacePrompt(id string, fork bool) (tea.Cmd, error) {
+	ctx := context.Background()
+	prompt, err := p.app.Messages.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !fork {
+		return nil, p.app.Messages.DeleteFrom(ctx, prompt.SessionID, prompt.ID)
+	}
+	forked, err := p.app.Sessions.Fork(ctx, prompt.SessionID, prompt.ID)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: the fork holds the messages up to the prompt, the copy of which is replaced by the edit.
+	messages, err := p.app.Messages.List(ctx, forked.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > 0 {
+		if err := p.app.Messages.DeleteFrom(ctx, forked.ID, messages[len(messages)-1].ID); err != nil {
+			return nil, err
+		}
+	}
+	p.session = forked
+	return utils.CmdHandler(chat.SessionSelectedMsg(forked)), nil
+}
 func (p *chatPage) BindingKeys() []key.Binding {
 	bindings := utils.KeyMapToSlice(keyMap)
 	bindings = append(bindings, p.messages.BindingKeys()...)
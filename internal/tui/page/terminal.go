@@ -0,0 +1,443 @@
+package page
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/app"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/session"
+	"github.com/yaydraco/tandem/internal/tools"
+	"github.com/yaydraco/tandem/internal/tui/bubbles/chat"
+	"github.com/yaydraco/tandem/internal/tui/layout"
+	"github.com/yaydraco/tandem/internal/tui/styles"
+	"github.com/yaydraco/tandem/internal/tui/theme"
+	"github.com/yaydraco/tandem/internal/utils"
+)
+
+var TerminalPage PageID = "terminal"
+
+// operatorIdleTime is how long the output of a command typed by the operator has to stay quiet for it to be recorded.
+const operatorIdleTime = 2 * time.Second
+
+type TerminalKeyMap struct {
+	Detach      key.Binding
+	NextSession key.Binding
+	PrevSession key.Binding
+	OpenSession key.Binding
+}
+
+// TerminalKeys are the only keys not typed into the attached shell session.
+var TerminalKeys = TerminalKeyMap{
+	Detach: key.NewBinding(
+		key.WithKeys("ctrl+]"),
+		key.WithHelp("ctrl+]", "detach"),
+	),
+	NextSession: key.NewBinding(
+		key.WithKeys("alt+n"),
+		key.WithHelp("alt+n", "next shell session"),
+	),
+	PrevSession: key.NewBinding(
+		key.WithKeys("alt+p"),
+		key.WithHelp("alt+p", "previous shell session"),
+	),
+	OpenSession: key.NewBinding(
+		key.WithKeys("alt+o"),
+		key.WithHelp("alt+o", "open an operator shell session"),
+	),
+}
+
+// terminalEscapes maps the special keys to the escape sequences of an xterm.
+var terminalEscapes = map[tea.KeyType]string{
+	tea.KeySpace:    " ",
+	tea.KeyUp:       "\x1b[A",
+	tea.KeyDown:     "\x1b[B",
+	tea.KeyRight:    "\x1b[C",
+	tea.KeyLeft:     "\x1b[D",
+	tea.KeyShiftTab: "\x1b[Z",
+	tea.KeyHome:     "\x1b[H",
+	tea.KeyEnd:      "\x1b[F",
+	tea.KeyInsert:   "\x1b[2~",
+	tea.KeyDelete:   "\x1b[3~",
+	tea.KeyPgUp:     "\x1b[5~",
+	tea.KeyPgDown:   "\x1b[6~",
+}
+
+type TerminalPageModel interface {
+	tea.Model
+	layout.Sizeable
+	layout.Bindings
+}
+
+// operatorCapture follows what the operator types into a shell session to record the commands.
+type operatorCapture struct {
+	// prompt is the line the operator started typing on.
+	prompt string
+	typed  []rune
+	typing bool
+	// generation counts the commands, a command is recorded at the latest when the next one is entered.
+	generation atomic.Int64
+}
+
+// terminalPage embeds a terminal attached to the shell sessions of the sandbox, the agents' as well as the operator's.
+// The commands typed by the operator are recorded into the chat session as operator tool calls so that the agents see them in context.
+// NOTE: the output is rendered line by line without emulating the terminal, full screen programs won't render properly.
+type terminalPage struct {
+	app           *app.App
+	width, height int
+	sessions      []*tools.ShellSession
+	selected      string
+	output        string
+
+	session  session.Session
+	captures map[string]*operatorCapture
+	// recordMu keeps the recorded commands in order.
+	recordMu sync.Mutex
+}
+
+func (p *terminalPage) Init() tea.Cmd {
+	p.refresh()
+	if len(p.sessions) == 0 {
+		return p.open()
+	}
+	return nil
+}
+
+func (p *terminalPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return p, p.SetSize(msg.Width, msg.Height)
+	case chat.SessionSelectedMsg:
+		p.session = msg
+	case chat.SessionClearedMsg:
+		p.session = session.Session{}
+	case pubsub.Event[tools.ShellSessionEvent]:
+		if msg.Type != pubsub.UpdatedEvent {
+			p.refresh()
+			return p, p.resize()
+		}
+		if msg.Payload.Name == p.selected {
+			p.refreshOutput()
+		}
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, TerminalKeys.NextSession):
+			p.cycle(1)
+			return p, p.resize()
+		case key.Matches(msg, TerminalKeys.PrevSession):
+			p.cycle(-1)
+			return p, p.resize()
+		case key.Matches(msg, TerminalKeys.OpenSession):
+			return p, p.open()
+		}
+		shell := p.shell()
+		if shell == nil {
+			return p, nil
+		}
+		input := terminalInput(msg)
+		if len(input) == 0 {
+			return p, nil
+		}
+		// NOTE: following the line before it's submitted, otherwise its echo would be mistaken for the output.
+		cmd := p.capture(shell, msg)
+		if err := shell.Write(input); err != nil {
+			return p, utils.ReportError(err)
+		}
+		return p, cmd
+	}
+	return p, nil
+}
+
+func (p *terminalPage) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	tabs := make([]string, 0, len(p.sessions))
+	for _, session := range p.sessions {
+		style := baseStyle.Padding(0, 1).Foreground(t.TextMuted())
+		if session.Name == p.selected {
+			style = style.Foreground(t.Background()).Background(t.Primary()).Bold(true)
+		}
+		tabs = append(tabs, style.Render(session.Name))
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+
+	var body string
+	if len(p.sessions) == 0 {
+		body = baseStyle.Foreground(t.TextMuted()).Render("No shell session open, press alt+o to open one.")
+	} else {
+		lines := strings.Split(p.output, "\n")
+		if rows := p.rows(); len(lines) > rows {
+			lines = lines[len(lines)-rows:]
+		}
+		for i, line := range lines {
+			lines[i] = ansi.Truncate(line, p.width, "")
+		}
+		body = baseStyle.Foreground(t.Text()).Render(strings.Join(lines, "\n"))
+	}
+
+	return baseStyle.Width(p.width).Height(p.height).Render(
+		lipgloss.JoinVertical(lipgloss.Left, header, body),
+	)
+}
+
+func (p *terminalPage) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(TerminalKeys)
+}
+
+func (p *terminalPage) GetSize() (int, int) {
+	return p.width, p.height
+}
+
+func (p *terminalPage) SetSize(width int, height int) tea.Cmd {
+	p.width = width
+	p.height = height
+	// NOTE: the sessions might have changed while the page wasn't shown.
+	p.refresh()
+	return p.resize()
+}
+
+func (p *terminalPage) rows() int {
+	// the header takes a row
+	return max(1, p.height-1)
+}
+
+func (p *terminalPage) refresh() {
+	p.sessions = tools.ShellSessions.List()
+	if p.shell() == nil {
+		p.selected = ""
+		if len(p.sessions) > 0 {
+			p.selected = p.sessions[len(p.sessions)-1].Name
+		}
+	}
+	p.refreshOutput()
+}
+
+func (p *terminalPage) refreshOutput() {
+	p.output = ""
+	if session := p.shell(); session != nil {
+		p.output = tools.CleanTerminalOutput(string(session.Transcript()))
+	}
+}
+
+func (p *terminalPage) cycle(step int) {
+	if len(p.sessions) == 0 {
+		return
+	}
+	i := 0
+	for j, session := range p.sessions {
+		if session.Name == p.selected {
+			i = j
+		}
+	}
+	i = (i + step + len(p.sessions)) % len(p.sessions)
+	p.selected = p.sessions[i].Name
+	p.refreshOutput()
+}
+
+func (p *terminalPage) shell() *tools.ShellSession {
+	for _, session := range p.sessions {
+		if session.Name == p.selected {
+			return session
+		}
+	}
+	return nil
+}
+
+// resize fits the PTY of the attached session to the page.
+func (p *terminalPage) resize() tea.Cmd {
+	session := p.shell()
+	if session == nil || p.width <= 0 {
+		return nil
+	}
+	rows, cols := uint(p.rows()), uint(p.width)
+	return func() tea.Msg {
+		if err := session.Resize(context.Background(), rows, cols); err != nil {
+			return utils.InfoMsg{Type: utils.InfoTypeWarn, Msg: fmt.Sprintf("failed to resize shell session %s: %s", session.Name, err)}
+		}
+		return nil
+	}
+}
+
+// open opens a shell session for the operator in the sandbox.
+func (p *terminalPage) open() tea.Cmd {
+	name := "operator"
+	for i := 2; ; i++ {
+		if _, err := tools.ShellSessions.Get(name); err != nil {
+			break
+		}
+		name = fmt.Sprintf("operator-%d", i)
+	}
+	p.selected = name
+	return func() tea.Msg {
+		if _, err := tools.ShellSessions.Open(context.Background(), name, "bash"); err != nil {
+			return utils.InfoMsg{Type: utils.InfoTypeError, Msg: fmt.Sprintf("failed to open the shell session: %s", err)}
+		}
+		return nil
+	}
+}
+
+// capture follows the line typed by the operator and returns the command recording it once submitted.
+func (p *terminalPage) capture(shell *tools.ShellSession, msg tea.KeyMsg) tea.Cmd {
+	c, ok := p.captures[shell.Name]
+	if !ok {
+		c = &operatorCapture{}
+		p.captures[shell.Name] = c
+	}
+	if msg.Type != tea.KeyEnter {
+		if !c.typing {
+			c.prompt = lastLine(shell)
+			c.typing = true
+		}
+		switch msg.Type {
+		case tea.KeyRunes:
+			c.typed = append(c.typed, msg.Runes...)
+		case tea.KeySpace:
+			c.typed = append(c.typed, ' ')
+		case tea.KeyBackspace:
+			if len(c.typed) > 0 {
+				c.typed = c.typed[:len(c.typed)-1]
+			}
+		case tea.KeyCtrlC, tea.KeyCtrlU:
+			c.typed = nil
+		}
+		return nil
+	}
+
+	// The command is read from its echo so that the edits, completions and history are accounted for.
+	command := string(c.typed)
+	if line := lastLine(shell); c.prompt != "" && strings.HasPrefix(line, c.prompt) {
+		command = strings.TrimPrefix(line, c.prompt)
+	}
+	prompt := c.prompt
+	c.typed, c.typing = nil, false
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	if p.session.ID == "" {
+		session, err := p.app.Sessions.Create(context.Background(), "Operator Session")
+		if err != nil {
+			return utils.ReportError(err)
+		}
+		p.session = session
+		cmds = append(cmds, utils.CmdHandler(chat.SessionSelectedMsg(session)))
+	}
+
+	generation := c.generation.Add(1)
+	start := shell.Offset()
+	sessionID := p.session.ID
+	return tea.Batch(append(cmds, func() tea.Msg {
+		offset := shell.Offset()
+		for quiet := time.Duration(0); quiet < operatorIdleTime && !shell.Closed() && c.generation.Load() == generation; {
+			time.Sleep(250 * time.Millisecond)
+			if current := shell.Offset(); current != offset {
+				offset, quiet = current, 0
+				continue
+			}
+			quiet += 250 * time.Millisecond
+		}
+		output := strings.TrimSpace(tools.CleanTerminalOutput(shell.Since(start)))
+		output = strings.TrimSpace(strings.TrimSuffix(output, strings.TrimSpace(prompt)))
+		if err := p.record(sessionID, shell.Name, command, output); err != nil {
+			return utils.InfoMsg{Type: utils.InfoTypeError, Msg: fmt.Sprintf("failed to record the operator's command: %s", err)}
+		}
+		return nil
+	})...)
+}
+
+// waitIdle blocks until the orchestrator is done with the session, woken up by the events it publishes once done with a request.
+func (p *terminalPage) waitIdle(sessionID string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := p.app.Orchestrator.Subscribe(ctx)
+	for p.app.Orchestrator.IsSessionBusy(sessionID) {
+		if _, ok := <-events; !ok {
+			return
+		}
+	}
+}
+
+// record adds the command typed by the operator to the chat session as a tool call along with its output.
+func (p *terminalPage) record(sessionID, shell, command, output string) error {
+	p.recordMu.Lock()
+	defer p.recordMu.Unlock()
+
+	ctx := context.Background()
+	// NOTE: waiting for the orchestrator to be done, its tool calls have to be followed by their results.
+	p.waitIdle(sessionID)
+
+	input, err := json.Marshal(tools.OperatorShellArgs{Name: shell, Command: command})
+	if err != nil {
+		return err
+	}
+	toolCallID := uuid.New().String()
+	if _, err := p.app.Messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role: message.Assistant,
+		Parts: []message.ContentPart{
+			message.ToolCall{
+				ID:       toolCallID,
+				Name:     tools.OperatorShellToolName,
+				Input:    string(input),
+				Type:     "function",
+				Finished: true,
+			},
+			message.Finish{Reason: message.FinishReasonToolUse, Time: time.Now().Unix()},
+		},
+	}); err != nil {
+		return err
+	}
+	_, err = p.app.Messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role: message.Tool,
+		Parts: []message.ContentPart{message.ToolResult{
+			ToolCallID: toolCallID,
+			Name:       tools.OperatorShellToolName,
+			Content:    output,
+		}},
+	})
+	return err
+}
+
+// lastLine returns the line the cursor of the shell session is on.
+func lastLine(shell *tools.ShellSession) string {
+	output := tools.CleanTerminalOutput(shell.Since(shell.Offset() - 4096))
+	return output[strings.LastIndex(output, "\n")+1:]
+}
+
+// terminalInput converts the key into the bytes a terminal would send for it.
+func terminalInput(msg tea.KeyMsg) []byte {
+	var input string
+	switch {
+	case msg.Type == tea.KeyRunes:
+		input = string(msg.Runes)
+	case msg.Type >= 0:
+		// NOTE: the rest of the key types are the control characters themselves.
+		input = string(rune(msg.Type))
+	default:
+		input = terminalEscapes[msg.Type]
+	}
+	if msg.Alt && input != "" {
+		input = "\x1b" + input
+	}
+	return []byte(input)
+}
+
+func NewTerminalPage(app *app.App) TerminalPageModel {
+	return &terminalPage{
+		app:      app,
+		captures: make(map[string]*operatorCapture),
+	}
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,7 +11,10 @@ import (
 	"github.com/yaydraco/tandem/internal/agent"
 	"github.com/yaydraco/tandem/internal/app"
 	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/engagement"
 	"github.com/yaydraco/tandem/internal/logging"
+	"github.com/yaydraco/tandem/internal/message"
+	"github.com/yaydraco/tandem/internal/models"
 	"github.com/yaydraco/tandem/internal/pubsub"
 	"github.com/yaydraco/tandem/internal/session"
 	"github.com/yaydraco/tandem/internal/tui/bubbles"
@@ -31,8 +35,13 @@ type keyMap struct {
 	Quit          key.Binding
 	Help          key.Binding
 	SwitchSession key.Binding
+	Engagements   key.Binding
+	Search        key.Binding
+	Fork          key.Binding
+	Branches      key.Binding
 	Filepicker    key.Binding
 	Models        key.Binding
+	Terminal      key.Binding
 }
 
 var keys = keyMap{
@@ -55,6 +64,26 @@ var keys = keyMap{
 		key.WithHelp("ctrl+s", "switch session"),
 	),
 
+	Engagements: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "switch engagement"),
+	),
+
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+k"),
+		key.WithHelp("ctrl+k", "search history"),
+	),
+
+	Fork: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "message actions: fork, edit, regenerate"),
+	),
+
+	Branches: key.NewBinding(
+		key.WithKeys("ctrl+b"),
+		key.WithHelp("ctrl+b", "branches of the session"),
+	),
+
 	Filepicker: key.NewBinding(
 		key.WithKeys("ctrl+f"),
 		key.WithHelp("ctrl+f", "select files to upload"),
@@ -63,6 +92,10 @@ var keys = keyMap{
 		key.WithKeys("ctrl+o"),
 		key.WithHelp("ctrl+o", "model selection"),
 	),
+	Terminal: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "attach to the shell sessions"),
+	),
 }
 
 var returnKey = key.NewBinding(
@@ -97,6 +130,22 @@ type appModel struct {
 	showModelDialog bool
 	modelDialog     dialog.ModelDialog
 
+	showEngagementDialog bool
+	engagementDialog     dialog.EngagementDialog
+	// NOTE: the engagement to restart in once the program exits, if switched to.
+	switchedEngagement *string
+
+	showSearchDialog bool
+	searchDialog     dialog.SearchDialog
+
+	showMessageDialog bool
+	messageDialog     dialog.MessageDialog
+	// regenerating is the message whose response is regenerated with the model selected next, if any.
+	regenerating *message.Message
+
+	showBranchDialog bool
+	branchDialog     dialog.BranchDialog
+
 	showFilepicker bool
 	filepicker     dialog.FilepickerCmp
 
@@ -115,9 +164,15 @@ func New(app *app.App) tea.Model {
 		sessionDialog: dialog.NewSessionDialogCmp(),
 		modelDialog:   dialog.NewModelDialogCmp(),
 		app:           app,
+
+		engagementDialog: dialog.NewEngagementDialogCmp(),
+		searchDialog:     dialog.NewSearchDialogCmp(app.Messages),
+		messageDialog:    dialog.NewMessageDialogCmp(),
+		branchDialog:     dialog.NewBranchDialogCmp(),
 		pages: map[page.PageID]tea.Model{
-			page.ChatPage: page.NewChatPage(app),
-			page.LogsPage: page.NewLogsPage(),
+			page.ChatPage:     page.NewChatPage(app),
+			page.LogsPage:     page.NewLogsPage(),
+			page.TerminalPage: page.NewTerminalPage(app),
 		},
 		filepicker: dialog.NewFilepickerCmp(app),
 	}
@@ -142,6 +197,14 @@ func (a appModel) Init() tea.Cmd {
 	cmds = append(cmds, cmd)
 	cmd = a.modelDialog.Init()
 	cmds = append(cmds, cmd)
+	cmd = a.engagementDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.searchDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.messageDialog.Init()
+	cmds = append(cmds, cmd)
+	cmd = a.branchDialog.Init()
+	cmds = append(cmds, cmd)
 
 	cmd = a.filepicker.Init()
 	cmds = append(cmds, cmd)
@@ -170,6 +233,22 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.sessionDialog = session.(dialog.SessionDialog)
 		cmds = append(cmds, sessionCmd)
 
+		engagements, engagementCmd := a.engagementDialog.Update(msg)
+		a.engagementDialog = engagements.(dialog.EngagementDialog)
+		cmds = append(cmds, engagementCmd)
+
+		search, searchCmd := a.searchDialog.Update(msg)
+		a.searchDialog = search.(dialog.SearchDialog)
+		cmds = append(cmds, searchCmd)
+
+		messages, messageCmd := a.messageDialog.Update(msg)
+		a.messageDialog = messages.(dialog.MessageDialog)
+		cmds = append(cmds, messageCmd)
+
+		branches, branchCmd := a.branchDialog.Update(msg)
+		a.branchDialog = branches.(dialog.BranchDialog)
+		cmds = append(cmds, branchCmd)
+
 		filepicker, filepickerCmd := a.filepicker.Update(msg)
 		a.filepicker = filepicker.(dialog.FilepickerCmp)
 		cmds = append(cmds, filepickerCmd)
@@ -235,6 +314,85 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.showSessionDialog = false
 		return a, nil
 
+	case dialog.CloseEngagementDialogMsg:
+		a.showEngagementDialog = false
+		return a, nil
+
+	case dialog.CloseSearchDialogMsg:
+		a.showSearchDialog = false
+		return a, nil
+
+	case dialog.CloseMessageDialogMsg:
+		a.showMessageDialog = false
+		return a, nil
+
+	case dialog.MessageSelectedMsg:
+		a.showMessageDialog = false
+		if a.app.Orchestrator.IsSessionBusy(a.selectedSession.ID) {
+			return a, utils.ReportWarn("Agent is busy, please wait...")
+		}
+		switch msg.Action {
+		case dialog.MessageActionEdit, dialog.MessageActionEditFork:
+			return a, utils.CmdHandler(chat.EditMsg{
+				Message: msg.Message,
+				Fork:    msg.Action == dialog.MessageActionEditFork,
+			})
+		case dialog.MessageActionRegenerate:
+			return a, a.regenerate(msg.Message, "")
+		case dialog.MessageActionRegenerateModel:
+			a.regenerating = &msg.Message
+			a.showModelDialog = true
+			return a, utils.ReportInfo("Select the model to regenerate the response with")
+		case dialog.MessageActionContinue:
+			if _, err := a.app.Orchestrator.Resume(context.Background(), a.selectedSession.ID); err != nil {
+				return a, utils.ReportError(err)
+			}
+			return a, nil
+		}
+		fork, err := a.app.Sessions.Fork(context.Background(), a.selectedSession.ID, msg.Message.ID)
+		if err != nil {
+			return a, utils.ReportError(err)
+		}
+		return a, tea.Batch(
+			utils.CmdHandler(chat.SessionSelectedMsg(fork)),
+			utils.ReportInfo("Forked the session, ctrl+b to go back to the original"),
+		)
+
+	case dialog.CloseBranchDialogMsg:
+		a.showBranchDialog = false
+		return a, nil
+
+	case dialog.BranchSelectedMsg:
+		a.showBranchDialog = false
+		if a.currentPage == page.ChatPage {
+			return a, utils.CmdHandler(chat.SessionSelectedMsg(msg.Session))
+		}
+		return a, nil
+
+	case dialog.SearchResultSelectedMsg:
+		a.showSearchDialog = false
+		// NOTE: the root session is opened, the task sessions of the agents being shown within it.
+		sess, err := a.app.Sessions.Get(context.Background(), msg.Result.RootSessionID)
+		if err != nil {
+			return a, utils.ReportError(err)
+		}
+		if a.currentPage == page.ChatPage {
+			return a, utils.CmdHandler(chat.SessionSelectedMsg(sess))
+		}
+		return a, nil
+
+	case dialog.EngagementSelectedMsg:
+		a.showEngagementDialog = false
+		name := msg.Engagement.Name
+		if name == config.Get().Engagement {
+			return a, nil
+		}
+		if err := engagement.Switch(name); err != nil {
+			return a, utils.ReportError(err)
+		}
+		a.switchedEngagement = &name
+		return a, tea.Quit
+
 	case startCompactSessionMsg:
 		// Start compacting the current session
 		a.isCompacting = true
@@ -267,7 +425,7 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else if payload.Done && payload.Type == agent.AgentEventTypeResponse && a.selectedSession.ID != "" {
 			model := a.app.Orchestrator.Model()
 			contextWindow := model.ContextWindow
-			tokens := a.selectedSession.CompletionTokens + a.selectedSession.PromptTokens
+			tokens := a.selectedSession.ContextTokens
 			if (tokens >= int64(float64(contextWindow)*0.95)) && config.Get().AutoCompact {
 				return a, utils.CmdHandler(startCompactSessionMsg{})
 			}
@@ -277,21 +435,48 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case dialog.CloseModelDialogMsg:
 		a.showModelDialog = false
+		a.regenerating = nil
 		return a, nil
 
 	case dialog.ModelSelectedMsg:
 		a.showModelDialog = false
 
+		// NOTE: the response is regenerated with the model selected, the model of the orchestrator being left as is.
+		if a.regenerating != nil {
+			regenerating := *a.regenerating
+			a.regenerating = nil
+			return a, tea.Batch(
+				a.regenerate(regenerating, msg.Model.ID),
+				utils.ReportInfo(fmt.Sprintf("Regenerating with %s", msg.Model.Name)),
+			)
+		}
+
 		model, err := a.app.Orchestrator.Update(config.Orchestrator, msg.Model.ID)
 		if err != nil {
 			return a, utils.ReportError(err)
 		}
-
+		if err := a.app.Audit.RecordConfig(context.Background()); err != nil {
+			return a, utils.ReportError(err)
+		}
 		return a, utils.ReportInfo(fmt.Sprintf("Model changed to %s", model.Name))
 
 	case chat.SessionSelectedMsg:
 		a.selectedSession = msg
 		a.sessionDialog.SetSelectedSession(msg.ID)
+		// NOTE: the chat and the terminal follow the same session, whichever page it's selected from.
+		for _, id := range []page.PageID{page.ChatPage, page.TerminalPage} {
+			if id != a.currentPage {
+				a.pages[id], cmd = a.pages[id].Update(msg)
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case chat.SessionClearedMsg:
+		a.selectedSession = session.Session{}
+		if a.currentPage != page.TerminalPage {
+			a.pages[page.TerminalPage], cmd = a.pages[page.TerminalPage].Update(msg)
+			cmds = append(cmds, cmd)
+		}
 
 	case pubsub.Event[session.Session]:
 		if msg.Type == pubsub.UpdatedEvent && msg.Payload.ID == a.selectedSession.ID {
@@ -304,7 +489,50 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case dialog.RenameSessionMsg:
+		if _, err := a.app.Sessions.Rename(context.Background(), msg.Session.ID, msg.Title); err != nil {
+			return a, utils.ReportError(err)
+		}
+		return a, a.reloadSessions()
+
+	case dialog.TagSessionMsg:
+		if _, err := a.app.Sessions.SetTags(context.Background(), msg.Session.ID, msg.Tags); err != nil {
+			return a, utils.ReportError(err)
+		}
+		return a, a.reloadSessions()
+
+	case dialog.ArchiveSessionMsg:
+		if _, err := a.app.Sessions.Archive(context.Background(), msg.Session.ID, msg.Archived); err != nil {
+			return a, utils.ReportError(err)
+		}
+		info := "Restored the session"
+		if msg.Archived {
+			info = "Archived the session, A to show the archived sessions"
+		}
+		return a, tea.Batch(a.reloadSessions(), utils.ReportInfo(info))
+
+	case dialog.DeleteSessionMsg:
+		if a.app.Orchestrator.IsSessionBusy(msg.Session.ID) {
+			return a, utils.ReportWarn("Agent is busy, please wait...")
+		}
+		if err := a.app.Sessions.Delete(context.Background(), msg.Session.ID); err != nil {
+			return a, utils.ReportError(err)
+		}
+		cmds = append(cmds, a.reloadSessions(), utils.ReportInfo("Deleted the session "+msg.Session.Title))
+		if msg.Session.ID == a.selectedSession.ID {
+			cmds = append(cmds, utils.CmdHandler(chat.SessionClearedMsg{}))
+		}
+		return a, tea.Batch(cmds...)
+
 	case tea.KeyMsg:
+		// NOTE: the keys are typed into the attached shell session, but for detaching.
+		if a.currentPage == page.TerminalPage && !a.showQuit {
+			if key.Matches(msg, page.TerminalKeys.Detach) {
+				return a, a.moveToPage(a.previousPage)
+			}
+			a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
+			return a, cmd
+		}
 		switch {
 
 		case key.Matches(msg, keys.Quit):
@@ -322,6 +550,18 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if a.showModelDialog {
 				a.showModelDialog = false
 			}
+			if a.showEngagementDialog {
+				a.showEngagementDialog = false
+			}
+			if a.showSearchDialog {
+				a.showSearchDialog = false
+			}
+			if a.showMessageDialog {
+				a.showMessageDialog = false
+			}
+			if a.showBranchDialog {
+				a.showBranchDialog = false
+			}
 
 			return a, nil
 		case key.Matches(msg, keys.SwitchSession):
@@ -335,11 +575,77 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return a, utils.ReportWarn("No sessions available")
 				}
 				a.sessionDialog.SetSessions(sessions)
+				a.sessionDialog.SetSelectedSession(a.selectedSession.ID)
 				a.showSessionDialog = true
 				return a, nil
 			}
 			return a, nil
 
+		case key.Matches(msg, keys.Engagements):
+			if a.showEngagementDialog {
+				a.showEngagementDialog = false
+				return a, nil
+			}
+			if a.currentPage == page.ChatPage && !a.showQuit && !a.showSessionDialog && !a.showModelDialog {
+				if a.app.Orchestrator.IsBusy() {
+					return a, utils.ReportWarn("Agent is busy, please wait...")
+				}
+				engagements, err := engagement.List()
+				if err != nil {
+					return a, utils.ReportError(err)
+				}
+				a.engagementDialog.SetEngagements(engagements, config.Get().Engagement)
+				a.showEngagementDialog = true
+			}
+			return a, nil
+
+		case key.Matches(msg, keys.Search):
+			if a.showSearchDialog {
+				a.showSearchDialog = false
+				return a, nil
+			}
+			if a.currentPage == page.ChatPage && !a.showQuit && !a.showSessionDialog && !a.showModelDialog && !a.showEngagementDialog {
+				a.showSearchDialog = true
+				return a, a.searchDialog.Reset()
+			}
+			return a, nil
+
+		case key.Matches(msg, keys.Fork):
+			if a.showMessageDialog {
+				a.showMessageDialog = false
+				return a, nil
+			}
+			if a.currentPage == page.ChatPage && !a.showQuit && !a.showSessionDialog && !a.showModelDialog && !a.showEngagementDialog && !a.showSearchDialog && !a.showBranchDialog {
+				if a.selectedSession.ID == "" {
+					return a, utils.ReportWarn("No session to act on the messages of")
+				}
+				messages, err := a.app.Messages.List(context.Background(), a.selectedSession.ID)
+				if err != nil {
+					return a, utils.ReportError(err)
+				}
+				a.messageDialog.SetMessages(messages)
+				a.showMessageDialog = true
+			}
+			return a, nil
+
+		case key.Matches(msg, keys.Branches):
+			if a.showBranchDialog {
+				a.showBranchDialog = false
+				return a, nil
+			}
+			if a.currentPage == page.ChatPage && !a.showQuit && !a.showSessionDialog && !a.showModelDialog && !a.showEngagementDialog && !a.showSearchDialog && !a.showMessageDialog {
+				if a.selectedSession.ID == "" {
+					return a, utils.ReportWarn("No session to show the branches of")
+				}
+				sessions, err := a.app.Sessions.List(context.Background())
+				if err != nil {
+					return a, utils.ReportError(err)
+				}
+				a.branchDialog.SetSessions(sessions, a.selectedSession.ID)
+				a.showBranchDialog = true
+			}
+			return a, nil
+
 		case key.Matches(msg, keys.Models):
 			if a.showModelDialog {
 				a.showModelDialog = false
@@ -347,6 +653,7 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			if a.currentPage == page.ChatPage && !a.showQuit && !a.showSessionDialog {
 				a.showModelDialog = true
+				a.regenerating = nil
 				return a, nil
 			}
 			return a, nil
@@ -376,6 +683,8 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case key.Matches(msg, keys.Logs):
 			return a, a.moveToPage(page.LogsPage)
+		case key.Matches(msg, keys.Terminal):
+			return a, a.moveToPage(page.TerminalPage)
 		case key.Matches(msg, keys.Help):
 
 			if a.showQuit {
@@ -436,6 +745,46 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if a.showEngagementDialog {
+		d, engagementCmd := a.engagementDialog.Update(msg)
+		a.engagementDialog = d.(dialog.EngagementDialog)
+		cmds = append(cmds, engagementCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showSearchDialog {
+		d, searchCmd := a.searchDialog.Update(msg)
+		a.searchDialog = d.(dialog.SearchDialog)
+		cmds = append(cmds, searchCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showMessageDialog {
+		d, messageCmd := a.messageDialog.Update(msg)
+		a.messageDialog = d.(dialog.MessageDialog)
+		cmds = append(cmds, messageCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showBranchDialog {
+		d, branchCmd := a.branchDialog.Update(msg)
+		a.branchDialog = d.(dialog.BranchDialog)
+		cmds = append(cmds, branchCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
 	s, _ := a.status.Update(msg)
 	a.status = s.(bubbles.StatusCmp)
 	a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
@@ -558,11 +907,123 @@ func (a appModel) View() string {
 		)
 	}
 
+	if a.showEngagementDialog {
+		overlay := a.engagementDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+		)
+	}
+
+	if a.showSearchDialog {
+		overlay := a.searchDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+		)
+	}
+
+	if a.showMessageDialog {
+		overlay := a.messageDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+		)
+	}
+
+	if a.showBranchDialog {
+		overlay := a.branchDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+		)
+	}
+
 	return appView
 }
 
+// SwitchedEngagement returns the engagement switched to from the picker, empty for the configuration of the
+// working directory, once the program exits for tandem to be restarted in it.
+func SwitchedEngagement(model tea.Model) (string, bool) {
+	var switched *string
+	switch a := model.(type) {
+	case appModel:
+		switched = a.switchedEngagement
+	case *appModel:
+		switched = a.switchedEngagement
+	}
+	if switched == nil {
+		return "", false
+	}
+	return *switched, true
+}
+
+// reloadSessions lists the sessions anew in the session dialog, once changed from it.
+func (a *appModel) reloadSessions() tea.Cmd {
+	sessions, err := a.app.Sessions.List(context.Background())
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	a.sessionDialog.SetSessions(sessions)
+	return nil
+}
+
+// regenerate generates the response to the prompt anew, or the response from the message on, the messages after it
+// being deleted. It's generated with the model given, or with the model of the orchestrator if it's empty.
+func (a *appModel) regenerate(msg message.Message, modelID models.ModelID) tea.Cmd {
+	if a.app.Orchestrator.IsSessionBusy(msg.SessionID) {
+		return utils.ReportWarn("Agent is busy, please wait...")
+	}
+	ctx := context.Background()
+	messages, err := a.app.Messages.List(ctx, msg.SessionID)
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	from := slices.IndexFunc(messages, func(m message.Message) bool { return m.ID == msg.ID })
+	if from == -1 {
+		return utils.ReportWarn("The message is no longer in the session")
+	}
+	if msg.Role == message.User {
+		from++
+	}
+	fromID := ""
+	if from < len(messages) {
+		fromID = messages[from].ID
+	}
+	if _, err := a.app.Orchestrator.Regenerate(ctx, msg.SessionID, fromID, modelID); err != nil {
+		return utils.ReportError(err)
+	}
+	return nil
+}
+
 func (a *appModel) moveToPage(pageID page.PageID) tea.Cmd {
-	if a.app.Orchestrator.IsBusy() {
+	// NOTE: the operator can still take over the shell sessions while the agents are working.
+	terminal := pageID == page.TerminalPage || a.currentPage == page.TerminalPage
+	if a.app.Orchestrator.IsBusy() && !terminal {
 		// For now we don't move to any page if the agent is busy
 		return utils.ReportWarn("Agent is busy, please wait...")
 	}
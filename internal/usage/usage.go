@@ -0,0 +1,162 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/models"
+	"github.com/yaydraco/tandem/internal/pubsub"
+)
+
+// Kind tells what a provider call was made for.
+type Kind string
+
+const (
+	KindCompletion Kind = "completion"
+	KindTitle      Kind = "title"
+	KindSummarize  Kind = "summarize"
+)
+
+// Usage is an entry of the ledger, recorded for every provider call.
+type Usage struct {
+	ID                  string
+	SessionID           string
+	MessageID           string
+	Provider            models.ModelProvider
+	Model               models.ModelID
+	Agent               string
+	Kind                Kind
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	Cost                float64
+	CreatedAt           int64
+}
+
+// Totals are the sums of the ledger entries of a session and its task sessions, or of the engagement.
+type Totals struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	Cost             float64
+}
+
+type CreateUsageParams struct {
+	SessionID           string
+	MessageID           string
+	Model               models.Model
+	Agent               string
+	Kind                Kind
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+}
+
+type Service interface {
+	pubsub.Subscriber[Usage]
+	Record(ctx context.Context, params CreateUsageParams) (Usage, error)
+	List(ctx context.Context, sessionID string) ([]Usage, error)
+	Totals(ctx context.Context, sessionID string) (Totals, error)
+	// EngagementTotals are the sums of every entry of the ledger, the ones of the sessions deleted since included.
+	EngagementTotals(ctx context.Context) (Totals, error)
+}
+
+type service struct {
+	*pubsub.Broker[Usage]
+	q db.Querier
+}
+
+// Cost calculates the cost of a provider call based on the model pricing.
+func Cost(model models.Model, inputTokens, outputTokens, cacheCreationTokens, cacheReadTokens int64) float64 {
+	return model.CostPer1MInCached/1e6*float64(cacheCreationTokens) +
+		model.CostPer1MOutCached/1e6*float64(cacheReadTokens) +
+		model.CostPer1MIn/1e6*float64(inputTokens) +
+		model.CostPer1MOut/1e6*float64(outputTokens)
+}
+
+func (s *service) Record(ctx context.Context, params CreateUsageParams) (Usage, error) {
+	dbUsage, err := s.q.CreateUsage(ctx, db.CreateUsageParams{
+		ID:                  uuid.New().String(),
+		SessionID:           params.SessionID,
+		MessageID:           sql.NullString{String: params.MessageID, Valid: params.MessageID != ""},
+		Provider:            string(params.Model.Provider),
+		Model:               string(params.Model.ID),
+		Agent:               params.Agent,
+		Kind:                string(params.Kind),
+		InputTokens:         params.InputTokens,
+		OutputTokens:        params.OutputTokens,
+		CacheCreationTokens: params.CacheCreationTokens,
+		CacheReadTokens:     params.CacheReadTokens,
+		Cost:                Cost(params.Model, params.InputTokens, params.OutputTokens, params.CacheCreationTokens, params.CacheReadTokens),
+	})
+	if err != nil {
+		return Usage{}, err
+	}
+	usage := s.fromDBItem(dbUsage)
+	s.Publish(pubsub.CreatedEvent, usage)
+	return usage, nil
+}
+
+func (s *service) List(ctx context.Context, sessionID string) ([]Usage, error) {
+	dbUsages, err := s.q.ListUsageBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]Usage, len(dbUsages))
+	for i, dbUsage := range dbUsages {
+		usages[i] = s.fromDBItem(dbUsage)
+	}
+	return usages, nil
+}
+
+func (s *service) Totals(ctx context.Context, sessionID string) (Totals, error) {
+	row, err := s.q.GetSessionTreeUsage(ctx, sessionID)
+	if err != nil {
+		return Totals{}, err
+	}
+	return Totals{
+		PromptTokens:     row.PromptTokens,
+		CompletionTokens: row.CompletionTokens,
+		Cost:             row.Cost,
+	}, nil
+}
+
+func (s *service) EngagementTotals(ctx context.Context) (Totals, error) {
+	row, err := s.q.GetEngagementUsage(ctx)
+	if err != nil {
+		return Totals{}, err
+	}
+	return Totals{
+		PromptTokens:     row.PromptTokens,
+		CompletionTokens: row.CompletionTokens,
+		Cost:             row.Cost,
+	}, nil
+}
+
+func (s *service) fromDBItem(item db.UsageLedger) Usage {
+	return Usage{
+		ID:                  item.ID,
+		SessionID:           item.SessionID,
+		MessageID:           item.MessageID.String,
+		Provider:            models.ModelProvider(item.Provider),
+		Model:               models.ModelID(item.Model),
+		Agent:               item.Agent,
+		Kind:                Kind(item.Kind),
+		InputTokens:         item.InputTokens,
+		OutputTokens:        item.OutputTokens,
+		CacheCreationTokens: item.CacheCreationTokens,
+		CacheReadTokens:     item.CacheReadTokens,
+		Cost:                item.Cost,
+		CreatedAt:           item.CreatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Usage](),
+		q:      q,
+	}
+}
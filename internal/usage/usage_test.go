@@ -0,0 +1,95 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/config"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/models"
+)
+
+// newTestQueries returns the queries of a database migrated in a temporary data directory.
+func newTestQueries(t *testing.T) *db.Queries {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	if _, err := config.Load(".", false); err != nil {
+		t.Fatalf("config.Load() failed: %v", err)
+	}
+	conn, err := db.Connect()
+	if err != nil {
+		t.Fatalf("db.Connect() failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return db.New(conn)
+}
+
+func TestTotals(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueries(t)
+	for _, s := range []db.CreateSessionParams{
+		{ID: "root", Title: "scan"},
+		{ID: "task", Title: "task", ParentSessionID: sql.NullString{String: "root", Valid: true}},
+		{ID: "other", Title: "other"},
+	} {
+		if _, err := q.CreateSession(ctx, s); err != nil {
+			t.Fatalf("CreateSession() failed: %v", err)
+		}
+	}
+	// The model costs $1 per million input tokens and $2 per million output tokens.
+	model := models.Model{ID: models.Claude4Sonnet, Provider: models.ProviderAnthropic, CostPer1MIn: 1, CostPer1MOut: 2}
+	s := NewService(q)
+	for _, params := range []CreateUsageParams{
+		{SessionID: "root", Model: model, Kind: KindCompletion, InputTokens: 1_000_000},
+		{SessionID: "task", Model: model, Kind: KindCompletion, OutputTokens: 1_000_000},
+		{SessionID: "other", Model: model, Kind: KindTitle, InputTokens: 2_000_000},
+	} {
+		if _, err := s.Record(ctx, params); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		expected  Totals
+	}{
+		{name: "session with its task sessions", sessionID: "root", expected: Totals{PromptTokens: 1_000_000, CompletionTokens: 1_000_000, Cost: 3}},
+		{name: "task session", sessionID: "task", expected: Totals{CompletionTokens: 1_000_000, Cost: 2}},
+		{name: "other session", sessionID: "other", expected: Totals{PromptTokens: 2_000_000, Cost: 2}},
+		{name: "unknown session", sessionID: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totals, err := s.Totals(ctx, tt.sessionID)
+			if err != nil {
+				t.Fatalf("Totals() failed: %v", err)
+			}
+			if totals != tt.expected {
+				t.Errorf("Totals(%s) = %+v, expected %+v", tt.sessionID, totals, tt.expected)
+			}
+		})
+	}
+
+	// The spend of the engagement doesn't go down as the sessions are deleted.
+	expected := Totals{PromptTokens: 3_000_000, CompletionTokens: 1_000_000, Cost: 5}
+	for _, id := range []string{"", "other"} {
+		if id != "" {
+			if err := q.DeleteSession(ctx, id); err != nil {
+				t.Fatalf("DeleteSession() failed: %v", err)
+			}
+		}
+		totals, err := s.EngagementTotals(ctx)
+		if err != nil {
+			t.Fatalf("EngagementTotals() failed: %v", err)
+		}
+		if totals != expected {
+			t.Errorf("EngagementTotals() = %+v, expected %+v", totals, expected)
+		}
+	}
+	if usages, err := s.List(ctx, "other"); err != nil || len(usages) != 1 {
+		t.Errorf("List() of the deleted session = %v, %v, expected its entry to be kept", usages, err)
+	}
+}
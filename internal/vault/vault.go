@@ -0,0 +1,265 @@
+// Package vault keeps the credentials discovered during the engagement, their secrets encrypted at rest.
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/encryption"
+	"github.com/yaydraco/tandem/internal/pubsub"
+	"github.com/yaydraco/tandem/internal/redact"
+)
+
+// KeyFile is the file of the data directory holding the key of the vault.
+const KeyFile = "vault.key"
+
+type Kind string
+
+const (
+	KindPassword Kind = "password"
+	KindHash     Kind = "hash"
+	KindToken    Kind = "token"
+	KindKey      Kind = "key"
+	KindOther    Kind = "other"
+)
+
+var (
+	Kinds = []Kind{KindPassword, KindHash, KindToken, KindKey, KindOther}
+
+	refPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+)
+
+// Credential is a credential of the vault, without its secret.
+type Credential struct {
+	ID        string
+	SessionID string
+	// Ref is the reference the agents use the credential by, through its placeholder.
+	Ref         string
+	Kind        Kind
+	Username    string
+	Target      string
+	Notes       string
+	Placeholder string
+	CreatedAt   int64
+	UpdatedAt   int64
+}
+
+type StoreParams struct {
+	SessionID string
+	Ref       string
+	Kind      Kind
+	Username  string
+	Target    string
+	Secret    string
+	Notes     string
+}
+
+type Service interface {
+	pubsub.Subscriber[Credential]
+	// Load registers the secrets of the vault with the redactor, for them to be redacted from anything persisted.
+	Load(ctx context.Context) error
+	Store(ctx context.Context, params StoreParams) (Credential, error)
+	Get(ctx context.Context, ref string) (Credential, error)
+	List(ctx context.Context) ([]Credential, error)
+	// Reveal returns the secret of the credential in plaintext.
+	Reveal(ctx context.Context, ref string) (string, error)
+}
+
+type service struct {
+	*pubsub.Broker[Credential]
+	q    db.Querier
+	aead cipher.AEAD
+}
+
+func NewService(q db.Querier, key []byte) (Service, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &service{
+		Broker: pubsub.NewBroker[Credential](),
+		q:      q,
+		aead:   aead,
+	}, nil
+}
+
+// LoadKey reads the key of the vault out of the data directory, generating it on first use.
+// The key is itself encrypted with the key of the data directory while it's encrypted.
+func LoadKey(dataDir string) ([]byte, error) {
+	path := filepath.Join(dataDir, KeyFile)
+	content, err := os.ReadFile(path)
+	if err == nil {
+		key := content
+		if encryption.IsEncrypted(content) {
+			if key, err = encryption.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("failed to decrypt the vault key: %w", err)
+			}
+		} else if active := encryption.Active(); active != nil {
+			// NOTE: the key was generated before the data directory was encrypted.
+			if err := encryption.ReencryptFile(path, nil, active); err != nil {
+				return nil, fmt.Errorf("failed to encrypt the vault key: %w", err)
+			}
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid vault key %s: expected 32 bytes, got %d", path, len(key))
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the vault key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	content = key
+	if active := encryption.Active(); active != nil {
+		if content, err = active.Encrypt(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	// NOTE: O_EXCL, for another tandem starting along not to overwrite the key it just generated.
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return LoadKey(dataDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the vault key: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to write the vault key: %w", err)
+	}
+	return key, nil
+}
+
+func (s *service) Load(ctx context.Context) error {
+	items, err := s.q.ListCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		secret, err := s.open(item.Secret)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt the credential %s: %w", item.Ref, err)
+		}
+		redact.Add(item.Ref, secret)
+	}
+	return nil
+}
+
+func (s *service) Store(ctx context.Context, params StoreParams) (Credential, error) {
+	if !refPattern.MatchString(params.Ref) {
+		return Credential{}, fmt.Errorf("invalid reference %q: letters, digits, dots, dashes and underscores only", params.Ref)
+	}
+	if !slices.Contains(Kinds, params.Kind) {
+		return Credential{}, fmt.Errorf("invalid kind: %s", params.Kind)
+	}
+	if params.Secret == "" {
+		return Credential{}, fmt.Errorf("the secret is empty")
+	}
+	sealed, err := s.seal(params.Secret)
+	if err != nil {
+		return Credential{}, err
+	}
+	item, err := s.q.UpsertCredential(ctx, db.UpsertCredentialParams{
+		ID:        uuid.New().String(),
+		SessionID: sql.NullString{String: params.SessionID, Valid: params.SessionID != ""},
+		Ref:       params.Ref,
+		Kind:      string(params.Kind),
+		Username:  params.Username,
+		Target:    params.Target,
+		Secret:    sealed,
+		Notes:     params.Notes,
+	})
+	if err != nil {
+		return Credential{}, err
+	}
+	redact.Add(params.Ref, params.Secret)
+	credential := s.fromDBItem(item)
+	s.Publish(pubsub.CreatedEvent, credential)
+	return credential, nil
+}
+
+func (s *service) Get(ctx context.Context, ref string) (Credential, error) {
+	item, err := s.q.GetCredentialByRef(ctx, ref)
+	if err != nil {
+		return Credential{}, err
+	}
+	return s.fromDBItem(item), nil
+}
+
+func (s *service) List(ctx context.Context) ([]Credential, error) {
+	items, err := s.q.ListCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]Credential, len(items))
+	for i, item := range items {
+		credentials[i] = s.fromDBItem(item)
+	}
+	return credentials, nil
+}
+
+func (s *service) Reveal(ctx context.Context, ref string) (string, error) {
+	item, err := s.q.GetCredentialByRef(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return s.open(item.Secret)
+}
+
+// seal encrypts the secret, prefixed with its nonce.
+func (s *service) seal(secret string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, []byte(secret), nil), nil
+}
+
+func (s *service) open(sealed []byte) (string, error) {
+	if len(sealed) < s.aead.NonceSize() {
+		return "", fmt.Errorf("invalid secret")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("wrong vault key or corrupted secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+func (s *service) fromDBItem(item db.Credential) Credential {
+	return Credential{
+		ID:          item.ID,
+		SessionID:   item.SessionID.String,
+		Ref:         item.Ref,
+		Kind:        Kind(item.Kind),
+		Username:    item.Username,
+		Target:      item.Target,
+		Notes:       item.Notes,
+		Placeholder: redact.Placeholder(item.Ref),
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
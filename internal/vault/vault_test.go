@@ -0,0 +1,230 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yaydraco/tandem/internal/db"
+	"github.com/yaydraco/tandem/internal/encryption"
+)
+
+// fakeQuerier keeps the credentials in memory, the vault using no other query.
+type fakeQuerier struct {
+	db.Querier
+	credentials map[string]db.Credential
+}
+
+func newFakeQuerier() *fakeQuerier {
+	return &fakeQuerier{credentials: make(map[string]db.Credential)}
+}
+
+func (q *fakeQuerier) UpsertCredential(ctx context.Context, arg db.UpsertCredentialParams) (db.Credential, error) {
+	item := db.Credential{
+		ID:        arg.ID,
+		SessionID: arg.SessionID,
+		Ref:       arg.Ref,
+		Kind:      arg.Kind,
+		Username:  arg.Username,
+		Target:    arg.Target,
+		Secret:    arg.Secret,
+		Notes:     arg.Notes,
+	}
+	q.credentials[arg.Ref] = item
+	return item, nil
+}
+
+func (q *fakeQuerier) GetCredentialByRef(ctx context.Context, ref string) (db.Credential, error) {
+	item, ok := q.credentials[ref]
+	if !ok {
+		return db.Credential{}, sql.ErrNoRows
+	}
+	return item, nil
+}
+
+func (q *fakeQuerier) ListCredentials(ctx context.Context) ([]db.Credential, error) {
+	items := make([]db.Credential, 0, len(q.credentials))
+	for _, item := range q.credentials {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func newTestService(t *testing.T, q db.Querier, key []byte) Service {
+	t.Helper()
+	s, err := NewService(q, key)
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	return s
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{1}, 32)
+
+	tests := []struct {
+		name string
+		// tamper alters the stored credential, before it's revealed.
+		tamper func(item *db.Credential)
+		// key is the key the credential is revealed with, the one it was stored with if nil.
+		key       []byte
+		expectErr bool
+	}{
+		{
+			name: "round trip",
+		},
+		{
+			name:      "tampered secret",
+			tamper:    func(item *db.Credential) { item.Secret[len(item.Secret)-1] ^= 1 },
+			expectErr: true,
+		},
+		{
+			name:      "truncated secret",
+			tamper:    func(item *db.Credential) { item.Secret = item.Secret[:4] },
+			expectErr: true,
+		},
+		{
+			name:      "wrong key",
+			key:       bytes.Repeat([]byte{2}, 32),
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newFakeQuerier()
+			credential, err := newTestService(t, q, key).Store(ctx, StoreParams{
+				Ref:    "ftp-admin",
+				Kind:   KindPassword,
+				Secret: "s3cr3t-passw0rd",
+			})
+			if err != nil {
+				t.Fatalf("Store() failed: %v", err)
+			}
+			if credential.Placeholder != "{{vault:ftp-admin}}" {
+				t.Errorf("Placeholder = %q", credential.Placeholder)
+			}
+			if bytes.Contains(q.credentials["ftp-admin"].Secret, []byte("s3cr3t-passw0rd")) {
+				t.Fatalf("the secret is stored in plaintext")
+			}
+			if tt.tamper != nil {
+				item := q.credentials["ftp-admin"]
+				tt.tamper(&item)
+				q.credentials["ftp-admin"] = item
+			}
+			revealKey := key
+			if tt.key != nil {
+				revealKey = tt.key
+			}
+			secret, err := newTestService(t, q, revealKey).Reveal(ctx, "ftp-admin")
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Reveal() = %q, expected an error", secret)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reveal() failed: %v", err)
+			}
+			if secret != "s3cr3t-passw0rd" {
+				t.Errorf("Reveal() = %q, expected the secret stored", secret)
+			}
+		})
+	}
+}
+
+func TestVaultStoreInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		params StoreParams
+	}{
+		{name: "invalid reference", params: StoreParams{Ref: "ftp admin", Kind: KindPassword, Secret: "s3cr3t-passw0rd"}},
+		{name: "invalid kind", params: StoreParams{Ref: "ftp-admin", Kind: "pin", Secret: "s3cr3t-passw0rd"}},
+		{name: "empty secret", params: StoreParams{Ref: "ftp-admin", Kind: KindPassword}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, newFakeQuerier(), bytes.Repeat([]byte{1}, 32))
+			if _, err := s.Store(context.Background(), tt.params); err == nil {
+				t.Errorf("Store() succeeded, expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dataDir := t.TempDir()
+	key, err := LoadKey(dataDir)
+	if err != nil {
+		t.Fatalf("LoadKey() failed: %v", err)
+	}
+	reloaded, err := LoadKey(dataDir)
+	if err != nil {
+		t.Fatalf("LoadKey() failed: %v", err)
+	}
+	if !bytes.Equal(key, reloaded) {
+		t.Errorf("LoadKey() generated another key instead of reading the first one")
+	}
+
+	if err := os.WriteFile(filepath.Join(dataDir, KeyFile), []byte("short"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKey(dataDir); err == nil {
+		t.Errorf("LoadKey() succeeded with an invalid key")
+	}
+}
+
+func TestLoadKeyEncrypted(t *testing.T) {
+	_, dataKey, err := encryption.NewParams("passphrase")
+	if err != nil {
+		t.Fatalf("NewParams() failed: %v", err)
+	}
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, KeyFile)
+
+	// The key generated before the data directory was encrypted is encrypted once it is.
+	key, err := LoadKey(dataDir)
+	if err != nil {
+		t.Fatalf("LoadKey() failed: %v", err)
+	}
+	encryption.Activate(dataKey)
+	t.Cleanup(func() { encryption.Activate(nil) })
+	reloaded, err := LoadKey(dataDir)
+	if err != nil {
+		t.Fatalf("LoadKey() failed: %v", err)
+	}
+	if !bytes.Equal(key, reloaded) {
+		t.Errorf("LoadKey() returned another key once the data directory is encrypted")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encryption.IsEncrypted(content) {
+		t.Errorf("the key isn't encrypted along with the data directory")
+	}
+
+	// The key is generated encrypted.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if key, err = LoadKey(dataDir); err != nil {
+		t.Fatalf("LoadKey() failed: %v", err)
+	}
+	if content, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !encryption.IsEncrypted(content) || bytes.Contains(content, key) {
+		t.Errorf("the key is generated in plaintext while the data directory is encrypted")
+	}
+
+	// The key can't be read without the key of the data directory.
+	encryption.Activate(nil)
+	if _, err := LoadKey(dataDir); !errors.Is(err, encryption.ErrLocked) {
+		t.Errorf("LoadKey() error = %v, expected %v", err, encryption.ErrLocked)
+	}
+}
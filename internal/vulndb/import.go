@@ -0,0 +1,331 @@
+package vulndb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/db"
+)
+
+var cvePattern = regexp.MustCompile(`^CVE-\d{4}-\d+$`)
+
+// nvdVulnerability is the part of a vulnerability of the NVD JSON 2.0 feeds the CVEs are imported from.
+type nvdVulnerability struct {
+	CVE struct {
+		ID           string `json:"id"`
+		Published    string `json:"published"`
+		Descriptions []struct {
+			Lang  string `json:"lang"`
+			Value string `json:"value"`
+		} `json:"descriptions"`
+		Metrics struct {
+			CVSSV40 []nvdMetric `json:"cvssMetricV40"`
+			CVSSV31 []nvdMetric `json:"cvssMetricV31"`
+			CVSSV30 []nvdMetric `json:"cvssMetricV30"`
+			CVSSV2  []nvdMetric `json:"cvssMetricV2"`
+		} `json:"metrics"`
+		Configurations []struct {
+			Nodes []struct {
+				CPEMatch []struct {
+					Vulnerable            bool   `json:"vulnerable"`
+					Criteria              string `json:"criteria"`
+					VersionStartIncluding string `json:"versionStartIncluding"`
+					VersionStartExcluding string `json:"versionStartExcluding"`
+					VersionEndIncluding   string `json:"versionEndIncluding"`
+					VersionEndExcluding   string `json:"versionEndExcluding"`
+				} `json:"cpeMatch"`
+			} `json:"nodes"`
+		} `json:"configurations"`
+	} `json:"cve"`
+}
+
+type nvdMetric struct {
+	Type     string `json:"type"`
+	CVSSData struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+		VectorString string  `json:"vectorString"`
+	} `json:"cvssData"`
+	// BaseSeverity is out of the data of the CVSS v2 metrics.
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+// kevCatalog is the CISA catalog of known exploited vulnerabilities.
+type kevCatalog struct {
+	Vulnerabilities []struct {
+		CVEID                      string `json:"cveID"`
+		VendorProject              string `json:"vendorProject"`
+		Product                    string `json:"product"`
+		VulnerabilityName          string `json:"vulnerabilityName"`
+		DateAdded                  string `json:"dateAdded"`
+		ShortDescription           string `json:"shortDescription"`
+		RequiredAction             string `json:"requiredAction"`
+		KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+	} `json:"vulnerabilities"`
+}
+
+// ImportNVD imports the CVEs of an NVD JSON 2.0 feed, e.g. nvdcve-2.0-2024.json, gzipped or not,
+// replacing the ones imported before. It returns the count of CVEs imported.
+func ImportNVD(ctx context.Context, conn *sql.DB, r io.Reader) (int, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return 0, err
+	}
+	dec := json.NewDecoder(r)
+	// NOTE: the feeds are streamed through, the ones of the latest years being hundreds of megabytes.
+	if err := seekArray(dec, "vulnerabilities"); err != nil {
+		return 0, fmt.Errorf("invalid NVD feed: %w", err)
+	}
+	count := 0
+	err = inTx(ctx, conn, func(q *db.Queries) error {
+		for dec.More() {
+			var v nvdVulnerability
+			if err := dec.Decode(&v); err != nil {
+				return fmt.Errorf("invalid NVD feed: %w", err)
+			}
+			if err := importNVDVulnerability(ctx, q, v); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func importNVDVulnerability(ctx context.Context, q *db.Queries, v nvdVulnerability) error {
+	cve := v.CVE
+	params := db.UpsertVulnerabilityParams{
+		ID:          cve.ID,
+		PublishedAt: cve.Published,
+	}
+	for _, d := range cve.Descriptions {
+		if d.Lang == "en" {
+			params.Description = d.Value
+			break
+		}
+	}
+	for _, metrics := range [][]nvdMetric{cve.Metrics.CVSSV40, cve.Metrics.CVSSV31, cve.Metrics.CVSSV30, cve.Metrics.CVSSV2} {
+		if len(metrics) == 0 {
+			continue
+		}
+		// NOTE: the score of the NVD itself is preferred over the ones of the CNAs.
+		metric := metrics[0]
+		for _, m := range metrics {
+			if m.Type == "Primary" {
+				metric = m
+				break
+			}
+		}
+		params.CvssScore = sql.NullFloat64{Float64: metric.CVSSData.BaseScore, Valid: true}
+		params.CvssSeverity = strings.ToLower(metric.CVSSData.BaseSeverity)
+		if params.CvssSeverity == "" {
+			params.CvssSeverity = strings.ToLower(metric.BaseSeverity)
+		}
+		params.CvssVector = metric.CVSSData.VectorString
+		break
+	}
+	if err := q.UpsertVulnerability(ctx, params); err != nil {
+		return fmt.Errorf("failed to import %s: %w", cve.ID, err)
+	}
+
+	if err := q.DeleteVulnerabilityCPEs(ctx, cve.ID); err != nil {
+		return fmt.Errorf("failed to import %s: %w", cve.ID, err)
+	}
+	for _, config := range cve.Configurations {
+		for _, node := range config.Nodes {
+			for _, match := range node.CPEMatch {
+				// NOTE: the matches which aren't vulnerable are the platforms the vulnerable ones run on.
+				if !match.Vulnerable {
+					continue
+				}
+				// cpe:2.3:part:vendor:product:version:...
+				parts := strings.Split(match.Criteria, ":")
+				if len(parts) < 6 {
+					continue
+				}
+				if err := q.CreateVulnerabilityCPE(ctx, db.CreateVulnerabilityCPEParams{
+					CveID:                 cve.ID,
+					Vendor:                parts[3],
+					Product:               parts[4],
+					Version:               parts[5],
+					VersionStartIncluding: match.VersionStartIncluding,
+					VersionStartExcluding: match.VersionStartExcluding,
+					VersionEndIncluding:   match.VersionEndIncluding,
+					VersionEndExcluding:   match.VersionEndExcluding,
+				}); err != nil {
+					return fmt.Errorf("failed to import %s: %w", cve.ID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ImportKEV imports the CISA catalog of known exploited vulnerabilities, known_exploited_vulnerabilities.json.
+// It returns the count of CVEs imported.
+func ImportKEV(ctx context.Context, conn *sql.DB, r io.Reader) (int, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return 0, err
+	}
+	var catalog kevCatalog
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return 0, fmt.Errorf("invalid KEV catalog: %w", err)
+	}
+	err = inTx(ctx, conn, func(q *db.Queries) error {
+		for _, v := range catalog.Vulnerabilities {
+			if err := q.UpsertKnownExploitedVulnerability(ctx, db.UpsertKnownExploitedVulnerabilityParams{
+				CveID:          v.CVEID,
+				Vendor:         v.VendorProject,
+				Product:        v.Product,
+				Name:           v.VulnerabilityName,
+				Description:    v.ShortDescription,
+				RequiredAction: v.RequiredAction,
+				Ransomware:     v.KnownRansomwareCampaignUse,
+				DateAdded:      v.DateAdded,
+			}); err != nil {
+				return fmt.Errorf("failed to import %s: %w", v.CVEID, err)
+			}
+		}
+		return nil
+	})
+	return len(catalog.Vulnerabilities), err
+}
+
+// ImportExploitDB imports the exploits of the index of Exploit-DB, files_exploits.csv, as found along with
+// searchsploit, e.g. in /usr/share/exploitdb of Kali. It returns the count of exploits imported.
+func ImportExploitDB(ctx context.Context, conn *sql.DB, r io.Reader) (int, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return 0, err
+	}
+	records := csv.NewReader(r)
+	records.FieldsPerRecord = -1
+	header, err := records.Read()
+	if err != nil {
+		return 0, fmt.Errorf("invalid Exploit-DB index: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"id", "file", "description"} {
+		if _, ok := columns[name]; !ok {
+			return 0, fmt.Errorf("invalid Exploit-DB index: no %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	count := 0
+	err = inTx(ctx, conn, func(q *db.Queries) error {
+		for {
+			record, err := records.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid Exploit-DB index: %w", err)
+			}
+			id := field(record, "id")
+			var port sql.NullInt64
+			if p, err := strconv.ParseInt(field(record, "port"), 10, 64); err == nil && p > 0 {
+				port = sql.NullInt64{Int64: p, Valid: true}
+			}
+			codes := field(record, "codes")
+			if err := q.UpsertExploit(ctx, db.UpsertExploitParams{
+				ID:          id,
+				Description: field(record, "description"),
+				Path:        field(record, "file"),
+				Type:        field(record, "type"),
+				Platform:    field(record, "platform"),
+				Port:        port,
+				Verified:    field(record, "verified") == "1",
+				Codes:       codes,
+				PublishedAt: field(record, "date_published"),
+			}); err != nil {
+				return fmt.Errorf("failed to import the exploit %s: %w", id, err)
+			}
+			if err := q.DeleteExploitVulnerabilities(ctx, id); err != nil {
+				return fmt.Errorf("failed to import the exploit %s: %w", id, err)
+			}
+			for _, code := range strings.Split(codes, ";") {
+				code = strings.TrimSpace(code)
+				if !cvePattern.MatchString(code) {
+					continue
+				}
+				if err := q.CreateExploitVulnerability(ctx, db.CreateExploitVulnerabilityParams{
+					ExploitID: id,
+					CveID:     code,
+				}); err != nil {
+					return fmt.Errorf("failed to import the exploit %s: %w", id, err)
+				}
+			}
+			count++
+		}
+	})
+	return count, err
+}
+
+// inTx runs the import in a transaction, thousands of rows being written at once.
+func inTx(ctx context.Context, conn *sql.DB, fn func(q *db.Queries) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(db.New(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// decompress transparently gunzips the gzipped feeds.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// seekArray moves the decoder to the first item of the array of the top level key.
+func seekArray(dec *json.Decoder, key string) error {
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return fmt.Errorf("expected an object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == key {
+			if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+				return fmt.Errorf("expected %s to be an array", key)
+			}
+			return nil
+		}
+		// NOTE: the value of another key is skipped over.
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("no %s", key)
+}
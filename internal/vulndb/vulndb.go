@@ -0,0 +1,380 @@
+package vulndb
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yaydraco/tandem/internal/db"
+)
+
+const (
+	maxLookupVulnerabilities = 50
+	maxLookupExploits        = 30
+)
+
+// productAliases maps the products as nmap names them to their CPE names, when stripping the vendor doesn't do.
+var productAliases = map[string][]string{
+	"apache_httpd":        {"http_server"},
+	"microsoft_iis_httpd": {"internet_information_services"},
+	"postgresql_db":       {"postgresql"},
+	"samba_smbd":          {"samba"},
+	"exim_smtpd":          {"exim"},
+	"postfix_smtpd":       {"postfix"},
+}
+
+var (
+	versionTokenPattern = regexp.MustCompile(`[0-9]+|[A-Za-z]+`)
+	// preReleaseTokens are the tokens of the versions released before the one they suffix, e.g. 1.0rc1 < 1.0.
+	preReleaseTokens = []string{"a", "alpha", "b", "beta", "dev", "pre", "rc"}
+)
+
+// Vulnerability is a CVE along with what's known of its exploitation.
+type Vulnerability struct {
+	ID          string
+	Description string
+	// CVSSScore is the base score of the latest CVSS version the CVE was scored with, 0 if it wasn't yet.
+	CVSSScore    float64
+	CVSSSeverity string
+	CVSSVector   string
+	PublishedAt  string
+	// KnownExploited is set for the CVEs of the CISA KEV catalog.
+	KnownExploited *KnownExploited
+	Exploits       []Exploit
+}
+
+type KnownExploited struct {
+	Name           string
+	RequiredAction string
+	Ransomware     string
+	DateAdded      string
+}
+
+// Exploit is an exploit of Exploit-DB.
+type Exploit struct {
+	ID          string
+	Description string
+	Path        string
+	Type        string
+	Platform    string
+	Verified    bool
+	Codes       []string
+	PublishedAt string
+}
+
+type LookupParams struct {
+	Vendor  string
+	Product string
+	// Version is left empty to look up the vulnerabilities of every version.
+	Version string
+}
+
+type LookupResult struct {
+	Vulnerabilities []Vulnerability
+	// Exploits are the exploits the titles of which mention the product, and its version if any,
+	// Exploit-DB listing many without a CVE.
+	Exploits []Exploit
+}
+
+type Service interface {
+	Lookup(ctx context.Context, params LookupParams) (LookupResult, error)
+	Get(ctx context.Context, id string) (Vulnerability, error)
+}
+
+type service struct {
+	q db.Querier
+}
+
+func NewService(q db.Querier) Service {
+	return &service{q: q}
+}
+
+// candidate is a CVE matching a lookup, along with what it's ranked by.
+type candidate struct {
+	id             string
+	knownExploited bool
+	exploited      bool
+	score          float64
+}
+
+func (s *service) Lookup(ctx context.Context, params LookupParams) (LookupResult, error) {
+	var result LookupResult
+	vendor := normalizeName(params.Vendor)
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, product := range productCandidates(params.Product) {
+		cpes, err := s.q.ListVulnerabilityCPEsByProduct(ctx, product)
+		if err != nil {
+			return LookupResult{}, err
+		}
+		for _, cpe := range cpes {
+			if seen[cpe.CveID] || (vendor != "" && cpe.Vendor != vendor) || !matchVersion(cpe, params.Version) {
+				continue
+			}
+			seen[cpe.CveID] = true
+			candidates = append(candidates, candidate{
+				id:             cpe.CveID,
+				knownExploited: cpe.KnownExploited != 0,
+				exploited:      cpe.Exploited != 0,
+				score:          cpe.CvssScore,
+			})
+		}
+	}
+	// NOTE: the CVEs of the KEV catalog may not have been imported from the NVD, thus they're matched by
+	// the product named in the catalog too, when no version narrows them down.
+	if params.Version == "" {
+		kevs, err := s.q.ListKnownExploitedVulnerabilitiesByProduct(ctx, "%"+strings.TrimSpace(params.Product)+"%")
+		if err != nil {
+			return LookupResult{}, err
+		}
+		for _, kev := range kevs {
+			if seen[kev.CveID] {
+				continue
+			}
+			seen[kev.CveID] = true
+			candidates = append(candidates, candidate{
+				id:             kev.CveID,
+				knownExploited: true,
+				exploited:      kev.Exploited != 0,
+				score:          kev.CvssScore,
+			})
+		}
+	}
+	// The CVEs are ranked before being read, only the ones returned being read in full.
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if a.knownExploited != b.knownExploited {
+			if a.knownExploited {
+				return -1
+			}
+			return 1
+		}
+		if a.exploited != b.exploited {
+			if a.exploited {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.score, a.score)
+	})
+	if len(candidates) > maxLookupVulnerabilities {
+		candidates = candidates[:maxLookupVulnerabilities]
+	}
+	for _, c := range candidates {
+		v, err := s.Get(ctx, c.id)
+		if err != nil {
+			return LookupResult{}, err
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, v)
+	}
+
+	exploits, err := s.searchExploits(ctx, params)
+	if err != nil {
+		return LookupResult{}, err
+	}
+	for _, e := range exploits {
+		linked := false
+		for _, v := range result.Vulnerabilities {
+			if slices.ContainsFunc(v.Exploits, func(other Exploit) bool { return other.ID == e.ID }) {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			result.Exploits = append(result.Exploits, e)
+		}
+	}
+	return result, nil
+}
+
+// searchExploits searches the titles of the exploits, the way searchsploit does.
+func (s *service) searchExploits(ctx context.Context, params LookupParams) ([]Exploit, error) {
+	product := strings.TrimSpace(params.Product)
+	if product == "" {
+		return nil, nil
+	}
+	pattern := "%" + product + "%"
+	if params.Version != "" {
+		pattern += params.Version + "%"
+	}
+	items, err := s.q.SearchExploits(ctx, db.SearchExploitsParams{
+		Description: pattern,
+		Limit:       maxLookupExploits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	exploits := make([]Exploit, len(items))
+	for i, item := range items {
+		exploits[i] = exploitFromDBItem(item)
+	}
+	return exploits, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (Vulnerability, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	item, err := s.q.GetVulnerability(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// NOTE: a CVE of the KEV catalog or Exploit-DB may not have been imported from the NVD.
+		item = db.Vulnerability{ID: id}
+	} else if err != nil {
+		return Vulnerability{}, err
+	}
+	v := Vulnerability{
+		ID:           item.ID,
+		Description:  item.Description,
+		CVSSScore:    item.CvssScore.Float64,
+		CVSSSeverity: item.CvssSeverity,
+		CVSSVector:   item.CvssVector,
+		PublishedAt:  item.PublishedAt,
+	}
+
+	kev, err := s.q.GetKnownExploitedVulnerability(ctx, id)
+	switch {
+	case err == nil:
+		v.KnownExploited = &KnownExploited{
+			Name:           kev.Name,
+			RequiredAction: kev.RequiredAction,
+			Ransomware:     kev.Ransomware,
+			DateAdded:      kev.DateAdded,
+		}
+		if v.Description == "" {
+			v.Description = kev.Description
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return Vulnerability{}, err
+	}
+
+	links, err := s.q.ListExploitVulnerabilitiesByCVE(ctx, id)
+	if err != nil {
+		return Vulnerability{}, err
+	}
+	for _, link := range links {
+		exploit, err := s.q.GetExploit(ctx, link.ExploitID)
+		if err != nil {
+			return Vulnerability{}, err
+		}
+		v.Exploits = append(v.Exploits, exploitFromDBItem(exploit))
+	}
+	if v.Description == "" && v.KnownExploited == nil && len(v.Exploits) == 0 {
+		return Vulnerability{}, sql.ErrNoRows
+	}
+	return v, nil
+}
+
+func exploitFromDBItem(item db.Exploit) Exploit {
+	var codes []string
+	if item.Codes != "" {
+		codes = strings.Split(item.Codes, ";")
+	}
+	return Exploit{
+		ID:          item.ID,
+		Description: item.Description,
+		Path:        item.Path,
+		Type:        item.Type,
+		Platform:    item.Platform,
+		Verified:    item.Verified,
+		Codes:       codes,
+		PublishedAt: item.PublishedAt,
+	}
+}
+
+// normalizeName turns a vendor or product name into its CPE form, e.g. Apache Tomcat into apache_tomcat.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "_")
+}
+
+// productCandidates returns the CPE names the product may go by: the product itself, its aliases,
+// and the product without its vendor prefix, e.g. tomcat for apache_tomcat.
+func productCandidates(product string) []string {
+	name := normalizeName(product)
+	if name == "" {
+		return nil
+	}
+	candidates := []string{name}
+	candidates = append(candidates, productAliases[name]...)
+	if _, rest, ok := strings.Cut(name, "_"); ok {
+		candidates = append(candidates, rest)
+	}
+	return slices.Compact(candidates)
+}
+
+// matchVersion reports whether the version is one of the versions of the CPE match, any version matching if empty.
+func matchVersion(cpe db.ListVulnerabilityCPEsByProductRow, version string) bool {
+	if version == "" {
+		return true
+	}
+	if cpe.Version != "*" && cpe.Version != "-" && cpe.Version != "" {
+		return CompareVersions(cpe.Version, version) == 0
+	}
+	if cpe.VersionStartIncluding != "" && CompareVersions(version, cpe.VersionStartIncluding) < 0 {
+		return false
+	}
+	if cpe.VersionStartExcluding != "" && CompareVersions(version, cpe.VersionStartExcluding) <= 0 {
+		return false
+	}
+	if cpe.VersionEndIncluding != "" && CompareVersions(version, cpe.VersionEndIncluding) > 0 {
+		return false
+	}
+	if cpe.VersionEndExcluding != "" && CompareVersions(version, cpe.VersionEndExcluding) >= 0 {
+		return false
+	}
+	// NOTE: a CPE match of any version without a range, e.g. of a product no longer maintained, matches every version.
+	return true
+}
+
+// CompareVersions compares the versions token by token, the numbers numerically and the letters alphabetically,
+// e.g. 2.4.9 < 2.4.49, 7.4p1 < 7.4p2, 1.0rc1 < 1.0 and 1.0 = 1.0.0.
+func CompareVersions(a, b string) int {
+	as := versionTokenPattern.FindAllString(strings.ToLower(a), -1)
+	bs := versionTokenPattern.FindAllString(strings.ToLower(b), -1)
+	for i := range max(len(as), len(bs)) {
+		// NOTE: a missing trailing number is taken for a 0, e.g. 1.0 = 1.0.0.
+		if i >= len(as) {
+			if slices.Contains(preReleaseTokens, bs[i]) {
+				return 1
+			}
+			if isZero(bs[i]) {
+				continue
+			}
+			return -1
+		}
+		if i >= len(bs) {
+			if slices.Contains(preReleaseTokens, as[i]) {
+				return -1
+			}
+			if isZero(as[i]) {
+				continue
+			}
+			return 1
+		}
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			// NOTE: a number is taken for a later version than letters, e.g. 1.0.1 > 1.0rc1.
+			c = 1
+		case bErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// isZero reports whether the version token is a number equal to 0.
+func isZero(token string) bool {
+	n, err := strconv.ParseUint(token, 10, 64)
+	return err == nil && n == 0
+}
@@ -20,9 +20,14 @@ pkgs.dockerTools.buildLayeredImage {
     iproute2
     nmap
     dirb
+    ffuf
+    seclists
     metasploit
+    exploitdb
+    curl
     nano
     nettools2
     nikto
+    nuclei
   ];
 }